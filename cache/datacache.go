@@ -30,6 +30,11 @@ func (c *DataCache) WithNamespace(namespace string) *DataCache {
 	}
 }
 
+// Namespace returns the prefix of the keys of this cache.
+func (c *DataCache) Namespace() string {
+	return c.namespace
+}
+
 func (c *DataCache) WithHandlers(handlers ...HandlerInterface) *DataCache {
 	return &DataCache{
 		namespace: c.namespace,
@@ -46,11 +46,22 @@ func awsEvents() error {
 	domain := getEnv("EVENT_DOMAIN", "")
 	sourceIP := getEnv("EVENT_SOURCE_IP", "")
 	filterOutSourceIP := getEnv("EVENT_SOURCE_IP_NOT", "")
-	startTime := time.Now()
-	endTime := time.Now().Add(-24 * time.Hour)
+	sourceCIDR := getEnv("EVENT_SOURCE_CIDR", "")
+	userAgent := getEnv("EVENT_USER_AGENT", "")
+	errorCode := getEnv("EVENT_ERROR_CODE", "")
+	startTime := time.Now().Add(-24 * time.Hour)
+	endTime := time.Now()
 	readonly := getEnv("EVENT_READONLY", "")
 	limit, _ := strconv.Atoi(getEnv("EVENT_LIMIT", "20"))
 
+	// event source: "lookup" (LookupEvents), "lake" (EVENT_DATA_STORE),
+	// "s3" (TRAIL_BUCKET, TRAIL_PREFIX) or "dir" (TRAIL_DIR, TRAIL_PREFIX)
+	sourceType := getEnv("EVENT_SOURCE", "lookup")
+	eventDataStore := getEnv("EVENT_DATA_STORE", "")
+	trailBucket := getEnv("TRAIL_BUCKET", "")
+	trailDir := getEnv("TRAIL_DIR", "")
+	trailPrefix := getEnv("TRAIL_PREFIX", "AWSLogs/")
+
 	//// Example of cached resources
 	//cacheTtl := 86400 * time.Second
 	//dataCache, err := getCache(ctx, cacheTtl)
@@ -84,6 +95,35 @@ func awsEvents() error {
 		lookup = lookup.WithReadOnly(readonly)
 	}
 
+	// client side filters, checked on the events returned
+	if domain != "" {
+		lookup = lookup.WithPredicate("domain:"+domain, func(e cloudtrail.Event) bool {
+			return strings.Contains(e.GetUsername(), domain)
+		})
+	}
+
+	if sourceIP != "" {
+		lookup = lookup.WithSourceIP(sourceIP)
+	}
+
+	if filterOutSourceIP != "" {
+		lookup = lookup.WithPredicate("not-sourceIP:"+filterOutSourceIP, func(e cloudtrail.Event) bool {
+			return e.GetSourceIPAddress() != filterOutSourceIP
+		})
+	}
+
+	if sourceCIDR != "" {
+		lookup = lookup.WithSourceCIDR(sourceCIDR)
+	}
+
+	if userAgent != "" {
+		lookup = lookup.WithUserAgent(userAgent)
+	}
+
+	if errorCode != "" {
+		lookup = lookup.WithErrorCode(errorCode)
+	}
+
 	if errs, ok := lookup.Errors(); !ok {
 		return slice.Head(errs).OrEmpty()
 	}
@@ -107,9 +147,22 @@ func awsEvents() error {
 		//	NewCloudTrailRepository(ctx2, client).
 		//	ListEventsByLookupCached(dataCache, lookup)
 
-		events, errChan := cloudtrail.
-			NewCloudTrailRepository(ctx2, client).
-			ListEventsByLookupAsync(lookup)
+		var source cloudtrail.EventSource
+		switch sourceType {
+		case "lake":
+			source = cloudtrail.NewLakeRepository(ctx2, client, eventDataStore).
+				WithScope(client.GetAccountID(), client.GetRegion())
+		case "s3":
+			source = cloudtrail.NewTrailLogSource(ctx2, cloudtrail.NewS3LogStore(client, trailBucket), trailPrefix).
+				WithScope(client.GetAccountID(), client.GetRegion())
+		case "dir":
+			source = cloudtrail.NewTrailLogSource(ctx2, cloudtrail.NewDirLogStore(trailDir), trailPrefix).
+				WithScope(client.GetAccountID(), client.GetRegion())
+		default:
+			source = cloudtrail.NewCloudTrailRepository(ctx2, client)
+		}
+
+		events, errChan := source.ListEventsByLookupAsync(lookup)
 
 		service.CancelContextOnError(ctx2, cancel, errChan)
 
@@ -124,37 +177,13 @@ func awsEvents() error {
 			Str("sourceIP", sourceIP).
 			Str("readonly", readonly).
 			Str("event", eventName).
+			Str("source", sourceType).
 			Time("start", startTime).
 			Time("end", endTime).
 			Int("limit", limit).
 			Msg("CloudTrail events")
 
-		filteredOutCount := 0
 		for e := range events {
-			if readonly != "" && e.GetReadOnly() != readonly {
-				log.Trace().Msg("Skipped event due to readonly filter")
-				filteredOutCount++
-				continue
-			}
-
-			if domain != "" && !strings.Contains(e.GetUsername(), domain) {
-				log.Trace().Msg("Skipped event due to user email domain filter")
-				filteredOutCount++
-				continue
-			}
-
-			if sourceIP != "" && e.GetSourceIPAddress() != sourceIP {
-				log.Trace().Str(sourceIP, e.GetSourceIPAddress()).Msg("Skipped event due to source IP address filter")
-				filteredOutCount++
-				continue
-			}
-
-			if filterOutSourceIP != "" && e.GetSourceIPAddress() == filterOutSourceIP {
-				log.Trace().Str("sourceIpAddress", e.GetSourceIPAddress()).Msg("Skipped event due to source IP address filter out")
-				filteredOutCount++
-				continue
-			}
-
 			log.Info().
 				Str("id", e.GetId()).
 				Str("name", e.GetName()).
@@ -165,8 +194,6 @@ func awsEvents() error {
 				Time("createAt", e.GetCreatedAt()).
 				Msg("cloudtrail event")
 		}
-
-		log.Debug().Int("filteredOut", filteredOutCount).Msg("Filtered out events")
 	}
 
 	return nil
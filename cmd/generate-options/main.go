@@ -54,61 +54,62 @@ func GetClient(client *v3.Client, optFns ...func(*{{.PackageName}}.Options)) *{{
 func titleCase(s string) string {
 	// Special cases for acronyms
 	acronyms := map[string]string{
-		"ec2":                    "EC2",
-		"s3":                     "S3",
-		"s3control":              "S3Control",
-		"s3outposts":             "S3Outposts",
-		"rds":                    "RDS",
-		"iam":                    "IAM",
-		"sns":                    "SNS",
-		"sqs":                    "SQS",
-		"acm":                    "ACM",
-		"eks":                    "EKS",
-		"ecs":                    "ECS",
-		"efs":                    "EFS",
-		"emr":                    "EMR",
-		"ssm":                    "SSM",
-		"sts":                    "STS",
-		"waf":                    "WAF",
-		"wafv2":                  "WAFv2",
-		"wafregional":            "WAFRegional",
-		"dynamodb":               "DynamoDB",
-		"cloudfront":             "CloudFront",
-		"cloudwatch":             "CloudWatch",
-		"cloudwatchlogs":         "CloudWatchLogs",
-		"cloudtrail":             "CloudTrail",
-		"cloudformation":         "CloudFormation",
-		"cloudcontrol":           "CloudControl",
-		"apigateway":             "APIGateway",
-		"elasticache":            "ElastiCache",
-		"elasticloadbalancingv2": "ElasticLoadBalancingV2",
-		"emrserverless":          "EMRServerless",
-		"glue":                   "Glue",
-		"lambda":                 "Lambda",
-		"athena":                 "Athena",
-		"autoscaling":            "AutoScaling",
-		"batch":                  "Batch",
-		"costexplorer":           "CostExplorer",
-		"health":                 "Health",
-		"pricing":                "Pricing",
-		"route53":                "Route53",
-		"secretsmanager":         "SecretsManager",
-		"securityhub":            "SecurityHub",
-		"servicecatalog":         "ServiceCatalog",
-		"servicediscovery":       "ServiceDiscovery",
-		"servicequotas":          "ServiceQuotas",
-		"ses":                    "SES",
-		"sfn":                    "StepFunctions",
-		"shield":                 "Shield",
-		"signer":                 "Signer",
-		"storagegateway":         "StorageGateway",
-		"swf":                    "SWF",
-		"synthetics":             "Synthetics",
-		"timestreamwrite":        "TimestreamWrite",
-		"transfer":               "Transfer",
-		"accessanalyzer":         "AccessAnalyzer",
-		"savingsplans":           "SavingsPlans",
-		"configservice":          "ConfigService",
+		"ec2":                      "EC2",
+		"s3":                       "S3",
+		"s3control":                "S3Control",
+		"s3outposts":               "S3Outposts",
+		"rds":                      "RDS",
+		"iam":                      "IAM",
+		"sns":                      "SNS",
+		"sqs":                      "SQS",
+		"acm":                      "ACM",
+		"eks":                      "EKS",
+		"ecs":                      "ECS",
+		"efs":                      "EFS",
+		"emr":                      "EMR",
+		"ssm":                      "SSM",
+		"sts":                      "STS",
+		"waf":                      "WAF",
+		"wafv2":                    "WAFv2",
+		"wafregional":              "WAFRegional",
+		"dynamodb":                 "DynamoDB",
+		"cloudfront":               "CloudFront",
+		"cloudwatch":               "CloudWatch",
+		"cloudwatchlogs":           "CloudWatchLogs",
+		"cloudtrail":               "CloudTrail",
+		"cloudformation":           "CloudFormation",
+		"cloudcontrol":             "CloudControl",
+		"apigateway":               "APIGateway",
+		"elasticache":              "ElastiCache",
+		"elasticloadbalancingv2":   "ElasticLoadBalancingV2",
+		"emrserverless":            "EMRServerless",
+		"glue":                     "Glue",
+		"lambda":                   "Lambda",
+		"athena":                   "Athena",
+		"autoscaling":              "AutoScaling",
+		"batch":                    "Batch",
+		"costexplorer":             "CostExplorer",
+		"health":                   "Health",
+		"pricing":                  "Pricing",
+		"resourcegroupstaggingapi": "ResourceGroupsTaggingAPI",
+		"route53":                  "Route53",
+		"secretsmanager":           "SecretsManager",
+		"securityhub":              "SecurityHub",
+		"servicecatalog":           "ServiceCatalog",
+		"servicediscovery":         "ServiceDiscovery",
+		"servicequotas":            "ServiceQuotas",
+		"ses":                      "SES",
+		"sfn":                      "StepFunctions",
+		"shield":                   "Shield",
+		"signer":                   "Signer",
+		"storagegateway":           "StorageGateway",
+		"swf":                      "SWF",
+		"synthetics":               "Synthetics",
+		"timestreamwrite":          "TimestreamWrite",
+		"transfer":                 "Transfer",
+		"accessanalyzer":           "AccessAnalyzer",
+		"savingsplans":             "SavingsPlans",
+		"configservice":            "ConfigService",
 	}
 
 	if title, ok := acronyms[s]; ok {
@@ -153,6 +154,7 @@ var awsServices = []string{
 	"lambda",
 	"pricing",
 	"rds",
+	"resourcegroupstaggingapi",
 	"route53",
 	"s3",
 	"s3control",
@@ -37,6 +37,7 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/lambda v1.102.0
 	github.com/aws/aws-sdk-go-v2/service/pricing v1.44.7
 	github.com/aws/aws-sdk-go-v2/service/rds v1.124.4
+	github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi v1.36.2
 	github.com/aws/aws-sdk-go-v2/service/route53 v1.65.9
 	github.com/aws/aws-sdk-go-v2/service/route53domains v1.39.2
 	github.com/aws/aws-sdk-go-v2/service/s3 v1.107.3
@@ -69,6 +70,7 @@ require (
 	github.com/imunhatep/gocollection v0.2.1
 	github.com/prometheus/client_golang v1.24.1
 	github.com/rs/zerolog v1.35.1
+	github.com/shopspring/decimal v1.4.0
 	github.com/stretchr/testify v1.12.1
 	golang.org/x/sys v0.47.0
 )
@@ -17,10 +17,14 @@ var (
 	AwsPoolResourcePerRegionCount *prometheus.GaugeVec
 	AwsObserverExecutionCount     *prometheus.GaugeVec
 	AwsObserverResourceQueueFull  *prometheus.CounterVec
+	AwsObserverSweepFailures      *prometheus.GaugeVec
 	AwsResourceCacheRead          *prometheus.CounterVec
 	AwsResourceCacheWrite         *prometheus.CounterVec
 	AwsResourceCacheHit           *prometheus.CounterVec
 	AwsResourceCacheError         *prometheus.CounterVec
+	AwsCostExplorerRequests       *prometheus.CounterVec
+	AwsCostExplorerSpend          *prometheus.CounterVec
+	AwsCostExplorerBudgetRejected *prometheus.CounterVec
 )
 
 // InitMetrics initialize Prometheus metrics
@@ -91,6 +95,15 @@ func InitMetrics(subsystem string) {
 		[]string{"resource_type"},
 	)
 
+	AwsObserverSweepFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "resources_observer_sweep_failure_count",
+			Help:      "AWS proxies that could not be queried in the last observer sweep, by failure class",
+		},
+		[]string{"account_id", "region", "resource_type", "class"},
+	)
+
 	AwsResourceCacheRead = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Subsystem: subsystem,
@@ -127,18 +140,51 @@ func InitMetrics(subsystem string) {
 		[]string{"ns", "name", "store"},
 	)
 
+	AwsCostExplorerRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "costexplorer_billable_request_count",
+			Help:      "number of billable Cost Explorer api requests",
+		},
+		[]string{"account_id", "method"},
+	)
+
+	AwsCostExplorerSpend = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "costexplorer_estimated_spend_usd",
+			Help:      "estimated Cost Explorer api spend in USD",
+		},
+		[]string{"account_id", "method"},
+	)
+
+	AwsCostExplorerBudgetRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "costexplorer_budget_rejected_count",
+			Help:      "number of Cost Explorer api requests refused by the request budget",
+		},
+		[]string{"account_id", "method"},
+	)
+
 	// repository
 	prometheus.MustRegister(AwsApiRequests)
 	prometheus.MustRegister(AwsApiRequestErrors)
 	prometheus.MustRegister(AwsApiResourcesFetched)
 	prometheus.MustRegister(AwsRepoCallDuration)
 
+	// cost explorer billing
+	prometheus.MustRegister(AwsCostExplorerRequests)
+	prometheus.MustRegister(AwsCostExplorerSpend)
+	prometheus.MustRegister(AwsCostExplorerBudgetRejected)
+
 	// middleware/pool
 	prometheus.MustRegister(AwsPoolResourcePerRegionCount)
 
 	// observer
 	prometheus.MustRegister(AwsObserverExecutionCount)
 	prometheus.MustRegister(AwsObserverResourceQueueFull)
+	prometheus.MustRegister(AwsObserverSweepFailures)
 
 	// datacache
 	prometheus.MustRegister(AwsResourceCacheRead)
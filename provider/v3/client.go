@@ -65,6 +65,33 @@ func (c *Client) GetRegion() types.AwsRegion {
 	return c.region
 }
 
+// WithRegion returns a client of the same account and credentials for region.
+// Service clients are bound to the region of their config, so the regional
+// client keeps its own service cache; it is built once and reused.
+func (c *Client) WithRegion(region types.AwsRegion) *Client {
+	if region == c.region {
+		return c
+	}
+
+	key := "client:" + region.String()
+	if cached, ok := c.cache.Load(key); ok {
+		return cached.(*Client)
+	}
+
+	cfg := c.cfg.Copy()
+	cfg.Region = region.String()
+
+	regional := &Client{
+		callerIdentity: c.callerIdentity,
+		accountID:      c.accountID,
+		region:         region,
+		cfg:            cfg,
+	}
+
+	cached, _ := c.cache.LoadOrStore(key, regional)
+	return cached.(*Client)
+}
+
 // GetAccountID returns the AWS account ID
 func (c *Client) GetAccountID() types.AwsAccountID {
 	return c.accountID
@@ -0,0 +1,22 @@
+package v3
+
+import (
+	"testing"
+
+	"github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientWithRegionKeepsAccount(t *testing.T) {
+	client := &Client{accountID: "111111111111", region: "eu-west-1"}
+	client.cfg.Region = "eu-west-1"
+
+	regional := client.WithRegion(types.DefaultAwsRegion)
+	assert.Equal(t, types.AwsAccountID("111111111111"), regional.GetAccountID())
+	assert.Equal(t, types.DefaultAwsRegion, regional.GetRegion())
+	assert.Equal(t, "us-east-1", regional.Config().Region)
+	assert.Equal(t, "eu-west-1", client.Config().Region, "the original client keeps its region")
+
+	assert.Same(t, regional, client.WithRegion(types.DefaultAwsRegion), "built once")
+	assert.Same(t, client, client.WithRegion("eu-west-1"))
+}
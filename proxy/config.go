@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/imunhatep/awslib/cache"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+	"github.com/imunhatep/awslib/service/configservice"
+	"github.com/imunhatep/gocollection/slice"
+	"github.com/rs/zerolog/log"
+)
+
+// FindConfigResources returns the resources of a type AWS Config recorded:
+// through the aggregator when one is named, else in the client's account and
+// region. Types Config cannot record fail with ErrResourceTypeNotSupported,
+// so a sweep reports them as a coverage gap rather than a failing account.
+func FindConfigResources(
+	ctx context.Context,
+	client *v3.Client,
+	dc *cache.DataCache,
+	aggregator string,
+	resourceType cfg.ResourceType,
+) ([]service.ResourceInterface, error) {
+	if !slices.Contains(resourceType.Values(), resourceType) {
+		return nil, fmt.Errorf("%w: %s", ErrResourceTypeNotSupported, cfgEntity.ResourceTypeToString(resourceType))
+	}
+
+	repo := configservice.NewConfigServiceRepository(ctx, client)
+
+	var found []configservice.Resource
+	var err error
+	switch {
+	case dc != nil && aggregator != "":
+		found, err = repo.WithCache(dc).ListAggregateResourcesByType(aggregator, resourceType)
+	case aggregator != "":
+		found, err = repo.ListAggregateResourcesByType(aggregator, resourceType)
+	case dc != nil:
+		found, err = repo.WithCache(dc).ListResourcesByType(resourceType)
+	default:
+		found, err = repo.ListResourcesByType(resourceType)
+	}
+
+	items := slice.Map(found, cast[configservice.Resource])
+
+	log.Info().
+		Str("accountID", client.GetAccountID().String()).
+		Str("region", client.GetRegion().String()).
+		Str("aggregator", aggregator).
+		Str("type", cfgEntity.ResourceTypeToString(resourceType)).
+		Msgf("[proxy.FindConfigResources] aws resources found: %d", len(items))
+
+	return items, err
+}
+
+// ConfigRepoProxy answers FindAll from the inventory AWS Config recorded,
+// instead of the Describe calls of the typed repositories. Like
+// GenericRepoProxy it satisfies RepoProxyInterface, so a pool of these feeds
+// resources.Provider unchanged.
+//
+// With an aggregator, one proxy in the aggregator's account and region serves
+// every source account and region: the resources carry their own account and
+// region, not the proxy's. Either way the entities are configservice.Resource,
+// with the recorded configuration untyped, and the inventory is only as fresh
+// and complete as the recorders: types not recorded come back empty.
+type ConfigRepoProxy struct {
+	*RepoProxy
+	aggregator string
+}
+
+// Config returns a view of this proxy that reads the Config inventory of its
+// own account and region. The client, context and cache are shared.
+func (e *RepoProxy) Config() *ConfigRepoProxy {
+	return &ConfigRepoProxy{RepoProxy: e}
+}
+
+// ConfigAggregator returns a view of this proxy that reads the inventory of
+// every source of the named aggregator with advanced queries. The client must
+// be in the aggregator's account and region.
+func (e *RepoProxy) ConfigAggregator(aggregator string) *ConfigRepoProxy {
+	return &ConfigRepoProxy{RepoProxy: e, aggregator: aggregator}
+}
+
+// WithCache mirrors RepoProxy.WithCache so a ConfigRepoProxy keeps caching when
+// it goes through RepoProxyPool.WithCache.
+func (e *ConfigRepoProxy) WithCache(dc *cache.DataCache) *ConfigRepoProxy {
+	return &ConfigRepoProxy{RepoProxy: e.RepoProxy.WithCache(dc), aggregator: e.aggregator}
+}
+
+func (e *ConfigRepoProxy) FindAll(resourceType cfg.ResourceType) ([]service.ResourceInterface, error) {
+	return FindConfigResources(e.ctx, e.client, e.cache, e.aggregator, resourceType)
+}
+
+// NewConfigRepoProxyPool builds a pool that reads the Config inventory of each
+// client's account and region. Global types are read once per account, from
+// the region RepoProxyPool.List prefers, which must be the one recording
+// global resources.
+func NewConfigRepoProxyPool(ctx context.Context, clients []*v3.Client) *RepoProxyPool {
+	var services []RepoProxyInterface
+	for _, client := range clients {
+		log.Trace().
+			Str("accountID", client.GetAccountID().String()).
+			Str("region", client.GetRegion().String()).
+			Msg("[RepoProxyPool.NewConfigRepoProxyPool] adding client to the pool")
+
+		services = append(services, NewRepoProxy(ctx, client).Config())
+	}
+
+	return &RepoProxyPool{services}
+}
+
+// NewConfigAggregatorRepoProxyPool builds a pool of one proxy that serves the
+// whole inventory of an aggregator, e.g. the organization's, from a client in
+// the aggregator's account and region.
+func NewConfigAggregatorRepoProxyPool(ctx context.Context, client *v3.Client, aggregator string) *RepoProxyPool {
+	log.Trace().
+		Str("accountID", client.GetAccountID().String()).
+		Str("region", client.GetRegion().String()).
+		Str("aggregator", aggregator).
+		Msg("[RepoProxyPool.NewConfigAggregatorRepoProxyPool] adding aggregator client to the pool")
+
+	return &RepoProxyPool{[]RepoProxyInterface{NewRepoProxy(ctx, client).ConfigAggregator(aggregator)}}
+}
@@ -0,0 +1,28 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/imunhatep/awslib/cache"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConfigResourcesRejectsTypesConfigCannotRecord(t *testing.T) {
+	_, err := FindConfigResources(context.Background(), nil, nil, "org", cfgEntity.ResourceTypeHealthEvent)
+	assert.ErrorIs(t, err, ErrResourceTypeNotSupported)
+}
+
+func TestConfigRepoProxyKeepsAggregatorThroughPoolCache(t *testing.T) {
+	pool := &RepoProxyPool{[]RepoProxyInterface{NewRepoProxy(context.Background(), nil).ConfigAggregator("org")}}
+
+	cached := pool.WithCache(&cache.DataCache{})
+	require.Len(t, cached.gateways, 1)
+
+	proxy, ok := cached.gateways[0].(*ConfigRepoProxy)
+	require.True(t, ok, "pool cache must not drop the Config view")
+	assert.Equal(t, "org", proxy.aggregator)
+	assert.NotNil(t, proxy.cache)
+}
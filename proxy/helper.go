@@ -5,8 +5,10 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/route53domains"
 	"github.com/imunhatep/awslib/cache"
+	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/accessanalyzer"
 	"github.com/imunhatep/awslib/service/autoscaling"
 	"github.com/imunhatep/awslib/service/batch"
 	"github.com/imunhatep/awslib/service/cloudfront"
@@ -20,14 +22,17 @@ import (
 	"github.com/imunhatep/awslib/service/emr"
 	"github.com/imunhatep/awslib/service/emrserverless"
 	"github.com/imunhatep/awslib/service/glue"
+	"github.com/imunhatep/awslib/service/health"
 	"github.com/imunhatep/awslib/service/iam"
 	"github.com/imunhatep/awslib/service/lambda"
 	"github.com/imunhatep/awslib/service/rds"
 	"github.com/imunhatep/awslib/service/route53"
 	"github.com/imunhatep/awslib/service/s3"
 	"github.com/imunhatep/awslib/service/secretmanager"
+	"github.com/imunhatep/awslib/service/securityhub"
 	"github.com/imunhatep/awslib/service/sns"
 	"github.com/imunhatep/awslib/service/sqs"
+	"github.com/imunhatep/awslib/service/ssm"
 	"github.com/imunhatep/gocollection/slice"
 )
 
@@ -451,6 +456,31 @@ func FindRoute53ResourceRecords(ctx context.Context, client *v3.Client, dc *cach
 	return all, nil
 }
 
+// FindHealthEvents returns the AWS Health events of the account, of every
+// region, with their affected entities. Health serves them from us-east-1
+// only, so the calls go through the account's client for us-east-1 whatever
+// the region of the proxy; with a cache, every region shares one read.
+func FindHealthEvents(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
+	client = client.WithRegion(ptypes.DefaultAwsRegion)
+
+	repo := health.NewHealthRepository(ctx, client)
+	if dc != nil {
+		cached := repo.WithCache(dc)
+		items, err := cached.ListEventsAll()
+		if err != nil {
+			return slice.Map(items, cast[health.Event]), err
+		}
+		items, err = cached.ListAffectedEntitiesByEvents(items)
+		return slice.Map(items, cast[health.Event]), err
+	}
+	items, err := repo.ListEventsAll()
+	if err != nil {
+		return slice.Map(items, cast[health.Event]), err
+	}
+	items, err = repo.ListAffectedEntitiesByEvents(items)
+	return slice.Map(items, cast[health.Event]), err
+}
+
 // FindSecretManagerSecrets returns a list of Secrets Manager secrets
 func FindSecretManagerSecrets(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
 	repo := secretmanager.NewSecretManagerRepository(ctx, client)
@@ -484,6 +514,50 @@ func FindSnsTopics(ctx context.Context, client *v3.Client, dc *cache.DataCache)
 	return slice.Map(items, cast[sns.Topic]), err
 }
 
+// FindSsmParameters returns a list of SSM parameters, without values
+func FindSsmParameters(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
+	repo := ssm.NewSsmRepository(ctx, client)
+	if dc != nil {
+		items, err := repo.WithCache(dc).ListParametersAll()
+		return slice.Map(items, cast[ssm.Parameter]), err
+	}
+	items, err := repo.ListParametersAll()
+	return slice.Map(items, cast[ssm.Parameter]), err
+}
+
+// FindAccessAnalyzers returns a list of IAM Access Analyzer analyzers
+func FindAccessAnalyzers(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
+	repo := accessanalyzer.NewAccessAnalyzerRepository(ctx, client)
+	if dc != nil {
+		items, err := repo.WithCache(dc).ListAnalyzersAll()
+		return slice.Map(items, cast[accessanalyzer.Analyzer]), err
+	}
+	items, err := repo.ListAnalyzersAll()
+	return slice.Map(items, cast[accessanalyzer.Analyzer]), err
+}
+
+// FindAccessAnalyzerFindings returns the active findings of every active analyzer
+func FindAccessAnalyzerFindings(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
+	repo := accessanalyzer.NewAccessAnalyzerRepository(ctx, client)
+	if dc != nil {
+		items, err := repo.WithCache(dc).ListFindingsActive()
+		return slice.Map(items, cast[accessanalyzer.Finding]), err
+	}
+	items, err := repo.ListFindingsActive()
+	return slice.Map(items, cast[accessanalyzer.Finding]), err
+}
+
+// FindSecurityHubFindings returns the active Security Hub findings
+func FindSecurityHubFindings(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
+	repo := securityhub.NewSecurityHubRepository(ctx, client)
+	if dc != nil {
+		items, err := repo.WithCache(dc).ListFindingsActive()
+		return slice.Map(items, cast[securityhub.Finding]), err
+	}
+	items, err := repo.ListFindingsActive()
+	return slice.Map(items, cast[securityhub.Finding]), err
+}
+
 // FindS3Buckets returns a list of S3 buckets
 func FindS3Buckets(ctx context.Context, client *v3.Client, dc *cache.DataCache) ([]service.ResourceInterface, error) {
 	repo := s3.NewS3Repository(ctx, client)
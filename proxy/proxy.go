@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 
 	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
@@ -13,6 +14,11 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ErrResourceTypeNotSupported is returned by FindAll for a resource type no
+// repository is wired for. It is a sentinel so a sweep can report the gap as a
+// coverage limit of the library rather than as a failing account or region.
+var ErrResourceTypeNotSupported = stderrors.New("resource type not supported")
+
 type RepoProxyInterface interface {
 	GetAccountID() ptypes.AwsAccountID
 	GetRegion() ptypes.AwsRegion
@@ -26,6 +32,9 @@ type RepoProxy struct {
 	ctx    context.Context
 	client *v3.Client
 	cache  *cache.DataCache
+	// bulkTags fills in the tags of bulkTagTypes from the Tagging API, see
+	// WithBulkTags
+	bulkTags bool
 }
 
 func NewRepoProxy(ctx context.Context, client *v3.Client) *RepoProxy {
@@ -38,9 +47,10 @@ func NewRepoProxy(ctx context.Context, client *v3.Client) *RepoProxy {
 // WithCache returns a new RepoProxy that passes the given DataCache to each repository.
 func (e *RepoProxy) WithCache(dc *cache.DataCache) *RepoProxy {
 	return &RepoProxy{
-		ctx:    e.ctx,
-		client: e.client,
-		cache:  dc,
+		ctx:      e.ctx,
+		client:   e.client,
+		cache:    dc,
+		bulkTags: e.bulkTags,
 	}
 }
 
@@ -61,6 +71,12 @@ func (e *RepoProxy) GetContext() context.Context {
 }
 
 func (e *RepoProxy) FindAll(resourceType cfg.ResourceType) (items []service.ResourceInterface, err error) {
+	if e.bulkTags {
+		if _, ok := bulkTagTypes[resourceType]; ok {
+			return e.findAllBulkTagged(resourceType)
+		}
+	}
+
 	switch resourceType {
 	case cfg.ResourceTypeAutoScalingGroup:
 		items, err = FindAutoScaleGroups(e.ctx, e.client, e.cache)
@@ -94,6 +110,8 @@ func (e *RepoProxy) FindAll(resourceType cfg.ResourceType) (items []service.Reso
 		items, err = FindEmrServerlessApplications(e.ctx, e.client, e.cache)
 	case cfgEntity.ResourceTypeEmrServerlessJobRun:
 		items, err = FindEmrServerlessJobRuns(e.ctx, e.client, e.cache)
+	case cfgEntity.ResourceTypeHealthEvent:
+		items, err = FindHealthEvents(e.ctx, e.client, e.cache)
 	case cfg.ResourceTypeFunction:
 		items, err = FindLambdaFunctions(e.ctx, e.client, e.cache)
 	case cfg.ResourceTypeInstance:
@@ -126,6 +144,14 @@ func (e *RepoProxy) FindAll(resourceType cfg.ResourceType) (items []service.Reso
 		items, err = FindSqsQueues(e.ctx, e.client, e.cache)
 	case cfg.ResourceTypeTopic:
 		items, err = FindSnsTopics(e.ctx, e.client, e.cache)
+	case cfgEntity.ResourceTypeSsmParameter:
+		items, err = FindSsmParameters(e.ctx, e.client, e.cache)
+	case cfg.ResourceTypeAccessAnalyzerAnalyzer:
+		items, err = FindAccessAnalyzers(e.ctx, e.client, e.cache)
+	case cfgEntity.ResourceTypeAccessAnalyzerFinding:
+		items, err = FindAccessAnalyzerFindings(e.ctx, e.client, e.cache)
+	case cfgEntity.ResourceTypeSecurityHubFinding:
+		items, err = FindSecurityHubFindings(e.ctx, e.client, e.cache)
 	case cfg.ResourceTypeUser:
 		items, err = FindIamUsers(e.ctx, e.client, e.cache)
 	case cfg.ResourceTypeVpc:
@@ -141,7 +167,7 @@ func (e *RepoProxy) FindAll(resourceType cfg.ResourceType) (items []service.Reso
 	case cfg.ResourceTypeEip:
 		items, err = FindEc2Addresses(e.ctx, e.client, e.cache)
 	default:
-		err = fmt.Errorf("resource type %s not supported", cfgEntity.ResourceTypeToString(resourceType))
+		err = fmt.Errorf("%w: %s", ErrResourceTypeNotSupported, cfgEntity.ResourceTypeToString(resourceType))
 	}
 
 	log.Info().
@@ -42,6 +42,8 @@ func (e *RepoProxyPool) WithCache(dc *cache.DataCache) *RepoProxyPool {
 		switch proxy := gw.(type) {
 		case *GenericRepoProxy:
 			services = append(services, proxy.WithCache(dc))
+		case *ConfigRepoProxy:
+			services = append(services, proxy.WithCache(dc))
 		case *RepoProxy:
 			services = append(services, proxy.WithCache(dc))
 		default:
@@ -52,6 +54,23 @@ func (e *RepoProxyPool) WithCache(dc *cache.DataCache) *RepoProxyPool {
 	return &RepoProxyPool{services}
 }
 
+// WithBulkTags switches every RepoProxy in the pool to bulk tag enrichment, see
+// RepoProxy.WithBulkTags. Other proxy types are kept as they are.
+func (e *RepoProxyPool) WithBulkTags() *RepoProxyPool {
+	services := []RepoProxyInterface{}
+
+	for _, gw := range e.gateways {
+		if proxy, ok := gw.(*RepoProxy); ok {
+			services = append(services, proxy.WithBulkTags())
+			continue
+		}
+
+		services = append(services, gw)
+	}
+
+	return &RepoProxyPool{services}
+}
+
 func (e *RepoProxyPool) List(resourceType cfg.ResourceType) []RepoProxyInterface {
 	// nothing to filter
 	if slice.IsEmpty(e.gateways) {
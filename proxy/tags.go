@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"sort"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/cache"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+	"github.com/imunhatep/awslib/service/tagging"
+	"github.com/rs/zerolog/log"
+)
+
+// untaggedNamespace suffixes the cache namespace of lists made without tags.
+const untaggedNamespace = "/untagged"
+
+// bulkTagTypes maps the resource types whose repositories fetch tags with one
+// call per resource to their Resource Groups Tagging API type filter. S3 is
+// left out on purpose: ListBuckets is account-wide while the Tagging API only
+// reports buckets of the client's region.
+var bulkTagTypes = map[cfg.ResourceType]string{
+	cfg.ResourceTypeFunction:                 "lambda:function",
+	cfg.ResourceTypeQueue:                    "sqs",
+	cfg.ResourceTypeTopic:                    "sns",
+	cfg.ResourceTypeTable:                    "dynamodb:table",
+	cfg.ResourceTypeLoadBalancerV2:           "elasticloadbalancing:loadbalancer",
+	cfgEntity.ResourceTypeCloudWatchLogGroup: "logs:log-group",
+	cfgEntity.ResourceTypeSsmParameter:       "ssm:parameter",
+}
+
+// WithBulkTags returns a RepoProxy that lists Lambda functions, SQS queues, SNS
+// topics, DynamoDB tables, load balancers, log groups and SSM parameters
+// without their per-resource tag calls, and fills in their tags from a single
+// Resource Groups Tagging API pass over the account-region instead.
+//
+// Those lists are cached under their own namespace of the DataCache, so an
+// entry listed without tags is never served to a proxy that fetches tags per
+// resource, nor the other way around. The tagging pass goes through the
+// DataCache too, whose TTL therefore decides how fresh the tags are; without a
+// cache every FindAll makes its own pass.
+func (e *RepoProxy) WithBulkTags() *RepoProxy {
+	return &RepoProxy{
+		ctx:      e.ctx,
+		client:   e.client,
+		cache:    e.cache,
+		bulkTags: true,
+	}
+}
+
+func (e *RepoProxy) findAllBulkTagged(resourceType cfg.ResourceType) ([]service.ResourceInterface, error) {
+	untagged := &RepoProxy{ctx: service.WithoutTagFetch(e.ctx), client: e.client, cache: untaggedCache(e.cache)}
+
+	items, err := untagged.FindAll(resourceType)
+	if err != nil {
+		return items, err
+	}
+
+	index, err := e.loadTagIndex()
+	if err != nil {
+		return items, err
+	}
+
+	return EnrichTags(items, index), nil
+}
+
+// untaggedCache returns the namespace of dc holding lists made without
+// per-resource tag calls, or nil without a cache.
+func untaggedCache(dc *cache.DataCache) *cache.DataCache {
+	if dc == nil {
+		return nil
+	}
+
+	return dc.WithNamespace(dc.Namespace() + untaggedNamespace)
+}
+
+// loadTagIndex runs the tagging pass for bulkTagTypes. Nothing is kept on the
+// proxy: a failed pass fails this FindAll only, and a later one tries again.
+func (e *RepoProxy) loadTagIndex() (map[string]map[string]string, error) {
+	filters := make([]string, 0, len(bulkTagTypes))
+	for _, filter := range bulkTagTypes {
+		filters = append(filters, filter)
+	}
+	sort.Strings(filters) // stable cache key
+
+	repo := tagging.NewTaggingRepository(e.ctx, e.client)
+
+	var resources []tagging.TaggedResource
+	var err error
+	if e.cache != nil {
+		resources, err = repo.WithCache(e.cache).ListResourcesByType(filters)
+	} else {
+		resources, err = repo.ListResourcesByType(filters)
+	}
+
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	log.Debug().
+		Str("accountID", e.client.GetAccountID().String()).
+		Str("region", e.client.GetRegion().String()).
+		Msgf("[RepoProxy.loadTagIndex] tagged resources indexed: %d", len(resources))
+
+	return TagIndex(resources), nil
+}
+
+// TagIndex keys the tags of tagged resources by tagging.ArnKey.
+func TagIndex(resources []tagging.TaggedResource) map[string]map[string]string {
+	index := make(map[string]map[string]string, len(resources))
+	for _, r := range resources {
+		if key := tagging.ArnKey(r.ARN); key != "" {
+			index[key] = r.Tags
+		}
+	}
+
+	return index
+}
+
+// EnrichTags sets the tags of every service.TagSetter found in the index. The
+// Tagging API does not report resources without tags, so a resource missing
+// from the index gets an empty tag set. Other resources pass through unchanged.
+func EnrichTags(items []service.ResourceInterface, index map[string]map[string]string) []service.ResourceInterface {
+	for i, item := range items {
+		setter, ok := item.(service.TagSetter)
+		if !ok {
+			continue
+		}
+
+		tags, found := index[tagging.ArnKey(item.GetArn())]
+		if !found {
+			tags = map[string]string{}
+		}
+
+		items[i] = setter.WithTags(tags)
+	}
+
+	return items
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/imunhatep/awslib/cache"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/cloudwatchlogs"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/lambda"
+	"github.com/imunhatep/awslib/service/tagging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func TestEnrichTags(t *testing.T) {
+	c := mockClient{}
+
+	items := []service.ResourceInterface{
+		lambda.NewFunction(c, lambdatypes.FunctionConfiguration{
+			FunctionName: aws.String("fn"),
+			FunctionArn:  aws.String("arn:aws:lambda:eu-west-1:123456789012:function:fn"),
+		}, nil),
+		cloudwatchlogs.NewLogGroup(c, cwltypes.LogGroup{
+			LogGroupName: aws.String("/untagged"),
+			Arn:          aws.String("arn:aws:logs:eu-west-1:123456789012:log-group:/untagged:*"),
+		}, map[string]string{"stale": "yes"}),
+		ec2.NewVolume(c, ec2types.Volume{VolumeId: aws.String("vol-1"), Tags: ec2.TagMapToTags(map[string]string{"keep": "me"})}),
+	}
+
+	index := TagIndex([]tagging.TaggedResource{
+		{ARN: "arn:aws:lambda:eu-west-1:123456789012:function:fn", Tags: map[string]string{"env": "prod"}},
+	})
+
+	enriched := EnrichTags(items, index)
+	require.Len(t, enriched, 3)
+
+	assert.Equal(t, map[string]string{"env": "prod"}, enriched[0].GetTags())
+	// absent from the Tagging API means no tags at all
+	assert.Empty(t, enriched[1].GetTags())
+	// not a TagSetter: EC2 tags come with the Describe call
+	assert.Equal(t, map[string]string{"keep": "me"}, enriched[2].GetTags())
+
+	_, ok := enriched[0].(lambda.Function)
+	assert.True(t, ok, "enrichment must keep the concrete entity type")
+}
+
+func TestWithoutTagFetch(t *testing.T) {
+	ctx := service.WithoutTagFetch(t.Context())
+	assert.True(t, service.TagFetchSkipped(ctx))
+	assert.False(t, service.TagFetchSkipped(t.Context()))
+}
+
+func TestUntaggedCacheKeepsCacheInOwnNamespace(t *testing.T) {
+	assert.Nil(t, untaggedCache(nil))
+
+	dc := cache.NewDataCache().WithNamespace("sweep")
+	untagged := untaggedCache(dc)
+	require.NotNil(t, untagged)
+	assert.Equal(t, "sweep/untagged", untagged.Namespace())
+	assert.Equal(t, "sweep", dc.Namespace())
+}
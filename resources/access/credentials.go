@@ -0,0 +1,77 @@
+package access
+
+import (
+	stderrors "errors"
+	"time"
+
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/iam"
+	"github.com/rs/zerolog/log"
+)
+
+// CredentialReport is the credential hygiene of a set of accounts.
+type CredentialReport struct {
+	Findings []iam.CredentialFinding
+	Users    []iam.UserCredentials
+	Roles    []iam.RoleUsage
+	// Rows holds the IAM credential report of each account.
+	Rows map[ptypes.AwsAccountID][]iam.CredentialReportRow
+	// Failed holds the accounts that could not be read completely, with every
+	// error of the account joined; their findings are missing or partial.
+	Failed map[ptypes.AwsAccountID]error
+}
+
+// LoadCredentialReport reads users, roles and the IAM credential report of
+// every account and audits them. An account that cannot be read is recorded
+// in Failed, and the others are still reported.
+func LoadCredentialReport(pool AwsClientPool, accounts []ptypes.AwsAccountID, auditor iam.CredentialAuditor, now time.Time) CredentialReport {
+	report := CredentialReport{
+		Rows:   map[ptypes.AwsAccountID][]iam.CredentialReportRow{},
+		Failed: map[ptypes.AwsAccountID]error{},
+	}
+
+	for _, account := range accounts {
+		client, err := pool.GetClient(account, ptypes.DefaultAwsRegion)
+		if err != nil {
+			report.Failed[account] = err
+			continue
+		}
+
+		repo := iam.NewIamRepository(pool.GetContext(), client)
+		var errs []error
+
+		users, err := repo.ListUserCredentialsAll()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, user := range users {
+			report.Users = append(report.Users, user)
+			report.Findings = append(report.Findings, auditor.AuditUser(user, now)...)
+		}
+
+		roles, err := repo.ListRoleUsageAll()
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, role := range roles {
+			report.Roles = append(report.Roles, role)
+			report.Findings = append(report.Findings, auditor.AuditRole(role, now)...)
+		}
+
+		rows, err := repo.GenerateCredentialReport()
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			report.Rows[account] = rows
+			report.Findings = append(report.Findings, auditor.AuditCredentialReport(account, rows)...)
+		}
+
+		if len(errs) > 0 {
+			err := stderrors.Join(errs...)
+			report.Failed[account] = err
+			log.Warn().Err(err).Str("account", account.String()).Msg("[access.LoadCredentialReport] account read incompletely")
+		}
+	}
+
+	return report
+}
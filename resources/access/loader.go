@@ -0,0 +1,86 @@
+package access
+
+import (
+	"context"
+
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service/iam"
+	"github.com/rs/zerolog/log"
+)
+
+type AwsClientPool interface {
+	GetContext() context.Context
+	GetClient(ptypes.AwsAccountID, ptypes.AwsRegion) (*v3.Client, error)
+}
+
+// LoadTrustGraph reads the roles of every account and builds their trust
+// graph. IAM is global, so one client per account is used. An account whose
+// roles cannot be read is logged and left out, but its principals still
+// count as internal.
+func LoadTrustGraph(pool AwsClientPool, accounts []ptypes.AwsAccountID) (*TrustGraph, error) {
+	var roles []RolePolicies
+
+	for _, account := range accounts {
+		client, err := pool.GetClient(account, ptypes.DefaultAwsRegion)
+		if err != nil {
+			log.Warn().Err(err).Str("account", account.String()).Msg("[access.LoadTrustGraph] failed to get client, skipping")
+			continue
+		}
+
+		accountRoles, err := LoadRolePolicies(pool.GetContext(), client)
+		if err != nil {
+			log.Warn().Err(err).Str("account", account.String()).Msg("[access.LoadTrustGraph] failed to read roles, skipping")
+			continue
+		}
+
+		roles = append(roles, accountRoles...)
+	}
+
+	if len(roles) == 0 && len(accounts) > 0 {
+		return nil, errors.New("no roles could be read in any account")
+	}
+
+	return NewTrustGraph(roles, accounts...), nil
+}
+
+// LoadRolePolicies reads every role of the client's account with its trust
+// policy and its attached and inline policies.
+func LoadRolePolicies(ctx context.Context, client *v3.Client) ([]RolePolicies, error) {
+	repo := iam.NewIamRepository(ctx, client)
+
+	roles, err := repo.ListRolesAll()
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]RolePolicies, 0, len(roles))
+	for _, role := range roles {
+		trust, err := role.GetTrustPolicy()
+		if err != nil {
+			log.Warn().Err(err).Str("role", role.GetArn()).Msg("[access.LoadRolePolicies] failed to decode trust policy, skipping")
+			continue
+		}
+
+		entry := RolePolicies{Role: role, Trust: trust}
+
+		versions, err := repo.ListAttachedRolePolicyVersionsByRole(role)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			entry.Identity = append(entry.Identity, version.GetDocument())
+		}
+
+		inline, err := repo.ListRoleInlinePolicyDocumentsByRole(role)
+		if err != nil {
+			return nil, err
+		}
+		entry.Identity = append(entry.Identity, inline...)
+
+		policies = append(policies, entry)
+	}
+
+	return policies, nil
+}
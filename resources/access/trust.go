@@ -0,0 +1,323 @@
+package access
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/iam"
+)
+
+// PrincipalKind is the kind of principal a trust policy names.
+type PrincipalKind string
+
+const (
+	// PrincipalRole is an IAM role of an account in the graph.
+	PrincipalRole PrincipalKind = "role"
+	// PrincipalAccount is a whole account: any of its principals allowed by
+	// their own identity policies.
+	PrincipalAccount PrincipalKind = "account"
+	// PrincipalIam is a user or role the graph holds no policies for.
+	PrincipalIam PrincipalKind = "iam"
+	// PrincipalService is an AWS service, e.g. "ec2.amazonaws.com".
+	PrincipalService PrincipalKind = "service"
+	// PrincipalFederated is an identity provider, OIDC or SAML.
+	PrincipalFederated PrincipalKind = "federated"
+	// PrincipalPublic is "*": anyone.
+	PrincipalPublic PrincipalKind = "public"
+)
+
+var assumeActions = []string{"sts:AssumeRole", "sts:AssumeRoleWithWebIdentity", "sts:AssumeRoleWithSAML"}
+
+// RolePolicies is a role with the policies deciding who may assume it and
+// what it may assume in turn.
+type RolePolicies struct {
+	Role iam.Role
+	// Trust is the assume role policy of the role.
+	Trust iam.PolicyDocument
+	// Identity holds the attached and inline policies of the role.
+	Identity []iam.PolicyDocument
+}
+
+// TrustEdge reads "From may assume To". From is a role ARN, an account root
+// ARN, an IAM ARN, a service or federated principal, or "*".
+type TrustEdge struct {
+	From   string        `json:"from"`
+	To     string        `json:"to"`
+	Kind   PrincipalKind `json:"kind"`
+	Action string        `json:"action"`
+	// Conditional is set when the trust statement has conditions, e.g.
+	// sts:ExternalId, that were not evaluated.
+	Conditional bool `json:"conditional,omitempty"`
+	// External is set when From lies outside the accounts of the graph.
+	External bool `json:"external,omitempty"`
+}
+
+// AccessPath is a principal reaching a role, directly or by chaining role
+// assumptions. Edges run from the principal to the role.
+type AccessPath struct {
+	Principal string
+	Kind      PrincipalKind
+	Edges     []TrustEdge
+}
+
+// TrustGraph holds who may assume which role across a set of accounts.
+//
+// It answers "may", not "will": Allow statements count whatever their
+// conditions, and only unconditional Deny statements are honoured. A
+// cross-account assumption needs both the trust policy of the role and the
+// identity policy of the caller; a trust policy naming a role of its own
+// account is enough on its own.
+type TrustGraph struct {
+	roles    map[string]RolePolicies
+	accounts map[string]bool
+	edges    []TrustEdge
+	into     map[string][]TrustEdge
+}
+
+// NewTrustGraph builds the graph of the given roles. Principals of the given
+// accounts, and of the accounts the roles live in, are not external.
+func NewTrustGraph(roles []RolePolicies, accounts ...ptypes.AwsAccountID) *TrustGraph {
+	g := &TrustGraph{
+		roles:    map[string]RolePolicies{},
+		accounts: map[string]bool{},
+		into:     map[string][]TrustEdge{},
+	}
+
+	for _, account := range accounts {
+		g.accounts[account.String()] = true
+	}
+
+	for _, role := range roles {
+		g.roles[role.Role.GetArn()] = role
+		g.accounts[role.Role.GetAccountID().String()] = true
+	}
+
+	for _, role := range roles {
+		for _, edge := range g.trustEdges(role) {
+			g.edges = append(g.edges, edge)
+			g.into[edge.To] = append(g.into[edge.To], edge)
+		}
+	}
+
+	for _, edges := range g.into {
+		sort.Slice(edges, func(i, j int) bool { return edges[i].From < edges[j].From })
+	}
+
+	return g
+}
+
+// Edges returns every edge, sorted by role and principal.
+func (g *TrustGraph) Edges() []TrustEdge {
+	edges := append([]TrustEdge{}, g.edges...)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+
+		return edges[i].From < edges[j].From
+	})
+
+	return edges
+}
+
+// TrustedBy returns the principals a role trusts directly.
+func (g *TrustGraph) TrustedBy(roleArn string) []TrustEdge {
+	return append([]TrustEdge{}, g.into[roleArn]...)
+}
+
+// ExternalTrusts returns the edges letting principals outside the accounts
+// of the graph, or anyone, assume a role.
+func (g *TrustGraph) ExternalTrusts() []TrustEdge {
+	var edges []TrustEdge
+	for _, edge := range g.Edges() {
+		if edge.External {
+			edges = append(edges, edge)
+		}
+	}
+
+	return edges
+}
+
+// PrincipalsReaching returns every principal that may end up acting as the
+// role, with the shortest chain of assumptions getting there.
+func (g *TrustGraph) PrincipalsReaching(roleArn string) []AccessPath {
+	var paths []AccessPath
+
+	// breadth first from the role backwards, so each principal is reached by
+	// its shortest chain
+	visited := map[string]bool{roleArn: true}
+	chains := map[string][]TrustEdge{roleArn: nil}
+	queue := []string{roleArn}
+
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range g.into[target] {
+			if visited[edge.From] {
+				continue
+			}
+			visited[edge.From] = true
+
+			chain := append([]TrustEdge{edge}, chains[target]...)
+			chains[edge.From] = chain
+			paths = append(paths, AccessPath{Principal: edge.From, Kind: edge.Kind, Edges: chain})
+
+			if edge.Kind == PrincipalRole {
+				queue = append(queue, edge.From)
+			}
+		}
+	}
+
+	return paths
+}
+
+func (g *TrustGraph) trustEdges(target RolePolicies) []TrustEdge {
+	targetArn := target.Role.GetArn()
+
+	var edges []TrustEdge
+	seen := map[string]bool{}
+	// every principal, whatever its kind, must get past the Deny statements
+	// of the trust policy
+	add := func(edge TrustEdge) {
+		key := edge.From + "|" + edge.Action
+		if seen[key] || edge.From == targetArn {
+			return
+		}
+
+		if mayAllow(iam.AccessRequest{Principal: edge.From, Action: edge.Action}, target.Trust) {
+			seen[key] = true
+			edges = append(edges, edge)
+		}
+	}
+
+	for _, statement := range target.Trust.Statement {
+		if !strings.EqualFold(statement.Effect, "Allow") {
+			continue
+		}
+
+		action := assumeAction(statement)
+		if action == "" {
+			continue
+		}
+
+		conditional := len(statement.Condition) > 0
+
+		for principalType, values := range statement.Principals() {
+			for _, value := range values {
+				switch {
+				case principalType == "Service":
+					add(TrustEdge{From: value, To: targetArn, Kind: PrincipalService, Action: action, Conditional: conditional})
+				case principalType == "Federated":
+					add(TrustEdge{From: value, To: targetArn, Kind: PrincipalFederated, Action: action, Conditional: conditional})
+				case principalType != "AWS":
+					continue
+				case value == "*":
+					add(TrustEdge{From: value, To: targetArn, Kind: PrincipalPublic, Action: action, Conditional: conditional, External: true})
+				default:
+					for _, edge := range g.iamEdges(target, value, action, conditional) {
+						add(edge)
+					}
+				}
+			}
+		}
+	}
+
+	return edges
+}
+
+// iamEdges resolves an AWS principal of a trust statement: to the roles of
+// the graph it covers that may call AssumeRole on the target, and to the
+// account or principal itself.
+func (g *TrustGraph) iamEdges(target RolePolicies, principal, action string, conditional bool) []TrustEdge {
+	targetArn := target.Role.GetArn()
+	targetAccount := target.Role.GetAccountID().String()
+	account := iam.PrincipalAccountID(principal)
+	root := accountRoot(targetArn, account)
+	isAccount := account != "" && iam.PrincipalMatches(principal, root)
+
+	var edges []TrustEdge
+
+	known := false
+	for arn, caller := range g.roles {
+		if !iam.PrincipalMatches(principal, arn) {
+			continue
+		}
+		known = known || arn == principal
+
+		// naming a role of the same account in the trust policy is enough;
+		// otherwise the caller's own policies must allow the call
+		direct := !isAccount && caller.Role.GetAccountID().String() == targetAccount
+		if !direct && !mayAllow(iam.AccessRequest{Action: "sts:AssumeRole", Resource: targetArn}, caller.Identity...) {
+			continue
+		}
+
+		edges = append(edges, TrustEdge{From: arn, To: targetArn, Kind: PrincipalRole, Action: action, Conditional: conditional})
+	}
+
+	if known {
+		return edges
+	}
+
+	kind := PrincipalIam
+	from := principal
+	if isAccount {
+		kind, from = PrincipalAccount, root
+	}
+
+	external := account == "" || !g.accounts[account]
+
+	return append(edges, TrustEdge{From: from, To: targetArn, Kind: kind, Action: action, Conditional: conditional, External: external})
+}
+
+// accountRoot returns the root ARN of an account, in the partition of the
+// role ARN.
+func accountRoot(roleArn, account string) string {
+	partition := "aws"
+	if parsed, err := arn.Parse(roleArn); err == nil {
+		partition = parsed.Partition
+	}
+
+	return "arn:" + partition + ":iam::" + account + ":root"
+}
+
+// assumeAction returns the STS action a trust statement allows.
+func assumeAction(statement iam.Statement) string {
+	if statement.Action == nil && statement.NotAction == nil {
+		return ""
+	}
+
+	for _, action := range assumeActions {
+		if matched, _ := (iam.Statement{Action: statement.Action, NotAction: statement.NotAction}).Matches(iam.AccessRequest{Action: action}); matched {
+			return action
+		}
+	}
+
+	return ""
+}
+
+// mayAllow evaluates policies as if every Allow condition held and every
+// conditional Deny did not apply.
+func mayAllow(request iam.AccessRequest, policies ...iam.PolicyDocument) bool {
+	relaxed := make([]iam.PolicyDocument, 0, len(policies))
+
+	for _, policy := range policies {
+		doc := iam.PolicyDocument{Version: policy.Version}
+		for _, statement := range policy.Statement {
+			if len(statement.Condition) > 0 {
+				if strings.EqualFold(statement.Effect, "Deny") {
+					continue
+				}
+				statement.Condition = nil
+			}
+
+			doc.Statement = append(doc.Statement, statement)
+		}
+
+		relaxed = append(relaxed, doc)
+	}
+
+	return iam.Evaluate(request, relaxed...).IsAllowed()
+}
@@ -0,0 +1,178 @@
+package access
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct {
+	account ptypes.AwsAccountID
+}
+
+func (c mockClient) GetRegion() ptypes.AwsRegion       { return ptypes.DefaultAwsRegion }
+func (c mockClient) GetAccountID() ptypes.AwsAccountID { return c.account }
+
+func policy(t *testing.T, doc string) iam.PolicyDocument {
+	t.Helper()
+
+	var p iam.PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(doc), &p))
+
+	return p
+}
+
+func role(t *testing.T, account, name, trust string, identity ...string) RolePolicies {
+	t.Helper()
+
+	entry := RolePolicies{
+		Role: iam.NewRole(mockClient{account: ptypes.AwsAccountID(account)}, types.Role{
+			RoleName: aws.String(name),
+			Arn:      aws.String("arn:aws:iam::" + account + ":role/" + name),
+		}),
+		Trust: policy(t, trust),
+	}
+
+	for _, doc := range identity {
+		entry.Identity = append(entry.Identity, policy(t, doc))
+	}
+
+	return entry
+}
+
+const (
+	prod  = "111111111111"
+	tools = "222222222222"
+)
+
+func TestTrustGraph(t *testing.T) {
+	roles := []RolePolicies{
+		// prod admin trusts the tools account and a vendor with an external id
+		role(t, prod, "admin", `{"Statement": [
+			{"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::222222222222:root"}, "Action": "sts:AssumeRole"},
+			{"Effect": "Allow", "Principal": {"AWS": "999999999999"}, "Action": "sts:AssumeRole",
+			 "Condition": {"StringEquals": {"sts:ExternalId": "x"}}}
+		]}`),
+		// prod deployer is reached from the admin role of its own account
+		role(t, prod, "deployer", `{"Statement": {"Effect": "Allow",
+			"Principal": {"AWS": "arn:aws:iam::111111111111:role/admin"}, "Action": "sts:AssumeRole"}}`),
+		// prod lambda role trusts a service
+		role(t, prod, "lambda", `{"Statement": {"Effect": "Allow",
+			"Principal": {"Service": "lambda.amazonaws.com"}, "Action": "sts:AssumeRole"}}`),
+		// tools ci may assume prod admin
+		role(t, tools, "ci", `{"Statement": {"Effect": "Allow",
+			"Principal": {"Federated": "arn:aws:iam::222222222222:oidc-provider/token.actions.githubusercontent.com"},
+			"Action": "sts:AssumeRoleWithWebIdentity"}}`,
+			`{"Statement": {"Effect": "Allow", "Action": "sts:AssumeRole", "Resource": "arn:aws:iam::111111111111:role/*"}}`),
+		// tools reader is in the trusted account but may not assume anything
+		role(t, tools, "reader", `{"Statement": {"Effect": "Allow",
+			"Principal": {"Service": "ec2.amazonaws.com"}, "Action": "sts:AssumeRole"}}`,
+			`{"Statement": {"Effect": "Allow", "Action": "s3:Get*", "Resource": "*"}}`),
+	}
+
+	g := NewTrustGraph(roles)
+
+	adminArn := "arn:aws:iam::" + prod + ":role/admin"
+	deployerArn := "arn:aws:iam::" + prod + ":role/deployer"
+	ciArn := "arn:aws:iam::" + tools + ":role/ci"
+
+	trusted := map[string]TrustEdge{}
+	for _, edge := range g.TrustedBy(adminArn) {
+		trusted[edge.From] = edge
+	}
+
+	assert.Contains(t, trusted, ciArn)
+	assert.Equal(t, PrincipalRole, trusted[ciArn].Kind)
+	assert.NotContains(t, trusted, "arn:aws:iam::"+tools+":role/reader")
+	assert.Equal(t, PrincipalAccount, trusted["arn:aws:iam::"+tools+":root"].Kind)
+	assert.False(t, trusted["arn:aws:iam::"+tools+":root"].External)
+
+	vendor := trusted["arn:aws:iam::999999999999:root"]
+	assert.True(t, vendor.External)
+	assert.True(t, vendor.Conditional)
+
+	external := g.ExternalTrusts()
+	require.Len(t, external, 1)
+	assert.Equal(t, adminArn, external[0].To)
+
+	reaching := map[string]AccessPath{}
+	for _, path := range g.PrincipalsReaching(deployerArn) {
+		reaching[path.Principal] = path
+	}
+
+	require.Contains(t, reaching, adminArn)
+	require.Contains(t, reaching, ciArn)
+	assert.Len(t, reaching[ciArn].Edges, 2)
+	assert.Equal(t, ciArn, reaching[ciArn].Edges[0].From)
+	assert.Equal(t, deployerArn, reaching[ciArn].Edges[1].To)
+
+	oidc := "arn:aws:iam::" + tools + ":oidc-provider/token.actions.githubusercontent.com"
+	require.Contains(t, reaching, oidc)
+	assert.Equal(t, PrincipalFederated, reaching[oidc].Kind)
+	assert.Len(t, reaching[oidc].Edges, 3)
+	assert.Equal(t, "sts:AssumeRoleWithWebIdentity", reaching[oidc].Edges[0].Action)
+
+	assert.NotContains(t, reaching, "lambda.amazonaws.com")
+}
+
+func TestTrustGraphSameAccountRoleNeedsNoIdentityPolicy(t *testing.T) {
+	roles := []RolePolicies{
+		role(t, prod, "target", `{"Statement": {"Effect": "Allow",
+			"Principal": {"AWS": "arn:aws:iam::111111111111:role/caller"}, "Action": "sts:AssumeRole"}}`),
+		role(t, prod, "caller", `{"Statement": {"Effect": "Allow",
+			"Principal": {"Service": "ec2.amazonaws.com"}, "Action": "sts:AssumeRole"}}`),
+	}
+
+	edges := NewTrustGraph(roles).TrustedBy("arn:aws:iam::" + prod + ":role/target")
+	require.Len(t, edges, 1)
+	assert.Equal(t, PrincipalRole, edges[0].Kind)
+}
+
+func TestTrustGraphDenyAppliesToEveryPrincipalKind(t *testing.T) {
+	roles := []RolePolicies{
+		role(t, prod, "target", `{"Statement": [
+			{"Effect": "Allow", "Principal": {"Service": ["ec2.amazonaws.com", "lambda.amazonaws.com"]}, "Action": "sts:AssumeRole"},
+			{"Effect": "Allow", "Principal": {"Federated": "arn:aws:iam::111111111111:saml-provider/okta"}, "Action": "sts:AssumeRoleWithSAML"},
+			{"Effect": "Allow", "Principal": {"AWS": ["*", "999999999999", "888888888888"]}, "Action": "sts:AssumeRole"},
+			{"Effect": "Deny", "Principal": {"Service": "lambda.amazonaws.com"}, "Action": "sts:AssumeRole"},
+			{"Effect": "Deny", "Principal": {"Federated": "arn:aws:iam::111111111111:saml-provider/okta"}, "Action": "sts:*"},
+			{"Effect": "Deny", "Principal": {"AWS": "arn:aws:iam::999999999999:root"}, "Action": "sts:AssumeRole"}
+		]}`),
+	}
+
+	from := map[string]bool{}
+	for _, edge := range NewTrustGraph(roles).TrustedBy("arn:aws:iam::" + prod + ":role/target") {
+		from[edge.From] = true
+	}
+
+	assert.Equal(t, map[string]bool{
+		"ec2.amazonaws.com":              true,
+		"*":                              true,
+		"arn:aws:iam::888888888888:root": true,
+	}, from)
+
+	// denying everyone leaves nothing
+	roles[0].Trust.Statement = append(roles[0].Trust.Statement,
+		policy(t, `{"Statement": {"Effect": "Deny", "Principal": "*", "Action": "sts:*"}}`).Statement...)
+	assert.Empty(t, NewTrustGraph(roles).TrustedBy("arn:aws:iam::"+prod+":role/target"))
+}
+
+func TestTrustGraphAccountRootInRolePartition(t *testing.T) {
+	target := role(t, prod, "target", `{"Statement": {"Effect": "Allow",
+		"Principal": {"AWS": "999999999999"}, "Action": "sts:AssumeRole"}}`)
+	target.Role = iam.NewRole(mockClient{account: prod}, types.Role{
+		RoleName: aws.String("target"),
+		Arn:      aws.String("arn:aws-cn:iam::" + prod + ":role/target"),
+	})
+
+	edges := NewTrustGraph([]RolePolicies{target}).TrustedBy("arn:aws-cn:iam::" + prod + ":role/target")
+	require.Len(t, edges, 1)
+	assert.Equal(t, PrincipalAccount, edges[0].Kind)
+	assert.Equal(t, "arn:aws-cn:iam::999999999999:root", edges[0].From)
+}
@@ -0,0 +1,117 @@
+package asff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	"github.com/imunhatep/awslib/resources/tagpolicy"
+	"github.com/imunhatep/awslib/resources/waste"
+	"github.com/imunhatep/awslib/service/securityhub"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// GeneratorPrefix starts the generator id of every finding exported here;
+	// filter on it to read them back from Security Hub.
+	GeneratorPrefix = "awslib/"
+
+	TypeUnusedResource = "Software and Configuration Checks/Cost Optimization/Unused Resource"
+	TypeTagPolicy      = "Software and Configuration Checks/Industry and Regulatory Standards/Tag Policy"
+)
+
+// WasteGenerator is the generator id of the findings of a waste rule.
+func WasteGenerator(ruleID string) string {
+	return GeneratorPrefix + "waste/" + ruleID
+}
+
+// WasteGenerators returns the generator ids of the given waste rules, for
+// Evaluated.
+func WasteGenerators(rules []waste.Rule) []string {
+	generators := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		generators = append(generators, WasteGenerator(rule.ID))
+	}
+
+	return generators
+}
+
+// TagPolicyGenerator is the generator id of the findings of a tag policy.
+func TagPolicyGenerator(policy string) string {
+	return GeneratorPrefix + "tagpolicy/" + policy
+}
+
+// FromWaste converts waste findings, one ASFF finding each, generated by
+// "awslib/waste/<rule id>". Findings without their resource are skipped.
+func FromWaste(findings []waste.Finding, now time.Time) []types.AwsSecurityFinding {
+	exported := make([]types.AwsSecurityFinding, 0, len(findings))
+
+	for _, finding := range findings {
+		if finding.Resource == nil {
+			log.Debug().Str("rule", finding.RuleID).Str("resource", finding.ResourceID).Msg("[asff.FromWaste] finding without resource, skipping")
+			continue
+		}
+
+		fields := map[string]string{"awslib/RuleId": finding.RuleID}
+		if finding.MonthlyWaste != nil {
+			fields["awslib/MonthlyWasteUsd"] = fmt.Sprintf("%.2f", *finding.MonthlyWaste)
+		}
+
+		exported = append(exported, securityhub.ResourceFinding{
+			GeneratorID:   WasteGenerator(finding.RuleID),
+			Types:         []string{TypeUnusedResource},
+			Severity:      wasteSeverity(finding.Severity),
+			Title:         fmt.Sprintf("Unused resource: %s", finding.RuleID),
+			Description:   finding.Rationale,
+			ProductFields: fields,
+			Resource:      finding.Resource,
+		}.Asff(now))
+	}
+
+	return exported
+}
+
+// FromTagPolicy converts the non-compliant resources of a tag policy report,
+// one ASFF finding per resource listing all its violations, generated by
+// "awslib/tagpolicy/<policy>".
+func FromTagPolicy(report *tagpolicy.Report, severity types.SeverityLabel, now time.Time) []types.AwsSecurityFinding {
+	exported := make([]types.AwsSecurityFinding, 0, len(report.Results))
+
+	for _, result := range report.Results {
+		if result.Resource == nil {
+			log.Debug().Str("policy", report.Policy).Str("resource", result.ResourceID).Msg("[asff.FromTagPolicy] result without resource, skipping")
+			continue
+		}
+
+		violations := make([]string, 0, len(result.Violations))
+		for _, v := range result.Violations {
+			violations = append(violations, v.String())
+		}
+
+		exported = append(exported, securityhub.ResourceFinding{
+			GeneratorID:   TagPolicyGenerator(report.Policy),
+			Types:         []string{TypeTagPolicy},
+			Severity:      severity,
+			Title:         fmt.Sprintf("Tag policy %s violated", report.Policy),
+			Description:   strings.Join(violations, "; "),
+			ProductFields: map[string]string{"awslib/Policy": report.Policy},
+			Resource:      result.Resource,
+		}.Asff(now))
+	}
+
+	return exported
+}
+
+func wasteSeverity(severity waste.Severity) types.SeverityLabel {
+	switch severity {
+	case waste.SeverityHigh:
+		return types.SeverityLabelHigh
+	case waste.SeverityMedium:
+		return types.SeverityLabelMedium
+	case waste.SeverityLow:
+		return types.SeverityLabelLow
+	}
+
+	return types.SeverityLabelInformational
+}
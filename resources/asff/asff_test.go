@@ -0,0 +1,105 @@
+package asff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/resources/tagpolicy"
+	"github.com/imunhatep/awslib/resources/waste"
+	"github.com/imunhatep/awslib/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockResource struct {
+	service.AbstractResource
+	tags map[string]string
+}
+
+func (m mockResource) GetName() string               { return m.ID }
+func (m mockResource) GetTags() map[string]string    { return m.tags }
+func (m mockResource) GetTagValue(tag string) string { return m.tags[tag] }
+
+func newMockResource(id string, resourceType cfg.ResourceType, tags map[string]string) mockResource {
+	return mockResource{
+		AbstractResource: service.AbstractResource{AccountID: "222222222222", Region: "eu-west-1", ID: id, Type: resourceType},
+		tags:             tags,
+	}
+}
+
+func TestFromWaste(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	cost := 8.0
+
+	findings := FromWaste([]waste.Finding{
+		{RuleID: "ebs-unattached", Severity: waste.SeverityMedium, Rationale: "volume attached to no instance", MonthlyWaste: &cost, Resource: newMockResource("vol-1", cfg.ResourceTypeVolume, nil)},
+		{RuleID: "eip-unassociated", Severity: waste.SeverityLow, ResourceID: "eipalloc-1"},
+	}, now)
+
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "awslib/waste/ebs-unattached", aws.ToString(findings[0].GeneratorId))
+	assert.Equal(t, []string{TypeUnusedResource}, findings[0].Types)
+	assert.Equal(t, types.SeverityLabelMedium, findings[0].Severity.Label)
+	assert.Equal(t, "volume attached to no instance", aws.ToString(findings[0].Description))
+	assert.Equal(t, "8.00", findings[0].ProductFields["awslib/MonthlyWasteUsd"])
+}
+
+func TestFromTagPolicy(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	policy, err := tagpolicy.NewPolicy("baseline",
+		tagpolicy.Rule{Key: "team", Required: true},
+		tagpolicy.Rule{Key: "env", AllowedValues: []string{"dev", "prod"}},
+	)
+	assert.NoError(t, err)
+
+	report := policy.Evaluate([]service.ResourceInterface{
+		newMockResource("vol-1", cfg.ResourceTypeVolume, map[string]string{"env": "test"}),
+		newMockResource("vol-2", cfg.ResourceTypeVolume, map[string]string{"team": "data", "env": "prod"}),
+	})
+
+	findings := FromTagPolicy(report, types.SeverityLabelLow, now)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "awslib/tagpolicy/baseline/222222222222/eu-west-1/vol-1", aws.ToString(findings[0].Id))
+	assert.Equal(t, `team: missing; env: value "test" not allowed`, aws.ToString(findings[0].Description))
+	assert.Equal(t, types.SeverityLabelLow, findings[0].Severity.Label)
+	assert.Equal(t, "test", findings[0].Resources[0].Tags["env"])
+}
+
+func TestOnlyGenerator(t *testing.T) {
+	findings := []types.AwsSecurityFinding{
+		{GeneratorId: aws.String("awslib/tagpolicy/base")},
+		{GeneratorId: aws.String("awslib/tagpolicy/baseline")},
+	}
+
+	assert.Len(t, onlyGenerator(findings, "awslib/tagpolicy/base"), 1)
+}
+
+func TestPublishTargets(t *testing.T) {
+	findings := []types.AwsSecurityFinding{{
+		Id:           aws.String("awslib/waste/ebs-volume-unattached/222222222222/eu-west-1/vol-1"),
+		GeneratorId:  aws.String(WasteGenerator(waste.RuleUnattachedVolume)),
+		AwsAccountId: aws.String("222222222222"),
+		Region:       aws.String("eu-west-1"),
+	}}
+
+	targets := publishTargets(findings, Evaluated{
+		Generators: append(WasteGenerators(waste.DefaultRules()), TagPolicyGenerator("base")),
+		Accounts:   []ptypes.AwsAccountID{"222222222222", "333333333333"},
+		Regions:    []ptypes.AwsRegion{"eu-west-1", "us-east-1"},
+	})
+
+	require.Len(t, targets, 4)
+
+	reported := targets[accountRegion{"222222222222", "eu-west-1"}]
+	assert.Len(t, reported.batch, 1)
+
+	// an account where everything was fixed still reconciles every generator
+	fixed := targets[accountRegion{"333333333333", "us-east-1"}]
+	assert.Empty(t, fixed.batch)
+	assert.True(t, fixed.generators["awslib/tagpolicy/base"])
+	assert.True(t, fixed.generators["awslib/waste/ebs-volume-unattached"])
+}
@@ -0,0 +1,150 @@
+package asff
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service/securityhub"
+	"github.com/rs/zerolog/log"
+)
+
+type AwsClientPool interface {
+	GetContext() context.Context
+	GetClient(ptypes.AwsAccountID, ptypes.AwsRegion) (*v3.Client, error)
+}
+
+type accountRegion struct {
+	account ptypes.AwsAccountID
+	region  ptypes.AwsRegion
+}
+
+// PublishResult is the outcome of Publish.
+type PublishResult struct {
+	Imported int
+	// Archived counts the previously imported findings closed as stale.
+	Archived int
+	// Rejected holds the findings Security Hub refused, with the reason.
+	Rejected []types.ImportFindingsError
+	// Failed holds the account and region pairs whose import failed, e.g.
+	// because Security Hub is not enabled there.
+	Failed map[string]error
+}
+
+// Evaluated is what a run checked: the generators it ran, e.g. from
+// WasteGenerators and TagPolicyGenerator, over the accounts and regions it
+// swept. Include the default region, where findings of global resources go.
+type Evaluated struct {
+	Generators []string
+	Accounts   []ptypes.AwsAccountID
+	Regions    []ptypes.AwsRegion
+}
+
+// publishTarget is the batch of one account and region, with the generators
+// whose earlier findings there are to be reconciled.
+type publishTarget struct {
+	batch      []types.AwsSecurityFinding
+	generators map[string]bool
+}
+
+// Publish imports findings into Security Hub, each into the account and
+// region it is about, as BatchImportFindings requires for the default
+// product. Findings still open keep the CreatedAt of their first import.
+// In every evaluated account and region, findings imported before by an
+// evaluated generator, or one of the batch, and no longer reported are
+// archived: fixed resources do not stay open, even when a generator reports
+// nothing anymore. A failing account or region is recorded in Failed, and
+// the others are still published.
+func Publish(pool AwsClientPool, findings []types.AwsSecurityFinding, evaluated Evaluated, now time.Time) PublishResult {
+	result := PublishResult{Failed: map[string]error{}}
+
+	for key, target := range publishTargets(findings, evaluated) {
+		name := key.account.String() + ":" + key.region.String()
+
+		client, err := pool.GetClient(key.account, key.region)
+		if err != nil {
+			result.Failed[name] = err
+			continue
+		}
+
+		repo := securityhub.NewSecurityHubRepository(pool.GetContext(), client)
+
+		batch := target.batch
+		for generator := range target.generators {
+			previous, err := repo.ListFindingsByFilter(securityhub.NewFindingFilter().
+				WithAccount(key.account).
+				WithRecordState(types.RecordStateActive).
+				WithGeneratorPrefix(generator))
+			if err != nil {
+				log.Warn().Err(err).Str("generator", generator).Str("target", name).Msg("[asff.Publish] failed to read previous findings, not archiving")
+				continue
+			}
+
+			batch = securityhub.KeepCreatedAt(previous, batch)
+
+			stale := onlyGenerator(securityhub.Stale(previous, batch, now), generator)
+			batch = append(batch, stale...)
+			result.Archived += len(stale)
+		}
+
+		if len(batch) == 0 {
+			continue
+		}
+
+		imported, err := repo.ImportFindings(batch)
+		result.Imported += imported.Imported
+		result.Rejected = append(result.Rejected, imported.Failed...)
+		if err != nil {
+			result.Failed[name] = err
+		}
+	}
+
+	return result
+}
+
+// publishTargets groups findings by account and region, and adds every
+// evaluated account and region, with no findings if none are about it. Each
+// target reconciles the evaluated generators and those of its findings.
+func publishTargets(findings []types.AwsSecurityFinding, evaluated Evaluated) map[accountRegion]*publishTarget {
+	targets := map[accountRegion]*publishTarget{}
+	target := func(key accountRegion) *publishTarget {
+		if targets[key] == nil {
+			targets[key] = &publishTarget{generators: map[string]bool{}}
+			for _, generator := range evaluated.Generators {
+				targets[key].generators[generator] = true
+			}
+		}
+
+		return targets[key]
+	}
+
+	for _, account := range evaluated.Accounts {
+		for _, region := range evaluated.Regions {
+			target(accountRegion{account, region})
+		}
+	}
+
+	for _, finding := range findings {
+		t := target(accountRegion{ptypes.AwsAccountID(aws.ToString(finding.AwsAccountId)), ptypes.AwsRegion(aws.ToString(finding.Region))})
+		t.batch = append(t.batch, finding)
+		t.generators[aws.ToString(finding.GeneratorId)] = true
+	}
+
+	return targets
+}
+
+// onlyGenerator keeps the findings of exactly generator: a prefix filter on
+// "awslib/tagpolicy/base" also matches "awslib/tagpolicy/baseline".
+func onlyGenerator(findings []types.AwsSecurityFinding, generator string) []types.AwsSecurityFinding {
+	var kept []types.AwsSecurityFinding
+	for _, finding := range findings {
+		if aws.ToString(finding.GeneratorId) == generator {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept
+}
@@ -23,11 +23,16 @@ type AwsClientPool interface {
 
 const ResourceCreatorUnknown = "unknown"
 
+// EventSourceFactory returns the CloudTrail event source to look up the
+// events of an account and region in.
+type EventSourceFactory func(ptypes.AwsAccountID, ptypes.AwsRegion) (cloudtrail.EventSource, error)
+
 type AwsBlame struct {
 	ctx           context.Context
 	clients       AwsClientPool
 	ttl           time.Duration
 	resourceTypes []types.ResourceType
+	sources       EventSourceFactory
 }
 
 func NewAwsBlame(ctx context.Context, clients AwsClientPool) *AwsBlame {
@@ -35,7 +40,13 @@ func NewAwsBlame(ctx context.Context, clients AwsClientPool) *AwsBlame {
 }
 
 func (b *AwsBlame) WithTtl(ttl time.Duration) *AwsBlame {
-	return &AwsBlame{ctx: b.ctx, clients: b.clients, ttl: ttl}
+	return &AwsBlame{
+		ctx:           b.ctx,
+		clients:       b.clients,
+		ttl:           ttl,
+		resourceTypes: b.resourceTypes,
+		sources:       b.sources,
+	}
 }
 
 func (b *AwsBlame) WithResourceTypeList(resourceTypes []types.ResourceType) *AwsBlame {
@@ -44,10 +55,28 @@ func (b *AwsBlame) WithResourceTypeList(resourceTypes []types.ResourceType) *Aws
 		clients:       b.clients,
 		ttl:           b.ttl,
 		resourceTypes: resourceTypes,
+		sources:       b.sources,
+	}
+}
+
+// WithEventSource looks events up in the sources the factory returns, e.g. a
+// scoped cloudtrail.LakeRepository or cloudtrail.TrailLogSource, instead of
+// LookupEvents. The ttl is then best set to the retention of that source.
+func (b *AwsBlame) WithEventSource(sources EventSourceFactory) *AwsBlame {
+	return &AwsBlame{
+		ctx:           b.ctx,
+		clients:       b.clients,
+		ttl:           b.ttl,
+		resourceTypes: b.resourceTypes,
+		sources:       sources,
 	}
 }
 
-func (b *AwsBlame) getRepo(accountID ptypes.AwsAccountID, region ptypes.AwsRegion) (*cloudtrail.CloudTrailRepository, error) {
+func (b *AwsBlame) getSource(accountID ptypes.AwsAccountID, region ptypes.AwsRegion) (cloudtrail.EventSource, error) {
+	if b.sources != nil {
+		return b.sources(accountID, region)
+	}
+
 	client, err := b.clients.GetClient(accountID, region)
 	return cloudtrail.NewCloudTrailRepository(b.ctx, client), err
 }
@@ -88,8 +117,8 @@ func (b *AwsBlame) Lookup(resource service.ResourceInterface) (*ResourceEvents,
 		return NewResourceEvents(resource), nil
 	}
 
-	// get aws cloudtrail repository for resource AWS Region
-	eventsRepo, err := b.getRepo(resource.GetAccountID(), resource.GetRegion())
+	// get cloudtrail event source for resource AWS Region
+	events, err := b.getSource(resource.GetAccountID(), resource.GetRegion())
 	if err != nil {
 		return NewResourceEvents(resource), errors.New(err)
 	}
@@ -100,9 +129,12 @@ func (b *AwsBlame) Lookup(resource service.ResourceInterface) (*ResourceEvents,
 		WithEndTime(resource.GetCreatedAt().Add(2 * time.Minute)).
 		WithResource(resource)
 
-	events, err := eventsRepo.ListEventsByLookup(lookup)
+	found, err := events.ListEventsByLookup(lookup)
+	if err != nil {
+		return NewResourceEvents(resource, found...), errors.New(err)
+	}
 
-	return NewResourceEvents(resource, events...), errors.New(err)
+	return NewResourceEvents(resource, found...), nil
 }
 
 type ResourceEvents struct {
@@ -122,9 +154,43 @@ func (r *ResourceEvents) GetEntity() service.ResourceInterface {
 	return r.resource
 }
 
-func (r *ResourceEvents) GetUsername() string {
+// GetCreatorEvent returns the first event that changed the resource, the one
+// it is blamed on.
+func (r *ResourceEvents) GetCreatorEvent() (cloudtrail.Event, bool) {
 	events := slice.FilterNot(r.events, func(e cloudtrail.Event) bool { return e.IsReadOnly() })
-	if creator, ok := slice.Head(events).Get(); ok {
+
+	return slice.Head(events).Get()
+}
+
+// GetPrincipal returns who created the resource: the IAM user, role or root
+// account behind the creating call and, for an assumed role, the session,
+// which holds the person's name when they signed in through SSO.
+func (r *ResourceEvents) GetPrincipal() (cloudtrail.Principal, bool) {
+	creator, ok := r.GetCreatorEvent()
+	if !ok {
+		return cloudtrail.Principal{}, false
+	}
+
+	principal := creator.GetPrincipal()
+
+	return principal, !principal.IsZero()
+}
+
+// GetCreator names who created the resource, e.g.
+// "arn:aws:iam::123456789012:role/deploy (jane@example.com)", or
+// ResourceCreatorUnknown.
+func (r *ResourceEvents) GetCreator() string {
+	if principal, ok := r.GetPrincipal(); ok {
+		return principal.String()
+	}
+
+	return ResourceCreatorUnknown
+}
+
+// GetUsername returns the user name CloudTrail reports for the creating call:
+// for an assumed role, the session name alone. GetPrincipal says which role.
+func (r *ResourceEvents) GetUsername() string {
+	if creator, ok := r.GetCreatorEvent(); ok {
 		return creator.GetUsername()
 	}
 
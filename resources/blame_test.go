@@ -0,0 +1,93 @@
+package resources
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/cloudtrail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blameLog holds a describe call and the call that created the instance,
+// by an SSO user through an assumed role.
+const blameLog = `{"Records": [
+	{"eventID": "e-describe", "eventTime": "2026-03-02T10:14:00Z", "eventName": "DescribeInstances",
+	 "eventSource": "ec2.amazonaws.com", "readOnly": true, "recipientAccountId": "123456789012", "awsRegion": "eu-central-1",
+	 "userIdentity": {"type": "IAMUser", "arn": "arn:aws:iam::123456789012:user/auditor", "userName": "auditor"},
+	 "responseElements": {"instanceId": "i-0abc"}},
+	{"eventID": "e-run", "eventTime": "2026-03-02T10:15:00Z", "eventName": "RunInstances",
+	 "eventSource": "ec2.amazonaws.com", "readOnly": false, "recipientAccountId": "123456789012", "awsRegion": "eu-central-1",
+	 "userIdentity": {"type": "AssumedRole", "arn": "arn:aws:sts::123456789012:assumed-role/AWSReservedSSO_Admin_0123/jane@example.com",
+	   "sessionContext": {"sessionIssuer": {"type": "Role", "arn": "arn:aws:iam::123456789012:role/AWSReservedSSO_Admin_0123",
+	     "userName": "AWSReservedSSO_Admin_0123"}}},
+	 "responseElements": {"instanceId": "i-0abc"}}
+]}`
+
+// fakeEventSource answers every lookup with the events matching it.
+type fakeEventSource struct {
+	events []cloudtrail.Event
+}
+
+func (s fakeEventSource) ListEventsByLookup(lookup *cloudtrail.LookupMiddleware) ([]cloudtrail.Event, error) {
+	var found []cloudtrail.Event
+	for _, e := range s.events {
+		if lookup.Match(e) {
+			found = append(found, e)
+		}
+	}
+
+	return found, nil
+}
+
+func (s fakeEventSource) ListEventsByLookupAsync(*cloudtrail.LookupMiddleware) (<-chan cloudtrail.Event, <-chan *errors.Error) {
+	return nil, nil
+}
+
+type noClients struct{}
+
+func (noClients) GetContext() context.Context { return context.Background() }
+func (noClients) GetClient(ptypes.AwsAccountID, ptypes.AwsRegion) (*v3.Client, error) {
+	return nil, errors.New("no clients in tests")
+}
+
+func TestAwsBlameReportsPrincipal(t *testing.T) {
+	events, err := cloudtrail.ReadTrailLog(strings.NewReader(blameLog), false)
+	require.NoError(t, err)
+
+	instance := fakeResource{service.AbstractResource{
+		AccountID: "123456789012",
+		Region:    "eu-central-1",
+		ID:        "i-0abc",
+		CreatedAt: time.Date(2026, 3, 2, 10, 15, 30, 0, time.UTC),
+	}}
+
+	blame := NewAwsBlame(context.Background(), noClients{}).
+		WithTtl(100 * 365 * 24 * time.Hour).
+		WithEventSource(func(ptypes.AwsAccountID, ptypes.AwsRegion) (cloudtrail.EventSource, error) {
+			return fakeEventSource{events: events}, nil
+		})
+
+	found, err := blame.Lookup(instance)
+	require.NoError(t, err)
+	require.Len(t, found.GetEvents(), 2)
+
+	principal, ok := found.GetPrincipal()
+	require.True(t, ok)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/AWSReservedSSO_Admin_0123", principal.Arn)
+	assert.Equal(t, "jane@example.com", principal.Session)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/AWSReservedSSO_Admin_0123 (jane@example.com)", found.GetCreator())
+	assert.Equal(t, "jane@example.com", found.GetUsername())
+	assert.Equal(t, "jane", found.GetUser())
+
+	nobody := NewResourceEvents(instance)
+	_, ok = nobody.GetPrincipal()
+	assert.False(t, ok)
+	assert.Equal(t, ResourceCreatorUnknown, nobody.GetCreator())
+}
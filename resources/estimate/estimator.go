@@ -0,0 +1,266 @@
+package estimate
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/resources/waste"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/shopspring/decimal"
+)
+
+// Status tells whether a resource could be priced.
+type Status string
+
+const (
+	StatusPriced  Status = "priced"
+	StatusUnknown Status = "unknown"
+)
+
+// Untagged groups the resources without the tag a report is rolled up by.
+const Untagged = "(untagged)"
+
+// Estimate is the estimated on-demand monthly cost of one resource. Monthly
+// is zero and Reason says why when Status is StatusUnknown: a zero cost and an
+// unknown one are never confused.
+type Estimate struct {
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+	ResourceType cfg.ResourceType    `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	Name         string              `json:"name,omitempty"`
+	Tags         map[string]string   `json:"tags,omitempty"`
+
+	Status  Status          `json:"status"`
+	Monthly decimal.Decimal `json:"monthly"`
+	Basis   string          `json:"basis,omitempty"`
+	Reason  string          `json:"reason,omitempty"`
+
+	Resource service.ResourceInterface `json:"-"`
+}
+
+// Estimator attaches an on-demand monthly cost estimate to inventoried
+// resources, from the Price List API or an offline pricing.Catalog.
+type Estimator struct {
+	lookup pricing.PriceLookup
+	rates  map[cfg.ResourceType]RateFunc
+}
+
+func NewEstimator(lookup pricing.PriceLookup) *Estimator {
+	return &Estimator{lookup: lookup, rates: DefaultRates()}
+}
+
+// WithRate adds or replaces the rate of a resource type.
+func (e *Estimator) WithRate(resourceType cfg.ResourceType, rate RateFunc) *Estimator {
+	e.rates[resourceType] = rate
+	return e
+}
+
+// Estimate prices every resource. Resources that cannot be priced are in the
+// report as unknown; err joins the failed price lookups, each once, and the
+// resources they concern are unknown too.
+func (e *Estimator) Estimate(resources []service.ResourceInterface) (*Report, error) {
+	prices := newPrices(e.lookup)
+	report := &Report{}
+	if catalog, ok := e.lookup.(*pricing.Catalog); ok {
+		report.Catalog = catalog.Version()
+	}
+
+	var errs []error
+	seen := map[string]bool{}
+
+	for _, resource := range resources {
+		estimate, err := e.estimate(prices, resource)
+		if err != nil && !seen[err.Error()] {
+			seen[err.Error()] = true
+			errs = append(errs, err)
+		}
+
+		report.Estimates = append(report.Estimates, estimate)
+	}
+
+	return report, stderrors.Join(errs...)
+}
+
+// MonthlyCost makes the Estimator a waste.Pricer, so waste findings carry the
+// same figures as the inventory estimate. Lookups are not memoized across
+// calls; pass a cached repository or a catalog.
+func (e *Estimator) MonthlyCost(resource service.ResourceInterface) (float64, bool, error) {
+	estimate, err := e.estimate(newPrices(e.lookup), resource)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return estimate.Monthly.InexactFloat64(), estimate.Status == StatusPriced, nil
+}
+
+var _ waste.Pricer = (*Estimator)(nil)
+
+func (e *Estimator) estimate(prices *Prices, resource service.ResourceInterface) (Estimate, error) {
+	estimate := Estimate{
+		AccountID:    resource.GetAccountID(),
+		Region:       resource.GetRegion(),
+		ResourceType: resource.GetType(),
+		ResourceID:   resource.GetIdOrArn(),
+		Name:         resource.GetName(),
+		Tags:         resource.GetTags(),
+		Status:       StatusUnknown,
+		Resource:     resource,
+	}
+
+	rate, ok := e.rates[resource.GetType()]
+	if !ok {
+		estimate.Reason = fmt.Sprintf("no rate for %s", resource.GetType())
+		return estimate, nil
+	}
+
+	r, err := rate(prices, resource)
+	if err != nil {
+		estimate.Reason = err.Error()
+		if stderrors.Is(err, ErrUnpriced) {
+			return estimate, nil
+		}
+
+		return estimate, err
+	}
+
+	estimate.Status = StatusPriced
+	estimate.Monthly = r.Monthly
+	estimate.Basis = r.Basis
+
+	return estimate, nil
+}
+
+// Rollup is the estimated cost of a group of resources.
+type Rollup struct {
+	Key     string          `json:"key"`
+	Monthly decimal.Decimal `json:"monthly"`
+	Priced  int             `json:"priced"`
+	Unknown int             `json:"unknown"`
+}
+
+// Report is the estimate of an inventory. Catalog is the version of the
+// pricing.Catalog the prices came from, empty when they came from the API.
+type Report struct {
+	Catalog   string     `json:"catalog,omitempty"`
+	Estimates []Estimate `json:"estimates"`
+}
+
+// Total sums the priced estimates; it is a lower bound when Unknown is not
+// empty.
+func (r *Report) Total() decimal.Decimal {
+	total := decimal.Zero
+	for _, e := range r.Estimates {
+		total = total.Add(e.Monthly)
+	}
+
+	return total
+}
+
+// Unknown returns the estimates of the resources that could not be priced.
+func (r *Report) Unknown() []Estimate {
+	var unknown []Estimate
+	for _, e := range r.Estimates {
+		if e.Status == StatusUnknown {
+			unknown = append(unknown, e)
+		}
+	}
+
+	return unknown
+}
+
+func (r *Report) ByAccount() []Rollup {
+	return r.rollup(func(e Estimate) string { return e.AccountID.String() })
+}
+
+func (r *Report) ByRegion() []Rollup {
+	return r.rollup(func(e Estimate) string { return e.Region.String() })
+}
+
+func (r *Report) ByResourceType() []Rollup {
+	return r.rollup(func(e Estimate) string { return string(e.ResourceType) })
+}
+
+// ByTag rolls up by the value of a tag; resources without it fall under
+// Untagged.
+func (r *Report) ByTag(key string) []Rollup {
+	return r.rollup(func(e Estimate) string {
+		if value := e.Tags[key]; value != "" {
+			return value
+		}
+		return Untagged
+	})
+}
+
+// rollup groups the estimates by key, most expensive group first.
+func (r *Report) rollup(key func(Estimate) string) []Rollup {
+	groups := map[string]*Rollup{}
+	for _, e := range r.Estimates {
+		k := key(e)
+		g, ok := groups[k]
+		if !ok {
+			g = &Rollup{Key: k}
+			groups[k] = g
+		}
+
+		g.Monthly = g.Monthly.Add(e.Monthly)
+		if e.Status == StatusPriced {
+			g.Priced++
+		} else {
+			g.Unknown++
+		}
+	}
+
+	rollups := make([]Rollup, 0, len(groups))
+	for _, g := range groups {
+		rollups = append(rollups, *g)
+	}
+
+	sort.Slice(rollups, func(i, j int) bool {
+		if !rollups[i].Monthly.Equal(rollups[j].Monthly) {
+			return rollups[i].Monthly.GreaterThan(rollups[j].Monthly)
+		}
+		return rollups[i].Key < rollups[j].Key
+	})
+
+	return rollups
+}
+
+// WriteTable renders one line per resource, most expensive first, unknown
+// ones last with their reason.
+func (r *Report) WriteTable(w io.Writer) error {
+	estimates := append([]Estimate(nil), r.Estimates...)
+	sort.SliceStable(estimates, func(i, j int) bool {
+		if estimates[i].Status != estimates[j].Status {
+			return estimates[i].Status == StatusPriced
+		}
+		return estimates[i].Monthly.GreaterThan(estimates[j].Monthly)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%d resources, %d unknown, %s USD per month\n",
+		len(r.Estimates), len(r.Unknown()), r.Total().StringFixed(2))
+	if len(estimates) == 0 {
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "ACCOUNT\tREGION\tTYPE\tRESOURCE\tNAME\tMONTHLY\tBASIS")
+	for _, e := range estimates {
+		monthly, basis := e.Monthly.StringFixed(2), e.Basis
+		if e.Status == StatusUnknown {
+			monthly, basis = "unknown", e.Reason
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.AccountID, e.Region, e.ResourceType, e.ResourceID, e.Name, monthly, basis)
+	}
+
+	return tw.Flush()
+}
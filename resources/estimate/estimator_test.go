@@ -0,0 +1,175 @@
+package estimate
+
+import (
+	"bytes"
+	stderrors "errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/imunhatep/awslib/service/rds"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func item(sku, family string, attributes map[string]string, usd string) pricing.PriceItem {
+	attributes["regionCode"] = "eu-west-1"
+
+	return pricing.PriceItem{
+		Product: pricing.PriceItemProduct{SKU: sku, ProductFamily: family, Attributes: attributes},
+		Terms: pricing.Terms{OnDemand: map[string]pricing.Term{sku + ".T": {
+			PriceDimensions: map[string]pricing.PriceDimension{sku + ".T.1": {
+				BeginRange: "0", EndRange: "Inf", PricePerUnit: pricing.PricePerUnit{USD: usd},
+			}},
+		}}},
+	}
+}
+
+func catalog() *pricing.Catalog {
+	return pricing.NewCatalog(&pricing.Offer{OfferCode: pricing.ServiceCodeEC2, Version: "1", Items: []pricing.PriceItem{
+		item("I1", "Compute Instance", map[string]string{
+			"instanceType": "m5.large", "operatingSystem": "Linux", "preInstalledSw": "NA", "tenancy": "Shared", "capacitystatus": "Used",
+		}, "0.1"),
+		item("V1", "Storage", map[string]string{"volumeApiName": "gp3"}, "0.08"),
+		item("V2", "System Operation", map[string]string{"volumeApiName": "gp3"}, "0.005"),
+		item("V3", "Provisioned Throughput", map[string]string{"volumeApiName": "gp3"}, "0.04"),
+		item("A1", "IP Address", map[string]string{"group": "ElasticIP:Address"}, "0.005"),
+		item("S1", "Storage Snapshot", map[string]string{"usagetype": "EU-EBS:SnapshotUsage"}, "0.05"),
+	}})
+}
+
+func instance(id string, state ec2types.InstanceStateName, platform string, tags ...ec2types.Tag) service.ResourceInterface {
+	return ec2.NewInstance(mockClient{}, ec2types.Instance{
+		InstanceId:      aws.String(id),
+		InstanceType:    ec2types.InstanceTypeM5Large,
+		State:           &ec2types.InstanceState{Name: state},
+		PlatformDetails: aws.String(platform),
+		Tags:            tags,
+	})
+}
+
+func TestEstimate(t *testing.T) {
+	team := ec2types.Tag{Key: aws.String("team"), Value: aws.String("payments")}
+
+	resources := []service.ResourceInterface{
+		instance("i-running", ec2types.InstanceStateNameRunning, "Linux/UNIX", team),
+		instance("i-stopped", ec2types.InstanceStateNameStopped, "Linux/UNIX", team),
+		instance("i-windows", ec2types.InstanceStateNameRunning, "Windows"),
+		ec2.NewVolume(mockClient{}, ec2types.Volume{
+			VolumeId: aws.String("vol-1"), VolumeType: ec2types.VolumeTypeGp3, Size: aws.Int32(100),
+			Iops: aws.Int32(4000), Throughput: aws.Int32(125),
+		}),
+		ec2.NewAddress(mockClient{}, ec2types.Address{AllocationId: aws.String("eipalloc-1")}),
+		ec2.NewSnapshot(mockClient{}, ec2types.Snapshot{SnapshotId: aws.String("snap-1"), VolumeSize: aws.Int32(50)}),
+	}
+
+	c := catalog()
+	report, err := NewEstimator(c).Estimate(resources)
+	require.NoError(t, err)
+	assert.Equal(t, c.Version(), report.Catalog)
+
+	byID := map[string]Estimate{}
+	for _, e := range report.Estimates {
+		byID[e.ResourceID] = e
+	}
+
+	assert.Equal(t, "73", byID["i-running"].Monthly.String())
+	assert.Equal(t, "m5.large × 730 h", byID["i-running"].Basis)
+	assert.Equal(t, StatusPriced, byID["i-stopped"].Status)
+	assert.True(t, byID["i-stopped"].Monthly.IsZero())
+
+	assert.Equal(t, StatusUnknown, byID["i-windows"].Status)
+	assert.Contains(t, byID["i-windows"].Reason, "Windows")
+
+	// 100 GiB × 0.08 + 1000 IOPS above the baseline × 0.005
+	assert.Equal(t, "13", byID["vol-1"].Monthly.String())
+	assert.Equal(t, "3.65", byID["eipalloc-1"].Monthly.String())
+
+	assert.Equal(t, "2.5", byID["snap-1"].Monthly.String())
+	assert.Equal(t, "92.15", report.Total().String())
+	assert.Len(t, report.Unknown(), 1)
+
+	byTeam := report.ByTag("team")
+	require.Len(t, byTeam, 2)
+	assert.Equal(t, "payments", byTeam[0].Key)
+	assert.Equal(t, "73", byTeam[0].Monthly.String())
+	assert.Equal(t, 2, byTeam[0].Priced)
+	assert.Equal(t, Untagged, byTeam[1].Key)
+	assert.Equal(t, "19.15", byTeam[1].Monthly.String())
+	assert.Equal(t, 1, byTeam[1].Unknown)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteTable(&out))
+	assert.Contains(t, out.String(), "6 resources, 1 unknown, 92.15 USD per month")
+}
+
+func TestEstimateLookupFailure(t *testing.T) {
+	db := func(id string) service.ResourceInterface {
+		return rds.NewDbInstance(mockClient{}, rdstypes.DBInstance{
+			DBInstanceIdentifier: aws.String(id), DBInstanceClass: aws.String("db.t4g.micro"), Engine: aws.String("postgres"),
+		})
+	}
+
+	report, err := NewEstimator(catalog()).Estimate([]service.ResourceInterface{db("db-1"), db("db-2")})
+
+	// the catalog has no RDS offer: a failure, reported once, not an unpriced type
+	require.Error(t, err)
+	assert.Len(t, report.Unknown(), 2)
+	assert.Contains(t, err.Error(), `no offer for service "AmazonRDS"`)
+}
+
+func TestEstimatorIsWastePricer(t *testing.T) {
+	cost, ok, err := NewEstimator(catalog()).MonthlyCost(instance("i-1", ec2types.InstanceStateNameRunning, "Linux/UNIX"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, 73.0, cost, 0.001)
+}
+
+// flakyLookup fails the first instance lookups, then answers from the catalog.
+type flakyLookup struct {
+	pricing.PriceLookup
+	failures int
+	calls    int
+}
+
+func (l *flakyLookup) GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*pricing.Ec2Product, error) {
+	l.calls++
+	if l.calls <= l.failures {
+		return nil, stderrors.New("throttled")
+	}
+
+	return l.PriceLookup.GetInstancePricing(region, instanceType)
+}
+
+func TestPricesRetryTransientFailures(t *testing.T) {
+	lookup := &flakyLookup{PriceLookup: catalog(), failures: 1}
+	prices := newPrices(lookup)
+
+	_, err := prices.InstanceHourly("eu-west-1", ec2types.InstanceTypeM5Large)
+	require.Error(t, err)
+
+	hourly, err := prices.InstanceHourly("eu-west-1", ec2types.InstanceTypeM5Large)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1", hourly.String())
+
+	_, err = prices.InstanceHourly("eu-west-1", ec2types.InstanceTypeM5Large)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lookup.calls, "a price found is memoized")
+
+	// unpriced answers are memoized too
+	_, err = prices.InstanceHourly("eu-west-1", ec2types.InstanceTypeT3Nano)
+	require.ErrorIs(t, err, ErrUnpriced)
+	_, err = prices.InstanceHourly("eu-west-1", ec2types.InstanceTypeT3Nano)
+	require.ErrorIs(t, err, ErrUnpriced)
+	assert.Equal(t, 3, lookup.calls)
+}
@@ -0,0 +1,208 @@
+package estimate
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnpriced is returned, wrapped with the reason, by rates that cannot
+// price a resource. The resource is then reported as unknown rather than as
+// a failure.
+var ErrUnpriced = stderrors.New("unpriced")
+
+// Prices memoizes the lookups of one estimation run, so an inventory of a
+// thousand m5.large instances costs one price lookup, not a thousand. It is
+// safe for concurrent use.
+type Prices struct {
+	lookup pricing.PriceLookup
+
+	mx   sync.Mutex
+	memo map[string]*memoEntry
+}
+
+// memoEntry is one lookup, done once its fetch returns. Callers of the same
+// key wait on it instead of fetching again.
+type memoEntry struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newPrices(lookup pricing.PriceLookup) *Prices {
+	return &Prices{lookup: lookup, memo: map[string]*memoEntry{}}
+}
+
+// Lookup returns the underlying price lookup, for rates needing a product the
+// helpers below do not cover.
+func (p *Prices) Lookup() pricing.PriceLookup {
+	return p.lookup
+}
+
+// InstanceHourly returns the Linux on-demand hourly price of an EC2 instance
+// type.
+func (p *Prices) InstanceHourly(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (decimal.Decimal, error) {
+	return memo(p, "instance:"+region.String()+":"+string(instanceType), func() (decimal.Decimal, error) {
+		product, err := p.lookup.GetInstancePricing(region, instanceType)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		if product == nil {
+			return decimal.Zero, unpriced("no on-demand price for %s in %s", instanceType, region)
+		}
+
+		hourly, err := decimal.NewFromString(product.GetOnDemandPrice())
+		if err != nil {
+			return decimal.Zero, unpriced("no on-demand price for %s in %s", instanceType, region)
+		}
+
+		return hourly, nil
+	})
+}
+
+// Volume returns the prices of an EBS volume type.
+func (p *Prices) Volume(region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*pricing.VolumePricing, error) {
+	return memo(p, "volume:"+region.String()+":"+string(volumeType), func() (*pricing.VolumePricing, error) {
+		prices, err := p.lookup.GetVolumePricing(region, volumeType)
+		if err == nil && prices == nil {
+			err = unpriced("no price for %s volumes in %s", volumeType, region)
+		}
+
+		return prices, err
+	})
+}
+
+// RdsHourly returns the on-demand hourly price of an RDS instance class.
+func (p *Prices) RdsHourly(region ptypes.AwsRegion, instanceClass, engine, deploymentOption string) (decimal.Decimal, error) {
+	key := fmt.Sprintf("rds:%s:%s:%s:%s", region, instanceClass, engine, deploymentOption)
+
+	return memo(p, key, func() (decimal.Decimal, error) {
+		product, err := p.lookup.GetRdsInstancePricing(region, instanceClass, engine, deploymentOption)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		var item *pricing.PriceItem
+		if product != nil {
+			item = &product.PriceItem
+		}
+
+		return hourly(item, "%s %s %s in %s", instanceClass, engine, deploymentOption, region)
+	})
+}
+
+// LoadBalancerHourly returns the hourly price of a load balancer type.
+func (p *Prices) LoadBalancerHourly(region ptypes.AwsRegion, lbType pricing.LoadBalancerType) (decimal.Decimal, error) {
+	return memo(p, "lb:"+region.String()+":"+string(lbType), func() (decimal.Decimal, error) {
+		prices, err := p.lookup.GetLoadBalancerPricing(region, lbType)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		var item *pricing.PriceItem
+		if prices != nil {
+			item = prices.Hours
+		}
+
+		return hourly(item, "%s load balancers in %s", lbType, region)
+	})
+}
+
+// NatGatewayHourly returns the hourly price of a NAT gateway.
+func (p *Prices) NatGatewayHourly(region ptypes.AwsRegion) (decimal.Decimal, error) {
+	return memo(p, "nat:"+region.String(), func() (decimal.Decimal, error) {
+		prices, err := p.lookup.GetNatGatewayPricing(region)
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		var item *pricing.PriceItem
+		if prices != nil {
+			item = prices.Hours
+		}
+
+		return hourly(item, "NAT gateways in %s", region)
+	})
+}
+
+// ProductPrice returns the first tier price of the first product matching the
+// filters, in whatever unit the product is priced, e.g. hourly for Elastic IPs
+// or per GB-month for snapshots.
+func (p *Prices) ProductPrice(what, serviceCode string, filters ...pricingtypes.Filter) (decimal.Decimal, error) {
+	key := "product:" + serviceCode
+	for _, f := range filters {
+		key += ":" + aws.ToString(f.Field) + "=" + aws.ToString(f.Value)
+	}
+
+	return memo(p, key, func() (decimal.Decimal, error) {
+		items, err := p.lookup.GetProductsByInput(&awspricing.GetProductsInput{
+			ServiceCode: aws.String(serviceCode),
+			Filters:     filters,
+		})
+		if err != nil {
+			return decimal.Zero, err
+		}
+
+		var item *pricing.PriceItem
+		if len(items) > 0 {
+			item = &items[0]
+		}
+
+		return hourly(item, "%s", what)
+	})
+}
+
+func hourly(item *pricing.PriceItem, format string, args ...any) (decimal.Decimal, error) {
+	if item != nil {
+		if tier, ok := item.OnDemandPrice(); ok {
+			return tier.Price, nil
+		}
+	}
+
+	return decimal.Zero, unpriced("no price for "+format, args...)
+}
+
+func unpriced(format string, args ...any) error {
+	return errors.Errorf("%w: "+format, append([]any{ErrUnpriced}, args...)...)
+}
+
+// memo runs fetch once per key and run. Concurrent callers of a key share
+// the fetch in flight, which runs outside the lock. A resource found
+// unpriced stays so for the run; any other failure is forgotten once
+// returned, so the next caller asks again.
+func memo[T any](p *Prices, key string, fetch func() (T, error)) (T, error) {
+	p.mx.Lock()
+	entry, ok := p.memo[key]
+	if !ok {
+		entry = &memoEntry{done: make(chan struct{})}
+		p.memo[key] = entry
+	}
+	p.mx.Unlock()
+
+	if ok {
+		<-entry.done
+		return entry.value.(T), entry.err
+	}
+
+	value, err := fetch()
+	entry.value, entry.err = value, err
+
+	if err != nil && !stderrors.Is(err, ErrUnpriced) {
+		p.mx.Lock()
+		delete(p.memo, key)
+		p.mx.Unlock()
+	}
+	close(entry.done)
+
+	return value, err
+}
@@ -0,0 +1,261 @@
+package estimate
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/elb"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/imunhatep/awslib/service/rds"
+	"github.com/shopspring/decimal"
+)
+
+// gp3 volumes include this much IOPS and throughput in the storage price.
+const (
+	gp3BaselineIops       = 3000
+	gp3BaselineThroughput = 125
+)
+
+// Rate is the monthly on-demand cost of one resource. Basis says what the
+// figure covers, e.g. "m5.large × 730 h", and names the charges it leaves out.
+type Rate struct {
+	Monthly decimal.Decimal
+	Basis   string
+}
+
+// RateFunc prices one resource. It returns an error wrapping ErrUnpriced when
+// the resource cannot be priced, any other error when a price lookup failed.
+type RateFunc func(prices *Prices, resource service.ResourceInterface) (Rate, error)
+
+// DefaultRates prices EC2 instances, EBS volumes and snapshots, RDS instances,
+// Elastic IPs, VPC endpoints, load balancers and, as inventoried through Cloud
+// Control, NAT gateways.
+func DefaultRates() map[cfg.ResourceType]RateFunc {
+	return map[cfg.ResourceType]RateFunc{
+		cfg.ResourceTypeInstance:       InstanceRate,
+		cfg.ResourceTypeVolume:         VolumeRate,
+		cfgEntity.ResourceTypeSnapshot: SnapshotRate,
+		cfg.ResourceTypeDBInstance:     DbInstanceRate,
+		cfg.ResourceTypeEip:            AddressRate,
+		cfg.ResourceTypeVPCEndpoint:    VpcEndpointRate,
+		cfg.ResourceTypeLoadBalancerV2: LoadBalancerRate,
+		cfg.ResourceTypeNatGateway:     NatGatewayRate,
+	}
+}
+
+// InstanceRate prices running instances at the Linux on-demand rate of their
+// type. Stopped instances cost nothing for compute; Windows and other licensed
+// platforms are reported as unpriced rather than underestimated.
+func InstanceRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	instance, ok := resource.(ec2.Instance)
+	if !ok {
+		return Rate{}, unpriced("%T is not an ec2.Instance", resource)
+	}
+
+	if instance.State != nil && instance.State.Name != ec2types.InstanceStateNameRunning {
+		return Rate{Basis: "not running"}, nil
+	}
+
+	if instance.Platform != "" || (instance.PlatformDetails != nil && aws.ToString(instance.PlatformDetails) != "Linux/UNIX") {
+		return Rate{}, unpriced("platform %s is not priced", aws.ToString(instance.PlatformDetails))
+	}
+
+	hourly, err := prices.InstanceHourly(instance.GetRegion(), instance.InstanceType)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return hours(hourly, string(instance.InstanceType)), nil
+}
+
+// VolumeRate prices provisioned storage and, for io1, io2 and gp3, the IOPS
+// and throughput provisioned beyond what the storage price includes.
+func VolumeRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	volume, ok := resource.(ec2.Volume)
+	if !ok {
+		return Rate{}, unpriced("%T is not an ec2.Volume", resource)
+	}
+
+	vp, err := prices.Volume(volume.GetRegion(), volume.VolumeType)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	size := decimal.NewFromInt32(volume.GetSize())
+	monthly := vp.Storage.OnDemandCost(size)
+	basis := fmt.Sprintf("%s GiB %s", size, volume.VolumeType)
+
+	iops := int64(aws.ToInt32(volume.Iops))
+	throughput := int64(aws.ToInt32(volume.Throughput))
+	if volume.VolumeType == ec2types.VolumeTypeGp3 {
+		iops = max(iops-gp3BaselineIops, 0)
+		throughput = max(throughput-gp3BaselineThroughput, 0)
+	}
+
+	if vp.Iops != nil && iops > 0 {
+		monthly = monthly.Add(vp.Iops.OnDemandCost(decimal.NewFromInt(iops)))
+		basis += fmt.Sprintf(" + %d IOPS", iops)
+	}
+
+	if vp.Throughput != nil && throughput > 0 {
+		monthly = monthly.Add(vp.Throughput.OnDemandCost(decimal.NewFromInt(throughput)))
+		basis += fmt.Sprintf(" + %d MiB/s", throughput)
+	}
+
+	return Rate{Monthly: monthly, Basis: basis}, nil
+}
+
+// SnapshotRate prices standard snapshot storage at the size of the source
+// volume. Snapshots are incremental, so this is an upper bound for all but the
+// first snapshot of a volume.
+func SnapshotRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	snapshot, ok := resource.(ec2.Snapshot)
+	if !ok {
+		return Rate{}, unpriced("%T is not an ec2.Snapshot", resource)
+	}
+
+	region := snapshot.GetRegion()
+	usage, ok := pricing.UsageType(region, "EBS:SnapshotUsage")
+	if !ok {
+		return Rate{}, unpriced("no usage type for snapshots in %s", region)
+	}
+
+	perGb, err := prices.ProductPrice("snapshot storage in "+region.String(), pricing.ServiceCodeEC2,
+		pricing.TermMatch("productFamily", "Storage Snapshot"),
+		pricing.TermMatch("usagetype", usage),
+		pricing.TermMatch("regionCode", region.String()),
+	)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	size := decimal.NewFromInt32(aws.ToInt32(snapshot.VolumeSize))
+
+	return Rate{Monthly: perGb.Mul(size), Basis: fmt.Sprintf("%s GiB snapshot, at most", size)}, nil
+}
+
+// DbInstanceRate prices the instance hours of available RDS instances; storage,
+// I/O and backups are not included.
+func DbInstanceRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	db, ok := resource.(rds.DbInstance)
+	if !ok {
+		return Rate{}, unpriced("%T is not an rds.DbInstance", resource)
+	}
+
+	if aws.ToString(db.DBInstanceStatus) == "stopped" {
+		return Rate{Basis: "stopped"}, nil
+	}
+
+	deployment := pricing.DeploymentSingleAZ
+	if aws.ToBool(db.MultiAZ) {
+		deployment = pricing.DeploymentMultiAZ
+	}
+
+	class := aws.ToString(db.DBInstanceClass)
+	hourly, err := prices.RdsHourly(db.GetRegion(), class, aws.ToString(db.Engine), deployment)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	rate := hours(hourly, class+" "+deployment)
+	rate.Basis += ", storage not included"
+
+	return rate, nil
+}
+
+// AddressRate prices Elastic IPs by the hour, attached or not: every public
+// IPv4 address is billed.
+func AddressRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	region := resource.GetRegion()
+
+	hourly, err := prices.ProductPrice("Elastic IPs in "+region.String(), pricing.ServiceCodeEC2,
+		pricing.TermMatch("productFamily", "IP Address"),
+		pricing.TermMatch("group", "ElasticIP:Address"),
+		pricing.TermMatch("regionCode", region.String()),
+	)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return hours(hourly, "public IPv4"), nil
+}
+
+// VpcEndpointRate prices interface and Gateway Load Balancer endpoints per
+// subnet and hour, data processed not included. Gateway endpoints are free.
+func VpcEndpointRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	endpoint, ok := resource.(ec2.VpcEndpoint)
+	if !ok {
+		return Rate{}, unpriced("%T is not an ec2.VpcEndpoint", resource)
+	}
+
+	if endpoint.VpcEndpointType == ec2types.VpcEndpointTypeGateway {
+		return Rate{Basis: "gateway endpoint"}, nil
+	}
+
+	region := endpoint.GetRegion()
+	usage, ok := pricing.UsageType(region, "VpcEndpoint-Hours")
+	if !ok {
+		return Rate{}, unpriced("no usage type for VPC endpoints in %s", region)
+	}
+
+	hourly, err := prices.ProductPrice("VPC endpoints in "+region.String(), pricing.ServiceCodeVPC,
+		pricing.TermMatch("productFamily", "VpcEndpoint"),
+		pricing.TermMatch("usagetype", usage),
+		pricing.TermMatch("regionCode", region.String()),
+	)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	subnets := max(len(endpoint.SubnetIds), 1)
+	rate := hours(hourly.Mul(decimal.NewFromInt(int64(subnets))), fmt.Sprintf("%d ENI", subnets))
+	rate.Basis += ", data processed not included"
+
+	return rate, nil
+}
+
+// LoadBalancerRate prices load balancer hours; capacity units are not
+// included, as they depend on traffic.
+func LoadBalancerRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	lb, ok := resource.(elb.LoadBalancer)
+	if !ok {
+		return Rate{}, unpriced("%T is not an elb.LoadBalancer", resource)
+	}
+
+	lbType := pricing.LoadBalancerType(lb.LoadBalancer.Type)
+	hourly, err := prices.LoadBalancerHourly(lb.GetRegion(), lbType)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	rate := hours(hourly, string(lbType))
+	rate.Basis += ", capacity units not included"
+
+	return rate, nil
+}
+
+// NatGatewayRate prices NAT gateway hours of any resource of the NAT gateway
+// type, typically a cloudcontrol.Resource; data processed is not included.
+func NatGatewayRate(prices *Prices, resource service.ResourceInterface) (Rate, error) {
+	hourly, err := prices.NatGatewayHourly(resource.GetRegion())
+	if err != nil {
+		return Rate{}, err
+	}
+
+	rate := hours(hourly, "NAT gateway")
+	rate.Basis += ", data processed not included"
+
+	return rate, nil
+}
+
+func hours(hourly decimal.Decimal, what string) Rate {
+	return Rate{
+		Monthly: hourly.Mul(decimal.NewFromInt(pricing.HoursPerMonth)),
+		Basis:   fmt.Sprintf("%s × %d h", what, pricing.HoursPerMonth),
+	}
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+)
+
+// node is the exported shape of a resource: enough to label it and find it
+// again, without serialising the whole SDK struct.
+type node struct {
+	Key       string           `json:"key"`
+	ID        string           `json:"id"`
+	Arn       string           `json:"arn,omitempty"`
+	Name      string           `json:"name"`
+	Type      cfg.ResourceType `json:"type"`
+	AccountID string           `json:"accountId"`
+	Region    string           `json:"region"`
+}
+
+type document struct {
+	Nodes []node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+func (g *Graph) document() document {
+	doc := document{Nodes: []node{}, Edges: g.Edges()}
+
+	for _, r := range g.Nodes() {
+		doc.Nodes = append(doc.Nodes, node{
+			Key:       Key(r),
+			ID:        r.GetId(),
+			Arn:       r.GetArn(),
+			Name:      r.GetName(),
+			Type:      r.GetType(),
+			AccountID: r.GetAccountID().String(),
+			Region:    r.GetRegion().String(),
+		})
+	}
+
+	return doc
+}
+
+// MarshalJSON renders the graph as {"nodes": [...], "edges": [...]}.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.document())
+}
+
+// WriteDot renders the graph in Graphviz DOT. Edge targets that are not in the
+// graph are drawn as dashed nodes, so what the inventory references but does not
+// hold is visible rather than silently missing.
+func (g *Graph) WriteDot(w io.Writer) error {
+	doc := g.document()
+
+	known := map[string]bool{}
+	if _, err := fmt.Fprintln(w, "digraph awslib {"); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+
+	for _, n := range doc.Nodes {
+		known[n.Key] = true
+		label := fmt.Sprintf("%s\n%s", cfgEntity.ResourceTypeToString(n.Type), n.Name)
+		fmt.Fprintf(w, "  %s [label=%s];\n", strconv.Quote(n.Key), strconv.Quote(label))
+	}
+
+	for _, e := range doc.Edges {
+		for _, ref := range []string{e.From, e.To} {
+			if !known[ref] {
+				known[ref] = true
+				fmt.Fprintf(w, "  %s [style=dashed];\n", strconv.Quote(ref))
+			}
+		}
+	}
+
+	for _, e := range doc.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(string(e.Kind)))
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
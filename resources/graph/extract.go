@@ -0,0 +1,267 @@
+package graph
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/ecs"
+	"github.com/imunhatep/awslib/service/eks"
+	"github.com/imunhatep/awslib/service/elb"
+	"github.com/imunhatep/awslib/service/lambda"
+	"github.com/imunhatep/awslib/service/rds"
+)
+
+// ExtractorFunc returns the edges a resource carries. Edge endpoints may be IDs
+// or ARNs; the graph resolves them to node keys.
+type ExtractorFunc func(resource service.ResourceInterface) []Edge
+
+// DefaultExtractors returns the built-in extractor per resource type. The map is
+// a fresh copy, so callers may modify it.
+func DefaultExtractors() map[cfg.ResourceType]ExtractorFunc {
+	return map[cfg.ResourceType]ExtractorFunc{
+		cfg.ResourceTypeSubnet:         extractSubnet,
+		cfg.ResourceTypeSecurityGroup:  extractSecurityGroup,
+		cfg.ResourceTypeRouteTable:     extractRouteTable,
+		cfg.ResourceTypeVPCEndpoint:    extractVpcEndpoint,
+		cfg.ResourceTypeInstance:       extractInstance,
+		cfg.ResourceTypeVolume:         extractVolume,
+		cfg.ResourceTypeEip:            extractAddress,
+		cfg.ResourceTypeECSService:     extractEcsService,
+		cfg.ResourceTypeEKSCluster:     extractEksCluster,
+		cfg.ResourceTypeFunction:       extractLambdaFunction,
+		cfg.ResourceTypeLoadBalancerV2: extractLoadBalancer,
+		cfg.ResourceTypeDBInstance:     extractDbInstance,
+	}
+}
+
+// edgeBuilder collects edges from one resource, dropping empty references so
+// extractors can pass optional SDK fields straight through.
+type edgeBuilder struct {
+	self  string
+	edges []Edge
+}
+
+func newEdgeBuilder(resource service.ResourceInterface) *edgeBuilder {
+	return &edgeBuilder{self: Key(resource)}
+}
+
+// to adds edges from the resource to each target.
+func (b *edgeBuilder) to(kind EdgeKind, targets ...string) *edgeBuilder {
+	for _, target := range targets {
+		if target != "" {
+			b.edges = append(b.edges, Edge{From: b.self, To: target, Kind: kind})
+		}
+	}
+
+	return b
+}
+
+// from adds edges from each source to the resource.
+func (b *edgeBuilder) from(kind EdgeKind, sources ...string) *edgeBuilder {
+	for _, source := range sources {
+		if source != "" {
+			b.edges = append(b.edges, Edge{From: source, To: b.self, Kind: kind})
+		}
+	}
+
+	return b
+}
+
+func (b *edgeBuilder) build() []Edge {
+	return b.edges
+}
+
+func extractSubnet(resource service.ResourceInterface) []Edge {
+	subnet, ok := resource.(ec2.Subnet)
+	if !ok {
+		return nil
+	}
+
+	return newEdgeBuilder(subnet).to(EdgeInVpc, subnet.GetVpcId()).build()
+}
+
+func extractSecurityGroup(resource service.ResourceInterface) []Edge {
+	group, ok := resource.(ec2.SecurityGroup)
+	if !ok {
+		return nil
+	}
+
+	return newEdgeBuilder(group).to(EdgeInVpc, group.GetVpcId()).build()
+}
+
+func extractRouteTable(resource service.ResourceInterface) []Edge {
+	table, ok := resource.(ec2.RouteTable)
+	if !ok {
+		return nil
+	}
+
+	return newEdgeBuilder(table).
+		to(EdgeInVpc, table.GetVpcId()).
+		to(EdgeAttachedTo, table.GetSubnetIds()...).
+		build()
+}
+
+func extractVpcEndpoint(resource service.ResourceInterface) []Edge {
+	endpoint, ok := resource.(ec2.VpcEndpoint)
+	if !ok {
+		return nil
+	}
+
+	groups := make([]string, 0, len(endpoint.Groups))
+	for _, g := range endpoint.Groups {
+		groups = append(groups, aws.ToString(g.GroupId))
+	}
+
+	return newEdgeBuilder(endpoint).
+		to(EdgeInVpc, endpoint.GetVpcId()).
+		to(EdgeAttachedTo, endpoint.GetSubnetIds()...).
+		to(EdgeAttachedTo, endpoint.GetRouteTableIds()...).
+		to(EdgeAttachedTo, groups...).
+		build()
+}
+
+func extractInstance(resource service.ResourceInterface) []Edge {
+	instance, ok := resource.(ec2.Instance)
+	if !ok {
+		return nil
+	}
+
+	groups := make([]string, 0, len(instance.SecurityGroups))
+	for _, g := range instance.SecurityGroups {
+		groups = append(groups, aws.ToString(g.GroupId))
+	}
+
+	return newEdgeBuilder(instance).
+		to(EdgeInVpc, aws.ToString(instance.VpcId)).
+		from(EdgeContains, aws.ToString(instance.SubnetId)).
+		to(EdgeAttachedTo, groups...).
+		build()
+}
+
+func extractVolume(resource service.ResourceInterface) []Edge {
+	volume, ok := resource.(ec2.Volume)
+	if !ok {
+		return nil
+	}
+
+	instances := make([]string, 0, len(volume.Attachments))
+	for _, a := range volume.Attachments {
+		instances = append(instances, aws.ToString(a.InstanceId))
+	}
+
+	return newEdgeBuilder(volume).to(EdgeAttachedTo, instances...).build()
+}
+
+func extractAddress(resource service.ResourceInterface) []Edge {
+	address, ok := resource.(ec2.Address)
+	if !ok {
+		return nil
+	}
+
+	// An address on a NAT gateway or a bare ENI has no instance; the network
+	// interface is still an attachment, even though it is not inventoried.
+	target := address.GetInstanceId()
+	if target == "" {
+		target = address.GetNetworkInterfaceId()
+	}
+
+	return newEdgeBuilder(address).to(EdgeAttachedTo, target).build()
+}
+
+func extractEcsService(resource service.ResourceInterface) []Edge {
+	svc, ok := resource.(ecs.Service)
+	if !ok {
+		return nil
+	}
+
+	b := newEdgeBuilder(svc).
+		from(EdgeContains, aws.ToString(svc.ClusterArn)).
+		to(EdgeUsesRole, aws.ToString(svc.RoleArn))
+
+	if nc := svc.NetworkConfiguration; nc != nil && nc.AwsvpcConfiguration != nil {
+		b.to(EdgeAttachedTo, nc.AwsvpcConfiguration.Subnets...).
+			to(EdgeAttachedTo, nc.AwsvpcConfiguration.SecurityGroups...)
+	}
+
+	return b.build()
+}
+
+func extractEksCluster(resource service.ResourceInterface) []Edge {
+	cluster, ok := resource.(eks.Cluster)
+	if !ok || cluster.Cluster == nil {
+		return nil
+	}
+
+	b := newEdgeBuilder(cluster).to(EdgeUsesRole, aws.ToString(cluster.RoleArn))
+
+	if vpc := cluster.ResourcesVpcConfig; vpc != nil {
+		b.to(EdgeInVpc, aws.ToString(vpc.VpcId)).
+			to(EdgeAttachedTo, vpc.SubnetIds...).
+			to(EdgeAttachedTo, vpc.SecurityGroupIds...).
+			to(EdgeAttachedTo, aws.ToString(vpc.ClusterSecurityGroupId))
+	}
+
+	return b.build()
+}
+
+func extractLambdaFunction(resource service.ResourceInterface) []Edge {
+	fn, ok := resource.(lambda.Function)
+	if !ok {
+		return nil
+	}
+
+	b := newEdgeBuilder(fn).to(EdgeUsesRole, aws.ToString(fn.Role))
+
+	if vpc := fn.VpcConfig; vpc != nil {
+		b.to(EdgeInVpc, aws.ToString(vpc.VpcId)).
+			to(EdgeAttachedTo, vpc.SubnetIds...).
+			to(EdgeAttachedTo, vpc.SecurityGroupIds...)
+	}
+
+	return b.build()
+}
+
+func extractLoadBalancer(resource service.ResourceInterface) []Edge {
+	lb, ok := resource.(elb.LoadBalancer)
+	if !ok {
+		return nil
+	}
+
+	subnets := make([]string, 0, len(lb.AvailabilityZones))
+	for _, az := range lb.AvailabilityZones {
+		subnets = append(subnets, aws.ToString(az.SubnetId))
+	}
+
+	return newEdgeBuilder(lb).
+		to(EdgeInVpc, aws.ToString(lb.VpcId)).
+		to(EdgeAttachedTo, subnets...).
+		to(EdgeAttachedTo, lb.SecurityGroups...).
+		build()
+}
+
+func extractDbInstance(resource service.ResourceInterface) []Edge {
+	db, ok := resource.(rds.DbInstance)
+	if !ok {
+		return nil
+	}
+
+	b := newEdgeBuilder(db)
+
+	groups := make([]string, 0, len(db.VpcSecurityGroups))
+	for _, g := range db.VpcSecurityGroups {
+		groups = append(groups, aws.ToString(g.VpcSecurityGroupId))
+	}
+	b.to(EdgeAttachedTo, groups...)
+
+	if sg := db.DBSubnetGroup; sg != nil {
+		subnets := make([]string, 0, len(sg.Subnets))
+		for _, s := range sg.Subnets {
+			subnets = append(subnets, aws.ToString(s.SubnetIdentifier))
+		}
+
+		b.to(EdgeInVpc, aws.ToString(sg.VpcId)).to(EdgeAttachedTo, subnets...)
+	}
+
+	return b.build()
+}
@@ -0,0 +1,318 @@
+package graph
+
+import (
+	"sort"
+	"sync"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/gocollection/slice"
+)
+
+// EdgeKind names a relationship between two resources. Every edge reads as
+// "From <kind> To".
+type EdgeKind string
+
+const (
+	// EdgeContains links a parent to a child it holds: a subnet and its
+	// instances, an ECS cluster and its services.
+	EdgeContains EdgeKind = "contains"
+
+	// EdgeAttachedTo links a resource to what it is attached to: a volume or an
+	// Elastic IP to its instance, a resource to its security groups, a route
+	// table to its subnets.
+	EdgeAttachedTo EdgeKind = "attached-to"
+
+	// EdgeUsesRole links a workload to the IAM role it runs as.
+	EdgeUsesRole EdgeKind = "uses-role"
+
+	// EdgeInVpc links a resource to the VPC it lives in.
+	EdgeInVpc EdgeKind = "in-vpc"
+)
+
+// Edge is a typed relationship. From and To are node keys once the edge is in a
+// Graph; an edge whose target was not part of the input keeps the raw reference
+// (an ID or ARN) as To, so "attached to something we did not inventory" stays
+// distinguishable from "attached to nothing".
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// Dependent returns the side of the edge that would break if the other side
+// went away. For containment that is the child; for every other kind it is the
+// resource carrying the reference.
+func (e Edge) Dependent() string {
+	if e.Kind == EdgeContains {
+		return e.To
+	}
+
+	return e.From
+}
+
+// Dependency returns the side of the edge the dependent relies on.
+func (e Edge) Dependency() string {
+	if e.Kind == EdgeContains {
+		return e.From
+	}
+
+	return e.To
+}
+
+// Graph links a set of resources through the IDs and ARNs they carry to each
+// other. Nodes are keyed by ARN where the resource has one and by ID otherwise;
+// every lookup accepts either.
+//
+// A Graph is safe for concurrent use.
+type Graph struct {
+	mx         sync.RWMutex
+	extractors map[cfg.ResourceType]ExtractorFunc
+
+	nodes map[string]service.ResourceInterface
+	alias map[string]string
+	edges []Edge
+
+	// dependency key -> dependent keys, rebuilt with the edges
+	dependents map[string][]string
+}
+
+// NewGraph builds a graph over resources using the default extractors.
+func NewGraph(resources ...service.ResourceInterface) *Graph {
+	g := &Graph{
+		extractors: DefaultExtractors(),
+		nodes:      map[string]service.ResourceInterface{},
+		alias:      map[string]string{},
+		edges:      []Edge{},
+	}
+
+	g.Add(resources...)
+
+	return g
+}
+
+// WithExtractor replaces the extractor for one resource type, or adds one for a
+// type the defaults do not cover. Edges already extracted are rebuilt.
+func (g *Graph) WithExtractor(resourceType cfg.ResourceType, extractor ExtractorFunc) *Graph {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.extractors[resourceType] = extractor
+	g.rebuild()
+
+	return g
+}
+
+// Add inserts resources and the edges extracted from them.
+func (g *Graph) Add(resources ...service.ResourceInterface) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	for _, resource := range resources {
+		key := Key(resource)
+		if key == "" {
+			continue
+		}
+
+		g.nodes[key] = resource
+		g.alias[key] = key
+
+		// IDs are only unique within a type and often within an account: two
+		// ECS clusters named "default" in different accounts share one. An ID
+		// seen twice is dropped from the index rather than resolved to whichever
+		// came last.
+		if id := resource.GetId(); id != "" && id != key {
+			if prev, ok := g.alias[id]; ok && prev != key {
+				g.alias[id] = ""
+			} else if !ok {
+				g.alias[id] = key
+			}
+		}
+	}
+
+	g.rebuild()
+}
+
+// rebuild re-extracts every edge. References are resolved at extraction time,
+// so a node added later than the resource pointing at it must re-resolve.
+func (g *Graph) rebuild() {
+	edges := []Edge{}
+	dependents := map[string][]string{}
+	seen := map[Edge]bool{}
+
+	for _, key := range g.sortedKeys() {
+		resource := g.nodes[key]
+
+		extract, ok := g.extractors[resource.GetType()]
+		if !ok {
+			continue
+		}
+
+		for _, e := range extract(resource) {
+			e.From = g.resolve(e.From)
+			e.To = g.resolve(e.To)
+
+			if e.From == "" || e.To == "" || e.From == e.To || seen[e] {
+				continue
+			}
+
+			seen[e] = true
+			edges = append(edges, e)
+			dependents[e.Dependency()] = append(dependents[e.Dependency()], e.Dependent())
+		}
+	}
+
+	g.edges = edges
+	g.dependents = dependents
+}
+
+func (g *Graph) resolve(ref string) string {
+	if key, ok := g.alias[ref]; ok && key != "" {
+		return key
+	}
+
+	return ref
+}
+
+func (g *Graph) sortedKeys() []string {
+	keys := make([]string, 0, len(g.nodes))
+	for k := range g.nodes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Node returns the resource behind an ID or ARN.
+func (g *Graph) Node(ref string) (service.ResourceInterface, bool) {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	resource, ok := g.nodes[g.resolve(ref)]
+	return resource, ok
+}
+
+// Nodes returns every resource in the graph, ordered by key.
+func (g *Graph) Nodes() []service.ResourceInterface {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	return slice.Map(g.sortedKeys(), func(k string) service.ResourceInterface { return g.nodes[k] })
+}
+
+// Edges returns every edge in the graph.
+func (g *Graph) Edges() []Edge {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	result := make([]Edge, len(g.edges))
+	copy(result, g.edges)
+
+	return result
+}
+
+// EdgesOf returns the edges touching a node, in either direction.
+func (g *Graph) EdgesOf(ref string) []Edge {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	key := g.resolve(ref)
+
+	return slice.Filter(g.edges, func(e Edge) bool { return e.From == key || e.To == key })
+}
+
+// Dependents returns the resources that directly depend on ref: what attaches
+// to a security group, what runs as a role, what a subnet contains.
+func (g *Graph) Dependents(ref string) []service.ResourceInterface {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	return g.collect(g.dependentKeys(g.resolve(ref)))
+}
+
+// Inside returns everything that transitively depends on ref. For a VPC that is
+// its subnets, security groups, route tables and endpoints, the instances in
+// those subnets, and the volumes and addresses attached to those instances.
+func (g *Graph) Inside(ref string) []service.ResourceInterface {
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	root := g.resolve(ref)
+	visited := map[string]bool{root: true}
+	queue := []string{root}
+
+	var found []string
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		for _, key := range g.dependentKeys(next) {
+			if visited[key] {
+				continue
+			}
+
+			visited[key] = true
+			found = append(found, key)
+			queue = append(queue, key)
+		}
+	}
+
+	return g.collect(found)
+}
+
+// Orphans returns resources of the given types that depend on nothing. With no
+// types it checks the ones an orphan is usually money: EBS volumes and Elastic
+// IPs. A reference to a resource outside the input still counts as attached.
+func (g *Graph) Orphans(resourceTypes ...cfg.ResourceType) []service.ResourceInterface {
+	if len(resourceTypes) == 0 {
+		resourceTypes = []cfg.ResourceType{cfg.ResourceTypeVolume, cfg.ResourceTypeEip}
+	}
+
+	g.mx.RLock()
+	defer g.mx.RUnlock()
+
+	hasDependency := map[string]bool{}
+	for _, e := range g.edges {
+		hasDependency[e.Dependent()] = true
+	}
+
+	var orphans []string
+	for _, key := range g.sortedKeys() {
+		if slice.Contains(resourceTypes, g.nodes[key].GetType()) && !hasDependency[key] {
+			orphans = append(orphans, key)
+		}
+	}
+
+	return g.collect(orphans)
+}
+
+func (g *Graph) dependentKeys(key string) []string {
+	return g.dependents[key]
+}
+
+// collect maps keys to resources, skipping references to resources that are
+// not in the graph.
+func (g *Graph) collect(keys []string) []service.ResourceInterface {
+	result := []service.ResourceInterface{}
+	seen := map[string]bool{}
+
+	for _, key := range keys {
+		if resource, ok := g.nodes[key]; ok && !seen[key] {
+			seen[key] = true
+			result = append(result, resource)
+		}
+	}
+
+	return result
+}
+
+// Key returns the node key of a resource: its ARN where it has one, its ID
+// otherwise. Extractors use it to refer to the resource they were given.
+func Key(resource service.ResourceInterface) string {
+	if a := resource.GetArn(); a != "" {
+		return a
+	}
+
+	return resource.GetId()
+}
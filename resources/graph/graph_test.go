@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/lambda"
+	"github.com/imunhatep/gocollection/slice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+const roleArn = "arn:aws:iam::123456789012:role/worker"
+
+func fixture() []service.ResourceInterface {
+	c := mockClient{}
+
+	return []service.ResourceInterface{
+		ec2.NewVpc(c, ec2types.Vpc{VpcId: aws.String("vpc-1")}),
+		ec2.NewSubnet(c, ec2types.Subnet{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")}),
+		ec2.NewSecurityGroup(c, ec2types.SecurityGroup{GroupId: aws.String("sg-1"), VpcId: aws.String("vpc-1")}),
+		ec2.NewInstance(c, ec2types.Instance{
+			InstanceId:     aws.String("i-1"),
+			VpcId:          aws.String("vpc-1"),
+			SubnetId:       aws.String("subnet-1"),
+			SecurityGroups: []ec2types.GroupIdentifier{{GroupId: aws.String("sg-1")}},
+		}),
+		ec2.NewVolume(c, ec2types.Volume{
+			VolumeId:    aws.String("vol-attached"),
+			Attachments: []ec2types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+		}),
+		ec2.NewVolume(c, ec2types.Volume{VolumeId: aws.String("vol-orphan")}),
+		ec2.NewAddress(c, ec2types.Address{AllocationId: aws.String("eipalloc-orphan"), PublicIp: aws.String("203.0.113.1")}),
+		ec2.NewAddress(c, ec2types.Address{
+			AllocationId:       aws.String("eipalloc-nat"),
+			PublicIp:           aws.String("203.0.113.2"),
+			NetworkInterfaceId: aws.String("eni-not-inventoried"),
+		}),
+		lambda.NewFunction(c, lambdatypes.FunctionConfiguration{
+			FunctionName: aws.String("fn"),
+			FunctionArn:  aws.String("arn:aws:lambda:eu-west-1:123456789012:function:fn"),
+			Role:         aws.String(roleArn),
+			VpcConfig: &lambdatypes.VpcConfigResponse{
+				VpcId:            aws.String("vpc-1"),
+				SecurityGroupIds: []string{"sg-1"},
+			},
+		}, nil),
+	}
+}
+
+func ids(resources []service.ResourceInterface) []string {
+	return slice.Map(resources, func(r service.ResourceInterface) string { return r.GetIdOrArn() })
+}
+
+func TestGraphInsideVpc(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	inside := ids(g.Inside("vpc-1"))
+
+	assert.ElementsMatch(t, []string{"subnet-1", "sg-1", "i-1", "vol-attached", "fn"}, inside)
+}
+
+func TestGraphDependentsOfSecurityGroup(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	assert.ElementsMatch(t, []string{"i-1", "fn"}, ids(g.Dependents("sg-1")))
+}
+
+func TestGraphLookupByIdOrArn(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	byID, ok := g.Node("i-1")
+	require.True(t, ok)
+
+	byArn, ok := g.Node(byID.GetArn())
+	require.True(t, ok)
+	assert.Equal(t, byID.GetId(), byArn.GetId())
+}
+
+// An address on a NAT gateway's network interface is attached even though the
+// interface is not part of the inventory; only the bare allocation is an orphan.
+func TestGraphOrphans(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	assert.ElementsMatch(t, []string{"vol-orphan", "eipalloc-orphan"}, ids(g.Orphans()))
+}
+
+// The role is not inventoried, but the edge to it must survive so the question
+// "what runs as this role" still has an answer.
+func TestGraphKeepsDanglingReferences(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	edges := g.EdgesOf(roleArn)
+	require.Len(t, edges, 1)
+	assert.Equal(t, EdgeUsesRole, edges[0].Kind)
+}
+
+// Resolution happens when edges are extracted, so a target added after the
+// resource pointing at it must still be linked.
+func TestGraphResolvesLateNodes(t *testing.T) {
+	c := mockClient{}
+	g := NewGraph(ec2.NewSubnet(c, ec2types.Subnet{SubnetId: aws.String("subnet-1"), VpcId: aws.String("vpc-1")}))
+	g.Add(ec2.NewVpc(c, ec2types.Vpc{VpcId: aws.String("vpc-1")}))
+
+	assert.Equal(t, []string{"subnet-1"}, ids(g.Dependents("vpc-1")))
+}
+
+func TestGraphCustomExtractor(t *testing.T) {
+	c := mockClient{}
+	snapshot := ec2.NewSnapshot(c, ec2types.Snapshot{SnapshotId: aws.String("snap-1"), VolumeId: aws.String("vol-orphan")})
+
+	g := NewGraph(append(fixture(), snapshot)...).
+		WithExtractor(cfg.ResourceType("AWS::EC2::Snapshot"), func(r service.ResourceInterface) []Edge {
+			s := r.(ec2.Snapshot)
+			return []Edge{{From: Key(s), To: aws.ToString(s.VolumeId), Kind: EdgeAttachedTo}}
+		})
+
+	assert.Equal(t, []string{"snap-1"}, ids(g.Dependents("vol-orphan")))
+}
+
+func TestGraphExport(t *testing.T) {
+	g := NewGraph(fixture()...)
+
+	raw, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	var doc document
+	require.NoError(t, json.Unmarshal(raw, &doc))
+	assert.Len(t, doc.Nodes, len(fixture()))
+	assert.Equal(t, len(g.Edges()), len(doc.Edges))
+
+	var dot bytes.Buffer
+	require.NoError(t, g.WriteDot(&dot))
+	assert.Contains(t, dot.String(), "digraph awslib {")
+	assert.Contains(t, dot.String(), `[label="in-vpc"]`)
+	assert.Contains(t, dot.String(), `"`+roleArn+`" [style=dashed]`)
+}
@@ -58,13 +58,19 @@ func (r *ResourceObserver) Handler() HandlerFunc {
 	return r.handler
 }
 
-// Serve runs the resource handler
-func (r *ResourceObserver) Serve(resourceTypes []types.ResourceType) error {
+// Serve runs the resource handler and returns a report of the sweep. The report
+// is returned even when a handler fails, covering the resource types processed
+// up to that point.
+func (r *ResourceObserver) Serve(resourceTypes []types.ResourceType) (*SweepReport, error) {
 	var h HandlerFunc
 
 	h = r.Handler()
 	h = applyMiddleware(h, r.middleware...)
 
+	report := NewSweepReport()
+	defer report.ExportMetrics()
+	defer report.Finish()
+
 	// runs aws api requests synchronously per resource type and asynchronously per region
 	for _, resourceType := range resourceTypes {
 		log.Trace().
@@ -75,13 +81,17 @@ func (r *ResourceObserver) Serve(resourceTypes []types.ResourceType) error {
 		resourceReader := r.getProvider(resourceType).Run()
 
 		// Execute chain
-		if err := h(resourceReader); err != nil {
+		err := h(resourceReader)
+
+		report.Add(resourceType, len(resourceReader.Read()), resourceReader.Failures())
+
+		if err != nil {
 			log.Error().Err(err).Msg("Error processing resources")
-			return err
+			return report, err
 		}
 	}
 
-	return nil
+	return report, nil
 }
 
 func (r *ResourceObserver) getProvider(resourceType types.ResourceType) ProviderInterface {
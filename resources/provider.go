@@ -1,6 +1,8 @@
 package resources
 
 import (
+	stderrors "errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -27,6 +29,10 @@ const ResourceBusSize = 10000
 // request deadline of its own.
 const DefaultRegionTimeout = 60 * time.Second
 
+// ErrProxyTimeout marks a proxy that was given up on after the provider's
+// timeout, as opposed to one that answered with an error of its own.
+var ErrProxyTimeout = stderrors.New("proxy timed out")
+
 // ProxyFailure records a proxy that produced no resources because it could not
 // be queried, so a caller can tell a short answer from a complete one.
 //
@@ -162,7 +168,7 @@ func (r Provider) findResourcesInRegion(
 	case <-timer.C:
 		// Only this proxy's result is given up on. The abandoned goroutine keeps
 		// running, completes into the buffered channel and exits.
-		err := errors.Errorf("timed out after %s", timeout)
+		err := errors.New(fmt.Errorf("%w after %s", ErrProxyTimeout, timeout))
 
 		log.Error().Err(err).
 			Str("accountID", gw.GetAccountID().String()).
@@ -0,0 +1,306 @@
+package rightsizing
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/costexplorer"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/shopspring/decimal"
+)
+
+// DefaultOwnerTags are the tag keys read, case-insensitively and in order, to
+// name a recommendation's owner.
+var DefaultOwnerTags = []string{"owner", "team"}
+
+// InstancePricer returns on-demand EC2 prices; pricing.PricingRepository and
+// its cached variant satisfy it.
+type InstancePricer interface {
+	GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*pricing.Ec2Product, error)
+}
+
+// Recommendation is one Cost Explorer rightsizing recommendation joined to the
+// live instance it is about.
+type Recommendation struct {
+	AccountID  ptypes.AwsAccountID `json:"accountId"`
+	Region     ptypes.AwsRegion    `json:"region,omitempty"`
+	InstanceID string              `json:"instanceId"`
+	Name       string              `json:"name,omitempty"`
+	Owner      string              `json:"owner,omitempty"`
+
+	Action          types.RightsizingType     `json:"action"`
+	CurrentType     string                    `json:"currentType"`
+	RecommendedType string                    `json:"recommendedType,omitempty"`
+	FindingReasons  []types.FindingReasonCode `json:"findingReasons,omitempty"`
+
+	// CurrentMonthlyCost and MonthlySaving are Cost Explorer's figures, which
+	// account for Savings Plans and reservations when the recommendations were
+	// requested with benefits considered.
+	CurrentMonthlyCost decimal.Decimal `json:"currentMonthlyCost"`
+	MonthlySaving      decimal.Decimal `json:"monthlySaving"`
+	Currency           string          `json:"currency"`
+
+	// OnDemandMonthlySaving is the difference of the Linux on-demand list
+	// prices of the two types over a month, or nil when no pricer is set or
+	// either type could not be priced.
+	OnDemandMonthlySaving *decimal.Decimal `json:"onDemandMonthlySaving,omitempty"`
+
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Instance is the live instance, nil when the inventory no longer has it:
+	// the recommendation is then stale.
+	Instance *ec2.Instance `json:"-"`
+}
+
+// Stale reports whether the instance was not found in the inventory, e.g.
+// because it was terminated after Cost Explorer computed the recommendation.
+func (r Recommendation) Stale() bool {
+	return r.Instance == nil
+}
+
+// Report is the joined list, ordered by monthly saving, largest first.
+type Report struct {
+	GeneratedAt     time.Time        `json:"generatedAt"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// TotalMonthlySaving sums the Cost Explorer saving of every recommendation
+// whose instance still exists.
+func (r *Report) TotalMonthlySaving() decimal.Decimal {
+	total := decimal.Zero
+	for _, rec := range r.Actionable() {
+		total = total.Add(rec.MonthlySaving)
+	}
+
+	return total
+}
+
+// Actionable returns the recommendations whose instance still exists.
+func (r *Report) Actionable() []Recommendation {
+	var actionable []Recommendation
+	for _, rec := range r.Recommendations {
+		if !rec.Stale() {
+			actionable = append(actionable, rec)
+		}
+	}
+
+	return actionable
+}
+
+// WriteTable renders one line per recommendation.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "%d recommendations, %d actionable, %s per month\n",
+		len(r.Recommendations), len(r.Actionable()), r.TotalMonthlySaving().StringFixed(2))
+	if len(r.Recommendations) == 0 {
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "ACCOUNT\tREGION\tINSTANCE\tNAME\tOWNER\tACTION\tCURRENT\tRECOMMENDED\tSAVING/MONTH\tON-DEMAND SAVING")
+	for _, rec := range r.Recommendations {
+		region := rec.Region.String()
+		if rec.Stale() {
+			region = "(gone)"
+		}
+
+		onDemand := "-"
+		if rec.OnDemandMonthlySaving != nil {
+			onDemand = rec.OnDemandMonthlySaving.StringFixed(2)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s %s\t%s\n",
+			rec.AccountID, region, rec.InstanceID, rec.Name, rec.Owner, rec.Action,
+			rec.CurrentType, rec.RecommendedType, rec.MonthlySaving.StringFixed(2), rec.Currency, onDemand,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// Joiner joins rightsizing recommendations to the live inventory and,
+// optionally, to on-demand prices.
+type Joiner struct {
+	pricer    InstancePricer
+	ownerTags []string
+	prices    map[string]*decimal.Decimal
+}
+
+func NewJoiner() *Joiner {
+	return &Joiner{ownerTags: DefaultOwnerTags, prices: map[string]*decimal.Decimal{}}
+}
+
+// WithPricer adds the on-demand saving of the recommended type to each
+// recommendation, one GetProducts call per region and instance type.
+func (j *Joiner) WithPricer(pricer InstancePricer) *Joiner {
+	j.pricer = pricer
+	return j
+}
+
+// WithOwnerTags replaces DefaultOwnerTags.
+func (j *Joiner) WithOwnerTags(keys ...string) *Joiner {
+	j.ownerTags = keys
+	return j
+}
+
+// Join matches each recommendation to an instance by account, region and
+// instance ID.
+// Pricing failures are joined into err; the report is complete regardless and
+// leaves the affected savings unpriced.
+func (j *Joiner) Join(recommendations []types.RightsizingRecommendation, instances []ec2.Instance) (*Report, error) {
+	byID := make(map[string]*ec2.Instance, len(instances))
+	for i := range instances {
+		byID[instanceKey(instances[i].GetAccountID(), instances[i].GetRegion(), instances[i].GetId())] = &instances[i]
+	}
+
+	report := &Report{GeneratedAt: time.Now()}
+	var errs []error
+
+	for _, source := range recommendations {
+		rec, err := j.join(source, byID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		report.Recommendations = append(report.Recommendations, rec)
+	}
+
+	sort.SliceStable(report.Recommendations, func(a, b int) bool {
+		return report.Recommendations[a].MonthlySaving.GreaterThan(report.Recommendations[b].MonthlySaving)
+	})
+
+	return report, stderrors.Join(errs...)
+}
+
+func (j *Joiner) join(source types.RightsizingRecommendation, byID map[string]*ec2.Instance) (Recommendation, error) {
+	rec := Recommendation{
+		AccountID:      ptypes.AwsAccountID(aws.ToString(source.AccountId)),
+		Action:         source.RightsizingType,
+		FindingReasons: source.FindingReasonCodes,
+	}
+
+	if current := source.CurrentInstance; current != nil {
+		rec.InstanceID = aws.ToString(current.ResourceId)
+		rec.Name = aws.ToString(current.InstanceName)
+		rec.CurrentType = costexplorer.InstanceType(current.ResourceDetails)
+		rec.Region = costexplorer.InstanceRegion(current.ResourceDetails)
+		rec.CurrentMonthlyCost, _ = decimal.NewFromString(aws.ToString(current.MonthlyCost))
+		rec.Tags = recommendationTags(current.Tags)
+	}
+
+	if target, ok := costexplorer.RightsizingTarget(source); ok {
+		rec.RecommendedType = costexplorer.InstanceType(target.ResourceDetails)
+	}
+	rec.MonthlySaving, rec.Currency = costexplorer.RightsizingSaving(source)
+
+	instance, found := byID[instanceKey(rec.AccountID, rec.Region, rec.InstanceID)]
+	if found {
+		rec.Instance = instance
+		rec.Tags = instance.GetTags()
+		if name := rec.Tags["Name"]; name != "" {
+			rec.Name = name
+		}
+		rec.CurrentType = string(instance.InstanceType)
+	}
+	rec.Owner = owner(rec.Tags, j.ownerTags)
+
+	if j.pricer == nil || !found {
+		return rec, nil
+	}
+
+	saving, err := j.onDemandSaving(rec)
+	rec.OnDemandMonthlySaving = saving
+	if err != nil {
+		return rec, errors.Errorf("pricing %s: %w", rec.InstanceID, err)
+	}
+
+	return rec, nil
+}
+
+// onDemandSaving prices the current type and, for Modify, the recommended one.
+// Terminating saves the whole on-demand cost.
+func (j *Joiner) onDemandSaving(rec Recommendation) (*decimal.Decimal, error) {
+	current, err := j.monthlyPrice(rec.Region, rec.CurrentType)
+	if err != nil || current == nil {
+		return nil, err
+	}
+
+	if rec.Action == types.RightsizingTypeTerminate {
+		return current, nil
+	}
+
+	target, err := j.monthlyPrice(rec.Region, rec.RecommendedType)
+	if err != nil || target == nil {
+		return nil, err
+	}
+
+	saving := current.Sub(*target)
+
+	return &saving, nil
+}
+
+func (j *Joiner) monthlyPrice(region ptypes.AwsRegion, instanceType string) (*decimal.Decimal, error) {
+	if instanceType == "" {
+		return nil, nil
+	}
+
+	key := region.String() + ":" + instanceType
+	if price, ok := j.prices[key]; ok {
+		return price, nil
+	}
+
+	product, err := j.pricer.GetInstancePricing(region, ec2types.InstanceType(instanceType))
+	if err != nil {
+		return nil, err
+	}
+
+	var price *decimal.Decimal
+	if product != nil {
+		if hourly, err := decimal.NewFromString(product.GetOnDemandPrice()); err == nil {
+			monthly := hourly.Mul(decimal.NewFromInt(pricing.HoursPerMonth))
+			price = &monthly
+		}
+	}
+
+	j.prices[key] = price
+
+	return price, nil
+}
+
+func instanceKey(accountID ptypes.AwsAccountID, region ptypes.AwsRegion, instanceID string) string {
+	return accountID.String() + "/" + region.String() + "/" + instanceID
+}
+
+func recommendationTags(values []types.TagValues) map[string]string {
+	tags := make(map[string]string, len(values))
+	for _, tag := range values {
+		if len(tag.Values) > 0 {
+			tags[aws.ToString(tag.Key)] = tag.Values[0]
+		}
+	}
+
+	return tags
+}
+
+func owner(tags map[string]string, keys []string) string {
+	for _, key := range keys {
+		for tagKey, value := range tags {
+			if strings.EqualFold(tagKey, key) && value != "" {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,158 @@
+package rightsizing
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/pricing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+type fixedPricer struct {
+	hourly map[ec2types.InstanceType]string
+	calls  int
+}
+
+func (p *fixedPricer) GetInstancePricing(_ ptypes.AwsRegion, instanceType ec2types.InstanceType) (*pricing.Ec2Product, error) {
+	p.calls++
+
+	price, ok := p.hourly[instanceType]
+	if !ok {
+		return nil, nil
+	}
+
+	return pricing.NewEc2Product(fmt.Sprintf(`{"terms":{"OnDemand":{"t":{"priceDimensions":{"d":{"pricePerUnit":{"USD":"%s"}}}}}}}`, price))
+}
+
+func ec2Details(instanceType string) *types.ResourceDetails {
+	return &types.ResourceDetails{EC2ResourceDetails: &types.EC2ResourceDetails{
+		InstanceType: aws.String(instanceType),
+		Region:       aws.String("EU (Ireland)"),
+	}}
+}
+
+func recommendation(id string, action types.RightsizingType, current, target, saving string) types.RightsizingRecommendation {
+	rec := types.RightsizingRecommendation{
+		AccountId:       aws.String("123456789012"),
+		RightsizingType: action,
+		CurrentInstance: &types.CurrentInstance{
+			ResourceId:      aws.String(id),
+			MonthlyCost:     aws.String("100"),
+			ResourceDetails: ec2Details(current),
+			Tags:            []types.TagValues{{Key: aws.String("Team"), Values: []string{"from-ce"}}},
+		},
+	}
+
+	if action == types.RightsizingTypeModify {
+		rec.ModifyRecommendationDetail = &types.ModifyRecommendationDetail{
+			TargetInstances: []types.TargetInstance{{
+				EstimatedMonthlySavings: aws.String(saving),
+				CurrencyCode:            aws.String("USD"),
+				DefaultTargetInstance:   true,
+				ResourceDetails:         ec2Details(target),
+			}},
+		}
+	} else {
+		rec.TerminateRecommendationDetail = &types.TerminateRecommendationDetail{
+			EstimatedMonthlySavings: aws.String(saving),
+			CurrencyCode:            aws.String("USD"),
+		}
+	}
+
+	return rec
+}
+
+func TestJoin(t *testing.T) {
+	instances := []ec2.Instance{
+		ec2.NewInstance(mockClient{}, ec2types.Instance{
+			InstanceId:   aws.String("i-big"),
+			InstanceType: ec2types.InstanceTypeM5Xlarge,
+			Tags:         []ec2types.Tag{{Key: aws.String("Name"), Value: aws.String("api")}, {Key: aws.String("Owner"), Value: aws.String("payments")}},
+		}),
+		ec2.NewInstance(mockClient{}, ec2types.Instance{
+			InstanceId:   aws.String("i-idle"),
+			InstanceType: ec2types.InstanceTypeM5Large,
+		}),
+	}
+
+	pricer := &fixedPricer{hourly: map[ec2types.InstanceType]string{"m5.xlarge": "0.2", "m5.large": "0.1"}}
+
+	report, err := NewJoiner().WithPricer(pricer).Join([]types.RightsizingRecommendation{
+		recommendation("i-big", types.RightsizingTypeModify, "m5.xlarge", "m5.large", "60.5"),
+		recommendation("i-idle", types.RightsizingTypeTerminate, "m5.large", "", "70"),
+		recommendation("i-gone", types.RightsizingTypeTerminate, "c5.large", "", "500"),
+	}, instances)
+	require.NoError(t, err)
+	require.Len(t, report.Recommendations, 3)
+
+	gone := report.Recommendations[0]
+	assert.Equal(t, "i-gone", gone.InstanceID)
+	assert.True(t, gone.Stale())
+	assert.Equal(t, "from-ce", gone.Owner)
+	assert.Nil(t, gone.OnDemandMonthlySaving)
+
+	idle := report.Recommendations[1]
+	assert.Equal(t, "i-idle", idle.InstanceID)
+	assert.Equal(t, ptypes.AwsRegion("eu-west-1"), idle.Region)
+	assert.Empty(t, idle.Name)
+	assert.Equal(t, "73", idle.OnDemandMonthlySaving.String())
+
+	big := report.Recommendations[2]
+	assert.Equal(t, "api", big.Name)
+	assert.Equal(t, "payments", big.Owner)
+	assert.Equal(t, "m5.xlarge", big.CurrentType)
+	assert.Equal(t, "m5.large", big.RecommendedType)
+	assert.Equal(t, "60.5", big.MonthlySaving.String())
+	assert.Equal(t, "USD", big.Currency)
+	assert.Equal(t, "73", big.OnDemandMonthlySaving.String())
+
+	assert.Equal(t, 2, pricer.calls, "prices are fetched once per region and type")
+	assert.Equal(t, "130.5", report.TotalMonthlySaving().String())
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteTable(&out))
+	assert.Contains(t, out.String(), "3 recommendations, 2 actionable, 130.50 per month")
+	assert.Contains(t, out.String(), "(gone)")
+}
+
+func TestJoinUnpricedType(t *testing.T) {
+	instances := []ec2.Instance{
+		ec2.NewInstance(mockClient{}, ec2types.Instance{InstanceId: aws.String("i-1"), InstanceType: ec2types.InstanceTypeM5Xlarge}),
+	}
+
+	report, err := NewJoiner().
+		WithPricer(&fixedPricer{hourly: map[ec2types.InstanceType]string{"m5.xlarge": "0.2"}}).
+		Join([]types.RightsizingRecommendation{recommendation("i-1", types.RightsizingTypeModify, "m5.xlarge", "m6g.large", "10")}, instances)
+	require.NoError(t, err)
+
+	assert.Nil(t, report.Recommendations[0].OnDemandMonthlySaving)
+}
+
+// The same instance ID in another region is another instance: the join must
+// not attach it, nor price the recommendation at its region's rates.
+func TestJoinMatchesRegion(t *testing.T) {
+	instances := []ec2.Instance{
+		ec2.NewInstance(mockClient{}, ec2types.Instance{InstanceId: aws.String("i-1"), InstanceType: ec2types.InstanceTypeM5Xlarge}),
+	}
+
+	rec := recommendation("i-1", types.RightsizingTypeTerminate, "m5.xlarge", "", "10")
+	rec.CurrentInstance.ResourceDetails.EC2ResourceDetails.Region = aws.String("US East (N. Virginia)")
+
+	report, err := NewJoiner().Join([]types.RightsizingRecommendation{rec}, instances)
+	require.NoError(t, err)
+
+	assert.True(t, report.Recommendations[0].Stale())
+	assert.Equal(t, ptypes.AwsRegion("us-east-1"), report.Recommendations[0].Region)
+}
@@ -0,0 +1,303 @@
+package resources
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/smithy-go"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/proxy"
+	"github.com/imunhatep/awslib/service/cfg"
+)
+
+// FailureClass buckets a proxy failure by what an operator would do about it.
+// The raw SDK error says the same thing a dozen ways across services; the class
+// is the part that decides whether to fix an IAM policy or credentials, enable
+// a region, back off, or file a library gap.
+type FailureClass string
+
+const (
+	FailureAccessDenied    FailureClass = "access_denied"
+	FailureAuth            FailureClass = "auth_failure"
+	FailureRegionDisabled  FailureClass = "region_disabled"
+	FailureThrottled       FailureClass = "throttled"
+	FailureTimeout         FailureClass = "timeout"
+	FailureUnsupportedType FailureClass = "unsupported_type"
+	// FailureUnsupportedEndpoint is a service with no endpoint in the region:
+	// the host does not resolve, e.g. the Health API outside us-east-1.
+	FailureUnsupportedEndpoint FailureClass = "unsupported_endpoint"
+	FailureOther               FailureClass = "other"
+)
+
+// FailureClasses lists every class in reporting order.
+func FailureClasses() []FailureClass {
+	return []FailureClass{
+		FailureAccessDenied,
+		FailureAuth,
+		FailureRegionDisabled,
+		FailureThrottled,
+		FailureTimeout,
+		FailureUnsupportedType,
+		FailureUnsupportedEndpoint,
+		FailureOther,
+	}
+}
+
+// API error codes per class. Matched on the smithy error code, so they hold
+// regardless of which service returned them or how deeply the error is wrapped.
+var failureCodes = map[FailureClass][]string{
+	FailureAccessDenied: {
+		"AccessDenied",
+		"AccessDeniedException",
+		"UnauthorizedOperation",
+		"AuthorizationError",
+		"AuthorizationErrorException",
+		"Forbidden",
+	},
+	// Credentials that are invalid, expired or badly signed; EC2 answers
+	// AuthFailure for all of these.
+	FailureAuth: {
+		"AuthFailure",
+		"ExpiredToken",
+		"ExpiredTokenException",
+		"InvalidAccessKeyId",
+		"SignatureDoesNotMatch",
+		"IncompleteSignature",
+	},
+	// InvalidClientTokenId and UnrecognizedClientException are what STS and the
+	// regional endpoints answer for a region the account has not opted into; see
+	// credentialFailure in provider/v3 for the same trade-off.
+	FailureRegionDisabled: {
+		"OptInRequired",
+		"InvalidClientTokenId",
+		"UnrecognizedClientException",
+	},
+	FailureThrottled: {
+		"Throttling",
+		"ThrottlingException",
+		"ThrottledException",
+		"RequestThrottled",
+		"RequestThrottledException",
+		"TooManyRequestsException",
+		"RequestLimitExceeded",
+		"SlowDown",
+		"ProvisionedThroughputExceededException",
+	},
+	// Cloud Control answers these for a type without a LIST handler.
+	FailureUnsupportedType: {
+		"UnsupportedActionException",
+		"TypeNotFoundException",
+	},
+}
+
+// ClassifyFailure maps a proxy error onto a FailureClass. Sentinels and context
+// errors are checked first, then the API error code, then a few message markers
+// for errors raised below the API layer (DNS, dial timeouts) that carry no code.
+func ClassifyFailure(err error) FailureClass {
+	if err == nil {
+		return FailureOther
+	}
+
+	switch {
+	case stderrors.Is(err, proxy.ErrResourceTypeNotSupported):
+		return FailureUnsupportedType
+	case stderrors.Is(err, ErrProxyTimeout), stderrors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	}
+
+	var apiErr smithy.APIError
+	if stderrors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		for _, class := range FailureClasses() {
+			for _, known := range failureCodes[class] {
+				if code == known {
+					return class
+				}
+			}
+		}
+	}
+
+	haystack := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(haystack, "not authorized to perform"):
+		return FailureAccessDenied
+	case strings.Contains(haystack, "no such host"):
+		return FailureUnsupportedEndpoint
+	case strings.Contains(haystack, "i/o timeout"), strings.Contains(haystack, "deadline exceeded"):
+		return FailureTimeout
+	}
+
+	return FailureOther
+}
+
+// SweepFailure is one proxy that could not be queried during a sweep.
+type SweepFailure struct {
+	ResourceType types.ResourceType  `json:"resourceType"`
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+	Class        FailureClass        `json:"class"`
+	Error        string              `json:"error"`
+
+	err error
+}
+
+// Cause returns the original error, so callers can still errors.As into the
+// SDK type behind a classified failure.
+func (f SweepFailure) Cause() error {
+	return f.err
+}
+
+// SweepReport aggregates what a ResourceObserver sweep found and, more to the
+// point, what it could not reach. ResourceReader.Failures answers that for one
+// resource type; the report answers it across all of them, so a coverage gap is
+// visible in one place instead of in a log line per region.
+type SweepReport struct {
+	StartedAt  time.Time                  `json:"startedAt"`
+	FinishedAt time.Time                  `json:"finishedAt"`
+	Resources  map[types.ResourceType]int `json:"resources"`
+	Failures   []SweepFailure             `json:"failures"`
+
+	mx sync.Mutex
+}
+
+func NewSweepReport() *SweepReport {
+	return &SweepReport{
+		StartedAt: time.Now(),
+		Resources: map[types.ResourceType]int{},
+		Failures:  []SweepFailure{},
+	}
+}
+
+// Add records the outcome of one resource type: how many resources were found
+// and which proxies failed.
+func (r *SweepReport) Add(resourceType types.ResourceType, found int, failures []ProxyFailure) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.Resources[resourceType] += found
+
+	for _, f := range failures {
+		msg := ""
+		if f.Err != nil {
+			msg = f.Err.Error()
+		}
+
+		r.Failures = append(r.Failures, SweepFailure{
+			ResourceType: resourceType,
+			AccountID:    f.AccountID,
+			Region:       f.Region,
+			Class:        ClassifyFailure(f.Err),
+			Error:        msg,
+			err:          f.Err,
+		})
+	}
+}
+
+// Finish stamps the end of the sweep.
+func (r *SweepReport) Finish() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.FinishedAt = time.Now()
+}
+
+// HasFailures reports whether any proxy could not be queried. False means the
+// inventory is complete, not merely non-empty.
+func (r *SweepReport) HasFailures() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	return len(r.Failures) > 0
+}
+
+// ByClass groups failures by class.
+func (r *SweepReport) ByClass() map[FailureClass][]SweepFailure {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	grouped := map[FailureClass][]SweepFailure{}
+	for _, f := range r.Failures {
+		grouped[f.Class] = append(grouped[f.Class], f)
+	}
+
+	return grouped
+}
+
+// sorted returns a copy of the failures ordered by type, account and region,
+// so the table and the metrics read the same way on every run.
+func (r *SweepReport) sorted() []SweepFailure {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	failures := make([]SweepFailure, len(r.Failures))
+	copy(failures, r.Failures)
+
+	sort.Slice(failures, func(i, j int) bool {
+		a, b := failures[i], failures[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.Region < b.Region
+	})
+
+	return failures
+}
+
+// WriteTable renders the failures as an aligned text table, one row per proxy.
+func (r *SweepReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "TYPE\tACCOUNT\tREGION\tCLASS\tERROR")
+	for _, f := range r.sorted() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			cfg.ResourceTypeToString(f.ResourceType),
+			f.AccountID,
+			f.Region,
+			f.Class,
+			firstLine(f.Error),
+		)
+	}
+
+	return tw.Flush()
+}
+
+// ExportMetrics publishes the report as AwsObserverSweepFailures gauges. The
+// gauge is reset first, so a region that recovered drops out instead of
+// reporting its last failure forever.
+func (r *SweepReport) ExportMetrics() {
+	if !metrics.AwsMetricsEnabled {
+		return
+	}
+
+	metrics.AwsObserverSweepFailures.Reset()
+	for _, f := range r.sorted() {
+		metrics.AwsObserverSweepFailures.WithLabelValues(
+			f.AccountID.String(),
+			f.Region.String(),
+			cfg.ResourceTypeToString(f.ResourceType),
+			string(f.Class),
+		).Inc()
+	}
+}
+
+// firstLine keeps table rows on one line: SDK errors wrapped with a stack trace
+// or a request ID can span several.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+
+	return s
+}
@@ -0,0 +1,110 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	cfgtypes "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/smithy-go"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func apiErr(code string) error {
+	return &smithy.GenericAPIError{Code: code, Message: "test"}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected FailureClass
+	}{
+		{"access denied", apiErr("AccessDeniedException"), FailureAccessDenied},
+		{"ec2 unauthorized", apiErr("UnauthorizedOperation"), FailureAccessDenied},
+		{"opt-in region", apiErr("InvalidClientTokenId"), FailureRegionDisabled},
+		{"throttled", apiErr("ThrottlingException"), FailureThrottled},
+		{"ec2 throttled", apiErr("RequestLimitExceeded"), FailureThrottled},
+		{"cloud control without list", apiErr("UnsupportedActionException"), FailureUnsupportedType},
+		{"provider timeout", errors.New(fmt.Errorf("%w after 1m0s", ErrProxyTimeout)), FailureTimeout},
+		{"context deadline", context.DeadlineExceeded, FailureTimeout},
+		{"unsupported type", fmt.Errorf("%w: aws::foo::bar", proxy.ErrResourceTypeNotSupported), FailureUnsupportedType},
+		{"ec2 bad credentials", apiErr("AuthFailure"), FailureAuth},
+		{"expired session", apiErr("ExpiredToken"), FailureAuth},
+		{"dns", stderrors.New("dial tcp: lookup health.eu-west-1.amazonaws.com: no such host"), FailureUnsupportedEndpoint},
+		{"unknown", stderrors.New("something else"), FailureOther},
+		{"unknown api code", apiErr("ValidationException"), FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClassifyFailure(tt.err))
+		})
+	}
+}
+
+// The SDK retryer wraps the final attempt's error, and repositories wrap it again
+// for a stack trace; the code must still be found underneath both.
+func TestClassifyFailureSeesThroughWrapping(t *testing.T) {
+	err := errors.New(&retry.MaxAttemptsError{Attempt: 5, Err: apiErr("Throttling")})
+
+	assert.Equal(t, FailureThrottled, ClassifyFailure(err))
+}
+
+func TestSweepReportAggregatesAcrossTypes(t *testing.T) {
+	report := NewSweepReport()
+
+	report.Add(cfgtypes.ResourceTypeInstance, 3, []ProxyFailure{
+		{AccountID: "111111111111", Region: "me-south-1", Err: apiErr("InvalidClientTokenId")},
+	})
+	report.Add(cfgtypes.ResourceTypeVolume, 5, nil)
+	report.Add(cfgtypes.ResourceTypeBucket, 0, []ProxyFailure{
+		{AccountID: "222222222222", Region: "eu-west-1", Err: apiErr("AccessDenied")},
+	})
+	report.Finish()
+
+	require.True(t, report.HasFailures())
+	assert.Len(t, report.Failures, 2)
+	assert.Equal(t, 3, report.Resources[cfgtypes.ResourceTypeInstance])
+	assert.Equal(t, 5, report.Resources[cfgtypes.ResourceTypeVolume])
+
+	byClass := report.ByClass()
+	assert.Len(t, byClass[FailureRegionDisabled], 1)
+	assert.Len(t, byClass[FailureAccessDenied], 1)
+
+	var apiError smithy.APIError
+	assert.True(t, stderrors.As(byClass[FailureAccessDenied][0].Cause(), &apiError), "original error must stay reachable")
+}
+
+func TestSweepReportRendering(t *testing.T) {
+	report := NewSweepReport()
+	report.Add(cfgtypes.ResourceTypeInstance, 1, []ProxyFailure{
+		{AccountID: "111111111111", Region: "me-south-1", Err: stderrors.New("first line\nsecond line")},
+	})
+
+	var table bytes.Buffer
+	require.NoError(t, report.WriteTable(&table))
+	assert.Contains(t, table.String(), "aws::ec2::instance")
+	assert.Contains(t, table.String(), "me-south-1")
+	assert.Contains(t, table.String(), "first line")
+	assert.NotContains(t, table.String(), "second line", "a row must stay on one line")
+
+	raw, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"class":"other"`)
+	assert.Contains(t, string(raw), `"error":"first line\nsecond line"`)
+}
+
+func TestSweepReportEmpty(t *testing.T) {
+	report := NewSweepReport()
+	report.Add(cfgtypes.ResourceTypeInstance, 0, nil)
+
+	assert.False(t, report.HasFailures(), "a type that answered zero resources is not a coverage gap")
+}
@@ -0,0 +1,229 @@
+package tagpolicy
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"strings"
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoTagger is returned for resources no registered Tagger handles.
+var ErrNoTagger = stderrors.New("no tagger for resource type")
+
+// Tagger writes tags through a service's own tagging calls.
+type Tagger interface {
+	TagResource(resource service.ResourceInterface, tags map[string]string) error
+	UntagResource(resource service.ResourceInterface, keys []string) error
+}
+
+// Router dispatches to a Tagger by resource type. A route ending in "::*" covers
+// a whole service, e.g. "AWS::EC2::*"; an exact type wins over a service route,
+// and the fallback, if set, takes everything else.
+type Router struct {
+	routes   map[string]Tagger
+	fallback Tagger
+}
+
+func NewRouter() *Router {
+	return &Router{routes: map[string]Tagger{}}
+}
+
+// Handle registers a tagger for a resource type or a "AWS::Service::*" route.
+func (r *Router) Handle(route string, tagger Tagger) *Router {
+	r.routes[route] = tagger
+	return r
+}
+
+// Fallback registers the tagger used when no route matches, typically one that
+// can tag any ARN.
+func (r *Router) Fallback(tagger Tagger) *Router {
+	r.fallback = tagger
+	return r
+}
+
+func (r *Router) taggerFor(resourceType cfg.ResourceType) (Tagger, error) {
+	if t, ok := r.routes[string(resourceType)]; ok {
+		return t, nil
+	}
+
+	if i := strings.LastIndex(string(resourceType), "::"); i > 0 {
+		if t, ok := r.routes[string(resourceType)[:i]+"::*"]; ok {
+			return t, nil
+		}
+	}
+
+	if r.fallback != nil {
+		return r.fallback, nil
+	}
+
+	return nil, errors.Errorf("%w: %s", ErrNoTagger, resourceType)
+}
+
+func (r *Router) TagResource(resource service.ResourceInterface, tags map[string]string) error {
+	t, err := r.taggerFor(resource.GetType())
+	if err != nil {
+		return err
+	}
+
+	return t.TagResource(resource, tags)
+}
+
+func (r *Router) UntagResource(resource service.ResourceInterface, keys []string) error {
+	t, err := r.taggerFor(resource.GetType())
+	if err != nil {
+		return err
+	}
+
+	return t.UntagResource(resource, keys)
+}
+
+// AuditEntry records one change an Applier made or would have made.
+type AuditEntry struct {
+	Time         time.Time           `json:"time"`
+	Policy       string              `json:"policy"`
+	DryRun       bool                `json:"dryRun"`
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+	ResourceType cfg.ResourceType    `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	ResourceArn  string              `json:"resourceArn,omitempty"`
+	Added        map[string]string   `json:"added,omitempty"`
+	Removed      []string            `json:"removed,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// Applier executes a Plan. Calls are spaced to stay under the tagging APIs'
+// rate limits, and every change is written to the audit log as a JSON line.
+type Applier struct {
+	tagger   Tagger
+	interval time.Duration
+	audit    io.Writer
+	dryRun   bool
+}
+
+// NewApplier returns an applier issuing at most 5 changes per second.
+func NewApplier(tagger Tagger) *Applier {
+	return &Applier{tagger: tagger, interval: 200 * time.Millisecond}
+}
+
+// WithRate sets the maximum number of changes per second; zero or less removes
+// the limit.
+func (a *Applier) WithRate(perSecond float64) *Applier {
+	n := *a
+	n.interval = 0
+	if perSecond > 0 {
+		n.interval = time.Duration(float64(time.Second) / perSecond)
+	}
+
+	return &n
+}
+
+// WithAudit writes an AuditEntry per change to w.
+func (a *Applier) WithAudit(w io.Writer) *Applier {
+	n := *a
+	n.audit = w
+	return &n
+}
+
+// WithDryRun returns an applier that audits the plan without calling AWS.
+func (a *Applier) WithDryRun() *Applier {
+	n := *a
+	n.dryRun = true
+	return &n
+}
+
+// Apply makes the changes of a plan in order. A failed change does not stop
+// the run; failures are joined into the returned error and recorded in their
+// audit entries. Cancelling ctx stops before the next change.
+func (a *Applier) Apply(ctx context.Context, plan *Plan) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	var errs []error
+
+	var ticker *time.Ticker
+	if a.interval > 0 && !a.dryRun {
+		ticker = time.NewTicker(a.interval)
+		defer ticker.Stop()
+	}
+
+	for i, change := range plan.Changes {
+		if ticker != nil && i > 0 {
+			select {
+			case <-ctx.Done():
+				return entries, stderrors.Join(append(errs, errors.New(ctx.Err()))...)
+			case <-ticker.C:
+			}
+		} else if ctx.Err() != nil {
+			return entries, stderrors.Join(append(errs, errors.New(ctx.Err()))...)
+		}
+
+		entry := AuditEntry{
+			Time:         time.Now(),
+			Policy:       plan.Policy,
+			DryRun:       a.dryRun,
+			AccountID:    change.AccountID,
+			Region:       change.Region,
+			ResourceType: change.ResourceType,
+			ResourceID:   change.ResourceID,
+			ResourceArn:  change.ResourceArn,
+			Added:        change.Add,
+			Removed:      change.Remove,
+		}
+
+		if !a.dryRun {
+			if err := a.apply(change); err != nil {
+				entry.Error = err.Error()
+				errs = append(errs, err)
+			}
+		}
+
+		entries = append(entries, entry)
+		a.write(entry)
+	}
+
+	return entries, stderrors.Join(errs...)
+}
+
+// apply sets the new keys before removing the miscased ones, so a failure
+// halfway leaves a duplicate tag rather than a lost value.
+func (a *Applier) apply(change Change) error {
+	if change.Resource == nil {
+		return errors.Errorf("change for %s carries no resource", change.ResourceID)
+	}
+
+	if len(change.Add) > 0 {
+		if err := a.tagger.TagResource(change.Resource, change.Add); err != nil {
+			return err
+		}
+	}
+
+	if len(change.Remove) > 0 {
+		if err := a.tagger.UntagResource(change.Resource, change.Remove); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Applier) write(entry AuditEntry) {
+	if a.audit == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = a.audit.Write(append(line, '\n'))
+	}
+
+	if err != nil {
+		log.Error().Err(err).Str("resource", entry.ResourceID).Msg("[Applier.write] failed to write audit entry")
+	}
+}
@@ -0,0 +1,64 @@
+package tagpolicy
+
+import (
+	"context"
+
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/ec2"
+)
+
+// ClientPool resolves the client for an account and region, as
+// resources.AwsClientPool does.
+type ClientPool interface {
+	GetClient(ptypes.AwsAccountID, ptypes.AwsRegion) (*v3.Client, error)
+}
+
+// Ec2Tagger tags EC2 resources by ID through CreateTags and DeleteTags, in the
+// resource's own account and region. Register it for "AWS::EC2::*".
+type Ec2Tagger struct {
+	ctx     context.Context
+	clients ClientPool
+}
+
+func NewEc2Tagger(ctx context.Context, clients ClientPool) *Ec2Tagger {
+	return &Ec2Tagger{ctx: ctx, clients: clients}
+}
+
+func (t *Ec2Tagger) repository(resource service.ResourceInterface) (*ec2.Ec2Repository, error) {
+	client, err := t.clients.GetClient(resource.GetAccountID(), resource.GetRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	return ec2.NewEc2Repository(t.ctx, client), nil
+}
+
+func (t *Ec2Tagger) TagResource(resource service.ResourceInterface, tags map[string]string) error {
+	repo, err := t.repository(resource)
+	if err != nil {
+		return err
+	}
+
+	_, err = repo.CreateResourceTags(resource.GetType(), ec2.BuildCreateTagsInput(tags, resource))
+
+	return err
+}
+
+func (t *Ec2Tagger) UntagResource(resource service.ResourceInterface, keys []string) error {
+	repo, err := t.repository(resource)
+	if err != nil {
+		return err
+	}
+
+	// an empty value matches whatever value the resource has
+	tags := make(map[string]string, len(keys))
+	for _, key := range keys {
+		tags[key] = ""
+	}
+
+	_, err = repo.DeleteResourceTags(resource.GetType(), ec2.BuildDeleteTagsInput(tags, resource))
+
+	return err
+}
@@ -0,0 +1,156 @@
+package tagpolicy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Change is the tag edit that brings one resource into compliance, as far as
+// the policy allows it to be fixed automatically.
+type Change struct {
+	ResourceType cfg.ResourceType    `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	ResourceArn  string              `json:"resourceArn,omitempty"`
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+
+	Add    map[string]string `json:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+
+	Resource service.ResourceInterface `json:"-"`
+}
+
+func (c Change) Empty() bool {
+	return len(c.Add) == 0 && len(c.Remove) == 0
+}
+
+// Plan is a dry-run remediation: the changes an Applier would make, and the
+// violations it cannot fix because the rule has no usable default.
+type Plan struct {
+	Policy     string   `json:"policy"`
+	Changes    []Change `json:"changes"`
+	Unresolved []Result `json:"unresolved"`
+}
+
+// NewPlan derives the remediation for a report. Per violation:
+//   - missing: set the rule's default;
+//   - key-case: move the value to the expected key, or the default when the
+//     value itself is not allowed, and remove the miscased key;
+//   - invalid: replace a value that differs from an allowed one only in case,
+//     otherwise set the default.
+//
+// Without a default, missing and invalid tags are left in Unresolved: guessing
+// an owner or cost centre is worse than leaving it for a human.
+func NewPlan(report *Report) *Plan {
+	plan := &Plan{Policy: report.Policy, Changes: []Change{}, Unresolved: []Result{}}
+
+	for _, result := range report.Results {
+		change := Change{
+			ResourceType: result.ResourceType,
+			ResourceID:   result.ResourceID,
+			ResourceArn:  result.ResourceArn,
+			AccountID:    result.AccountID,
+			Region:       result.Region,
+			Add:          map[string]string{},
+			Resource:     result.Resource,
+		}
+		unresolved := []Violation{}
+
+		for _, v := range result.Violations {
+			value, ok := remediate(v)
+			if !ok {
+				unresolved = append(unresolved, v)
+				continue
+			}
+
+			change.Add[v.Key] = value
+			if v.Kind == ViolationKeyCase && !slices.Contains(change.Remove, v.ActualKey) {
+				change.Remove = append(change.Remove, v.ActualKey)
+			}
+		}
+
+		if !change.Empty() {
+			sort.Strings(change.Remove)
+			plan.Changes = append(plan.Changes, change)
+		}
+
+		if len(unresolved) > 0 {
+			pending := result
+			pending.Violations = unresolved
+			plan.Unresolved = append(plan.Unresolved, pending)
+		}
+	}
+
+	return plan
+}
+
+func remediate(v Violation) (string, bool) {
+	rule := v.Rule
+
+	switch v.Kind {
+	case ViolationKeyCase, ViolationInvalid:
+		if rule.Allows(v.Actual) {
+			return v.Actual, true
+		}
+
+		for _, allowed := range rule.AllowedValues {
+			if strings.EqualFold(allowed, v.Actual) {
+				return allowed, true
+			}
+		}
+	}
+
+	return rule.Default, rule.Default != ""
+}
+
+// WriteTable renders the plan for review before it is applied.
+func (p *Plan) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "policy %s: %d change(s), %d unresolved\n", p.Policy, len(p.Changes), len(p.Unresolved))
+
+	if len(p.Changes) > 0 {
+		fmt.Fprintln(tw, "ACCOUNT\tREGION\tTYPE\tRESOURCE\tCHANGE")
+		for _, c := range p.Changes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.AccountID, c.Region, cfgEntity.ResourceTypeToString(c.ResourceType), c.ResourceID, describeChange(c))
+		}
+	}
+
+	if len(p.Unresolved) > 0 {
+		fmt.Fprintln(tw, "UNRESOLVED")
+		for _, r := range p.Unresolved {
+			for _, v := range r.Violations {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.AccountID, r.Region, cfgEntity.ResourceTypeToString(r.ResourceType), r.ResourceID, v)
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func describeChange(c Change) string {
+	keys := make([]string, 0, len(c.Add))
+	for k := range c.Add {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys)+len(c.Remove))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("+%s=%s", k, c.Add[k]))
+	}
+	for _, k := range c.Remove {
+		parts = append(parts, "-"+k)
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,209 @@
+package tagpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+// ViolationKind tells what is wrong with a tag.
+type ViolationKind string
+
+const (
+	// ViolationMissing means a required key is absent.
+	ViolationMissing ViolationKind = "missing"
+	// ViolationInvalid means the key is present but its value is not allowed.
+	ViolationInvalid ViolationKind = "invalid"
+	// ViolationKeyCase means the key is present only in a different case, e.g.
+	// "Environment" where the policy requires "environment". Tag keys are case
+	// sensitive, so cost allocation treats the two as different keys.
+	ViolationKeyCase ViolationKind = "key-case"
+)
+
+// Rule constrains one tag key. A rule with neither AllowedValues nor Pattern
+// accepts any value; one with both accepts a value matching either.
+type Rule struct {
+	Key           string   `json:"key"`
+	Required      bool     `json:"required,omitempty"`
+	AllowedValues []string `json:"allowedValues,omitempty"`
+	Pattern       string   `json:"pattern,omitempty"`
+
+	// Default is the value a remediation plan sets when the key is missing or
+	// invalid. Without it such violations are reported but left unresolved.
+	Default string `json:"default,omitempty"`
+
+	// ResourceTypes and Accounts scope the rule; empty means every type or
+	// account.
+	ResourceTypes []cfg.ResourceType    `json:"resourceTypes,omitempty"`
+	Accounts      []ptypes.AwsAccountID `json:"accounts,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// AppliesTo reports whether the rule is in scope for the resource.
+func (r Rule) AppliesTo(resource service.ResourceInterface) bool {
+	if len(r.ResourceTypes) > 0 && !slices.Contains(r.ResourceTypes, resource.GetType()) {
+		return false
+	}
+
+	if len(r.Accounts) > 0 && !slices.Contains(r.Accounts, resource.GetAccountID()) {
+		return false
+	}
+
+	return true
+}
+
+// Allows reports whether value satisfies the rule. Rules built without
+// NewPolicy, as literals or decoded from JSON, have their pattern compiled on
+// first use; an invalid pattern matches nothing.
+func (r Rule) Allows(value string) bool {
+	if len(r.AllowedValues) == 0 && r.Pattern == "" {
+		return true
+	}
+
+	if slices.Contains(r.AllowedValues, value) {
+		return true
+	}
+
+	pattern := r.pattern
+	if pattern == nil && r.Pattern != "" {
+		pattern, _ = compilePattern(r.Pattern)
+	}
+
+	return pattern != nil && pattern.MatchString(value)
+}
+
+// patterns caches compiled rule patterns by source, for rules that did not go
+// through NewPolicy.
+var patterns sync.Map
+
+// compilePattern anchors a rule pattern to the whole value and compiles it.
+func compilePattern(source string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(source); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	pattern, err := regexp.Compile("^(?:" + source + ")$")
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(source, pattern)
+
+	return pattern, nil
+}
+
+// Violation is one rule a resource breaks.
+type Violation struct {
+	Key  string        `json:"key"`
+	Kind ViolationKind `json:"kind"`
+
+	// ActualKey is the key found on the resource for ViolationKeyCase; Actual is
+	// the value found, empty when the key is missing.
+	ActualKey string `json:"actualKey,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+
+	Rule Rule `json:"-"`
+}
+
+func (v Violation) String() string {
+	switch v.Kind {
+	case ViolationMissing:
+		return fmt.Sprintf("%s: missing", v.Key)
+	case ViolationKeyCase:
+		return fmt.Sprintf("%s: found as %q", v.Key, v.ActualKey)
+	}
+
+	return fmt.Sprintf("%s: value %q not allowed", v.Key, v.Actual)
+}
+
+// Policy is a named set of tag rules.
+type Policy struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// NewPolicy validates the rules and compiles their patterns.
+func NewPolicy(name string, rules ...Rule) (*Policy, error) {
+	p := &Policy{Name: name, Rules: make([]Rule, 0, len(rules))}
+
+	for _, rule := range rules {
+		if rule.Key == "" {
+			return nil, errors.Errorf("policy %s: rule without key", name)
+		}
+
+		if rule.Pattern != "" {
+			pattern, err := compilePattern(rule.Pattern)
+			if err != nil {
+				return nil, errors.Errorf("policy %s: rule %s: %w", name, rule.Key, err)
+			}
+			rule.pattern = pattern
+		}
+
+		if rule.Default != "" && !rule.Allows(rule.Default) {
+			return nil, errors.Errorf("policy %s: rule %s: default %q breaks the rule itself", name, rule.Key, rule.Default)
+		}
+
+		p.Rules = append(p.Rules, rule)
+	}
+
+	return p, nil
+}
+
+// ParsePolicy reads a policy from its JSON definition:
+//
+//	{"name": "baseline", "rules": [{"key": "env", "required": true, "allowedValues": ["dev", "prod"]}]}
+func ParsePolicy(data []byte) (*Policy, error) {
+	var definition Policy
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return NewPolicy(definition.Name, definition.Rules...)
+}
+
+// Check returns the violations of one resource, in rule order.
+func (p *Policy) Check(resource service.ResourceInterface) []Violation {
+	tags := resource.GetTags()
+	violations := []Violation{}
+
+	for _, rule := range p.Rules {
+		if !rule.AppliesTo(resource) {
+			continue
+		}
+
+		value, ok := tags[rule.Key]
+		if !ok {
+			if actualKey, found := foldedKey(tags, rule.Key); found {
+				violations = append(violations, Violation{Key: rule.Key, Kind: ViolationKeyCase, ActualKey: actualKey, Actual: tags[actualKey], Rule: rule})
+			} else if rule.Required {
+				violations = append(violations, Violation{Key: rule.Key, Kind: ViolationMissing, Rule: rule})
+			}
+
+			continue
+		}
+
+		if !rule.Allows(value) {
+			violations = append(violations, Violation{Key: rule.Key, Kind: ViolationInvalid, Actual: value, Rule: rule})
+		}
+	}
+
+	return violations
+}
+
+func foldedKey(tags map[string]string, key string) (string, bool) {
+	for k := range tags {
+		if strings.EqualFold(k, key) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,216 @@
+package tagpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func volume(id string, tags map[string]string) service.ResourceInterface {
+	return ec2.NewVolume(mockClient{}, ec2types.Volume{VolumeId: aws.String(id), Tags: ec2.TagMapToTags(tags)})
+}
+
+const definition = `{
+	"name": "baseline",
+	"rules": [
+		{"key": "env", "required": true, "allowedValues": ["dev", "prod"], "default": "dev"},
+		{"key": "owner", "required": true, "pattern": "[a-z]+@example\\.com"},
+		{"key": "backup", "resourceTypes": ["AWS::EC2::Volume"], "allowedValues": ["daily", "none"]},
+		{"key": "cost-centre", "required": true, "accounts": ["999999999999"]}
+	]
+}`
+
+func mustPolicy(t *testing.T) *Policy {
+	p, err := ParsePolicy([]byte(definition))
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestPolicyCheck(t *testing.T) {
+	p := mustPolicy(t)
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		want []string
+	}{
+		{"compliant", map[string]string{"env": "prod", "owner": "ops@example.com"}, []string{}},
+		{"missing", map[string]string{}, []string{"env: missing", "owner: missing"}},
+		{"invalid", map[string]string{"env": "staging", "owner": "Ops@example.com"}, []string{`env: value "staging" not allowed`, `owner: value "Ops@example.com" not allowed`}},
+		{"key case", map[string]string{"Env": "prod", "owner": "ops@example.com"}, []string{`env: found as "Env"`}},
+		{"optional rule checks value", map[string]string{"env": "dev", "owner": "ops@example.com", "backup": "weekly"}, []string{`backup: value "weekly" not allowed`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, v := range p.Check(volume("vol-1", tt.tags)) {
+				got = append(got, v.String())
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// A policy built as a literal never went through NewPolicy: its patterns must
+// still be enforced, not accept every value.
+func TestPolicyLiteralEnforcesPattern(t *testing.T) {
+	p := &Policy{Name: "literal", Rules: []Rule{
+		{Key: "owner", Required: true, Pattern: `[a-z]+@example\.com`},
+		{Key: "cost-center", Pattern: "("},
+	}}
+
+	assert.Empty(t, p.Check(volume("vol-1", map[string]string{"owner": "ops@example.com"})))
+
+	violations := p.Check(volume("vol-2", map[string]string{"owner": "Ops", "cost-center": "42"}))
+	require.Len(t, violations, 2)
+	assert.Equal(t, `owner: value "Ops" not allowed`, violations[0].String())
+	assert.Equal(t, ViolationInvalid, violations[1].Kind, "an invalid pattern matches nothing")
+}
+
+func TestNewPolicyRejectsBadRules(t *testing.T) {
+	_, err := NewPolicy("p", Rule{Key: "env", Pattern: "("})
+	assert.Error(t, err)
+
+	_, err = NewPolicy("p", Rule{Key: "env", AllowedValues: []string{"dev"}, Default: "prod"})
+	assert.Error(t, err)
+
+	_, err = NewPolicy("p", Rule{Required: true})
+	assert.Error(t, err)
+}
+
+func TestReportAndPlan(t *testing.T) {
+	p := mustPolicy(t)
+
+	report := p.Evaluate([]service.ResourceInterface{
+		volume("vol-ok", map[string]string{"env": "prod", "owner": "ops@example.com"}),
+		volume("vol-case", map[string]string{"Env": "Prod", "owner": "ops@example.com"}),
+		volume("vol-bare", map[string]string{}),
+	})
+
+	assert.Equal(t, 3, report.Evaluated)
+	require.Len(t, report.Results, 2)
+	assert.InDelta(t, 1.0/3, report.Compliance(), 0.001)
+	assert.Equal(t, map[string]int{"env": 2, "owner": 1}, report.ByKey())
+
+	plan := NewPlan(report)
+	require.Len(t, plan.Changes, 2)
+
+	byID := map[string]Change{}
+	for _, c := range plan.Changes {
+		byID[c.ResourceID] = c
+	}
+
+	assert.Equal(t, map[string]string{"env": "prod"}, byID["vol-case"].Add)
+	assert.Equal(t, []string{"Env"}, byID["vol-case"].Remove)
+	assert.Equal(t, map[string]string{"env": "dev"}, byID["vol-bare"].Add)
+
+	// owner has no default: nobody should be made owner by guess
+	require.Len(t, plan.Unresolved, 1)
+	assert.Equal(t, "vol-bare", plan.Unresolved[0].ResourceID)
+	assert.Equal(t, ViolationMissing, plan.Unresolved[0].Violations[0].Kind)
+
+	var out bytes.Buffer
+	require.NoError(t, plan.WriteTable(&out))
+	assert.Contains(t, out.String(), "+env=prod -Env")
+}
+
+type recordingTagger struct {
+	tagged   map[string]map[string]string
+	untagged map[string][]string
+	fail     error
+}
+
+func (r *recordingTagger) TagResource(resource service.ResourceInterface, tags map[string]string) error {
+	if r.fail != nil {
+		return r.fail
+	}
+	r.tagged[resource.GetId()] = tags
+	return nil
+}
+
+func (r *recordingTagger) UntagResource(resource service.ResourceInterface, keys []string) error {
+	r.untagged[resource.GetId()] = keys
+	return nil
+}
+
+func newRecordingTagger() *recordingTagger {
+	return &recordingTagger{tagged: map[string]map[string]string{}, untagged: map[string][]string{}}
+}
+
+func TestApplierAppliesAndAudits(t *testing.T) {
+	p := mustPolicy(t)
+	plan := NewPlan(p.Evaluate([]service.ResourceInterface{
+		volume("vol-case", map[string]string{"Env": "prod", "owner": "ops@example.com"}),
+	}))
+
+	tagger := newRecordingTagger()
+	var audit bytes.Buffer
+
+	router := NewRouter().Handle("AWS::EC2::*", tagger)
+	entries, err := NewApplier(router).WithRate(0).WithAudit(&audit).Apply(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	assert.Equal(t, map[string]string{"env": "prod"}, tagger.tagged["vol-case"])
+	assert.Equal(t, []string{"Env"}, tagger.untagged["vol-case"])
+
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(audit.String())), &entry))
+	assert.Equal(t, "vol-case", entry.ResourceID)
+	assert.False(t, entry.DryRun)
+}
+
+func TestApplierDryRunTouchesNothing(t *testing.T) {
+	plan := NewPlan(mustPolicy(t).Evaluate([]service.ResourceInterface{volume("vol-bare", nil)}))
+
+	tagger := newRecordingTagger()
+	entries, err := NewApplier(tagger).WithDryRun().Apply(context.Background(), plan)
+
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.True(t, entries[0].DryRun)
+	assert.Empty(t, tagger.tagged)
+}
+
+func TestApplierCollectsFailures(t *testing.T) {
+	plan := NewPlan(mustPolicy(t).Evaluate([]service.ResourceInterface{
+		volume("vol-a", nil),
+		volume("vol-b", nil),
+	}))
+
+	boom := stderrors.New("throttled")
+	tagger := newRecordingTagger()
+	tagger.fail = boom
+
+	entries, err := NewApplier(tagger).WithRate(1000).Apply(context.Background(), plan)
+	assert.ErrorIs(t, err, boom)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "throttled", entries[1].Error)
+}
+
+func TestRouterWithoutRoute(t *testing.T) {
+	err := NewRouter().Handle(string(cfg.ResourceTypeInstance), newRecordingTagger()).
+		TagResource(volume("vol-1", nil), map[string]string{"env": "dev"})
+
+	assert.ErrorIs(t, err, ErrNoTagger)
+}
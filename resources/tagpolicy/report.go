@@ -0,0 +1,146 @@
+package tagpolicy
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Result is the compliance of one resource.
+type Result struct {
+	ResourceType cfg.ResourceType    `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	ResourceArn  string              `json:"resourceArn,omitempty"`
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+	Violations   []Violation         `json:"violations"`
+
+	Resource service.ResourceInterface `json:"-"`
+}
+
+func (r Result) Compliant() bool {
+	return len(r.Violations) == 0
+}
+
+// Report is the outcome of evaluating a policy over an inventory. Resources no
+// rule applies to are not counted.
+type Report struct {
+	Policy      string    `json:"policy"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+	Evaluated   int       `json:"evaluated"`
+	Results     []Result  `json:"results"`
+}
+
+// Evaluate checks every resource against the policy. Results hold the
+// non-compliant resources only, ordered by account, region, type and ID.
+func (p *Policy) Evaluate(resources []service.ResourceInterface) *Report {
+	report := &Report{Policy: p.Name, EvaluatedAt: time.Now(), Results: []Result{}}
+
+	for _, resource := range resources {
+		if !p.inScope(resource) {
+			continue
+		}
+
+		report.Evaluated++
+
+		violations := p.Check(resource)
+		if len(violations) == 0 {
+			continue
+		}
+
+		report.Results = append(report.Results, Result{
+			ResourceType: resource.GetType(),
+			ResourceID:   resource.GetId(),
+			ResourceArn:  resource.GetArn(),
+			AccountID:    resource.GetAccountID(),
+			Region:       resource.GetRegion(),
+			Violations:   violations,
+			Resource:     resource,
+		})
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+
+		return a.ResourceID < b.ResourceID
+	})
+
+	return report
+}
+
+func (p *Policy) inScope(resource service.ResourceInterface) bool {
+	for _, rule := range p.Rules {
+		if rule.AppliesTo(resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Compliance returns the share of evaluated resources without violations, 1
+// when nothing was evaluated.
+func (r *Report) Compliance() float64 {
+	if r.Evaluated == 0 {
+		return 1
+	}
+
+	return float64(r.Evaluated-len(r.Results)) / float64(r.Evaluated)
+}
+
+// ByKey counts violations per tag key.
+func (r *Report) ByKey() map[string]int {
+	counts := map[string]int{}
+	for _, result := range r.Results {
+		for _, v := range result.Violations {
+			counts[v.Key]++
+		}
+	}
+
+	return counts
+}
+
+// WriteTable renders the non-compliant resources, one line each.
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "policy %s: %d/%d compliant (%.1f%%)\n", r.Policy, r.Evaluated-len(r.Results), r.Evaluated, r.Compliance()*100)
+	if len(r.Results) == 0 {
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "ACCOUNT\tREGION\tTYPE\tRESOURCE\tVIOLATIONS")
+	for _, result := range r.Results {
+		violations := make([]string, 0, len(result.Violations))
+		for _, v := range result.Violations {
+			violations = append(violations, v.String())
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			result.AccountID,
+			result.Region,
+			cfgEntity.ResourceTypeToString(result.ResourceType),
+			result.ResourceID,
+			strings.Join(violations, "; "),
+		)
+	}
+
+	return tw.Flush()
+}
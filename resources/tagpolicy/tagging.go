@@ -0,0 +1,72 @@
+package tagpolicy
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/tagging"
+)
+
+// ResourceGroupsTagger tags any resource with an ARN through the Resource
+// Groups Tagging API, in the resource's own account and region. It suits the
+// Router fallback: one tagger for every service without a dedicated one.
+type ResourceGroupsTagger struct {
+	ctx     context.Context
+	clients ClientPool
+}
+
+func NewResourceGroupsTagger(ctx context.Context, clients ClientPool) *ResourceGroupsTagger {
+	return &ResourceGroupsTagger{ctx: ctx, clients: clients}
+}
+
+func (t *ResourceGroupsTagger) repository(resource service.ResourceInterface) (*tagging.TaggingRepository, error) {
+	if resource.GetArn() == "" {
+		return nil, errors.Errorf("%s %s has no ARN to tag", resource.GetType(), resource.GetId())
+	}
+
+	client, err := t.clients.GetClient(resource.GetAccountID(), resource.GetRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	return tagging.NewTaggingRepository(t.ctx, client), nil
+}
+
+func (t *ResourceGroupsTagger) TagResource(resource service.ResourceInterface, tags map[string]string) error {
+	repo, err := t.repository(resource)
+	if err != nil {
+		return err
+	}
+
+	failed, err := repo.TagResources([]string{resource.GetArn()}, tags)
+	if err != nil {
+		return err
+	}
+
+	return failure(failed)
+}
+
+func (t *ResourceGroupsTagger) UntagResource(resource service.ResourceInterface, keys []string) error {
+	repo, err := t.repository(resource)
+	if err != nil {
+		return err
+	}
+
+	failed, err := repo.UntagResources([]string{resource.GetArn()}, keys)
+	if err != nil {
+		return err
+	}
+
+	return failure(failed)
+}
+
+func failure(failed map[string]types.FailureInfo) error {
+	for resourceArn, info := range failed {
+		return errors.Errorf("%s: %s: %s", resourceArn, info.ErrorCode, aws.ToString(info.ErrorMessage))
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package waste
+
+import (
+	stderrors "errors"
+	"sort"
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/rs/zerolog/log"
+)
+
+// Severity ranks a finding by how much it is likely to cost or block.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityHigh:
+		return 3
+	case SeverityMedium:
+		return 2
+	case SeverityLow:
+		return 1
+	}
+
+	return 0
+}
+
+// Finding is one resource a rule considers unused.
+type Finding struct {
+	RuleID    string   `json:"ruleId"`
+	Severity  Severity `json:"severity"`
+	Rationale string   `json:"rationale"`
+
+	ResourceType cfg.ResourceType    `json:"resourceType"`
+	ResourceID   string              `json:"resourceId"`
+	ResourceArn  string              `json:"resourceArn,omitempty"`
+	AccountID    ptypes.AwsAccountID `json:"accountId"`
+	Region       ptypes.AwsRegion    `json:"region"`
+
+	// MonthlyWaste is the estimated on-demand monthly cost of the resource, or
+	// nil when no Pricer is configured or it cannot price this resource. Nil is
+	// "unknown", never "free".
+	MonthlyWaste *float64 `json:"monthlyWaste,omitempty"`
+
+	Resource service.ResourceInterface `json:"-"`
+}
+
+// CheckFunc inspects one resource. It returns a rationale and true when the
+// resource is unused; an error means the rule could not decide, which is
+// reported rather than treated as "in use".
+type CheckFunc func(resource service.ResourceInterface, inventory *Inventory) (string, bool, error)
+
+// Rule flags unused resources of one type.
+type Rule struct {
+	ID           string
+	Severity     Severity
+	ResourceType cfg.ResourceType
+	Check        CheckFunc
+}
+
+// Pricer estimates what a resource costs per month. ok is false for resources
+// it cannot price. estimate.Estimator is one, pricing from the Price List API
+// or an offline catalog.
+type Pricer interface {
+	MonthlyCost(resource service.ResourceInterface) (cost float64, ok bool, err error)
+}
+
+// Finder runs a set of rules over an inventory of resources, typically the
+// contents of a ResourcePoolMiddleware after a sweep.
+type Finder struct {
+	rules  []Rule
+	pricer Pricer
+}
+
+// NewFinder returns a finder with the given rules, or DefaultRules when none
+// are given.
+func NewFinder(rules ...Rule) *Finder {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+
+	return &Finder{rules: rules}
+}
+
+// WithPricer returns a finder that attaches a monthly cost estimate to each
+// finding.
+func (f *Finder) WithPricer(pricer Pricer) *Finder {
+	return &Finder{rules: f.rules, pricer: pricer}
+}
+
+// WithRules returns a finder with additional rules.
+func (f *Finder) WithRules(rules ...Rule) *Finder {
+	return &Finder{rules: append(append([]Rule{}, f.rules...), rules...), pricer: f.pricer}
+}
+
+// Find evaluates every rule against the resources. Findings are ordered by
+// severity, then estimated waste, both descending. Rule and pricing errors do
+// not stop the run: they are collected into the returned error, alongside
+// whatever the remaining rules found.
+func (f *Finder) Find(resources []service.ResourceInterface) ([]Finding, error) {
+	start := time.Now()
+	inventory := NewInventory(resources)
+
+	findings := []Finding{}
+	var errs []error
+
+	for _, rule := range f.rules {
+		for _, resource := range inventory.ByType(rule.ResourceType) {
+			rationale, unused, err := rule.Check(resource, inventory)
+			if err != nil {
+				errs = append(errs, errors.Errorf("rule %s on %s: %w", rule.ID, resource.GetIdOrArn(), err))
+				continue
+			}
+
+			if !unused {
+				continue
+			}
+
+			finding := Finding{
+				RuleID:       rule.ID,
+				Severity:     rule.Severity,
+				Rationale:    rationale,
+				ResourceType: resource.GetType(),
+				ResourceID:   resource.GetId(),
+				ResourceArn:  resource.GetArn(),
+				AccountID:    resource.GetAccountID(),
+				Region:       resource.GetRegion(),
+				Resource:     resource,
+			}
+
+			if f.pricer != nil {
+				cost, ok, err := f.pricer.MonthlyCost(resource)
+				if err != nil {
+					errs = append(errs, errors.Errorf("pricing %s: %w", resource.GetIdOrArn(), err))
+				} else if ok {
+					finding.MonthlyWaste = &cost
+				}
+			}
+
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity.rank() != b.Severity.rank() {
+			return a.Severity.rank() > b.Severity.rank()
+		}
+
+		return monthly(a) > monthly(b)
+	})
+
+	log.Debug().
+		Int("resources", len(resources)).
+		Int("findings", len(findings)).
+		Dur("elapsed", time.Since(start)).
+		Msg("[Finder.Find] waste rules evaluated")
+
+	return findings, stderrors.Join(errs...)
+}
+
+// TotalMonthlyWaste sums the priced findings. Unpriced findings are excluded,
+// so the total is a lower bound.
+func TotalMonthlyWaste(findings []Finding) float64 {
+	total := 0.0
+	for _, f := range findings {
+		total += monthly(f)
+	}
+
+	return total
+}
+
+func monthly(f Finding) float64 {
+	if f.MonthlyWaste == nil {
+		return 0
+	}
+
+	return *f.MonthlyWaste
+}
+
+// Inventory indexes resources by type and by ID so rules can check references
+// between them, e.g. whether a snapshot's source volume still exists.
+type Inventory struct {
+	byType map[cfg.ResourceType][]service.ResourceInterface
+	byID   map[string]service.ResourceInterface
+}
+
+func NewInventory(resources []service.ResourceInterface) *Inventory {
+	inv := &Inventory{
+		byType: map[cfg.ResourceType][]service.ResourceInterface{},
+		byID:   map[string]service.ResourceInterface{},
+	}
+
+	for _, r := range resources {
+		inv.byType[r.GetType()] = append(inv.byType[r.GetType()], r)
+		inv.byID[scopedID(r.GetAccountID(), r.GetRegion(), r.GetId())] = r
+	}
+
+	return inv
+}
+
+// ByType returns the resources of one type.
+func (i *Inventory) ByType(resourceType cfg.ResourceType) []service.ResourceInterface {
+	return i.byType[resourceType]
+}
+
+// Has reports whether the inventory holds any resource of the type. Rules that
+// look for a missing reference use it to tell "the target is gone" from "the
+// target type was never fetched".
+func (i *Inventory) Has(resourceType cfg.ResourceType) bool {
+	return len(i.byType[resourceType]) > 0
+}
+
+// Lookup finds a resource by ID within one account and region.
+func (i *Inventory) Lookup(accountID ptypes.AwsAccountID, region ptypes.AwsRegion, id string) (service.ResourceInterface, bool) {
+	r, ok := i.byID[scopedID(accountID, region, id)]
+	return r, ok
+}
+
+func scopedID(accountID ptypes.AwsAccountID, region ptypes.AwsRegion, id string) string {
+	return accountID.String() + ":" + region.String() + ":" + id
+}
@@ -0,0 +1,148 @@
+package waste
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwltypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	emrtypes "github.com/aws/aws-sdk-go-v2/service/emrserverless/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	"github.com/imunhatep/awslib/service/cloudwatchlogs"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/ecs"
+	"github.com/imunhatep/awslib/service/elb"
+	"github.com/imunhatep/awslib/service/emrserverless"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct {
+	region ptypes.AwsRegion
+}
+
+func (m mockClient) GetRegion() ptypes.AwsRegion     { return m.region }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+var euWest1 = mockClient{region: "eu-west-1"}
+
+type fixedPricer map[string]float64
+
+func (p fixedPricer) MonthlyCost(r service.ResourceInterface) (float64, bool, error) {
+	cost, ok := p[r.GetId()]
+	return cost, ok, nil
+}
+
+func ruleIDs(findings []Finding) map[string]string {
+	out := map[string]string{}
+	for _, f := range findings {
+		out[f.ResourceID] = f.RuleID
+	}
+
+	return out
+}
+
+func TestFinderDefaultRules(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	resources := []service.ResourceInterface{
+		ec2.NewVolume(euWest1, ec2types.Volume{VolumeId: aws.String("vol-free"), State: ec2types.VolumeStateAvailable, Size: aws.Int32(100)}),
+		ec2.NewVolume(euWest1, ec2types.Volume{VolumeId: aws.String("vol-used"), State: ec2types.VolumeStateInUse}),
+		ec2.NewAddress(euWest1, ec2types.Address{AllocationId: aws.String("eipalloc-idle"), PublicIp: aws.String("203.0.113.1")}),
+		ec2.NewAddress(euWest1, ec2types.Address{AllocationId: aws.String("eipalloc-used"), AssociationId: aws.String("eipassoc-1")}),
+		ec2.NewSnapshot(euWest1, ec2types.Snapshot{SnapshotId: aws.String("snap-orphan"), VolumeId: aws.String("vol-deleted")}),
+		ec2.NewSnapshot(euWest1, ec2types.Snapshot{SnapshotId: aws.String("snap-live"), VolumeId: aws.String("vol-used")}),
+		ec2.NewSnapshot(euWest1, ec2types.Snapshot{SnapshotId: aws.String("snap-copy"), VolumeId: aws.String(snapshotPlaceholderVolume)}),
+		// same volume ID in another region does not keep the snapshot alive
+		ec2.NewSnapshot(mockClient{region: "us-east-1"}, ec2types.Snapshot{SnapshotId: aws.String("snap-elsewhere"), VolumeId: aws.String("vol-used")}),
+		ecs.NewCluster(euWest1, ecstypes.Cluster{ClusterName: aws.String("empty")}),
+		ecs.NewCluster(euWest1, ecstypes.Cluster{ClusterName: aws.String("busy"), RunningTasksCount: 2}),
+		cloudwatchlogs.NewLogGroup(euWest1, cwltypes.LogGroup{LogGroupName: aws.String("/forever")}, nil),
+		cloudwatchlogs.NewLogGroup(euWest1, cwltypes.LogGroup{LogGroupName: aws.String("/week"), RetentionInDays: aws.Int32(7)}, nil),
+		emrserverless.NewApplication(euWest1, &emrtypes.Application{ApplicationId: aws.String("app-idle"), CreatedAt: &old}),
+		emrserverless.NewApplication(euWest1, &emrtypes.Application{ApplicationId: aws.String("app-busy"), CreatedAt: &old}),
+		emrserverless.NewJobRun(euWest1, &emrtypes.JobRun{JobRunId: aws.String("run-1"), ApplicationId: aws.String("app-busy"), CreatedAt: aws.Time(time.Now())}),
+	}
+
+	findings, err := NewFinder().Find(resources)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"vol-free":       RuleUnattachedVolume,
+		"eipalloc-idle":  RuleUnassociatedAddress,
+		"snap-orphan":    RuleOrphanedSnapshot,
+		"snap-elsewhere": RuleOrphanedSnapshot,
+		"empty":          RuleEmptyEcsCluster,
+		"/forever":       RuleLogGroupNoRetention,
+		"app-idle":       RuleIdleEmrServerlessApp,
+	}, ruleIDs(findings))
+}
+
+// Without volumes in the inventory there is no telling a deleted source volume
+// from one that was never fetched.
+func TestOrphanedSnapshotNeedsVolumes(t *testing.T) {
+	findings, err := NewFinder(OrphanedSnapshot()).Find([]service.ResourceInterface{
+		ec2.NewSnapshot(euWest1, ec2types.Snapshot{SnapshotId: aws.String("snap-1"), VolumeId: aws.String("vol-1")}),
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+// Without job runs in the inventory there is no telling an idle application
+// from one whose runs were never fetched.
+func TestIdleEmrServerlessApplicationNeedsJobRuns(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour)
+
+	findings, err := NewFinder(IdleEmrServerlessApplication(DefaultIdlePeriod)).Find([]service.ResourceInterface{
+		emrserverless.NewApplication(euWest1, &emrtypes.Application{ApplicationId: aws.String("app-1"), CreatedAt: &old}),
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestFinderOrdersBySeverityThenWaste(t *testing.T) {
+	resources := []service.ResourceInterface{
+		ec2.NewVolume(euWest1, ec2types.Volume{VolumeId: aws.String("vol-small"), State: ec2types.VolumeStateAvailable}),
+		ec2.NewVolume(euWest1, ec2types.Volume{VolumeId: aws.String("vol-large"), State: ec2types.VolumeStateAvailable}),
+		cloudwatchlogs.NewLogGroup(euWest1, cwltypes.LogGroup{LogGroupName: aws.String("/forever")}, nil),
+		elb.NewLoadBalancer(euWest1, elbtypes.LoadBalancer{LoadBalancerArn: aws.String("arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/app/empty/1")}, nil),
+	}
+
+	noTargets := func(elb.LoadBalancer) (int, error) { return 0, nil }
+	pricer := fixedPricer{"vol-small": 1, "vol-large": 10}
+
+	findings, err := NewFinder().WithRules(LoadBalancerWithoutTargets(noTargets)).WithPricer(pricer).Find(resources)
+	require.NoError(t, err)
+	require.Len(t, findings, 4)
+
+	assert.Equal(t, RuleLoadBalancerNoTargets, findings[0].RuleID)
+	assert.Equal(t, "vol-large", findings[1].ResourceID)
+	assert.Equal(t, "vol-small", findings[2].ResourceID)
+	assert.Equal(t, RuleLogGroupNoRetention, findings[3].RuleID)
+
+	assert.Nil(t, findings[3].MonthlyWaste)
+	assert.Equal(t, 11.0, TotalMonthlyWaste(findings))
+}
+
+// A rule that cannot decide must not hide the other findings.
+func TestFinderCollectsRuleErrors(t *testing.T) {
+	boom := stderrors.New("throttled")
+
+	resources := []service.ResourceInterface{
+		ec2.NewVolume(euWest1, ec2types.Volume{VolumeId: aws.String("vol-free"), State: ec2types.VolumeStateAvailable}),
+		elb.NewLoadBalancer(euWest1, elbtypes.LoadBalancer{LoadBalancerArn: aws.String("arn:aws:elasticloadbalancing:eu-west-1:123456789012:loadbalancer/app/lb/1")}, nil),
+	}
+
+	findings, err := NewFinder(UnattachedVolume(), LoadBalancerWithoutTargets(func(elb.LoadBalancer) (int, error) { return 0, boom })).Find(resources)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, map[string]string{"vol-free": RuleUnattachedVolume}, ruleIDs(findings))
+}
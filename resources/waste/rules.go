@@ -0,0 +1,245 @@
+package waste
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	emrtypes "github.com/aws/aws-sdk-go-v2/service/emrserverless/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/service"
+	cfgEntity "github.com/imunhatep/awslib/service/cfg"
+	"github.com/imunhatep/awslib/service/cloudwatchlogs"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/imunhatep/awslib/service/ecs"
+	"github.com/imunhatep/awslib/service/elb"
+	"github.com/imunhatep/awslib/service/emrserverless"
+)
+
+const (
+	RuleUnattachedVolume      = "ebs-volume-unattached"
+	RuleUnassociatedAddress   = "eip-unassociated"
+	RuleOrphanedSnapshot      = "ebs-snapshot-orphaned"
+	RuleEmptyEcsCluster       = "ecs-cluster-empty"
+	RuleLoadBalancerNoTargets = "elb-no-targets"
+	RuleIdleEmrServerlessApp  = "emrserverless-application-idle"
+	RuleLogGroupNoRetention   = "logs-group-no-retention"
+)
+
+// DefaultIdlePeriod is how long an EMR Serverless application may go without a
+// job run before it is reported as idle.
+const DefaultIdlePeriod = 30 * 24 * time.Hour
+
+// snapshotPlaceholderVolume is the VolumeId AWS reports for snapshots copied
+// from another snapshot or created from an AMI: there never was a source volume
+// in this account, so its absence means nothing.
+const snapshotPlaceholderVolume = "vol-ffffffff"
+
+// DefaultRules returns every rule that needs nothing beyond the inventory. The
+// load balancer rule needs a TargetCounter and is added with
+// LoadBalancerWithoutTargets.
+func DefaultRules() []Rule {
+	return []Rule{
+		UnattachedVolume(),
+		UnassociatedAddress(),
+		OrphanedSnapshot(),
+		EmptyEcsCluster(),
+		IdleEmrServerlessApplication(DefaultIdlePeriod),
+		LogGroupWithoutRetention(),
+	}
+}
+
+// UnattachedVolume flags EBS volumes in the available state: billed per GB
+// whether or not anything reads them.
+func UnattachedVolume() Rule {
+	return Rule{
+		ID:           RuleUnattachedVolume,
+		Severity:     SeverityMedium,
+		ResourceType: cfg.ResourceTypeVolume,
+		Check: func(resource service.ResourceInterface, _ *Inventory) (string, bool, error) {
+			volume, ok := resource.(ec2.Volume)
+			if !ok || volume.GetState() != ec2types.VolumeStateAvailable {
+				return "", false, nil
+			}
+
+			return fmt.Sprintf("%d GiB %s volume is not attached to any instance", volume.GetSize(), volume.VolumeType), true, nil
+		},
+	}
+}
+
+// UnassociatedAddress flags Elastic IPs with no association. An idle public
+// IPv4 address is billed hourly.
+func UnassociatedAddress() Rule {
+	return Rule{
+		ID:           RuleUnassociatedAddress,
+		Severity:     SeverityMedium,
+		ResourceType: cfg.ResourceTypeEip,
+		Check: func(resource service.ResourceInterface, _ *Inventory) (string, bool, error) {
+			address, ok := resource.(ec2.Address)
+			if !ok || address.IsAssociated() {
+				return "", false, nil
+			}
+
+			return fmt.Sprintf("elastic IP %s is not associated with an instance or network interface", address.GetPublicIp()), true, nil
+		},
+	}
+}
+
+// OrphanedSnapshot flags snapshots whose source volume no longer exists in the
+// same account and region. It only fires when the inventory holds volumes at
+// all: run over snapshots alone, every snapshot would look orphaned.
+func OrphanedSnapshot() Rule {
+	return Rule{
+		ID:           RuleOrphanedSnapshot,
+		Severity:     SeverityLow,
+		ResourceType: cfgEntity.ResourceTypeSnapshot,
+		Check: func(resource service.ResourceInterface, inventory *Inventory) (string, bool, error) {
+			snapshot, ok := resource.(ec2.Snapshot)
+			if !ok || !inventory.Has(cfg.ResourceTypeVolume) {
+				return "", false, nil
+			}
+
+			volumeID := aws.ToString(snapshot.VolumeId)
+			if volumeID == "" || volumeID == snapshotPlaceholderVolume {
+				return "", false, nil
+			}
+
+			if _, found := inventory.Lookup(snapshot.GetAccountID(), snapshot.GetRegion(), volumeID); found {
+				return "", false, nil
+			}
+
+			return fmt.Sprintf("source volume %s no longer exists", volumeID), true, nil
+		},
+	}
+}
+
+// EmptyEcsCluster flags clusters with no services, tasks or container
+// instances. The cluster itself is free; it is reported as clutter.
+func EmptyEcsCluster() Rule {
+	return Rule{
+		ID:           RuleEmptyEcsCluster,
+		Severity:     SeverityLow,
+		ResourceType: cfg.ResourceTypeECSCluster,
+		Check: func(resource service.ResourceInterface, _ *Inventory) (string, bool, error) {
+			cluster, ok := resource.(ecs.Cluster)
+			if !ok {
+				return "", false, nil
+			}
+
+			if cluster.ActiveServicesCount > 0 || cluster.RunningTasksCount > 0 ||
+				cluster.PendingTasksCount > 0 || cluster.RegisteredContainerInstancesCount > 0 {
+				return "", false, nil
+			}
+
+			return "cluster has no services, tasks or container instances", true, nil
+		},
+	}
+}
+
+// TargetCounter returns how many targets are registered behind a load
+// balancer. NewElbTargetCounter provides one backed by the ELB API.
+type TargetCounter func(lb elb.LoadBalancer) (int, error)
+
+// ClientPool resolves the client for an account and region, as
+// resources.AwsClientPool does.
+type ClientPool interface {
+	GetClient(ptypes.AwsAccountID, ptypes.AwsRegion) (*v3.Client, error)
+}
+
+// NewElbTargetCounter counts targets through DescribeTargetGroups and
+// DescribeTargetHealth in the load balancer's own account and region.
+func NewElbTargetCounter(ctx context.Context, clients ClientPool) TargetCounter {
+	return func(lb elb.LoadBalancer) (int, error) {
+		client, err := clients.GetClient(lb.GetAccountID(), lb.GetRegion())
+		if err != nil {
+			return 0, err
+		}
+
+		return elb.NewLoadBalancerRepository(ctx, client).GetLoadBalancerTargetCount(lb.GetArn())
+	}
+}
+
+// LoadBalancerWithoutTargets flags load balancers that forward to no
+// registered target. The balancer is billed hourly regardless.
+func LoadBalancerWithoutTargets(countTargets TargetCounter) Rule {
+	return Rule{
+		ID:           RuleLoadBalancerNoTargets,
+		Severity:     SeverityHigh,
+		ResourceType: cfg.ResourceTypeLoadBalancerV2,
+		Check: func(resource service.ResourceInterface, _ *Inventory) (string, bool, error) {
+			lb, ok := resource.(elb.LoadBalancer)
+			if !ok {
+				return "", false, nil
+			}
+
+			count, err := countTargets(lb)
+			if err != nil || count > 0 {
+				return "", false, err
+			}
+
+			return fmt.Sprintf("%s load balancer has no registered targets", lb.LoadBalancer.Type), true, nil
+		},
+	}
+}
+
+// IdleEmrServerlessApplication flags applications with no job run within
+// idleFor. Job runs are looked up in the inventory, so include
+// AWS::EMRServerless::JobRun in the sweep: without any, no application is
+// flagged. The rationale calls out a started application holding
+// pre-initialized capacity, which is billed while idle.
+func IdleEmrServerlessApplication(idleFor time.Duration) Rule {
+	return Rule{
+		ID:           RuleIdleEmrServerlessApp,
+		Severity:     SeverityLow,
+		ResourceType: cfgEntity.ResourceTypeEmrServerlessApplication,
+		Check: func(resource service.ResourceInterface, inventory *Inventory) (string, bool, error) {
+			app, ok := resource.(emrserverless.Application)
+			if !ok || app.Application == nil || !inventory.Has(cfgEntity.ResourceTypeEmrServerlessJobRun) {
+				return "", false, nil
+			}
+
+			cutoff := time.Now().Add(-idleFor)
+			if app.GetCreatedAt().After(cutoff) {
+				return "", false, nil
+			}
+
+			for _, r := range inventory.ByType(cfgEntity.ResourceTypeEmrServerlessJobRun) {
+				run, ok := r.(emrserverless.JobRun)
+				if ok && run.JobRun != nil &&
+					aws.ToString(run.ApplicationId) == aws.ToString(app.ApplicationId) &&
+					run.GetCreatedAt().After(cutoff) {
+					return "", false, nil
+				}
+			}
+
+			rationale := fmt.Sprintf("no job run in the last %s", idleFor)
+			if app.State == emrtypes.ApplicationStateStarted && len(app.InitialCapacity) > 0 {
+				rationale += ", while holding pre-initialized capacity"
+			}
+
+			return rationale, true, nil
+		},
+	}
+}
+
+// LogGroupWithoutRetention flags log groups that keep events forever. Storage
+// grows without bound; the fix is a retention policy, not a deletion.
+func LogGroupWithoutRetention() Rule {
+	return Rule{
+		ID:           RuleLogGroupNoRetention,
+		Severity:     SeverityLow,
+		ResourceType: cfgEntity.ResourceTypeCloudWatchLogGroup,
+		Check: func(resource service.ResourceInterface, _ *Inventory) (string, bool, error) {
+			group, ok := resource.(cloudwatchlogs.LogGroup)
+			if !ok || group.RetentionInDays != nil {
+				return "", false, nil
+			}
+
+			return fmt.Sprintf("log group retains events indefinitely (%d bytes stored)", aws.ToInt64(group.StoredBytes)), true, nil
+		},
+	}
+}
@@ -0,0 +1,237 @@
+package accessanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsanalyzer "github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/accessanalyzer"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/imunhatep/awslib/service/iam"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// AccessAnalyzerRepository reads IAM Access Analyzer analyzers and their
+// findings, and validates policies. Analyzers are regional: findings of a
+// region come from the analyzers of that region.
+type AccessAnalyzerRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewAccessAnalyzerRepository(ctx context.Context, client *v3.Client) *AccessAnalyzerRepository {
+	repo := &AccessAnalyzerRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *AccessAnalyzerRepository) analyzerClient() *awsanalyzer.Client {
+	return accessanalyzer.GetClient(r.client)
+}
+
+func (r *AccessAnalyzerRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *AccessAnalyzerRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+func (r *AccessAnalyzerRepository) ListAnalyzersAll() ([]Analyzer, error) {
+	return r.ListAnalyzersByInput(&awsanalyzer.ListAnalyzersInput{})
+}
+
+func (r *AccessAnalyzerRepository) ListAnalyzersByInput(query *awsanalyzer.ListAnalyzersInput) ([]Analyzer, error) {
+	start := time.Now()
+	var analyzers []Analyzer
+
+	p := awsanalyzer.NewListAnalyzersPaginator(r.analyzerClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListAnalyzers", cfg.ResourceTypeAccessAnalyzerAnalyzer)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListAnalyzers", cfg.ResourceTypeAccessAnalyzerAnalyzer)).Inc()
+			}
+
+			return analyzers, errors.New(err)
+		}
+
+		for _, v := range resp.Analyzers {
+			analyzers = append(analyzers, NewAnalyzer(r.client, v))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("ListAnalyzers", cfg.ResourceTypeAccessAnalyzerAnalyzer)).
+			Add(float64(len(analyzers)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListAnalyzersByInput", cfg.ResourceTypeAccessAnalyzerAnalyzer)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return analyzers, nil
+}
+
+// ListFindingsActive returns the active findings of every active analyzer
+// of the region, external and unused access alike.
+func (r *AccessAnalyzerRepository) ListFindingsActive() ([]Finding, error) {
+	analyzers, err := r.ListAnalyzersAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, analyzer := range analyzers {
+		if !analyzer.IsActive() {
+			log.Debug().Str("analyzer", analyzer.GetArn()).Str("status", string(analyzer.Status)).Msg("[AccessAnalyzerRepository.ListFindingsActive] analyzer not active, skipping")
+			continue
+		}
+
+		items, err := r.ListFindingsByInput(&awsanalyzer.ListFindingsV2Input{
+			AnalyzerArn: analyzer.Arn,
+			Filter: map[string]types.Criterion{
+				"status": {Eq: []string{string(types.FindingStatusActive)}},
+			},
+		})
+		if err != nil {
+			return findings, err
+		}
+
+		findings = append(findings, items...)
+	}
+
+	return findings, nil
+}
+
+// ListFindingsByAnalyzer returns every finding of an analyzer, archived and
+// resolved ones included.
+func (r *AccessAnalyzerRepository) ListFindingsByAnalyzer(analyzer Analyzer) ([]Finding, error) {
+	return r.ListFindingsByInput(&awsanalyzer.ListFindingsV2Input{AnalyzerArn: analyzer.Arn})
+}
+
+func (r *AccessAnalyzerRepository) ListFindingsByInput(query *awsanalyzer.ListFindingsV2Input) ([]Finding, error) {
+	start := time.Now()
+	var findings []Finding
+
+	p := awsanalyzer.NewListFindingsV2Paginator(r.analyzerClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListFindingsV2", ccfg.ResourceTypeAccessAnalyzerFinding)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListFindingsV2", ccfg.ResourceTypeAccessAnalyzerFinding)).Inc()
+			}
+
+			return findings, errors.New(err)
+		}
+
+		for _, v := range resp.Findings {
+			findings = append(findings, NewFinding(r.client, aws.ToString(query.AnalyzerArn), v))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("ListFindingsV2", ccfg.ResourceTypeAccessAnalyzerFinding)).
+			Add(float64(len(findings)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListFindingsByInput", ccfg.ResourceTypeAccessAnalyzerFinding)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return findings, nil
+}
+
+// ValidatePolicy checks a policy document before it is pushed, e.g.
+// types.PolicyTypeIdentityPolicy for a policy attached to a user or role.
+func (r *AccessAnalyzerRepository) ValidatePolicy(document iam.PolicyDocument, policyType types.PolicyType) (PolicyValidation, error) {
+	content, err := json.Marshal(document)
+	if err != nil {
+		return PolicyValidation{}, errors.New(err)
+	}
+
+	return r.ValidatePolicyByInput(&awsanalyzer.ValidatePolicyInput{
+		PolicyDocument: aws.String(string(content)),
+		PolicyType:     policyType,
+	})
+}
+
+// ValidateTrustPolicy checks a role trust policy, which Access Analyzer
+// validates as a resource policy of AWS::IAM::AssumeRolePolicyDocument.
+func (r *AccessAnalyzerRepository) ValidateTrustPolicy(document iam.PolicyDocument) (PolicyValidation, error) {
+	content, err := json.Marshal(document)
+	if err != nil {
+		return PolicyValidation{}, errors.New(err)
+	}
+
+	return r.ValidatePolicyByInput(&awsanalyzer.ValidatePolicyInput{
+		PolicyDocument:             aws.String(string(content)),
+		PolicyType:                 types.PolicyTypeResourcePolicy,
+		ValidatePolicyResourceType: types.ValidatePolicyResourceTypeRoleTrust,
+	})
+}
+
+// ValidatePolicyByInput follows ValidatePolicy pagination and returns every
+// finding.
+func (r *AccessAnalyzerRepository) ValidatePolicyByInput(query *awsanalyzer.ValidatePolicyInput) (PolicyValidation, error) {
+	start := time.Now()
+	validation := PolicyValidation{}
+
+	p := awsanalyzer.NewValidatePolicyPaginator(r.analyzerClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ValidatePolicy", cfg.ResourceTypePolicy)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ValidatePolicy", cfg.ResourceTypePolicy)).Inc()
+			}
+
+			return validation, errors.New(err)
+		}
+
+		validation.Findings = append(validation.Findings, resp.Findings...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ValidatePolicyByInput", cfg.ResourceTypePolicy)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return validation, nil
+}
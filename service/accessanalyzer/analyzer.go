@@ -0,0 +1,63 @@
+package accessanalyzer
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+type Analyzer struct {
+	service.AbstractResource
+	types.AnalyzerSummary
+}
+
+func NewAnalyzer(client AwsClient, analyzer types.AnalyzerSummary) Analyzer {
+	aArn, _ := arn.Parse(aws.ToString(analyzer.Arn))
+
+	return Analyzer{
+		AbstractResource: service.AbstractResource{
+			AccountID: client.GetAccountID(),
+			Region:    client.GetRegion(),
+			ID:        aws.ToString(analyzer.Name),
+			ARN:       &aArn,
+			CreatedAt: aws.ToTime(analyzer.CreatedAt),
+			Type:      cfg.ResourceTypeAccessAnalyzerAnalyzer,
+		},
+		AnalyzerSummary: analyzer,
+	}
+}
+
+func (e Analyzer) GetName() string {
+	return aws.ToString(e.Name)
+}
+
+func (e Analyzer) IsActive() bool {
+	return e.Status == types.AnalyzerStatusActive
+}
+
+// IsUnusedAccess reports an analyzer of unused access, as opposed to
+// external or internal access.
+func (e Analyzer) IsUnusedAccess() bool {
+	return e.AnalyzerSummary.Type == types.TypeAccountUnusedAccess || e.AnalyzerSummary.Type == types.TypeOrganizationUnusedAccess
+}
+
+func (e Analyzer) GetTags() map[string]string {
+	tags := make(map[string]string)
+
+	for key, value := range e.Tags {
+		tags[key] = value
+	}
+
+	return tags
+}
+
+func (e Analyzer) GetTagValue(tag string) string {
+	val, ok := e.GetTags()[tag]
+	if !ok {
+		return ""
+	}
+
+	return val
+}
@@ -0,0 +1,95 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package accessanalyzer
+
+import (
+	"fmt"
+
+	awsanalyzer "github.com/aws/aws-sdk-go-v2/service/accessanalyzer"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// AccessAnalyzerRepositoryCached wraps AccessAnalyzerRepository and caches results of Get*/List* calls.
+type AccessAnalyzerRepositoryCached struct {
+	repo  *AccessAnalyzerRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a AccessAnalyzerRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *AccessAnalyzerRepository) WithCache(dc *cache.DataCache) *AccessAnalyzerRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &AccessAnalyzerRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListAnalyzersAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *AccessAnalyzerRepositoryCached) ListAnalyzersAll() ([]Analyzer, error) {
+	cacheKey := cache.Key("ListAnalyzersAll")
+	var cached []Analyzer
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAnalyzersAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAnalyzersByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *AccessAnalyzerRepositoryCached) ListAnalyzersByInput(query *awsanalyzer.ListAnalyzersInput) ([]Analyzer, error) {
+	cacheKey := cache.Key("ListAnalyzersByInput", query)
+	var cached []Analyzer
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAnalyzersByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListFindingsActive returns cached results when available, otherwise delegates to the underlying repository.
+func (c *AccessAnalyzerRepositoryCached) ListFindingsActive() ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsActive")
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsActive()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListFindingsByAnalyzer returns cached results when available, otherwise delegates to the underlying repository.
+func (c *AccessAnalyzerRepositoryCached) ListFindingsByAnalyzer(analyzer Analyzer) ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsByAnalyzer", analyzer)
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsByAnalyzer(analyzer)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListFindingsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *AccessAnalyzerRepositoryCached) ListFindingsByInput(query *awsanalyzer.ListFindingsV2Input) ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsByInput", query)
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -0,0 +1,79 @@
+package accessanalyzer
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Finding is an Access Analyzer finding: a resource reachable from outside
+// the zone of trust, or access granted but unused. Findings have no ARN;
+// they are identified by id within their analyzer.
+type Finding struct {
+	service.AbstractResource
+	types.FindingSummaryV2
+	AnalyzerArn string
+}
+
+func NewFinding(client AwsClient, analyzerArn string, finding types.FindingSummaryV2) Finding {
+	return Finding{
+		AbstractResource: service.AbstractResource{
+			AccountID: client.GetAccountID(),
+			Region:    client.GetRegion(),
+			ID:        aws.ToString(finding.Id),
+			CreatedAt: aws.ToTime(finding.CreatedAt),
+			Type:      ccfg.ResourceTypeAccessAnalyzerFinding,
+		},
+		FindingSummaryV2: finding,
+		AnalyzerArn:      analyzerArn,
+	}
+}
+
+// GetName names the finding by its type and resource, e.g.
+// "ExternalAccess arn:aws:s3:::bucket".
+func (e Finding) GetName() string {
+	return string(e.FindingType) + " " + e.GetResourceArn()
+}
+
+// GetResourceArn returns the ARN of the resource the finding is about.
+func (e Finding) GetResourceArn() string {
+	return aws.ToString(e.Resource)
+}
+
+// GetResourceOwnerAccount returns the account owning the resource, which
+// for organization analyzers is not the analyzer's.
+func (e Finding) GetResourceOwnerAccount() ptypes.AwsAccountID {
+	return ptypes.AwsAccountID(aws.ToString(e.ResourceOwnerAccount))
+}
+
+func (e Finding) IsActive() bool {
+	return e.Status == types.FindingStatusActive
+}
+
+func (e Finding) IsExternalAccess() bool {
+	return e.FindingType == types.FindingTypeExternalAccess
+}
+
+// IsUnusedAccess reports a finding of an unused role, access key, password
+// or permission.
+func (e Finding) IsUnusedAccess() bool {
+	switch e.FindingType {
+	case types.FindingTypeUnusedIamRole,
+		types.FindingTypeUnusedIamUserAccessKey,
+		types.FindingTypeUnusedIamUserPassword,
+		types.FindingTypeUnusedPermission:
+		return true
+	}
+
+	return false
+}
+
+func (e Finding) GetTags() map[string]string {
+	return map[string]string{}
+}
+
+func (e Finding) GetTagValue(tag string) string {
+	return ""
+}
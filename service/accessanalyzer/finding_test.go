@@ -0,0 +1,54 @@
+package accessanalyzer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-central-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func TestNewFinding(t *testing.T) {
+	finding := NewFinding(mockClient{}, "arn:aws:access-analyzer:eu-central-1:123456789012:analyzer/org", types.FindingSummaryV2{
+		Id:                   aws.String("f-1"),
+		FindingType:          types.FindingTypeExternalAccess,
+		Resource:             aws.String("arn:aws:s3:::shared-bucket"),
+		ResourceOwnerAccount: aws.String("210987654321"),
+		Status:               types.FindingStatusActive,
+	})
+
+	assert.Equal(t, "f-1", finding.GetId())
+	assert.Equal(t, ccfg.ResourceTypeAccessAnalyzerFinding, finding.GetType())
+	assert.Equal(t, "ExternalAccess arn:aws:s3:::shared-bucket", finding.GetName())
+	assert.Equal(t, ptypes.AwsAccountID("210987654321"), finding.GetResourceOwnerAccount())
+	assert.True(t, finding.IsActive())
+	assert.True(t, finding.IsExternalAccess())
+	assert.False(t, finding.IsUnusedAccess())
+
+	finding.FindingType = types.FindingTypeUnusedIamUserAccessKey
+	assert.True(t, finding.IsUnusedAccess())
+}
+
+func TestPolicyValidation(t *testing.T) {
+	validation := PolicyValidation{Findings: []types.ValidatePolicyFinding{
+		{FindingType: types.ValidatePolicyFindingTypeSuggestion, IssueCode: aws.String("EMPTY_ARRAY_ACTION")},
+		{FindingType: types.ValidatePolicyFindingTypeWarning, IssueCode: aws.String("MISSING_VERSION")},
+	}}
+	assert.True(t, validation.IsValid())
+	assert.Len(t, validation.Warnings(), 1)
+
+	validation.Findings = append(validation.Findings, types.ValidatePolicyFinding{
+		FindingType: types.ValidatePolicyFindingTypeSecurityWarning,
+		IssueCode:   aws.String("PASS_ROLE_WITH_STAR_IN_RESOURCE"),
+	})
+	assert.False(t, validation.IsValid())
+	assert.Len(t, validation.SecurityWarnings(), 1)
+	assert.Empty(t, validation.Errors())
+}
@@ -0,0 +1,44 @@
+package accessanalyzer
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/accessanalyzer/types"
+)
+
+// PolicyValidation is what ValidatePolicy found in a policy, from errors
+// that make it invalid down to suggestions.
+type PolicyValidation struct {
+	Findings []types.ValidatePolicyFinding
+}
+
+// IsValid reports a policy without errors or security warnings; plain
+// warnings and suggestions do not block it.
+func (v PolicyValidation) IsValid() bool {
+	return len(v.Errors()) == 0 && len(v.SecurityWarnings()) == 0
+}
+
+func (v PolicyValidation) Errors() []types.ValidatePolicyFinding {
+	return v.byType(types.ValidatePolicyFindingTypeError)
+}
+
+func (v PolicyValidation) SecurityWarnings() []types.ValidatePolicyFinding {
+	return v.byType(types.ValidatePolicyFindingTypeSecurityWarning)
+}
+
+func (v PolicyValidation) Warnings() []types.ValidatePolicyFinding {
+	return v.byType(types.ValidatePolicyFindingTypeWarning)
+}
+
+func (v PolicyValidation) Suggestions() []types.ValidatePolicyFinding {
+	return v.byType(types.ValidatePolicyFindingTypeSuggestion)
+}
+
+func (v PolicyValidation) byType(findingType types.ValidatePolicyFindingType) []types.ValidatePolicyFinding {
+	var findings []types.ValidatePolicyFinding
+	for _, finding := range v.Findings {
+		if finding.FindingType == findingType {
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
@@ -8,23 +8,40 @@ import (
 )
 
 const (
-	ResourceTypeDBEngineVersion          awscfg.ResourceType = "AWS::RDS::DBEngineVersion"
-	ResourceTypeSnapshot                 awscfg.ResourceType = "AWS::EC2::Snapshot"
-	ResourceTypeEmrServerlessApplication awscfg.ResourceType = "AWS::EMRServerless::Application"
-	ResourceTypeEmrCluster               awscfg.ResourceType = "AWS::EMR::Cluster"
-	ResourceTypeEmrServerlessJobRun      awscfg.ResourceType = "AWS::EMRServerless::JobRun"
-	ResourceTypeCloudWatchLogGroup       awscfg.ResourceType = "AWS::Logs::LogGroup"
-	ResourceTypeGlueDatabase             awscfg.ResourceType = "AWS::Glue::Database"
-	ResourceTypeGlueTable                awscfg.ResourceType = "AWS::Glue::Table"
-	ResourceTypeGlueJob                  awscfg.ResourceType = "AWS::Glue::Job"
-	ResourceTypeTrailEvent               awscfg.ResourceType = "AWS::CloudTrail::Event"
-	ResourceTypeHealthEvent              awscfg.ResourceType = "AWS::Health::Event"
-	ResourceTypeRoute53ResourceRecord    awscfg.ResourceType = "AWS::Route53::ResourceRecord"
-	ResourceTypeRoute53DomainSummary     awscfg.ResourceType = "AWS::Route53Domains::DomainSummary"
-	ResourceTypeRoute53Domain            awscfg.ResourceType = "AWS::Route53Domains::Domain"
-	ResourceTypeCostAndUsage             awscfg.ResourceType = "AWS::CostExplorer::CostAndUsage"
-	ResourceTypeCostForecast             awscfg.ResourceType = "AWS::CostExplorer::CostForecast"
-	ResourceTypeCostDimensionValue       awscfg.ResourceType = "AWS::CostExplorer::DimensionValue"
+	ResourceTypeDBEngineVersion            awscfg.ResourceType = "AWS::RDS::DBEngineVersion"
+	ResourceTypeSnapshot                   awscfg.ResourceType = "AWS::EC2::Snapshot"
+	ResourceTypeEmrServerlessApplication   awscfg.ResourceType = "AWS::EMRServerless::Application"
+	ResourceTypeEmrCluster                 awscfg.ResourceType = "AWS::EMR::Cluster"
+	ResourceTypeEmrServerlessJobRun        awscfg.ResourceType = "AWS::EMRServerless::JobRun"
+	ResourceTypeCloudWatchLogGroup         awscfg.ResourceType = "AWS::Logs::LogGroup"
+	ResourceTypeGlueDatabase               awscfg.ResourceType = "AWS::Glue::Database"
+	ResourceTypeGlueTable                  awscfg.ResourceType = "AWS::Glue::Table"
+	ResourceTypeGlueJob                    awscfg.ResourceType = "AWS::Glue::Job"
+	ResourceTypeTrailEvent                 awscfg.ResourceType = "AWS::CloudTrail::Event"
+	ResourceTypeHealthEvent                awscfg.ResourceType = "AWS::Health::Event"
+	ResourceTypeRoute53ResourceRecord      awscfg.ResourceType = "AWS::Route53::ResourceRecord"
+	ResourceTypeRoute53DomainSummary       awscfg.ResourceType = "AWS::Route53Domains::DomainSummary"
+	ResourceTypeRoute53Domain              awscfg.ResourceType = "AWS::Route53Domains::Domain"
+	ResourceTypeCostAndUsage               awscfg.ResourceType = "AWS::CostExplorer::CostAndUsage"
+	ResourceTypeCostForecast               awscfg.ResourceType = "AWS::CostExplorer::CostForecast"
+	ResourceTypeCostDimensionValue         awscfg.ResourceType = "AWS::CostExplorer::DimensionValue"
+	ResourceTypeCostAnomaly                awscfg.ResourceType = "AWS::CostExplorer::Anomaly"
+	ResourceTypeSavingsPlansCoverage       awscfg.ResourceType = "AWS::CostExplorer::SavingsPlansCoverage"
+	ResourceTypeSavingsPlansUtilization    awscfg.ResourceType = "AWS::CostExplorer::SavingsPlansUtilization"
+	ResourceTypeSavingsPlansRecommendation awscfg.ResourceType = "AWS::CostExplorer::SavingsPlansPurchaseRecommendation"
+	ResourceTypeReservationCoverage        awscfg.ResourceType = "AWS::CostExplorer::ReservationCoverage"
+	ResourceTypeReservationUtilization     awscfg.ResourceType = "AWS::CostExplorer::ReservationUtilization"
+	ResourceTypeReservationRecommendation  awscfg.ResourceType = "AWS::CostExplorer::ReservationPurchaseRecommendation"
+	ResourceTypeRightsizingRecommendation  awscfg.ResourceType = "AWS::CostExplorer::RightsizingRecommendation"
+	ResourceTypeSavingsPlan                awscfg.ResourceType = "AWS::SavingsPlans::SavingsPlan"
+	ResourceTypePriceListProduct           awscfg.ResourceType = "AWS::Pricing::Product"
+	ResourceTypePriceListService           awscfg.ResourceType = "AWS::Pricing::Service"
+	ResourceTypePriceListAttributeValue    awscfg.ResourceType = "AWS::Pricing::AttributeValue"
+	ResourceTypeTaggedResource             awscfg.ResourceType = "AWS::ResourceGroupsTaggingAPI::Resource"
+	ResourceTypeSsmParameter               awscfg.ResourceType = "AWS::SSM::Parameter"
+	ResourceTypeAccessAnalyzerFinding      awscfg.ResourceType = "AWS::AccessAnalyzer::Finding"
+	ResourceTypeSecurityHubFinding         awscfg.ResourceType = "AWS::SecurityHub::Finding"
+	ResourceTypeConfigQueryResult          awscfg.ResourceType = "AWS::Config::QueryResult"
 
 	// CloudFront SaaS Manager (multi-tenant distributions). ListDistributionTenants
 	// returns summaries; the full tenant only comes back from a Get, so the two are
@@ -66,11 +83,16 @@ func ResourceTypeListGlobal() []awscfg.ResourceType {
 		// cloudfront — the control plane is global, not regional
 		ResourceTypeCloudFrontDistributionTenantSummary,
 		ResourceTypeCloudFrontConnectionGroup,
+		// health — served from us-east-1 for every region
+		ResourceTypeHealthEvent,
 	}
 }
 
 func ResourceTypeListRegional() []awscfg.ResourceType {
 	return []awscfg.ResourceType{
+		// accessanalyzer
+		awscfg.ResourceTypeAccessAnalyzerAnalyzer,
+		ResourceTypeAccessAnalyzerFinding,
 		// athena
 		awscfg.ResourceTypeAthenaDataCatalog,
 		awscfg.ResourceTypeAthenaWorkGroup,
@@ -83,6 +105,8 @@ func ResourceTypeListRegional() []awscfg.ResourceType {
 		ResourceTypeCloudWatchLogGroup,
 		// cloudtrail
 		awscfg.ResourceTypeTrail,
+		// securityhub
+		ResourceTypeSecurityHubFinding,
 		// s3 bucket
 		awscfg.ResourceTypeBucket,
 		// rds
@@ -125,5 +149,7 @@ func ResourceTypeListRegional() []awscfg.ResourceType {
 		// sns
 		awscfg.ResourceTypeTable,
 		awscfg.ResourceTypeTopic,
+		// ssm
+		ResourceTypeSsmParameter,
 	}
 }
@@ -0,0 +1,63 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package cloudtrail
+
+import (
+	"fmt"
+
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// LakeRepositoryCached wraps LakeRepository and caches results of Get*/List* calls.
+type LakeRepositoryCached struct {
+	repo  *LakeRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a LakeRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *LakeRepository) WithCache(dc *cache.DataCache) *LakeRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &LakeRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListEventsByLookup returns cached results when available, otherwise delegates to the underlying repository.
+func (c *LakeRepositoryCached) ListEventsByLookup(lookup *LookupMiddleware) ([]Event, error) {
+	cacheKey := cache.Key("ListEventsByLookup", lookup)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListEventsByLookup(lookup)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListEventsByLookupAsync delegates directly to the underlying repository (channel return – not cached).
+func (c *LakeRepositoryCached) ListEventsByLookupAsync(lookup *LookupMiddleware) (<-chan Event, <-chan *errors.Error) {
+	return c.repo.ListEventsByLookupAsync(lookup)
+}
+
+// ListEventsByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *LakeRepositoryCached) ListEventsByQuery(sql string) ([]Event, error) {
+	cacheKey := cache.Key("ListEventsByQuery", sql)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListEventsByQuery(sql)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListEventsByQueryAsync delegates directly to the underlying repository (channel return – not cached).
+func (c *LakeRepositoryCached) ListEventsByQueryAsync(sql string) (<-chan Event, <-chan *errors.Error) {
+	return c.repo.ListEventsByQueryAsync(sql)
+}
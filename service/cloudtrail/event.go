@@ -1,11 +1,13 @@
 package cloudtrail
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
 	"github.com/imunhatep/awslib/helper"
 	"github.com/imunhatep/awslib/service"
 )
@@ -16,18 +18,6 @@ type Event struct {
 	EventData CloudTrailEvent
 }
 
-// UserIdentity represents the user identity information in a CloudTrail event
-type UserIdentity struct {
-	Type string `json:"type"`
-}
-
-// CloudTrailEvent represents the structure of a CloudTrail event
-type CloudTrailEvent struct {
-	EventID         string       `json:"eventID"`
-	UserIdentity    UserIdentity `json:"userIdentity"`
-	SourceIPAddress string       `json:"sourceIPAddress"`
-}
-
 func NewEvent(client AwsClient, event types.Event, eventData CloudTrailEvent) Event {
 	ebs := Event{
 		AbstractResource: service.AbstractResource{
@@ -99,9 +89,80 @@ func (e Event) GetTime() time.Time {
 }
 
 func (e Event) GetReadOnly() string {
-	return *e.Event.ReadOnly
+	return aws.ToString(e.Event.ReadOnly)
 }
 
 func (e Event) IsReadOnly() bool {
 	return e.GetReadOnly() == "true"
 }
+
+func (e Event) GetUserIdentity() UserIdentity {
+	return e.EventData.UserIdentity
+}
+
+// GetPrincipal returns the IAM user, role or root account behind the event,
+// with the session for assumed roles; GetUsername only has the session name.
+func (e Event) GetPrincipal() Principal {
+	return NewPrincipal(e.EventData.UserIdentity)
+}
+
+func (e Event) GetAccessKeyId() string {
+	if e.EventData.UserIdentity.AccessKeyID != "" {
+		return e.EventData.UserIdentity.AccessKeyID
+	}
+
+	return aws.ToString(e.Event.AccessKeyId)
+}
+
+func (e Event) GetUserAgent() string {
+	return e.EventData.UserAgent
+}
+
+func (e Event) GetErrorCode() string {
+	return e.EventData.ErrorCode
+}
+
+func (e Event) GetErrorMessage() string {
+	return e.EventData.ErrorMessage
+}
+
+// IsError tells a failed call, e.g. an AccessDenied.
+func (e Event) IsError() bool {
+	return e.EventData.ErrorCode != ""
+}
+
+func (e Event) GetRequestParameters() json.RawMessage {
+	return e.EventData.RequestParameters
+}
+
+func (e Event) GetResponseElements() json.RawMessage {
+	return e.EventData.ResponseElements
+}
+
+// DecodeRequestParameters unmarshals the request parameters into v, e.g. a
+// struct with the fields of the API call's input. Events without request
+// parameters leave v untouched.
+func (e Event) DecodeRequestParameters(v any) error {
+	return decodeRaw(e.EventData.RequestParameters, v)
+}
+
+// DecodeResponseElements unmarshals the response elements into v.
+func (e Event) DecodeResponseElements(v any) error {
+	return decodeRaw(e.EventData.ResponseElements, v)
+}
+
+func (e Event) GetTlsDetails() *TlsDetails {
+	return e.EventData.TlsDetails
+}
+
+func decodeRaw(raw json.RawMessage, v any) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
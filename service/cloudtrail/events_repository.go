@@ -25,13 +25,16 @@ func (r *CloudTrailRepository) ListEventsByInputAsync(query *cloudtrail.LookupEv
 	source := make(chan Event)
 	errChan := make(chan *errors.Error)
 
-	go r.fetchEventsByInput(query, source, errChan)
+	go r.fetchEventsByInput(query, nil, source, errChan)
 
 	return source, errChan
 }
 
+// fetchEventsByInput streams the events of a query that match, all when match
+// is nil. MaxResults counts matching events.
 func (r *CloudTrailRepository) fetchEventsByInput(
 	query *cloudtrail.LookupEventsInput,
+	match func(Event) bool,
 	source chan<- Event,
 	errChan chan<- *errors.Error,
 ) {
@@ -46,7 +49,8 @@ func (r *CloudTrailRepository) fetchEventsByInput(
 
 	// reach end of pages or max results
 	eventsFetchedCount := 0
-	for p.HasMorePages() && (query.MaxResults == nil || eventsFetchedCount < int(*query.MaxResults)) {
+	eventsMatchedCount := 0
+	for p.HasMorePages() && (query.MaxResults == nil || eventsMatchedCount < int(*query.MaxResults)) {
 		if metrics.AwsMetricsEnabled {
 			metrics.AwsApiRequests.With(r.promLabels("LookupEvents", ccfg.ResourceTypeTrailEvent)).Inc()
 		}
@@ -78,11 +82,21 @@ func (r *CloudTrailRepository) fetchEventsByInput(
 				continue
 			}
 
+			e := NewEvent(r.client, event, cloudTrailEvent)
+			if match != nil && !match(e) {
+				continue
+			}
+
+			if query.MaxResults != nil && eventsMatchedCount >= int(*query.MaxResults) {
+				break
+			}
+			eventsMatchedCount++
+
 			select {
 			case <-r.ctx.Done():
 				break
 			default:
-				service.WriteToChan(source, NewEvent(r.client, event, cloudTrailEvent))
+				service.WriteToChan(source, e)
 			}
 		}
 	}
@@ -109,7 +123,12 @@ func (r *CloudTrailRepository) ListEventsByLookupAsync(lookup *LookupMiddleware)
 		return nil, errChan
 	}
 
-	return r.ListEventsByInputAsync(lookup.Get())
+	source := make(chan Event)
+	errChan := make(chan *errors.Error)
+
+	go r.fetchEventsByInput(lookup.Get(), lookup.matchClientSide, source, errChan)
+
+	return source, errChan
 }
 
 func (r *CloudTrailRepository) ListEventsByLookup(lookup *LookupMiddleware) ([]Event, error) {
@@ -117,8 +136,11 @@ func (r *CloudTrailRepository) ListEventsByLookup(lookup *LookupMiddleware) ([]E
 		return []Event{}, errors.New(slice.Head(errs).OrEmpty())
 	}
 
-	// get cloudtrail events by lookup query
-	events, err := r.ListEventsByInput(lookup.Get())
+	// get cloudtrail events by lookup query, the attributes and predicates
+	// LookupEvents cannot take checked on the events returned
+	source, errChan := r.ListEventsByLookupAsync(lookup)
+
+	events, err := service.ReadChannels(r.ctx, source, errChan)
 	if err != nil {
 		return events, errors.New(err)
 	}
@@ -7,8 +7,21 @@ import "encoding/gob"
 // init registers this package's types with encoding/gob so they can be
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
+	gob.Register(Addendum{})
 	gob.Register(CloudTrailEvent{})
+	gob.Register(DirLogStore{})
 	gob.Register(Event{})
+	gob.Register(EventPredicate{})
 	gob.Register(LookupMiddleware{})
+	gob.Register(OnBehalfOf{})
+	gob.Register(Principal{})
+	gob.Register(RecordResource{})
+	gob.Register(S3LogStore{})
+	gob.Register(SessionAttributes{})
+	gob.Register(SessionContext{})
+	gob.Register(SessionIssuer{})
+	gob.Register(TlsDetails{})
+	gob.Register(TrailLogSource{})
 	gob.Register(UserIdentity{})
+	gob.Register(WebIdFederationData{})
 }
@@ -0,0 +1,503 @@
+package cloudtrail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awscloudtrail "github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	cloudtrailopts "github.com/imunhatep/awslib/provider/v3/clients/cloudtrail"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// lakeTimeLayout is how CloudTrail Lake formats eventTime, in UTC.
+const lakeTimeLayout = "2006-01-02 15:04:05.000"
+
+// lakeColumns are the columns LakeRepository selects; rows of other queries
+// are converted from the columns they have of the same names.
+var lakeColumns = []string{
+	"eventID",
+	"eventTime",
+	"eventName",
+	"eventSource",
+	"awsRegion",
+	"recipientAccountId",
+	"sourceIPAddress",
+	"userAgent",
+	"errorCode",
+	"errorMessage",
+	"readOnly",
+	"userIdentity.type AS identityType",
+	"userIdentity.principalid AS principalId",
+	"userIdentity.arn AS identityArn",
+	"userIdentity.accountid AS identityAccountId",
+	"userIdentity.username AS userName",
+	"userIdentity.accesskeyid AS accessKeyId",
+	"userIdentity.invokedby AS invokedBy",
+	"userIdentity.sessioncontext.sessionissuer.type AS sessionIssuerType",
+	"userIdentity.sessioncontext.sessionissuer.arn AS sessionIssuerArn",
+	"userIdentity.sessioncontext.sessionissuer.accountid AS sessionIssuerAccountId",
+	"userIdentity.sessioncontext.sessionissuer.username AS sessionIssuerName",
+	"userIdentity.sessioncontext.sourceidentity AS sourceIdentity",
+	"json_format(CAST(requestParameters AS JSON)) AS requestParameters",
+	"json_format(CAST(responseElements AS JSON)) AS responseElements",
+	"tlsDetails.tlsversion AS tlsVersion",
+	"tlsDetails.ciphersuite AS cipherSuite",
+	"tlsDetails.clientprovidedhostheader AS clientProvidedHostHeader",
+	"json_format(CAST(transform(resources, r -> r.arn) AS JSON)) AS resourceArns",
+	"json_format(CAST(transform(resources, r -> r.type) AS JSON)) AS resourceTypes",
+}
+
+// LakeRepository queries a CloudTrail Lake event data store with SQL. Unlike
+// LookupEvents it covers data events, the retention of the data store and any
+// number of lookup attributes at once. A query is billed by the data it scans:
+// always bound lookups by time.
+type LakeRepository struct {
+	ctx            context.Context
+	client         *v3.Client
+	eventDataStore string
+	pollInterval   time.Duration
+
+	accountID ptypes.AwsAccountID
+	region    ptypes.AwsRegion
+}
+
+// NewLakeRepository queries the event data store of the given ID or ARN, with
+// a client of the account owning it.
+func NewLakeRepository(ctx context.Context, client *v3.Client, eventDataStore string) *LakeRepository {
+	repo := &LakeRepository{
+		ctx:            ctx,
+		client:         client,
+		eventDataStore: eventDataStore[strings.LastIndex(eventDataStore, "/")+1:],
+		pollInterval:   time.Second,
+	}
+
+	return repo
+}
+
+// WithScope restricts lookups to the events of one account and region, as
+// LookupEvents is, for event data stores collecting a whole organization or
+// every region. Empty values do not restrict. The cache of WithCache is keyed
+// by the client, not the scope: to cache scoped lookups, cache
+// ListEventsByQuery(r.Query(lookup)) instead.
+func (r *LakeRepository) WithScope(accountID ptypes.AwsAccountID, region ptypes.AwsRegion) *LakeRepository {
+	scoped := *r
+	scoped.accountID = accountID
+	scoped.region = region
+
+	return &scoped
+}
+
+// WithPollInterval returns a copy of the repository polling a running query
+// for its results every interval.
+func (r *LakeRepository) WithPollInterval(interval time.Duration) *LakeRepository {
+	polled := *r
+	polled.pollInterval = interval
+
+	return &polled
+}
+
+func (r *LakeRepository) cloudtrailClient() *awscloudtrail.Client {
+	return cloudtrailopts.GetClient(r.client)
+}
+
+func (r *LakeRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// Query returns the SQL statement a lookup translates to, every lookup
+// attribute included. Resource lookups look at every resource of an event, a
+// resource name matching anywhere in the resource ARN: events without
+// resources are not found by resource. Predicates are checked on the rows
+// returned, so lookups with predicates are not limited in SQL.
+func (r *LakeRepository) Query(lookup *LookupMiddleware) string {
+	query := lookup.Get()
+
+	var where []string
+	if query.StartTime != nil {
+		where = append(where, fmt.Sprintf("eventTime >= %s", lakeString(query.StartTime.UTC().Format(lakeTimeLayout))))
+	}
+
+	if query.EndTime != nil {
+		where = append(where, fmt.Sprintf("eventTime <= %s", lakeString(query.EndTime.UTC().Format(lakeTimeLayout))))
+	}
+
+	if r.accountID != "" {
+		where = append(where, fmt.Sprintf("recipientAccountId = %s", lakeString(r.accountID.String())))
+	}
+
+	if r.region != "" {
+		where = append(where, fmt.Sprintf("awsRegion = %s", lakeString(r.region.String())))
+	}
+
+	for _, attr := range lookup.Attributes() {
+		value := aws.ToString(attr.AttributeValue)
+
+		switch attr.AttributeKey {
+		case types.LookupAttributeKeyEventId:
+			where = append(where, "eventID = "+lakeString(value))
+		case types.LookupAttributeKeyEventName:
+			where = append(where, "eventName = "+lakeString(value))
+		case types.LookupAttributeKeyEventSource:
+			where = append(where, "eventSource = "+lakeString(value))
+		case types.LookupAttributeKeyAccessKeyId:
+			where = append(where, "userIdentity.accesskeyid = "+lakeString(value))
+		case types.LookupAttributeKeyReadOnly:
+			readOnly, _ := strconv.ParseBool(value)
+			where = append(where, "readOnly = "+strconv.FormatBool(readOnly))
+		case types.LookupAttributeKeyUsername:
+			where = append(where, fmt.Sprintf("(userIdentity.username = %s OR userIdentity.arn LIKE %s)",
+				lakeString(value), lakeLike("%/", value, "")))
+		case types.LookupAttributeKeyResourceType:
+			where = append(where, "any_match(resources, r -> r.type = "+lakeString(value)+")")
+		case types.LookupAttributeKeyResourceName:
+			where = append(where, "any_match(resources, r -> r.arn LIKE "+lakeLike("%", value, "%")+")")
+		}
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(lakeColumns, ", "), r.eventDataStore)
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	sql += " ORDER BY eventTime DESC"
+	if query.MaxResults != nil && len(lookup.Predicates()) == 0 {
+		sql += fmt.Sprintf(" LIMIT %d", aws.ToInt32(query.MaxResults))
+	}
+
+	return sql
+}
+
+func (r *LakeRepository) ListEventsByLookupAsync(lookup *LookupMiddleware) (<-chan Event, <-chan *errors.Error) {
+	return emitEvents(r.ctx, lookup, func(emit func(Event) bool) error {
+		return r.fetchEventsByQuery(r.Query(lookup), func(e Event) bool {
+			return !lookup.matchPredicates(e) || emit(e)
+		})
+	})
+}
+
+func (r *LakeRepository) ListEventsByLookup(lookup *LookupMiddleware) ([]Event, error) {
+	source, errChan := r.ListEventsByLookupAsync(lookup)
+
+	return service.ReadChannels(r.ctx, source, errChan)
+}
+
+// ListEventsByQuery runs a SQL statement and returns its rows as events. Rows
+// are read by the column names LakeRepository.Query selects; alias columns to
+// them, e.g. "userIdentity.arn AS identityArn".
+func (r *LakeRepository) ListEventsByQuery(sql string) ([]Event, error) {
+	source, errChan := r.ListEventsByQueryAsync(sql)
+
+	return service.ReadChannels(r.ctx, source, errChan)
+}
+
+func (r *LakeRepository) ListEventsByQueryAsync(sql string) (<-chan Event, <-chan *errors.Error) {
+	return emitEvents(r.ctx, NewLookupMiddleware(), func(emit func(Event) bool) error {
+		return r.fetchEventsByQuery(sql, emit)
+	})
+}
+
+func (r *LakeRepository) fetchEventsByQuery(sql string, emit func(Event) bool) error {
+	start := time.Now()
+
+	log.Debug().Str("query", sql).Msg("[LakeRepository.fetchEventsByQuery] starting query")
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("StartQuery", ccfg.ResourceTypeTrailEvent)).Inc()
+	}
+
+	started, err := r.cloudtrailClient().StartQuery(r.ctx, &awscloudtrail.StartQueryInput{QueryStatement: aws.String(sql)})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("StartQuery", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		return errors.New(err)
+	}
+
+	// a query left running keeps scanning, and billing, until it completes:
+	// cancel it when the lookup is abandoned before then
+	done := false
+	defer func() {
+		if !done {
+			r.cancelQuery(started.QueryId)
+		}
+	}()
+
+	input := &awscloudtrail.GetQueryResultsInput{QueryId: started.QueryId}
+
+	eventsFetchedCount := 0
+	for {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetQueryResults", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		resp, err := r.cloudtrailClient().GetQueryResults(r.ctx, input)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetQueryResults", ccfg.ResourceTypeTrailEvent)).Inc()
+			}
+
+			return errors.New(err)
+		}
+
+		switch resp.QueryStatus {
+		case types.QueryStatusQueued, types.QueryStatusRunning:
+			select {
+			case <-r.ctx.Done():
+				return errors.New(r.ctx.Err())
+			case <-time.After(r.pollInterval):
+			}
+			continue
+		case types.QueryStatusFinished:
+			done = true
+		default:
+			done = true
+			return errors.Errorf("cloudtrail lake query %s %s: %s",
+				aws.ToString(started.QueryId), resp.QueryStatus, aws.ToString(resp.ErrorMessage))
+		}
+
+		for _, row := range resp.QueryResultRows {
+			event, err := newLakeEvent(row)
+			if err != nil {
+				return err
+			}
+
+			eventsFetchedCount++
+			if !emit(event) {
+				resp.NextToken = nil
+				break
+			}
+		}
+
+		if resp.NextToken == nil {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetQueryResults", ccfg.ResourceTypeTrailEvent)).
+			Add(float64(eventsFetchedCount))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListEventsByQuery", ccfg.ResourceTypeTrailEvent)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return nil
+}
+
+// cancelQuery stops a query still queued or running. It runs on a context of
+// its own, as the lookup's may be the reason the query is abandoned.
+func (r *LakeRepository) cancelQuery(queryID *string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.ctx), 10*time.Second)
+	defer cancel()
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("CancelQuery", ccfg.ResourceTypeTrailEvent)).Inc()
+	}
+
+	_, err := r.cloudtrailClient().CancelQuery(ctx, &awscloudtrail.CancelQueryInput{QueryId: queryID})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("CancelQuery", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		log.Warn().Err(err).
+			Str("queryID", aws.ToString(queryID)).
+			Msg("[LakeRepository.cancelQuery] failed to cancel abandoned query")
+	}
+}
+
+// newLakeEvent converts a result row, a list of single column maps, to an
+// Event.
+func newLakeEvent(row []map[string]string) (Event, error) {
+	columns := map[string]string{}
+	for _, column := range row {
+		for name, value := range column {
+			columns[name] = value
+		}
+	}
+
+	eventTime, err := parseLakeTime(columns["eventTime"])
+	if err != nil {
+		return Event{}, err
+	}
+
+	record := CloudTrailEvent{
+		EventID:            columns["eventID"],
+		EventTime:          eventTime,
+		EventName:          columns["eventName"],
+		EventSource:        columns["eventSource"],
+		AwsRegion:          columns["awsRegion"],
+		RecipientAccountID: columns["recipientAccountId"],
+		SourceIPAddress:    columns["sourceIPAddress"],
+		UserAgent:          columns["userAgent"],
+		ErrorCode:          columns["errorCode"],
+		ErrorMessage:       columns["errorMessage"],
+		UserIdentity: UserIdentity{
+			Type:        columns["identityType"],
+			PrincipalID: columns["principalId"],
+			Arn:         columns["identityArn"],
+			AccountID:   columns["identityAccountId"],
+			UserName:    columns["userName"],
+			AccessKeyID: columns["accessKeyId"],
+			InvokedBy:   columns["invokedBy"],
+		},
+	}
+
+	if arn := columns["sessionIssuerArn"]; arn != "" {
+		record.UserIdentity.SessionContext = &SessionContext{
+			SessionIssuer: &SessionIssuer{
+				Type:      columns["sessionIssuerType"],
+				Arn:       arn,
+				AccountID: columns["sessionIssuerAccountId"],
+				UserName:  columns["sessionIssuerName"],
+			},
+		}
+	}
+
+	if sourceIdentity := columns["sourceIdentity"]; sourceIdentity != "" {
+		if record.UserIdentity.SessionContext == nil {
+			record.UserIdentity.SessionContext = &SessionContext{}
+		}
+		record.UserIdentity.SessionContext.SourceIdentity = sourceIdentity
+	}
+
+	if version := columns["tlsVersion"]; version != "" {
+		record.TlsDetails = &TlsDetails{
+			TlsVersion:               version,
+			CipherSuite:              columns["cipherSuite"],
+			ClientProvidedHostHeader: columns["clientProvidedHostHeader"],
+		}
+	}
+
+	if record.RequestParameters, err = lakeJSON(columns["requestParameters"]); err != nil {
+		return Event{}, err
+	}
+	if record.ResponseElements, err = lakeJSON(columns["responseElements"]); err != nil {
+		return Event{}, err
+	}
+
+	if value, ok := columns["readOnly"]; ok {
+		if readOnly, err := strconv.ParseBool(value); err == nil {
+			record.ReadOnly = &readOnly
+		}
+	}
+
+	record.Resources, err = lakeResources(columns)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return newRecordEvent(record, nil)
+}
+
+// lakeResources reads the resources of a row: the parallel JSON arrays
+// resourceArns and resourceTypes, or a single resourceArn and resourceType.
+func lakeResources(columns map[string]string) ([]RecordResource, error) {
+	if arns := columns["resourceArns"]; arns != "" {
+		var arnList, typeList []string
+		if err := json.Unmarshal([]byte(arns), &arnList); err != nil {
+			return nil, errors.Errorf("cloudtrail lake: unexpected resourceArns %q: %w", arns, err)
+		}
+		if kinds := columns["resourceTypes"]; kinds != "" {
+			if err := json.Unmarshal([]byte(kinds), &typeList); err != nil {
+				return nil, errors.Errorf("cloudtrail lake: unexpected resourceTypes %q: %w", kinds, err)
+			}
+		}
+
+		resources := make([]RecordResource, 0, len(arnList))
+		for i, arn := range arnList {
+			resource := RecordResource{ARN: arn}
+			if i < len(typeList) {
+				resource.Type = typeList[i]
+			}
+			resources = append(resources, resource)
+		}
+
+		return resources, nil
+	}
+
+	if arn := columns["resourceArn"]; arn != "" {
+		return []RecordResource{{ARN: arn, Type: columns["resourceType"]}}, nil
+	}
+
+	return nil, nil
+}
+
+// lakeJSON reads a JSON column Lake stores as a map of strings, where nested
+// objects and arrays are flattened to JSON strings, and nests them back.
+func lakeJSON(value string) (json.RawMessage, error) {
+	if value == "" || value == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, errors.Errorf("cloudtrail lake: unexpected JSON column %q: %w", value, err)
+	}
+
+	for key, field := range fields {
+		var nested string
+		if json.Unmarshal(field, &nested) != nil {
+			continue
+		}
+
+		nested = strings.TrimSpace(nested)
+		if (strings.HasPrefix(nested, "{") || strings.HasPrefix(nested, "[")) && json.Valid([]byte(nested)) {
+			fields[key] = json.RawMessage(nested)
+		}
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return raw, nil
+}
+
+func parseLakeTime(value string) (time.Time, error) {
+	for _, layout := range []string{lakeTimeLayout, time.DateTime, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.Errorf("cloudtrail lake: unexpected eventTime %q", value)
+}
+
+// lakeString quotes a SQL string literal.
+func lakeString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// lakeLikeEscaper escapes the LIKE wildcards, and the escape character itself.
+var lakeLikeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// lakeLike builds a LIKE pattern matching value literally between the prefix
+// and suffix wildcards, with its ESCAPE clause.
+func lakeLike(prefix, value, suffix string) string {
+	return lakeString(prefix+lakeLikeEscaper.Replace(value)+suffix) + ` ESCAPE '\'`
+}
@@ -0,0 +1,478 @@
+package cloudtrail
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	s3opts "github.com/imunhatep/awslib/provider/v3/clients/s3"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// logDatePath matches the delivery date of a trail log file key, e.g.
+// "AWSLogs/123456789012/CloudTrail/eu-central-1/2024/05/17/...json.gz".
+var logDatePath = regexp.MustCompile(`/(\d{4}/\d{2}/\d{2})/`)
+
+// ErrUnboundedLookup fails lookups of an unscoped TrailLogSource without a
+// start time, which would read every file of every account and region.
+var ErrUnboundedLookup = stderrors.New("cloudtrail logs: unscoped lookups must set a start time")
+
+// LogStore lists and opens trail log files by slash separated keys.
+type LogStore interface {
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Dirs returns the names of the directories right under prefix, which
+	// ends with a slash.
+	Dirs(ctx context.Context, prefix string) ([]string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// DirLogStore reads trail log files from a local directory, e.g. a copy of
+// the trail bucket made with "aws s3 sync".
+type DirLogStore struct {
+	dir string
+}
+
+func NewDirLogStore(dir string) *DirLogStore {
+	return &DirLogStore{dir: dir}
+}
+
+// List returns the keys of the files under prefix; a missing prefix is empty.
+func (s *DirLogStore) List(_ context.Context, prefix string) ([]string, error) {
+	root := filepath.Join(s.dir, filepath.FromSlash(prefix))
+
+	var keys []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		key, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, filepath.ToSlash(key))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return keys, nil
+}
+
+func (s *DirLogStore) Dirs(_ context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(prefix)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.New(err)
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+
+	return dirs, nil
+}
+
+func (s *DirLogStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return f, nil
+}
+
+// S3LogStore reads trail log files from the bucket a trail delivers to.
+type S3LogStore struct {
+	client *v3.Client
+	bucket string
+}
+
+func NewS3LogStore(client *v3.Client, bucket string) *S3LogStore {
+	return &S3LogStore{client: client, bucket: bucket}
+}
+
+func (s *S3LogStore) s3Client() *s3.Client {
+	return s3opts.GetClient(s.client)
+}
+
+func (s *S3LogStore) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    s.client.GetAccountID().String(),
+		"region":        s.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+func (s *S3LogStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+
+	p := s3.NewListObjectsV2Paginator(s.s3Client(), &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(s.promLabels("ListObjectsV2", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(s.promLabels("ListObjectsV2", ccfg.ResourceTypeTrailEvent)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		for _, object := range resp.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+
+	return keys, nil
+}
+
+func (s *S3LogStore) Dirs(ctx context.Context, prefix string) ([]string, error) {
+	var dirs []string
+
+	p := s3.NewListObjectsV2Paginator(s.s3Client(), &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(s.promLabels("ListObjectsV2", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(s.promLabels("ListObjectsV2", ccfg.ResourceTypeTrailEvent)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		for _, common := range resp.CommonPrefixes {
+			dirs = append(dirs, path.Base(aws.ToString(common.Prefix)))
+		}
+	}
+
+	return dirs, nil
+}
+
+func (s *S3LogStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(s.promLabels("GetObject", ccfg.ResourceTypeTrailEvent)).Inc()
+	}
+
+	resp, err := s.s3Client().GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(s.promLabels("GetObject", ccfg.ResourceTypeTrailEvent)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	return resp.Body, nil
+}
+
+// TrailLogSource answers lookups from the gzipped JSON log files a trail
+// delivers, read from a LogStore. It covers data events and the whole
+// retention of the bucket, and applies every lookup attribute, but reads
+// every file of the lookup's days: bound lookups by time. Unscoped lookups
+// must set a start time. Events are returned in file order, not newest first.
+type TrailLogSource struct {
+	ctx    context.Context
+	store  LogStore
+	prefix string
+
+	accountID ptypes.AwsAccountID
+	region    ptypes.AwsRegion
+}
+
+// NewTrailLogSource reads the log files under prefix, the trail's S3 key
+// prefix followed by "AWSLogs/" and, for organization trails, the
+// organization ID, e.g. "trails/AWSLogs/o-a1b2c3d4e5/".
+func NewTrailLogSource(ctx context.Context, store LogStore, prefix string) *TrailLogSource {
+	return &TrailLogSource{ctx: ctx, store: store, prefix: prefix}
+}
+
+// WithScope reads the files of one account and region only, as LookupEvents
+// is scoped. Without a scope the accounts and regions under the prefix are
+// listed first, and the days of the lookup read in each.
+func (s *TrailLogSource) WithScope(accountID ptypes.AwsAccountID, region ptypes.AwsRegion) *TrailLogSource {
+	scoped := *s
+	scoped.accountID = accountID
+	scoped.region = region
+
+	return &scoped
+}
+
+func (s *TrailLogSource) ListEventsByLookup(lookup *LookupMiddleware) ([]Event, error) {
+	source, errChan := s.ListEventsByLookupAsync(lookup)
+
+	return service.ReadChannels(s.ctx, source, errChan)
+}
+
+func (s *TrailLogSource) ListEventsByLookupAsync(lookup *LookupMiddleware) (<-chan Event, <-chan *errors.Error) {
+	return emitEvents(s.ctx, lookup, func(emit func(Event) bool) error {
+		keys, err := s.listKeys(lookup)
+		if err != nil {
+			return err
+		}
+
+		log.Debug().
+			Str("prefix", s.prefix).
+			Int("files", len(keys)).
+			Msg("[TrailLogSource.ListEventsByLookupAsync] reading trail log files")
+
+		for _, key := range keys {
+			more, err := s.readFile(key, lookup, emit)
+			if err != nil {
+				return err
+			}
+			if !more {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+// listKeys lists the log files delivered on the days of the lookup, in the
+// scoped account and region or else in each one under the prefix. A file is
+// named by its delivery time, up to about 15 minutes after its events: the
+// day after the lookup ends is read too.
+func (s *TrailLogSource) listKeys(lookup *LookupMiddleware) ([]string, error) {
+	query := lookup.Get()
+
+	scoped := s.accountID != "" && s.region != ""
+	if !scoped && query.StartTime == nil {
+		return nil, ErrUnboundedLookup
+	}
+
+	var first, last string
+	if query.StartTime != nil {
+		first = query.StartTime.UTC().Format("2006/01/02")
+	}
+	if query.EndTime != nil {
+		last = query.EndTime.UTC().Add(24 * time.Hour).Format("2006/01/02")
+	}
+
+	scopes, err := s.scopePrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, scope := range scopes {
+		for _, prefix := range datePrefixes(scope, query.StartTime, query.EndTime) {
+			listed, err := s.store.List(s.ctx, prefix)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, key := range listed {
+				if !isTrailLogFile(key) {
+					continue
+				}
+
+				if day := logDatePath.FindStringSubmatch(key); day != nil {
+					if (first != "" && day[1] < first) || (last != "" && day[1] > last) {
+						continue
+					}
+				}
+
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys, nil
+}
+
+// scopePrefixes returns the prefix of the log files of each account and
+// region to read: the scoped one, or every one found under the prefix.
+func (s *TrailLogSource) scopePrefixes() ([]string, error) {
+	accounts := []string{s.accountID.String()}
+	if s.accountID == "" {
+		dirs, err := s.store.Dirs(s.ctx, s.prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = dirs
+	}
+
+	var prefixes []string
+	for _, account := range accounts {
+		base := path.Join(s.prefix, account, "CloudTrail") + "/"
+
+		regions := []string{s.region.String()}
+		if s.region == "" {
+			dirs, err := s.store.Dirs(s.ctx, base)
+			if err != nil {
+				return nil, err
+			}
+
+			regions = dirs
+		}
+
+		for _, region := range regions {
+			prefixes = append(prefixes, base+region+"/")
+		}
+	}
+
+	return prefixes, nil
+}
+
+// datePrefixes narrows the prefix of an account and region to the days of a
+// lookup, or to its months when it spans more than 31 days. Without a start
+// time the whole prefix is read; without an end time the lookup runs to now.
+func datePrefixes(prefix string, start, end *time.Time) []string {
+	if start == nil {
+		return []string{prefix}
+	}
+
+	until := time.Now().UTC()
+	if end != nil {
+		until = end.UTC()
+	}
+	until = until.Add(24 * time.Hour).Truncate(24 * time.Hour)
+
+	from := start.UTC().Truncate(24 * time.Hour)
+	if until.Sub(from) <= 32*24*time.Hour {
+		var prefixes []string
+		for day := from; !day.After(until); day = day.AddDate(0, 0, 1) {
+			prefixes = append(prefixes, prefix+day.Format("2006/01/02")+"/")
+		}
+
+		return prefixes
+	}
+
+	var prefixes []string
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(until); month = month.AddDate(0, 1, 0) {
+		prefixes = append(prefixes, prefix+month.Format("2006/01")+"/")
+	}
+
+	return prefixes
+}
+
+// readFile emits the events of a log file matching the lookup, returning
+// false once emit does.
+func (s *TrailLogSource) readFile(key string, lookup *LookupMiddleware, emit func(Event) bool) (bool, error) {
+	body, err := s.store.Open(s.ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	events, err := ReadTrailLog(body, strings.HasSuffix(key, ".gz"))
+	if err != nil {
+		return false, errors.Errorf("%s: %w", key, err)
+	}
+
+	for _, event := range events {
+		if !lookup.Match(event) {
+			continue
+		}
+
+		if !emit(event) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// ReadTrailLog decodes a trail log file, a {"Records": [...]} document, into
+// events.
+func ReadTrailLog(r io.Reader, gzipped bool) ([]Event, error) {
+	if gzipped {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+		defer zr.Close()
+
+		r = zr
+	}
+
+	var file struct {
+		Records []json.RawMessage `json:"Records"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errors.New(err)
+	}
+
+	events := make([]Event, 0, len(file.Records))
+	for _, raw := range file.Records {
+		var record CloudTrailEvent
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return nil, errors.New(err)
+		}
+
+		event, err := newRecordEvent(record, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// isTrailLogFile tells log files from digest files and anything else stored
+// along them.
+func isTrailLogFile(key string) bool {
+	if strings.Contains(key, "CloudTrail-Digest") || strings.Contains(key, "CloudTrail-Insight") {
+		return false
+	}
+
+	return strings.HasSuffix(key, ".json.gz") || strings.HasSuffix(key, ".json")
+}
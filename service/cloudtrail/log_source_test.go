@@ -0,0 +1,92 @@
+package cloudtrail
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTrailLog(t *testing.T, dir, key, content string) {
+	t.Helper()
+
+	p := filepath.Join(dir, filepath.FromSlash(key))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+
+	f, err := os.Create(p)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	_, err = zw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+}
+
+func TestTrailLogSource(t *testing.T) {
+	dir := t.TempDir()
+	base := "AWSLogs/o-abc/123456789012/CloudTrail/eu-central-1/"
+
+	writeTrailLog(t, dir, base+"2026/03/02/123456789012_CloudTrail_eu-central-1_20260302T1020Z_a.json.gz", trailLog)
+	// a day outside the lookup, another region and a digest file are not read
+	writeTrailLog(t, dir, base+"2026/02/20/123456789012_CloudTrail_eu-central-1_20260220T1020Z_b.json.gz", `{"Records": [{"eventID": "old", "eventTime": "2026-02-20T10:00:00Z"}]}`)
+	writeTrailLog(t, dir, "AWSLogs/o-abc/123456789012/CloudTrail/us-east-1/2026/03/02/c.json.gz", `{"Records": [{"eventID": "other", "eventTime": "2026-03-02T10:00:00Z"}]}`)
+	writeTrailLog(t, dir, "AWSLogs/o-abc/123456789012/CloudTrail-Digest/eu-central-1/2026/03/02/d.json.gz", `{}`)
+
+	lookup := NewLookupMiddleware().
+		WithStartTime(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)).
+		WithEndTime(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+
+	source := NewTrailLogSource(context.Background(), NewDirLogStore(dir), "AWSLogs/o-abc/")
+
+	scoped := source.WithScope("123456789012", "eu-central-1")
+	events, err := scoped.ListEventsByLookup(lookup)
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = scoped.ListEventsByLookup(NewLookupMiddleware().
+		WithStartTime(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)).
+		WithEndTime(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)).
+		WithResourceId("i-0abc"))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "e-run", events[0].GetId())
+
+	limited, err := scoped.ListEventsByLookup(NewLookupMiddleware().WithLimit(2))
+	require.NoError(t, err)
+	assert.Len(t, limited, 2)
+
+	// unscoped, the days of every account and region under the prefix are read
+	_, err = source.ListEventsByLookup(NewLookupMiddleware())
+	assert.ErrorIs(t, err, ErrUnboundedLookup)
+
+	since := NewLookupMiddleware().
+		WithStartTime(time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)).
+		WithEndTime(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC))
+
+	all, err := source.ListEventsByLookup(since)
+	require.NoError(t, err)
+	assert.Len(t, all, 5)
+
+	// several attributes at once
+	both, err := source.ListEventsByLookup(since.WithEventName("GetObject").WithUsername("reporter"))
+	require.NoError(t, err)
+	require.Len(t, both, 1)
+	assert.Equal(t, "e-get", both[0].GetId())
+}
+
+func TestDatePrefixes(t *testing.T) {
+	start := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"p/"}, datePrefixes("p/", nil, &end))
+	assert.Equal(t, []string{"p/2026/03/02/", "p/2026/03/03/", "p/2026/03/04/"}, datePrefixes("p/", &start, &end))
+
+	later := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"p/2026/03/", "p/2026/04/", "p/2026/05/"}, datePrefixes("p/", &start, &later))
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"fmt"
+	"net/netip"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,11 +20,35 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// attributeSelectivity ranks lookup attributes from the one matching the
+// fewest events to the one matching the most. LookupEvents accepts a single
+// attribute: the most selective is sent, the others are checked client side.
+var attributeSelectivity = []types.LookupAttributeKey{
+	types.LookupAttributeKeyEventId,
+	types.LookupAttributeKeyAccessKeyId,
+	types.LookupAttributeKeyResourceName,
+	types.LookupAttributeKeyUsername,
+	types.LookupAttributeKeyEventName,
+	types.LookupAttributeKeyResourceType,
+	types.LookupAttributeKeyEventSource,
+	types.LookupAttributeKeyReadOnly,
+}
+
 type LookupHandler func(*cloudtrail.LookupEventsInput) (*cloudtrail.LookupEventsInput, error)
 
+// EventPredicate is a condition on events no lookup attribute expresses,
+// checked client side. Name identifies it in LookupMiddleware.Hash: predicates
+// of the same name must select the same events.
+type EventPredicate struct {
+	Name  string
+	Match func(Event) bool
+}
+
 type LookupMiddleware struct {
-	lookup *cloudtrail.LookupEventsInput
-	errs   []error
+	// lookup holds every attribute; Get narrows it to one
+	lookup     *cloudtrail.LookupEventsInput
+	predicates []EventPredicate
+	errs       []error
 }
 
 func NewLookupMiddleware() *LookupMiddleware {
@@ -32,8 +58,46 @@ func NewLookupMiddleware() *LookupMiddleware {
 	}
 }
 
+// Get returns the LookupEvents query: the time range, the limit and the most
+// selective lookup attribute.
 func (l *LookupMiddleware) Get() *cloudtrail.LookupEventsInput {
-	return l.lookup
+	query := *l.lookup
+	query.LookupAttributes = nil
+
+	if i := l.serverAttribute(); i >= 0 {
+		query.LookupAttributes = []types.LookupAttribute{l.lookup.LookupAttributes[i]}
+	}
+
+	return &query
+}
+
+// Attributes returns every lookup attribute, the one Get sends included.
+func (l *LookupMiddleware) Attributes() []types.LookupAttribute {
+	return l.lookup.LookupAttributes
+}
+
+// Predicates returns the client side conditions added with WithPredicate and
+// the predicate helpers.
+func (l *LookupMiddleware) Predicates() []EventPredicate {
+	return l.predicates
+}
+
+// serverAttribute returns the position of the attribute Get sends, -1 when
+// there is none.
+func (l *LookupMiddleware) serverAttribute() int {
+	best, bestRank := -1, len(attributeSelectivity)
+	for i, attr := range l.lookup.LookupAttributes {
+		rank := slices.Index(attributeSelectivity, attr.AttributeKey)
+		if rank < 0 {
+			rank = len(attributeSelectivity)
+		}
+
+		if best < 0 || rank < bestRank {
+			best, bestRank = i, rank
+		}
+	}
+
+	return best
 }
 
 func (l *LookupMiddleware) Hash() string {
@@ -42,6 +106,10 @@ func (l *LookupMiddleware) Hash() string {
 		optStr = append(optStr, fmt.Sprintf("%s:%s", string(q.AttributeKey), aws.ToString(q.AttributeValue)))
 	}
 
+	for _, p := range l.predicates {
+		optStr = append(optStr, fmt.Sprintf("Predicate:%s", p.Name))
+	}
+
 	optStr = append(optStr, fmt.Sprintf("EventCategory:%s", l.lookup.EventCategory))
 	optStr = append(optStr, fmt.Sprintf("StartTime:%s", l.lookup.StartTime))
 	optStr = append(optStr, fmt.Sprintf("EndTime:%s", l.lookup.EndTime))
@@ -59,6 +127,83 @@ func (l *LookupMiddleware) Errors() ([]error, bool) {
 	return l.errs, len(l.errs) == 0
 }
 
+// Match tells whether an event satisfies the time range, every lookup
+// attribute and every predicate of the lookup; sources that cannot filter
+// server side apply it to every event they read. A resource name matches the
+// name of a resource of the event or, as LookupEvents also looks into the
+// request and response, any part of the event document.
+func (l *LookupMiddleware) Match(e Event) bool {
+	if l.lookup.StartTime != nil && e.GetTime().Before(*l.lookup.StartTime) {
+		return false
+	}
+
+	if l.lookup.EndTime != nil && e.GetTime().After(*l.lookup.EndTime) {
+		return false
+	}
+
+	return matchAttributes(e, l.lookup.LookupAttributes) && l.matchPredicates(e)
+}
+
+// matchClientSide checks what Get leaves out of the LookupEvents query.
+func (l *LookupMiddleware) matchClientSide(e Event) bool {
+	server := l.serverAttribute()
+	for i, attr := range l.lookup.LookupAttributes {
+		if i != server && !matchAttributes(e, []types.LookupAttribute{attr}) {
+			return false
+		}
+	}
+
+	return l.matchPredicates(e)
+}
+
+func (l *LookupMiddleware) matchPredicates(e Event) bool {
+	for _, p := range l.predicates {
+		if !p.Match(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchAttributes(e Event, attrs []types.LookupAttribute) bool {
+	for _, attr := range attrs {
+		value := aws.ToString(attr.AttributeValue)
+
+		var ok bool
+		switch attr.AttributeKey {
+		case types.LookupAttributeKeyEventId:
+			ok = e.GetId() == value
+		case types.LookupAttributeKeyEventName:
+			ok = e.GetName() == value
+		case types.LookupAttributeKeyEventSource:
+			ok = e.GetSource() == value
+		case types.LookupAttributeKeyReadOnly:
+			ok = e.GetReadOnly() == value
+		case types.LookupAttributeKeyUsername:
+			ok = e.GetUsername() == value
+		case types.LookupAttributeKeyAccessKeyId:
+			ok = aws.ToString(e.Event.AccessKeyId) == value
+		case types.LookupAttributeKeyResourceType:
+			ok = slices.ContainsFunc(e.GetResources(), func(r types.Resource) bool {
+				return aws.ToString(r.ResourceType) == value
+			})
+		case types.LookupAttributeKeyResourceName:
+			ok = slices.ContainsFunc(e.GetResources(), func(r types.Resource) bool {
+				return aws.ToString(r.ResourceName) == value
+			}) || strings.Contains(aws.ToString(e.Event.CloudTrailEvent), value)
+		default:
+			ok = true
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (l *LookupMiddleware) WithHandler(f LookupHandler) *LookupMiddleware {
 	var err error
 	if l.lookup, err = f(l.lookup); err != nil {
@@ -68,6 +213,16 @@ func (l *LookupMiddleware) WithHandler(f LookupHandler) *LookupMiddleware {
 	return l
 }
 
+// WithPredicate adds a client side condition; name must identify it, see
+// EventPredicate.
+func (l *LookupMiddleware) WithPredicate(name string, match func(Event) bool) *LookupMiddleware {
+	log.Trace().Str("predicate", name).Msg("lookup: query")
+
+	l.predicates = append(l.predicates, EventPredicate{Name: name, Match: match})
+
+	return l
+}
+
 func (l *LookupMiddleware) WithStartTime(start time.Time) *LookupMiddleware {
 	return l.WithHandler(LookupStartTimeHandler(start))
 }
@@ -84,6 +239,14 @@ func (l *LookupMiddleware) WithEventName(value string) *LookupMiddleware {
 	return l.WithHandler(LookupEventByAttribute(types.LookupAttributeKeyEventName, value))
 }
 
+func (l *LookupMiddleware) WithEventSource(value string) *LookupMiddleware {
+	return l.WithHandler(LookupEventByAttribute(types.LookupAttributeKeyEventSource, value))
+}
+
+func (l *LookupMiddleware) WithAccessKeyId(value string) *LookupMiddleware {
+	return l.WithHandler(LookupEventByAttribute(types.LookupAttributeKeyAccessKeyId, value))
+}
+
 func (l *LookupMiddleware) WithResourceType(value cfg.ResourceType) *LookupMiddleware {
 	return l.WithHandler(LookupEventByAttribute(types.LookupAttributeKeyResourceType, string(value)))
 }
@@ -104,6 +267,57 @@ func (l *LookupMiddleware) WithUsername(value string) *LookupMiddleware {
 	return l.WithHandler(LookupEventByAttribute(types.LookupAttributeKeyUsername, value))
 }
 
+// WithSourceIP keeps the events made from an address; AWS services calling
+// on a user's behalf have their service name as source instead.
+func (l *LookupMiddleware) WithSourceIP(ip string) *LookupMiddleware {
+	return l.WithPredicate("sourceIP:"+ip, func(e Event) bool {
+		return e.GetSourceIPAddress() == ip
+	})
+}
+
+// WithSourceCIDR keeps the events made from an address of the network, e.g.
+// "10.0.0.0/8".
+func (l *LookupMiddleware) WithSourceCIDR(cidr string) *LookupMiddleware {
+	network, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		l.errs = append(l.errs, errors.New(err))
+		return l
+	}
+
+	return l.WithPredicate("sourceCIDR:"+network.String(), func(e Event) bool {
+		addr, err := netip.ParseAddr(e.GetSourceIPAddress())
+		return err == nil && network.Contains(addr)
+	})
+}
+
+// WithUserAgent keeps the events whose user agent contains value, e.g.
+// "terraform" or "console.amazonaws.com".
+func (l *LookupMiddleware) WithUserAgent(value string) *LookupMiddleware {
+	return l.WithPredicate("userAgent:"+value, func(e Event) bool {
+		return strings.Contains(e.EventData.UserAgent, value)
+	})
+}
+
+// WithErrorCode keeps the failed calls of an error code, e.g. "AccessDenied".
+func (l *LookupMiddleware) WithErrorCode(code string) *LookupMiddleware {
+	return l.WithPredicate("errorCode:"+code, func(e Event) bool {
+		return e.EventData.ErrorCode == code
+	})
+}
+
+// WithResourceArn keeps the events naming a resource by its ARN, among their
+// resources or anywhere in the event document.
+func (l *LookupMiddleware) WithResourceArn(arn string) *LookupMiddleware {
+	return l.WithPredicate("resourceArn:"+arn, func(e Event) bool {
+		return slices.ContainsFunc(e.GetResources(), func(r types.Resource) bool {
+			return aws.ToString(r.ResourceName) == arn
+		}) || strings.Contains(aws.ToString(e.Event.CloudTrailEvent), arn)
+	})
+}
+
+// LookupEventByAttribute adds a lookup attribute. LookupEvents accepts one
+// only: LookupMiddleware sends the most selective and checks the others on
+// the events returned.
 func LookupEventByAttribute(key types.LookupAttributeKey, value string) LookupHandler {
 	return func(q *cloudtrail.LookupEventsInput) (*cloudtrail.LookupEventsInput, error) {
 		log.Trace().
@@ -111,21 +325,10 @@ func LookupEventByAttribute(key types.LookupAttributeKey, value string) LookupHa
 			Str("value", value).
 			Msg("[CloudTrail.LookupEventByAttribute]")
 
-		eventName := types.LookupAttribute{
+		q.LookupAttributes = append(q.LookupAttributes, types.LookupAttribute{
 			AttributeKey:   key,
 			AttributeValue: &value,
-		}
-
-		if len(q.LookupAttributes) < 1 {
-			q.LookupAttributes = append(q.LookupAttributes, eventName)
-		} else {
-			log.Warn().
-				Str("key", string(key)).
-				Str("value", value).
-				Msg("[CloudTrail.LookupEventByAttribute] skipped. Only 1 lookup attribute is supported, refer to AWS CloudTrail SDK docs")
-
-			return q, errors.New("only 1 lookup attribute is supported, refer to AWS CloudTrail SDK docs")
-		}
+		})
 
 		return q, nil
 	}
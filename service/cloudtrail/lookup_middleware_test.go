@@ -0,0 +1,107 @@
+package cloudtrail
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func matchingIds(events []Event, match func(Event) bool) []string {
+	var ids []string
+	for _, e := range events {
+		if match(e) {
+			ids = append(ids, e.GetId())
+		}
+	}
+
+	return ids
+}
+
+func TestLookupMiddlewareMatch(t *testing.T) {
+	events := readTrailLog(t)
+	ids := func(lookup *LookupMiddleware) []string {
+		_, ok := lookup.Errors()
+		require.True(t, ok)
+
+		return matchingIds(events, lookup.Match)
+	}
+
+	assert.Equal(t, []string{"e-run", "e-get", "e-login"}, ids(NewLookupMiddleware()))
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithResourceId("i-0abc")))
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithResourceType("AWS::S3::Object")))
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithUsername("jane@example.com")))
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithReadOnly("true")))
+	assert.Equal(t, []string{"e-login"}, ids(NewLookupMiddleware().WithEventName("ConsoleLogin")))
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithEventSource("s3.amazonaws.com")))
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithAccessKeyId("ASIAEXAMPLE")))
+
+	window := NewLookupMiddleware().
+		WithStartTime(time.Date(2026, 3, 2, 10, 16, 0, 0, time.UTC)).
+		WithEndTime(time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, []string{"e-get"}, ids(window))
+
+	// attributes and predicates all apply
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithReadOnly("false").WithEventSource("ec2.amazonaws.com")))
+	assert.Empty(t, ids(NewLookupMiddleware().WithUsername("reporter").WithEventName("RunInstances")))
+
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithSourceIP("10.0.0.2")))
+	assert.Equal(t, []string{"e-run", "e-get"}, ids(NewLookupMiddleware().WithSourceCIDR("10.0.0.0/8")))
+	assert.Equal(t, []string{"e-login"}, ids(NewLookupMiddleware().WithSourceCIDR("192.0.2.0/24")))
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithUserAgent("Terraform/")))
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithErrorCode("AccessDenied")))
+	assert.Equal(t, []string{"e-get"}, ids(NewLookupMiddleware().WithResourceArn("arn:aws:s3:::reports/q1.csv")))
+	assert.Equal(t, []string{"e-run"}, ids(NewLookupMiddleware().WithPredicate("jane", func(e Event) bool {
+		return e.GetUsername() == "jane@example.com"
+	})))
+
+	_, ok := NewLookupMiddleware().WithSourceCIDR("10.0.0.0/33").Errors()
+	assert.False(t, ok)
+}
+
+func TestLookupMiddlewareGet(t *testing.T) {
+	lookup := NewLookupMiddleware().
+		WithLimit(20).
+		WithReadOnly("false").
+		WithEventName("RunInstances").
+		WithUsername("jane@example.com").
+		WithSourceIP("10.0.0.1")
+
+	_, ok := lookup.Errors()
+	require.True(t, ok)
+
+	// the most selective attribute goes to LookupEvents
+	query := lookup.Get()
+	require.Len(t, query.LookupAttributes, 1)
+	assert.Equal(t, types.LookupAttributeKeyUsername, query.LookupAttributes[0].AttributeKey)
+	assert.Equal(t, "jane@example.com", aws.ToString(query.LookupAttributes[0].AttributeValue))
+	assert.Equal(t, int32(20), aws.ToInt32(query.MaxResults))
+	assert.Len(t, lookup.Attributes(), 3)
+
+	// the others are checked on the events LookupEvents returns, the server
+	// attribute is not checked again
+	events := readTrailLog(t)
+	assert.Equal(t, []string{"e-run"}, matchingIds(events, lookup.matchClientSide))
+
+	assert.Empty(t, NewLookupMiddleware().Get().LookupAttributes)
+}
+
+func TestLookupMiddlewareHash(t *testing.T) {
+	base := func() *LookupMiddleware {
+		return NewLookupMiddleware().
+			WithStartTime(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)).
+			WithUsername("jane")
+	}
+
+	assert.Equal(t, base().Hash(), base().Hash())
+	assert.Equal(t,
+		base().WithEventName("RunInstances").WithSourceIP("10.0.0.1").Hash(),
+		base().WithSourceIP("10.0.0.1").WithEventName("RunInstances").Hash(),
+	)
+	assert.NotEqual(t, base().Hash(), base().WithEventName("RunInstances").Hash())
+	assert.NotEqual(t, base().Hash(), base().WithSourceIP("10.0.0.1").Hash())
+	assert.NotEqual(t, base().WithSourceIP("10.0.0.1").Hash(), base().WithSourceIP("10.0.0.2").Hash())
+}
@@ -0,0 +1,245 @@
+package cloudtrail
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CloudTrailEvent is a CloudTrail record: the document LookupEvents returns as
+// CloudTrailEvent, one of the Records of a trail log file. Fields whose shape
+// depends on the API called are kept as raw JSON.
+type CloudTrailEvent struct {
+	EventVersion  string       `json:"eventVersion,omitempty"`
+	EventID       string       `json:"eventID"`
+	EventTime     time.Time    `json:"eventTime"`
+	EventSource   string       `json:"eventSource,omitempty"`
+	EventName     string       `json:"eventName,omitempty"`
+	EventType     string       `json:"eventType,omitempty"`
+	EventCategory string       `json:"eventCategory,omitempty"`
+	AwsRegion     string       `json:"awsRegion,omitempty"`
+	UserIdentity  UserIdentity `json:"userIdentity"`
+
+	SourceIPAddress string `json:"sourceIPAddress"`
+	UserAgent       string `json:"userAgent"`
+	ErrorCode       string `json:"errorCode"`
+	ErrorMessage    string `json:"errorMessage,omitempty"`
+
+	RequestParameters   json.RawMessage `json:"requestParameters,omitempty"`
+	ResponseElements    json.RawMessage `json:"responseElements,omitempty"`
+	AdditionalEventData json.RawMessage `json:"additionalEventData,omitempty"`
+	ServiceEventDetails json.RawMessage `json:"serviceEventDetails,omitempty"`
+	EdgeDeviceDetails   json.RawMessage `json:"edgeDeviceDetails,omitempty"`
+
+	RequestID       string           `json:"requestID,omitempty"`
+	SharedEventID   string           `json:"sharedEventID,omitempty"`
+	ApiVersion      string           `json:"apiVersion,omitempty"`
+	ReadOnly        *bool            `json:"readOnly,omitempty"`
+	ManagementEvent *bool            `json:"managementEvent,omitempty"`
+	Resources       []RecordResource `json:"resources,omitempty"`
+
+	RecipientAccountID   string `json:"recipientAccountId,omitempty"`
+	VpcEndpointID        string `json:"vpcEndpointId,omitempty"`
+	VpcEndpointAccountID string `json:"vpcEndpointAccountId,omitempty"`
+
+	SessionCredentialFromConsole string      `json:"sessionCredentialFromConsole,omitempty"`
+	TlsDetails                   *TlsDetails `json:"tlsDetails,omitempty"`
+	Addendum                     *Addendum   `json:"addendum,omitempty"`
+}
+
+// UserIdentity is who made a call, as CloudTrail records it. For assumed
+// roles and federated users, SessionContext.SessionIssuer is the role or IAM
+// user the session was issued by.
+type UserIdentity struct {
+	Type             string          `json:"type"`
+	PrincipalID      string          `json:"principalId,omitempty"`
+	Arn              string          `json:"arn,omitempty"`
+	AccountID        string          `json:"accountId,omitempty"`
+	AccessKeyID      string          `json:"accessKeyId,omitempty"`
+	UserName         string          `json:"userName,omitempty"`
+	InvokedBy        string          `json:"invokedBy,omitempty"`
+	CredentialID     string          `json:"credentialId,omitempty"`
+	IdentityProvider string          `json:"identityProvider,omitempty"`
+	SessionContext   *SessionContext `json:"sessionContext,omitempty"`
+	OnBehalfOf       *OnBehalfOf     `json:"onBehalfOf,omitempty"`
+}
+
+type SessionContext struct {
+	SessionIssuer       *SessionIssuer       `json:"sessionIssuer,omitempty"`
+	WebIdFederationData *WebIdFederationData `json:"webIdFederationData,omitempty"`
+	Attributes          *SessionAttributes   `json:"attributes,omitempty"`
+	SourceIdentity      string               `json:"sourceIdentity,omitempty"`
+	Ec2RoleDelivery     string               `json:"ec2RoleDelivery,omitempty"`
+	AssumedRoot         string               `json:"assumedRoot,omitempty"`
+}
+
+type SessionIssuer struct {
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId,omitempty"`
+	Arn         string `json:"arn,omitempty"`
+	AccountID   string `json:"accountId,omitempty"`
+	UserName    string `json:"userName,omitempty"`
+}
+
+type WebIdFederationData struct {
+	FederatedProvider string          `json:"federatedProvider,omitempty"`
+	Attributes        json.RawMessage `json:"attributes,omitempty"`
+}
+
+// SessionAttributes are recorded as strings: CreationDate in RFC 3339,
+// MfaAuthenticated as "true" or "false".
+type SessionAttributes struct {
+	CreationDate     string `json:"creationDate,omitempty"`
+	MfaAuthenticated string `json:"mfaAuthenticated,omitempty"`
+}
+
+// OnBehalfOf is the IAM Identity Center user a call was made for.
+type OnBehalfOf struct {
+	UserID           string `json:"userId,omitempty"`
+	IdentityStoreArn string `json:"identityStoreArn,omitempty"`
+}
+
+type RecordResource struct {
+	ARN       string `json:"ARN"`
+	AccountID string `json:"accountId,omitempty"`
+	Type      string `json:"type"`
+}
+
+type TlsDetails struct {
+	TlsVersion               string `json:"tlsVersion,omitempty"`
+	CipherSuite              string `json:"cipherSuite,omitempty"`
+	ClientProvidedHostHeader string `json:"clientProvidedHostHeader,omitempty"`
+}
+
+// Addendum explains a record delivered late or corrected after delivery.
+type Addendum struct {
+	Reason            string `json:"reason,omitempty"`
+	UpdatedFields     string `json:"updatedFields,omitempty"`
+	OriginalRequestID string `json:"originalRequestID,omitempty"`
+	OriginalEventID   string `json:"originalEventID,omitempty"`
+}
+
+// username is the user name LookupEvents reports: the IAM user name, the
+// session name of an assumed role, or "root".
+func (r CloudTrailEvent) username() string {
+	identity := r.UserIdentity
+
+	switch {
+	case identity.UserName != "":
+		return identity.UserName
+	case identity.Type == "Root":
+		return "root"
+	case strings.Contains(identity.Arn, "/"):
+		return identity.Arn[strings.LastIndex(identity.Arn, "/")+1:]
+	}
+
+	return ""
+}
+
+// Principal is the identity behind a call: the IAM user, role or root account
+// whose permissions were used and, for a role session, who held the session.
+type Principal struct {
+	// Type is the userIdentity type: IAMUser, AssumedRole, Root,
+	// FederatedUser, AWSService, IdentityCenterUser, ...
+	Type string `json:"type"`
+	// Arn is the ARN of the IAM user, role or root account, not of the
+	// session; for AWS services, the service name.
+	Arn       string `json:"arn"`
+	AccountID string `json:"accountId,omitempty"`
+	// Name is the user or role name.
+	Name string `json:"name,omitempty"`
+	// Session is the role session or federated user name; IAM Identity Center
+	// and most SSO setups put the person's user name or e-mail there.
+	Session        string `json:"session,omitempty"`
+	SourceIdentity string `json:"sourceIdentity,omitempty"`
+	AccessKeyID    string `json:"accessKeyId,omitempty"`
+	// InvokedBy is the AWS service that made the call on the principal's
+	// behalf, e.g. "cloudformation.amazonaws.com".
+	InvokedBy string `json:"invokedBy,omitempty"`
+}
+
+// NewPrincipal resolves the identity of a record, looking through assumed
+// role and federated sessions to the role or user that issued them.
+func NewPrincipal(identity UserIdentity) Principal {
+	p := Principal{
+		Type:        identity.Type,
+		Arn:         identity.Arn,
+		AccountID:   identity.AccountID,
+		Name:        identity.UserName,
+		AccessKeyID: identity.AccessKeyID,
+		InvokedBy:   identity.InvokedBy,
+	}
+
+	if identity.SessionContext != nil {
+		p.SourceIdentity = identity.SessionContext.SourceIdentity
+	}
+
+	switch identity.Type {
+	case "AssumedRole", "FederatedUser":
+		// arn:aws:sts::123456789012:assumed-role/<role>/<session>
+		// arn:aws:sts::123456789012:federated-user/<name>
+		if i := strings.LastIndex(identity.Arn, "/"); i >= 0 {
+			p.Session = identity.Arn[i+1:]
+		}
+
+		if identity.SessionContext != nil && identity.SessionContext.SessionIssuer != nil {
+			issuer := identity.SessionContext.SessionIssuer
+			p.Arn = issuer.Arn
+			p.Name = issuer.UserName
+			if issuer.AccountID != "" {
+				p.AccountID = issuer.AccountID
+			}
+		} else if role, ok := roleFromSessionArn(identity.Arn); ok {
+			p.Arn, p.Name = role, role[strings.LastIndex(role, "/")+1:]
+		}
+	case "Root":
+		p.Name = "root"
+	case "AWSService":
+		p.Arn, p.Name = identity.InvokedBy, identity.InvokedBy
+	case "IdentityCenterUser":
+		if identity.OnBehalfOf != nil {
+			p.Session = identity.OnBehalfOf.UserID
+			p.Arn = identity.OnBehalfOf.IdentityStoreArn
+		}
+	}
+
+	return p
+}
+
+// roleFromSessionArn derives the role ARN of an assumed role session ARN,
+// without the role path, which the session ARN does not carry.
+func roleFromSessionArn(arn string) (string, bool) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return "", false
+	}
+
+	role := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")[0]
+
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], role), true
+}
+
+// IsZero tells an unresolved principal, as of an event without identity.
+func (p Principal) IsZero() bool {
+	return p.Type == "" && p.Arn == ""
+}
+
+// String names the principal and, when there is one, its session, e.g.
+// "arn:aws:iam::123456789012:role/deploy (jane@example.com)".
+func (p Principal) String() string {
+	name := p.Arn
+	if name == "" {
+		name = p.Type
+	}
+
+	if p.Session != "" {
+		name += " (" + p.Session + ")"
+	}
+
+	if p.InvokedBy != "" && p.Type != "AWSService" {
+		name += " via " + p.InvokedBy
+	}
+
+	return name
+}
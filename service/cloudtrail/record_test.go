@@ -0,0 +1,161 @@
+package cloudtrail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullRecord is a RunInstances call by an SSO user through an assumed role,
+// with every part of the record schema the parser models.
+const fullRecord = `{"Records": [{
+	"eventVersion": "1.10",
+	"userIdentity": {
+		"type": "AssumedRole",
+		"principalId": "AROAEXAMPLE:jane@example.com",
+		"arn": "arn:aws:sts::123456789012:assumed-role/AWSReservedSSO_Admin_0123/jane@example.com",
+		"accountId": "123456789012",
+		"accessKeyId": "ASIAEXAMPLE",
+		"sessionContext": {
+			"sessionIssuer": {
+				"type": "Role",
+				"principalId": "AROAEXAMPLE",
+				"arn": "arn:aws:iam::123456789012:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123",
+				"accountId": "123456789012",
+				"userName": "AWSReservedSSO_Admin_0123"
+			},
+			"attributes": {"creationDate": "2026-03-02T09:00:00Z", "mfaAuthenticated": "false"},
+			"sourceIdentity": "jane"
+		},
+		"invokedBy": "cloudformation.amazonaws.com"
+	},
+	"eventTime": "2026-03-02T10:15:00Z",
+	"eventSource": "ec2.amazonaws.com",
+	"eventName": "RunInstances",
+	"awsRegion": "eu-central-1",
+	"sourceIPAddress": "cloudformation.amazonaws.com",
+	"userAgent": "cloudformation.amazonaws.com",
+	"errorCode": "Client.UnauthorizedOperation",
+	"errorMessage": "You are not authorized to perform this operation.",
+	"requestParameters": {"instanceType": "m5.large", "instancesSet": {"items": [{"imageId": "ami-0abc", "minCount": 1}]}},
+	"responseElements": null,
+	"requestID": "req-1",
+	"eventID": "e-full",
+	"readOnly": false,
+	"resources": [{"ARN": "arn:aws:ec2:eu-central-1:123456789012:instance/i-0abc", "accountId": "123456789012", "type": "AWS::EC2::Instance"}],
+	"eventType": "AwsApiCall",
+	"managementEvent": true,
+	"recipientAccountId": "123456789012",
+	"eventCategory": "Management",
+	"tlsDetails": {"tlsVersion": "TLSv1.3", "cipherSuite": "TLS_AES_128_GCM_SHA256", "clientProvidedHostHeader": "ec2.eu-central-1.amazonaws.com"},
+	"sessionCredentialFromConsole": "true"
+}]}`
+
+func TestReadFullRecord(t *testing.T) {
+	events, err := ReadTrailLog(strings.NewReader(fullRecord), false)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	e := events[0]
+	record := e.EventData
+
+	assert.Equal(t, "1.10", record.EventVersion)
+	assert.Equal(t, "AwsApiCall", record.EventType)
+	assert.Equal(t, "Management", record.EventCategory)
+	assert.True(t, *record.ManagementEvent)
+	assert.Equal(t, "req-1", record.RequestID)
+	assert.Equal(t, "true", record.SessionCredentialFromConsole)
+
+	identity := e.GetUserIdentity()
+	require.NotNil(t, identity.SessionContext)
+	require.NotNil(t, identity.SessionContext.SessionIssuer)
+	assert.Equal(t, "AWSReservedSSO_Admin_0123", identity.SessionContext.SessionIssuer.UserName)
+	assert.Equal(t, "false", identity.SessionContext.Attributes.MfaAuthenticated)
+
+	assert.Equal(t, "ASIAEXAMPLE", e.GetAccessKeyId())
+	assert.Equal(t, "cloudformation.amazonaws.com", e.GetUserAgent())
+	assert.True(t, e.IsError())
+	assert.Equal(t, "Client.UnauthorizedOperation", e.GetErrorCode())
+	assert.Equal(t, "You are not authorized to perform this operation.", e.GetErrorMessage())
+	assert.Equal(t, "TLSv1.3", e.GetTlsDetails().TlsVersion)
+	assert.Equal(t, []string{"arn:aws:ec2:eu-central-1:123456789012:instance/i-0abc"}, e.GetResourcesByType("AWS::EC2::Instance"))
+
+	var params struct {
+		InstanceType string `json:"instanceType"`
+		InstancesSet struct {
+			Items []struct {
+				ImageID string `json:"imageId"`
+			} `json:"items"`
+		} `json:"instancesSet"`
+	}
+	require.NoError(t, e.DecodeRequestParameters(&params))
+	assert.Equal(t, "m5.large", params.InstanceType)
+	assert.Equal(t, "ami-0abc", params.InstancesSet.Items[0].ImageID)
+
+	// null response elements leave the target untouched
+	response := map[string]any{"kept": true}
+	require.NoError(t, e.DecodeResponseElements(&response))
+	assert.Equal(t, map[string]any{"kept": true}, response)
+
+	principal := e.GetPrincipal()
+	assert.Equal(t, "arn:aws:iam::123456789012:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123", principal.Arn)
+	assert.Equal(t, "AWSReservedSSO_Admin_0123", principal.Name)
+	assert.Equal(t, "jane@example.com", principal.Session)
+	assert.Equal(t, "jane", principal.SourceIdentity)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/aws-reserved/sso.amazonaws.com/AWSReservedSSO_Admin_0123 (jane@example.com) via cloudformation.amazonaws.com", principal.String())
+
+	// LookupEvents reports the session name alone
+	assert.Equal(t, "jane@example.com", e.GetUsername())
+}
+
+func TestNewPrincipal(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity UserIdentity
+		want     Principal
+	}{
+		{
+			name:     "iam user",
+			identity: UserIdentity{Type: "IAMUser", Arn: "arn:aws:iam::123456789012:user/ci", AccountID: "123456789012", UserName: "ci"},
+			want:     Principal{Type: "IAMUser", Arn: "arn:aws:iam::123456789012:user/ci", AccountID: "123456789012", Name: "ci"},
+		},
+		{
+			name:     "root",
+			identity: UserIdentity{Type: "Root", Arn: "arn:aws:iam::123456789012:root", AccountID: "123456789012"},
+			want:     Principal{Type: "Root", Arn: "arn:aws:iam::123456789012:root", AccountID: "123456789012", Name: "root"},
+		},
+		{
+			name:     "assumed role without session context",
+			identity: UserIdentity{Type: "AssumedRole", Arn: "arn:aws:sts::123456789012:assumed-role/deploy/pipeline-42"},
+			want:     Principal{Type: "AssumedRole", Arn: "arn:aws:iam::123456789012:role/deploy", Name: "deploy", Session: "pipeline-42"},
+		},
+		{
+			name: "federated user",
+			identity: UserIdentity{Type: "FederatedUser", Arn: "arn:aws:sts::123456789012:federated-user/bob",
+				SessionContext: &SessionContext{SessionIssuer: &SessionIssuer{Type: "IAMUser", Arn: "arn:aws:iam::123456789012:user/broker", UserName: "broker"}}},
+			want: Principal{Type: "FederatedUser", Arn: "arn:aws:iam::123456789012:user/broker", Name: "broker", Session: "bob"},
+		},
+		{
+			name:     "aws service",
+			identity: UserIdentity{Type: "AWSService", InvokedBy: "autoscaling.amazonaws.com"},
+			want:     Principal{Type: "AWSService", Arn: "autoscaling.amazonaws.com", Name: "autoscaling.amazonaws.com", InvokedBy: "autoscaling.amazonaws.com"},
+		},
+		{
+			name: "identity center user",
+			identity: UserIdentity{Type: "IdentityCenterUser", AccountID: "123456789012",
+				OnBehalfOf: &OnBehalfOf{UserID: "u-1", IdentityStoreArn: "arn:aws:identitystore::123456789012:identitystore/d-1"}},
+			want: Principal{Type: "IdentityCenterUser", Arn: "arn:aws:identitystore::123456789012:identitystore/d-1", AccountID: "123456789012", Session: "u-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewPrincipal(tt.identity))
+		})
+	}
+
+	assert.True(t, NewPrincipal(UserIdentity{}).IsZero())
+	assert.Equal(t, "autoscaling.amazonaws.com", NewPrincipal(tests[4].identity).String())
+}
@@ -0,0 +1,135 @@
+package cloudtrail
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+// EventSource yields the CloudTrail events matching a lookup. CloudTrailRepository
+// answers from LookupEvents: management events of the last 90 days, one lookup
+// attribute, 2 requests per second. LakeRepository and TrailLogSource answer
+// the same lookups from a CloudTrail Lake event data store and from the trail
+// log files, beyond those limits.
+type EventSource interface {
+	ListEventsByLookup(lookup *LookupMiddleware) ([]Event, error)
+	ListEventsByLookupAsync(lookup *LookupMiddleware) (<-chan Event, <-chan *errors.Error)
+}
+
+var (
+	_ EventSource = (*CloudTrailRepository)(nil)
+	_ EventSource = (*LakeRepository)(nil)
+	_ EventSource = (*TrailLogSource)(nil)
+)
+
+// recordClient stands in for the AwsClient of events that were not fetched
+// through one: their account and region are those of the record.
+type recordClient struct {
+	accountID ptypes.AwsAccountID
+	region    ptypes.AwsRegion
+}
+
+func (c recordClient) GetAccountID() ptypes.AwsAccountID { return c.accountID }
+func (c recordClient) GetRegion() ptypes.AwsRegion       { return c.region }
+
+// newRecordEvent builds an Event from a CloudTrail record, shaped as
+// LookupEvents would have returned it. raw is kept as the CloudTrailEvent
+// document; when nil, the record itself is.
+func newRecordEvent(record CloudTrailEvent, raw []byte) (Event, error) {
+	if raw == nil {
+		var err error
+		if raw, err = json.Marshal(record); err != nil {
+			return Event{}, errors.New(err)
+		}
+	}
+
+	event := types.Event{
+		EventId:         aws.String(record.EventID),
+		EventName:       aws.String(record.EventName),
+		EventSource:     aws.String(record.EventSource),
+		EventTime:       aws.Time(record.EventTime),
+		Username:        aws.String(record.username()),
+		CloudTrailEvent: aws.String(string(raw)),
+	}
+
+	if record.UserIdentity.AccessKeyID != "" {
+		event.AccessKeyId = aws.String(record.UserIdentity.AccessKeyID)
+	}
+
+	if record.ReadOnly != nil {
+		event.ReadOnly = aws.String(strconv.FormatBool(*record.ReadOnly))
+	}
+
+	for _, r := range record.Resources {
+		event.Resources = append(event.Resources, types.Resource{
+			ResourceName: aws.String(r.ARN),
+			ResourceType: aws.String(r.Type),
+		})
+	}
+
+	client := recordClient{
+		accountID: ptypes.AwsAccountID(record.RecipientAccountID),
+		region:    ptypes.AwsRegion(record.AwsRegion),
+	}
+
+	return NewEvent(client, event, record), nil
+}
+
+// emitEvents runs fetch in a goroutine, streaming the events it emits until
+// fetch returns, the context is done or the lookup limit is reached; emit
+// then returns false.
+func emitEvents(
+	ctx context.Context,
+	lookup *LookupMiddleware,
+	fetch func(emit func(Event) bool) error,
+) (<-chan Event, <-chan *errors.Error) {
+	if errs, ok := lookup.Errors(); !ok {
+		errChan := make(chan *errors.Error, 1)
+		defer close(errChan)
+
+		errChan <- errors.New(errs[0])
+
+		return nil, errChan
+	}
+
+	source := make(chan Event)
+	errChan := make(chan *errors.Error)
+
+	limit := int(aws.ToInt32(lookup.Get().MaxResults))
+
+	go func() {
+		defer close(source)
+		defer close(errChan)
+
+		count := 0
+		emit := func(e Event) bool {
+			if limit > 0 && count >= limit {
+				return false
+			}
+
+			select {
+			case <-ctx.Done():
+				return false
+			case source <- e:
+				count++
+				return limit == 0 || count < limit
+			}
+		}
+
+		// the error is handed over before the channels close, so a reader
+		// returning on the first closed channel does not miss it
+		if err := fetch(emit); err != nil {
+			select {
+			case errChan <- errors.New(err):
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return source, errChan
+}
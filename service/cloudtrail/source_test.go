@@ -0,0 +1,153 @@
+package cloudtrail
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// trailLog is an abridged trail log file: an instance launched through an
+// assumed role, a read by an IAM user and a console login by root.
+const trailLog = `{"Records": [
+	{"eventVersion": "1.09", "eventID": "e-run", "eventTime": "2026-03-02T10:15:00Z",
+	 "eventName": "RunInstances", "eventSource": "ec2.amazonaws.com", "awsRegion": "eu-central-1",
+	 "recipientAccountId": "123456789012", "sourceIPAddress": "10.0.0.1", "readOnly": false,
+	 "userAgent": "APN/1.0 HashiCorp/1.0 Terraform/1.9.5",
+	 "userIdentity": {"type": "AssumedRole", "arn": "arn:aws:sts::123456789012:assumed-role/deploy/jane@example.com",
+	   "accessKeyId": "ASIAEXAMPLE"},
+	 "responseElements": {"instancesSet": {"items": [{"instanceId": "i-0abc"}]}}},
+	{"eventVersion": "1.09", "eventID": "e-get", "eventTime": "2026-03-02T10:20:00Z",
+	 "eventName": "GetObject", "eventSource": "s3.amazonaws.com", "awsRegion": "eu-central-1",
+	 "recipientAccountId": "123456789012", "sourceIPAddress": "10.0.0.2", "readOnly": true,
+	 "errorCode": "AccessDenied",
+	 "userIdentity": {"type": "IAMUser", "userName": "reporter", "arn": "arn:aws:iam::123456789012:user/reporter"},
+	 "resources": [{"ARN": "arn:aws:s3:::reports/q1.csv", "type": "AWS::S3::Object"}]},
+	{"eventVersion": "1.09", "eventID": "e-login", "eventTime": "2026-03-02T23:55:00Z",
+	 "eventName": "ConsoleLogin", "eventSource": "signin.amazonaws.com", "awsRegion": "eu-central-1",
+	 "recipientAccountId": "123456789012", "sourceIPAddress": "192.0.2.10",
+	 "userIdentity": {"type": "Root", "arn": "arn:aws:iam::123456789012:root"}}
+]}`
+
+func readTrailLog(t *testing.T) []Event {
+	t.Helper()
+
+	events, err := ReadTrailLog(strings.NewReader(trailLog), false)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	return events
+}
+
+func TestReadTrailLog(t *testing.T) {
+	events := readTrailLog(t)
+
+	run := events[0]
+	assert.Equal(t, "e-run", run.GetId())
+	assert.Equal(t, "RunInstances", run.GetName())
+	assert.Equal(t, "jane@example.com", run.GetUsername())
+	assert.Equal(t, "false", run.GetReadOnly())
+	assert.Equal(t, "10.0.0.1", run.GetSourceIPAddress())
+	assert.Equal(t, "AssumedRole", run.EventData.UserIdentity.Type)
+	assert.Equal(t, "ASIAEXAMPLE", aws.ToString(run.AccessKeyId))
+	assert.Equal(t, "123456789012", run.GetAccountID().String())
+	assert.Equal(t, "eu-central-1", run.GetRegion().String())
+	assert.Contains(t, aws.ToString(run.CloudTrailEvent), "i-0abc")
+
+	get := events[1]
+	assert.Equal(t, "reporter", get.GetUsername())
+	assert.True(t, get.IsReadOnly())
+	assert.Equal(t, []string{"arn:aws:s3:::reports/q1.csv"}, get.GetResourcesByType("AWS::S3::Object"))
+
+	login := events[2]
+	assert.Equal(t, "root", login.GetUsername())
+	assert.Equal(t, "", login.GetReadOnly())
+}
+
+func TestLakeRepositoryQuery(t *testing.T) {
+	repo := NewLakeRepository(context.Background(), nil, "arn:aws:cloudtrail:eu-central-1:123456789012:eventdatastore/eds-1").
+		WithScope("210987654321", "eu-west-1")
+
+	lookup := NewLookupMiddleware().
+		WithStartTime(time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)).
+		WithEndTime(time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)).
+		WithLimit(50).
+		WithUsername("o'brien")
+
+	sql := repo.Query(lookup)
+
+	assert.True(t, strings.HasPrefix(sql, "SELECT eventID, eventTime, "))
+	assert.Contains(t, sql, " FROM eds-1 WHERE ")
+	assert.Contains(t, sql, "eventTime >= '2026-03-02 10:00:00.000' AND eventTime <= '2026-03-02 11:00:00.000'")
+	assert.Contains(t, sql, "recipientAccountId = '210987654321' AND awsRegion = 'eu-west-1'")
+	assert.Contains(t, sql, `(userIdentity.username = 'o''brien' OR userIdentity.arn LIKE '%/o''brien' ESCAPE '\')`)
+	assert.True(t, strings.HasSuffix(sql, " ORDER BY eventTime DESC LIMIT 50"))
+
+	// every attribute is in SQL; predicates are not, nor then is the limit
+	sql = repo.Query(lookup.WithEventName("RunInstances").WithSourceIP("10.0.0.1"))
+	assert.Contains(t, sql, `(userIdentity.username = 'o''brien' OR userIdentity.arn LIKE '%/o''brien' ESCAPE '\') AND eventName = 'RunInstances'`)
+	assert.NotContains(t, sql, "10.0.0.1")
+	assert.True(t, strings.HasSuffix(sql, " ORDER BY eventTime DESC"))
+
+	// any resource of the event, wildcards in the name taken literally
+	sql = repo.Query(NewLookupMiddleware().WithResourceId("my_bucket%2").WithResourceType("AWS::S3::Bucket"))
+	assert.Contains(t, sql, `any_match(resources, r -> r.arn LIKE '%my\_bucket\%2%' ESCAPE '\')`)
+	assert.Contains(t, sql, "any_match(resources, r -> r.type = 'AWS::S3::Bucket')")
+	assert.NotContains(t, sql, "element_at(resources, 1)")
+}
+
+func TestNewLakeEvent(t *testing.T) {
+	event, err := newLakeEvent([]map[string]string{
+		{"eventID": "e-1"},
+		{"eventTime": "2026-03-02 10:15:00.000"},
+		{"eventName": "CreateBucket"},
+		{"eventSource": "s3.amazonaws.com"},
+		{"awsRegion": "eu-central-1"},
+		{"recipientAccountId": "123456789012"},
+		{"readOnly": "false"},
+		{"identityType": "AssumedRole"},
+		{"identityArn": "arn:aws:sts::123456789012:assumed-role/deploy/jane"},
+		{"resourceArns": `["arn:aws:s3:::reports","arn:aws:s3:::reports/q1.csv"]`},
+		{"resourceTypes": `["AWS::S3::Bucket","AWS::S3::Object"]`},
+		{"sourceIdentity": "jane@example.com"},
+		{"requestParameters": `{"bucketName":"reports","CreateBucketConfiguration":"{\"LocationConstraint\":\"eu-central-1\"}"}`},
+		{"responseElements": "null"},
+		{"tlsVersion": "TLSv1.3"},
+		{"cipherSuite": "TLS_AES_128_GCM_SHA256"},
+		{"clientProvidedHostHeader": "reports.s3.eu-central-1.amazonaws.com"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "e-1", event.GetId())
+	assert.Equal(t, time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC), event.GetTime())
+	assert.Equal(t, "jane", event.GetUsername())
+	assert.False(t, event.IsReadOnly())
+	assert.Equal(t, "AssumedRole", event.EventData.UserIdentity.Type)
+	assert.Equal(t, []string{"arn:aws:s3:::reports"}, event.GetResourcesByType("AWS::S3::Bucket"))
+	assert.Equal(t, []string{"arn:aws:s3:::reports/q1.csv"}, event.GetResourcesByType("AWS::S3::Object"))
+	assert.Equal(t, "jane@example.com", event.EventData.UserIdentity.SessionContext.SourceIdentity)
+	assert.JSONEq(t,
+		`{"bucketName": "reports", "CreateBucketConfiguration": {"LocationConstraint": "eu-central-1"}}`,
+		string(event.EventData.RequestParameters))
+	assert.Nil(t, event.EventData.ResponseElements)
+	assert.Equal(t, &TlsDetails{
+		TlsVersion:               "TLSv1.3",
+		CipherSuite:              "TLS_AES_128_GCM_SHA256",
+		ClientProvidedHostHeader: "reports.s3.eu-central-1.amazonaws.com",
+	}, event.GetTlsDetails())
+
+	_, err = newLakeEvent([]map[string]string{{"eventTime": "yesterday"}})
+	assert.Error(t, err)
+}
+
+func TestLakeRepositoryWithPollIntervalCopies(t *testing.T) {
+	repo := NewLakeRepository(context.Background(), nil, "eds-1")
+	polled := repo.WithPollInterval(10 * time.Millisecond)
+
+	assert.Equal(t, 10*time.Millisecond, polled.pollInterval)
+	assert.Equal(t, time.Second, repo.pollInterval)
+}
@@ -45,6 +45,12 @@ func (e LogGroup) GetTags() map[string]string {
 	return e.Tags
 }
 
+// WithTags returns a copy carrying the given tags, for bulk tag enrichment.
+func (e LogGroup) WithTags(tags map[string]string) service.ResourceInterface {
+	e.Tags = tags
+	return e
+}
+
 func (e LogGroup) GetTagValue(tag string) string {
 	val, ok := e.GetTags()[tag]
 	if !ok {
@@ -13,6 +13,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/cloudwatchlogs"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -78,7 +79,10 @@ func (r *CloudWatchLogsRepository) ListLogGroupsByInput(query *awscloudwatchlogs
 	}
 
 	for _, logGroup := range resp.LogGroups {
-		tags, _ := r.GetLogGroupTags(logGroup)
+		var tags map[string]string
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetLogGroupTags(logGroup)
+		}
 		table := NewLogGroup(r.client, logGroup, tags)
 		logGroups = append(logGroups, table)
 	}
@@ -0,0 +1,109 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package configservice
+
+import (
+	"fmt"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// ConfigServiceRepositoryCached wraps ConfigServiceRepository and caches results of Get*/List* calls.
+type ConfigServiceRepositoryCached struct {
+	repo  *ConfigServiceRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a ConfigServiceRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *ConfigServiceRepository) WithCache(dc *cache.DataCache) *ConfigServiceRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &ConfigServiceRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListAggregateQueryResults returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListAggregateQueryResults(aggregator string, expression string) ([]string, error) {
+	cacheKey := cache.Key("ListAggregateQueryResults", aggregator, expression)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAggregateQueryResults(aggregator, expression)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAggregateResourcesByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListAggregateResourcesByQuery(aggregator string, expression string) ([]Resource, error) {
+	cacheKey := cache.Key("ListAggregateResourcesByQuery", aggregator, expression)
+	var cached []Resource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAggregateResourcesByQuery(aggregator, expression)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAggregateResourcesByType returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListAggregateResourcesByType(aggregator string, resourceType cfg.ResourceType) ([]Resource, error) {
+	cacheKey := cache.Key("ListAggregateResourcesByType", aggregator, resourceType)
+	var cached []Resource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAggregateResourcesByType(aggregator, resourceType)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListDiscoveredResourcesByType returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListDiscoveredResourcesByType(resourceType cfg.ResourceType) ([]cfg.ResourceIdentifier, error) {
+	cacheKey := cache.Key("ListDiscoveredResourcesByType", resourceType)
+	var cached []cfg.ResourceIdentifier
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListDiscoveredResourcesByType(resourceType)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListResourcesByKeys returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListResourcesByKeys(keys []cfg.ResourceKey) ([]Resource, error) {
+	cacheKey := cache.Key("ListResourcesByKeys", keys)
+	var cached []Resource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesByKeys(keys)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListResourcesByType returns cached results when available, otherwise delegates to the underlying repository.
+func (c *ConfigServiceRepositoryCached) ListResourcesByType(resourceType cfg.ResourceType) ([]Resource, error) {
+	cacheKey := cache.Key("ListResourcesByType", resourceType)
+	var cached []Resource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesByType(resourceType)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
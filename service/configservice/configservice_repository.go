@@ -0,0 +1,311 @@
+package configservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/service/configservice"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/configservice"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// batchGetSize is the most keys BatchGetResourceConfig accepts per call.
+	batchGetSize = 100
+	// batchGetAttempts bounds the retries of keys Config left unprocessed.
+	batchGetAttempts = 3
+	// batchGetBackoff is the wait before the first retry of unprocessed
+	// keys, doubled for each retry after it.
+	batchGetBackoff = 500 * time.Millisecond
+	// pageLimit is the largest page ListDiscoveredResources and the query
+	// APIs return.
+	pageLimit = 100
+)
+
+// queryColumns are the columns NewResourceFromQuery reads.
+var queryColumns = []string{
+	"resourceId",
+	"resourceName",
+	"resourceType",
+	"arn",
+	"accountId",
+	"awsRegion",
+	"availabilityZone",
+	"resourceCreationTime",
+	"configurationItemCaptureTime",
+	"configurationItemStatus",
+	"tags",
+	"configuration",
+}
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// ConfigServiceRepository reads the inventory AWS Config recorded: that of
+// the client's account and region, or through an aggregator that of every
+// account and region the aggregator collects from. It only sees what the
+// configuration recorders record.
+type ConfigServiceRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewConfigServiceRepository(ctx context.Context, client *v3.Client) *ConfigServiceRepository {
+	repo := &ConfigServiceRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *ConfigServiceRepository) configClient() *awsconfig.Client {
+	return configservice.GetClient(r.client)
+}
+
+func (r *ConfigServiceRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *ConfigServiceRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// ResourceQuery returns the advanced query selecting the resources of a type
+// in the columns NewResourceFromQuery reads. conditions are ANDed to it, e.g.
+// "accountId = '123456789012'".
+func ResourceQuery(resourceType cfg.ResourceType, conditions ...string) string {
+	where := append([]string{fmt.Sprintf("resourceType = '%s'", resourceType)}, conditions...)
+
+	return "SELECT " + strings.Join(queryColumns, ", ") + " WHERE " + strings.Join(where, " AND ")
+}
+
+// ListDiscoveredResourcesByType returns the identifiers of the resources of a
+// type Config recorded in the client's account and region, deleted ones
+// excluded.
+func (r *ConfigServiceRepository) ListDiscoveredResourcesByType(resourceType cfg.ResourceType) ([]cfg.ResourceIdentifier, error) {
+	start := time.Now()
+	var identifiers []cfg.ResourceIdentifier
+
+	query := &awsconfig.ListDiscoveredResourcesInput{ResourceType: resourceType, Limit: pageLimit}
+
+	p := awsconfig.NewListDiscoveredResourcesPaginator(r.configClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListDiscoveredResources", resourceType)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListDiscoveredResources", resourceType)).Inc()
+			}
+
+			return identifiers, errors.New(err)
+		}
+
+		identifiers = append(identifiers, resp.ResourceIdentifiers...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListDiscoveredResourcesByType", resourceType)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return identifiers, nil
+}
+
+// ListResourcesByType returns the current configuration of every resource of
+// a type recorded in the client's account and region: ListDiscoveredResources
+// for the ids, then BatchGetResourceConfig for the configurations.
+func (r *ConfigServiceRepository) ListResourcesByType(resourceType cfg.ResourceType) ([]Resource, error) {
+	identifiers, err := r.ListDiscoveredResourcesByType(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]cfg.ResourceKey, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		keys = append(keys, cfg.ResourceKey{ResourceId: identifier.ResourceId, ResourceType: identifier.ResourceType})
+	}
+
+	return r.ListResourcesByKeys(keys)
+}
+
+// ListResourcesByKeys returns the current configuration of the given
+// resources, 100 per call. Keys Config leaves unprocessed are retried, with a
+// growing wait, up to batchGetAttempts calls; keys still unprocessed after
+// that fail the call, alongside the resources read.
+// Resources recorded as deleted are left out.
+func (r *ConfigServiceRepository) ListResourcesByKeys(keys []cfg.ResourceKey) ([]Resource, error) {
+	start := time.Now()
+	resources := []Resource{}
+
+	resourceType := cfg.ResourceType("")
+	if len(keys) > 0 {
+		resourceType = keys[0].ResourceType
+	}
+
+	for i := 0; i < len(keys); i += batchGetSize {
+		pending := keys[i:min(i+batchGetSize, len(keys))]
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			if attempt > batchGetAttempts {
+				return resources, errors.Errorf("%d resources left unprocessed by BatchGetResourceConfig", len(pending))
+			}
+
+			if attempt > 1 {
+				select {
+				case <-r.ctx.Done():
+					return resources, errors.New(r.ctx.Err())
+				case <-time.After(batchGetDelay(attempt)):
+				}
+			}
+
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequests.With(r.promLabels("BatchGetResourceConfig", resourceType)).Inc()
+			}
+
+			resp, err := r.configClient().BatchGetResourceConfig(r.ctx, &awsconfig.BatchGetResourceConfigInput{ResourceKeys: pending})
+			if err != nil {
+				if metrics.AwsMetricsEnabled {
+					metrics.AwsApiRequestErrors.With(r.promLabels("BatchGetResourceConfig", resourceType)).Inc()
+				}
+
+				return resources, errors.New(err)
+			}
+
+			for _, item := range resp.BaseConfigurationItems {
+				resource, err := NewResource(item)
+				if err != nil {
+					log.Warn().Err(err).
+						Str("id", aws.ToString(item.ResourceId)).
+						Str("type", ccfg.ResourceTypeToString(item.ResourceType)).
+						Msg("[ConfigServiceRepository.ListResourcesByKeys] failed parsing configuration, skipping")
+					continue
+				}
+
+				if !resource.IsDeleted() {
+					resources = append(resources, resource)
+				}
+			}
+
+			pending = resp.UnprocessedResourceKeys
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("BatchGetResourceConfig", resourceType)).
+			Add(float64(len(resources)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListResourcesByKeys", resourceType)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return resources, nil
+}
+
+// batchGetDelay is the wait before an attempt of BatchGetResourceConfig:
+// none for the first, then batchGetBackoff doubling.
+func batchGetDelay(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+
+	return batchGetBackoff << (attempt - 2)
+}
+
+// ListAggregateResourcesByType returns the resources of a type across every
+// source account and region of an aggregator. The client must be in the
+// aggregator's account and region.
+func (r *ConfigServiceRepository) ListAggregateResourcesByType(aggregator string, resourceType cfg.ResourceType) ([]Resource, error) {
+	return r.ListAggregateResourcesByQuery(aggregator, ResourceQuery(resourceType))
+}
+
+// ListAggregateResourcesByQuery runs an advanced query selecting the columns
+// of ResourceQuery, e.g. one narrowed with conditions, and returns the
+// resources. Resources recorded as deleted are left out.
+func (r *ConfigServiceRepository) ListAggregateResourcesByQuery(aggregator string, expression string) ([]Resource, error) {
+	rows, err := r.ListAggregateQueryResults(aggregator, expression)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(rows))
+	for _, row := range rows {
+		resource, err := NewResourceFromQuery(row)
+		if err != nil {
+			return resources, err
+		}
+
+		if !resource.IsDeleted() {
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+// ListAggregateQueryResults runs any advanced query against an aggregator and
+// returns its rows as JSON, e.g. for "SELECT resourceType, COUNT(*) GROUP BY
+// resourceType".
+func (r *ConfigServiceRepository) ListAggregateQueryResults(aggregator string, expression string) ([]string, error) {
+	start := time.Now()
+	var rows []string
+
+	query := &awsconfig.SelectAggregateResourceConfigInput{
+		ConfigurationAggregatorName: aws.String(aggregator),
+		Expression:                  aws.String(expression),
+		Limit:                       pageLimit,
+	}
+
+	p := awsconfig.NewSelectAggregateResourceConfigPaginator(r.configClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("SelectAggregateResourceConfig", ccfg.ResourceTypeConfigQueryResult)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("SelectAggregateResourceConfig", ccfg.ResourceTypeConfigQueryResult)).Inc()
+			}
+
+			return rows, errors.New(err)
+		}
+
+		rows = append(rows, resp.Results...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("SelectAggregateResourceConfig", ccfg.ResourceTypeConfigQueryResult)).
+			Add(float64(len(rows)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListAggregateQueryResults", ccfg.ResourceTypeConfigQueryResult)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return rows, nil
+}
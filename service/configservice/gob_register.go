@@ -0,0 +1,10 @@
+package configservice
+
+import "encoding/gob"
+
+// init registers the containers a recorded configuration decodes into, so a
+// Resource survives the cache; see the same registration in cloudcontrol.
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
@@ -0,0 +1,241 @@
+package configservice
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+// Resource is a resource as AWS Config recorded it, of any recorded type.
+//
+// Like cloudcontrol.Resource it embeds no service-specific SDK struct: the
+// recorded configuration stays in Configuration, untyped. Unlike it, the
+// account and region are the resource's own, as Config reports them, not the
+// client's: an aggregator returns the resources of every source account and
+// region through one client. Global resources such as IAM roles carry the
+// region "global".
+//
+// Configuration, SupplementaryConfiguration and Tags are exported so that they
+// survive the gob encoding of the cache handlers.
+type Resource struct {
+	service.AbstractResource
+	ResourceName     string
+	AvailabilityZone string
+	Status           types.ConfigurationItemStatus
+	// CaptureTime is when Config recorded this configuration, which may lag
+	// the resource by the recording frequency.
+	CaptureTime time.Time
+
+	// Configuration is the parsed configuration JSON object, shaped like the
+	// service's Describe output for the type.
+	Configuration              map[string]interface{}
+	SupplementaryConfiguration map[string]string
+	Tags                       map[string]string
+}
+
+// NewResource builds a resource from a BatchGetResourceConfig item. Config
+// leaves tags out of these items, so they are lifted from the configuration
+// when the type records them there, as EC2 types do.
+func NewResource(item types.BaseConfigurationItem) (Resource, error) {
+	configuration, err := parseConfiguration(aws.ToString(item.Configuration))
+	if err != nil {
+		return Resource{}, err
+	}
+
+	e := Resource{
+		AbstractResource: service.AbstractResource{
+			AccountID: ptypes.AwsAccountID(aws.ToString(item.AccountId)),
+			Region:    ptypes.AwsRegion(aws.ToString(item.AwsRegion)),
+			ID:        aws.ToString(item.ResourceId),
+			ARN:       parseArn(aws.ToString(item.Arn)),
+			CreatedAt: aws.ToTime(item.ResourceCreationTime),
+			Type:      item.ResourceType,
+		},
+		ResourceName:               aws.ToString(item.ResourceName),
+		AvailabilityZone:           aws.ToString(item.AvailabilityZone),
+		Status:                     item.ConfigurationItemStatus,
+		CaptureTime:                aws.ToTime(item.ConfigurationItemCaptureTime),
+		Configuration:              configuration,
+		SupplementaryConfiguration: item.SupplementaryConfiguration,
+		Tags:                       tagsFromConfiguration(configuration),
+	}
+
+	return e, nil
+}
+
+// queryRow is one result of ResourceQuery, as advanced queries return it.
+type queryRow struct {
+	ResourceID                   string                        `json:"resourceId"`
+	ResourceName                 string                        `json:"resourceName"`
+	ResourceType                 types.ResourceType            `json:"resourceType"`
+	Arn                          string                        `json:"arn"`
+	AccountID                    string                        `json:"accountId"`
+	AwsRegion                    string                        `json:"awsRegion"`
+	AvailabilityZone             string                        `json:"availabilityZone"`
+	ResourceCreationTime         string                        `json:"resourceCreationTime"`
+	ConfigurationItemCaptureTime string                        `json:"configurationItemCaptureTime"`
+	ConfigurationItemStatus      types.ConfigurationItemStatus `json:"configurationItemStatus"`
+	Tags                         []map[string]interface{}      `json:"tags"`
+	Configuration                map[string]interface{}        `json:"configuration"`
+	SupplementaryConfiguration   map[string]interface{}        `json:"supplementaryConfiguration"`
+}
+
+// NewResourceFromQuery builds a resource from one result of a query selecting
+// the columns of ResourceQuery. Advanced queries return tags, unlike
+// BatchGetResourceConfig.
+func NewResourceFromQuery(result string) (Resource, error) {
+	var row queryRow
+	if err := json.Unmarshal([]byte(result), &row); err != nil {
+		return Resource{}, errors.New(err)
+	}
+
+	e := Resource{
+		AbstractResource: service.AbstractResource{
+			AccountID: ptypes.AwsAccountID(row.AccountID),
+			Region:    ptypes.AwsRegion(row.AwsRegion),
+			ID:        row.ResourceID,
+			ARN:       parseArn(row.Arn),
+			CreatedAt: parseTime(row.ResourceCreationTime),
+			Type:      row.ResourceType,
+		},
+		ResourceName:               row.ResourceName,
+		AvailabilityZone:           row.AvailabilityZone,
+		Status:                     row.ConfigurationItemStatus,
+		CaptureTime:                parseTime(row.ConfigurationItemCaptureTime),
+		Configuration:              row.Configuration,
+		SupplementaryConfiguration: map[string]string{},
+		Tags:                       tagsFromList(row.Tags),
+	}
+
+	if e.Configuration == nil {
+		e.Configuration = map[string]interface{}{}
+	}
+
+	// supplementary configuration comes back as objects; keep it as the JSON
+	// strings BatchGetResourceConfig returns
+	for key, value := range row.SupplementaryConfiguration {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return Resource{}, errors.New(err)
+		}
+		e.SupplementaryConfiguration[key] = string(encoded)
+	}
+
+	return e, nil
+}
+
+// GetName returns the resource name Config recorded, else the Name tag.
+func (e Resource) GetName() string {
+	if e.ResourceName != "" {
+		return e.ResourceName
+	}
+
+	return e.Tags["Name"]
+}
+
+// GetAttributes exposes the recorded configuration, like
+// cloudcontrol.Resource does its properties.
+func (e Resource) GetAttributes() map[string]interface{} {
+	return e.Configuration
+}
+
+// IsDeleted reports a resource Config recorded as deleted.
+func (e Resource) IsDeleted() bool {
+	return e.Status == types.ConfigurationItemStatusResourceDeleted ||
+		e.Status == types.ConfigurationItemStatusResourceDeletedNotRecorded
+}
+
+func (e Resource) GetTags() map[string]string {
+	return e.Tags
+}
+
+func (e Resource) GetTagValue(tag string) string {
+	return e.Tags[tag]
+}
+
+func parseConfiguration(configuration string) (map[string]interface{}, error) {
+	parsed := map[string]interface{}{}
+	if configuration == "" || configuration == "null" {
+		return parsed, nil
+	}
+
+	if err := json.Unmarshal([]byte(configuration), &parsed); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return parsed, nil
+}
+
+func parseTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+func parseArn(resourceArn string) *arn.ARN {
+	parsed, err := arn.Parse(resourceArn)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
+
+// tagsFromConfiguration finds tags in a recorded configuration, either as a
+// list of key/value objects or as a map, under the keys services use.
+func tagsFromConfiguration(configuration map[string]interface{}) map[string]string {
+	for _, key := range []string{"tags", "Tags", "tagList", "TagList", "tagSet", "TagSet"} {
+		switch tags := configuration[key].(type) {
+		case []interface{}:
+			list := make([]map[string]interface{}, 0, len(tags))
+			for _, tag := range tags {
+				if entry, ok := tag.(map[string]interface{}); ok {
+					list = append(list, entry)
+				}
+			}
+			return tagsFromList(list)
+		case map[string]interface{}:
+			mapped := make(map[string]string, len(tags))
+			for k, v := range tags {
+				if value, ok := v.(string); ok {
+					mapped[k] = value
+				}
+			}
+			return mapped
+		}
+	}
+
+	return map[string]string{}
+}
+
+func tagsFromList(list []map[string]interface{}) map[string]string {
+	tags := make(map[string]string, len(list))
+	for _, entry := range list {
+		var key, value string
+		for k, v := range entry {
+			s, _ := v.(string)
+			switch strings.ToLower(k) {
+			case "key":
+				key = s
+			case "value":
+				value = s
+			}
+		}
+
+		if key != "" {
+			tags[key] = value
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,116 @@
+package configservice
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// instanceConfiguration is a trimmed EC2 instance as Config records it, with
+// its tags inside the configuration.
+const instanceConfiguration = `{
+	"instanceId": "i-1",
+	"instanceType": "t3.micro",
+	"state": {"code": 16, "name": "running"},
+	"tags": [{"key": "Name", "value": "web"}, {"key": "env", "value": "prod"}]
+}`
+
+func TestNewResource(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	resource, err := NewResource(types.BaseConfigurationItem{
+		AccountId:               aws.String("210987654321"),
+		AwsRegion:               aws.String("eu-west-1"),
+		ResourceId:              aws.String("i-1"),
+		ResourceType:            types.ResourceTypeInstance,
+		Arn:                     aws.String("arn:aws:ec2:eu-west-1:210987654321:instance/i-1"),
+		ResourceCreationTime:    &created,
+		ConfigurationItemStatus: types.ConfigurationItemStatusOk,
+		Configuration:           aws.String(instanceConfiguration),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ptypes.AwsAccountID("210987654321"), resource.GetAccountID())
+	assert.Equal(t, ptypes.AwsRegion("eu-west-1"), resource.GetRegion())
+	assert.Equal(t, types.ResourceTypeInstance, resource.GetType())
+	assert.Equal(t, "arn:aws:ec2:eu-west-1:210987654321:instance/i-1", resource.GetArn())
+	assert.Equal(t, created, resource.GetCreatedAt())
+	assert.Equal(t, "t3.micro", resource.GetAttributes()["instanceType"])
+	assert.Equal(t, map[string]string{"Name": "web", "env": "prod"}, resource.GetTags())
+	assert.Equal(t, "web", resource.GetName())
+	assert.False(t, resource.IsDeleted())
+
+	_, err = NewResource(types.BaseConfigurationItem{Configuration: aws.String("{broken")})
+	assert.Error(t, err)
+}
+
+func TestNewResourceFromQuery(t *testing.T) {
+	resource, err := NewResourceFromQuery(`{
+		"resourceId": "shared-bucket",
+		"resourceName": "shared-bucket",
+		"resourceType": "AWS::S3::Bucket",
+		"arn": "arn:aws:s3:::shared-bucket",
+		"accountId": "210987654321",
+		"awsRegion": "eu-central-1",
+		"resourceCreationTime": "2025-06-01T08:00:00.000Z",
+		"configurationItemCaptureTime": "2026-10-18T22:10:00.000Z",
+		"configurationItemStatus": "OK",
+		"tags": [{"key": "team", "value": "data"}],
+		"configuration": {"name": "shared-bucket"},
+		"supplementaryConfiguration": {"BucketVersioningConfiguration": {"status": "Enabled"}}
+	}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "shared-bucket", resource.GetId())
+	assert.Equal(t, types.ResourceTypeBucket, resource.GetType())
+	assert.Equal(t, ptypes.AwsAccountID("210987654321"), resource.GetAccountID())
+	assert.Equal(t, time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), resource.GetCreatedAt())
+	assert.Equal(t, time.Date(2026, 10, 18, 22, 10, 0, 0, time.UTC), resource.CaptureTime)
+	assert.Equal(t, "data", resource.GetTagValue("team"))
+	assert.JSONEq(t, `{"status": "Enabled"}`, resource.SupplementaryConfiguration["BucketVersioningConfiguration"])
+
+	deleted, err := NewResourceFromQuery(`{"resourceId": "gone", "configurationItemStatus": "ResourceDeleted"}`)
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.NotNil(t, deleted.GetAttributes())
+}
+
+func TestResourceSurvivesGobRoundTrip(t *testing.T) {
+	in, err := NewResource(types.BaseConfigurationItem{
+		ResourceId:    aws.String("i-1"),
+		ResourceType:  types.ResourceTypeInstance,
+		Configuration: aws.String(instanceConfiguration),
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(in))
+
+	var out Resource
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&out))
+
+	assert.Equal(t, map[string]interface{}{"code": float64(16), "name": "running"}, out.Configuration["state"])
+	assert.Equal(t, in.GetTags(), out.GetTags())
+}
+
+func TestResourceQuery(t *testing.T) {
+	assert.Equal(t,
+		"SELECT resourceId, resourceName, resourceType, arn, accountId, awsRegion, availabilityZone, "+
+			"resourceCreationTime, configurationItemCaptureTime, configurationItemStatus, tags, configuration "+
+			"WHERE resourceType = 'AWS::EC2::Volume' AND accountId = '210987654321'",
+		ResourceQuery(types.ResourceTypeVolume, "accountId = '210987654321'"),
+	)
+}
+
+func TestBatchGetDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), batchGetDelay(1))
+	assert.Equal(t, batchGetBackoff, batchGetDelay(2))
+	assert.Equal(t, 2*batchGetBackoff, batchGetDelay(3))
+}
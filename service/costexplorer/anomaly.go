@@ -0,0 +1,192 @@
+package costexplorer
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// DetectionMethod selects how the Detector derives the expected value and the
+// normal spread of a day from the days before it.
+type DetectionMethod string
+
+const (
+	// MethodMeanStdDev compares a day with the mean and standard deviation of
+	// its baseline. It reacts faster but one earlier spike inflates the spread.
+	MethodMeanStdDev DetectionMethod = "mean-stddev"
+
+	// MethodMedian compares a day with the median and the scaled median
+	// absolute deviation of its baseline, which earlier spikes barely move.
+	MethodMedian DetectionMethod = "median"
+)
+
+// madScale makes the median absolute deviation comparable to a standard
+// deviation for normally distributed data.
+const madScale = 1.4826
+
+// SpendAnomaly is one day whose spend departs from its baseline. Score is the
+// departure in units of the baseline spread; it is negative for drops.
+type SpendAnomaly struct {
+	Group    string  `json:"group"`
+	Date     string  `json:"date"`
+	Value    float64 `json:"value"`
+	Expected float64 `json:"expected"`
+	Impact   float64 `json:"impact"`
+	Score    float64 `json:"score"`
+}
+
+// Detector finds anomalies in daily cost series locally, from data already
+// fetched with GetCostAndUsage, as a free alternative to Cost Anomaly
+// Detection. Each day is compared with a rolling baseline of the Window days
+// before it; the first Window days of a series are never flagged.
+type Detector struct {
+	method    DetectionMethod
+	window    int
+	threshold float64
+	minImpact float64
+	drops     bool
+}
+
+// NewDetector returns a median-based detector over a 14-day baseline that
+// flags increases scoring at least 3 and costing at least 1 unit more than
+// expected.
+func NewDetector() Detector {
+	return Detector{method: MethodMedian, window: 14, threshold: 3, minImpact: 1}
+}
+
+func (d Detector) WithMethod(method DetectionMethod) Detector {
+	d.method = method
+	return d
+}
+
+// WithWindow sets the number of baseline days; values below 2 are raised to 2.
+func (d Detector) WithWindow(days int) Detector {
+	d.window = max(days, 2)
+	return d
+}
+
+func (d Detector) WithThreshold(score float64) Detector {
+	d.threshold = score
+	return d
+}
+
+// WithMinImpact ignores departures smaller than the given amount, so a series
+// moving from 0.01 to 0.05 does not page anyone.
+func (d Detector) WithMinImpact(amount float64) Detector {
+	d.minImpact = amount
+	return d
+}
+
+// WithDrops also reports days spending markedly less than expected, which can
+// point at a broken workload rather than a saving.
+func (d Detector) WithDrops() Detector {
+	d.drops = true
+	return d
+}
+
+// Detect returns the anomalous days of one series; dates and values are
+// parallel slices in time order. A zero Detector uses a 2-day baseline, as
+// WithWindow would; use NewDetector for meaningful defaults.
+func (d Detector) Detect(group string, dates []string, values []float64) []SpendAnomaly {
+	var anomalies []SpendAnomaly
+
+	window := max(d.window, 2)
+	for i := window; i < len(values) && i < len(dates); i++ {
+		expected, spread := d.baseline(values[i-window : i])
+
+		// a flat baseline has no spread; fall back to a small fraction of the
+		// expected value so a change on a constant series still scores finitely
+		spread = max(spread, math.Abs(expected)*0.01, 0.01)
+
+		impact := values[i] - expected
+		score := impact / spread
+
+		if impact == 0 || math.Abs(impact) < d.minImpact || math.Abs(score) < d.threshold {
+			continue
+		}
+		if score < 0 && !d.drops {
+			continue
+		}
+
+		anomalies = append(anomalies, SpendAnomaly{
+			Group:    group,
+			Date:     dates[i],
+			Value:    values[i],
+			Expected: expected,
+			Impact:   impact,
+			Score:    score,
+		})
+	}
+
+	return anomalies
+}
+
+func (d Detector) baseline(values []float64) (expected, spread float64) {
+	if d.method == MethodMeanStdDev {
+		return meanStdDev(values)
+	}
+
+	expected = median(values)
+
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - expected)
+	}
+
+	return expected, median(deviations) * madScale
+}
+
+// DetectTable runs Detect over the daily series of every group of a DAILY
+// granularity table; days a group has no row for count as zero spend.
+// Anomalies are ordered by impact, largest first.
+func (d Detector) DetectTable(t CostTable, metric string) []SpendAnomaly {
+	pivot := t.ByGroup(metric)
+
+	var anomalies []SpendAnomaly
+	for i, group := range pivot.Rows {
+		values := make([]float64, len(pivot.Columns))
+		for j, v := range pivot.Values[i] {
+			values[j] = v.InexactFloat64()
+		}
+
+		anomalies = append(anomalies, d.Detect(group, pivot.Columns, values)...)
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].Impact) > math.Abs(anomalies[j].Impact)
+	})
+
+	return anomalies
+}
+
+func meanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
@@ -0,0 +1,83 @@
+package costexplorer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dailySeries(values ...float64) ([]string, []float64) {
+	dates := make([]string, len(values))
+	for i := range values {
+		dates[i] = fmt.Sprintf("2026-09-%02d", i+1)
+	}
+
+	return dates, values
+}
+
+func TestDetectorDetect(t *testing.T) {
+	dates, values := dailySeries(10, 11, 9, 10, 12, 10, 11, 9, 10, 10, 11, 10, 9, 10, 40, 10, 2)
+
+	for _, method := range []DetectionMethod{MethodMedian, MethodMeanStdDev} {
+		detector := NewDetector().WithMethod(method)
+
+		anomalies := detector.Detect("Amazon EC2", dates, values)
+		require.Len(t, anomalies, 1, method)
+		assert.Equal(t, "2026-09-15", anomalies[0].Date)
+		assert.Equal(t, "Amazon EC2", anomalies[0].Group)
+		assert.Greater(t, anomalies[0].Impact, 25.0)
+	}
+
+	withDrops := NewDetector().WithDrops().Detect("Amazon EC2", dates, values)
+	require.Len(t, withDrops, 2)
+	assert.Negative(t, withDrops[1].Score)
+
+	// the spike inflates the standard deviation of the following baselines,
+	// hiding the drop from the mean-based method
+	assert.Len(t, NewDetector().WithMethod(MethodMeanStdDev).WithDrops().Detect("Amazon EC2", dates, values), 1)
+}
+
+func TestDetectorDetect_FlatBaselineAndMinImpact(t *testing.T) {
+	dates, values := dailySeries(5, 5, 5, 5, 5, 5.5, 9)
+
+	anomalies := NewDetector().WithWindow(5).Detect("Amazon S3", dates, values)
+	require.Len(t, anomalies, 1, "the 0.5 step is below the minimum impact")
+	assert.Equal(t, "2026-09-07", anomalies[0].Date)
+
+	assert.Empty(t, NewDetector().WithWindow(5).WithMinImpact(10).Detect("Amazon S3", dates, values))
+}
+
+func TestDetectorZeroValue(t *testing.T) {
+	dates, values := dailySeries(5, 5, 5, 50)
+
+	assert.NotPanics(t, func() {
+		anomalies := Detector{}.Detect("Amazon S3", dates, values)
+		require.Len(t, anomalies, 1)
+		assert.Equal(t, "2026-09-04", anomalies[0].Date)
+	})
+	assert.Zero(t, median(nil))
+}
+
+func TestDetectorDetectTable(t *testing.T) {
+	cu := CostAndUsage{GroupDefinitions: []types.GroupDefinition{GroupByService()}}
+	for day := 1; day <= 20; day++ {
+		start, end := fmt.Sprintf("2026-09-%02d", day), fmt.Sprintf("2026-09-%02d", day+1)
+
+		groups := []types.Group{group("3", "Amazon S3")}
+		if day == 18 {
+			groups = append(groups, group("50", "AWS Lambda")) // first appearance
+		} else if day > 1 && day < 18 {
+			groups = append(groups, group("1", "AWS Lambda"))
+		}
+		cu.ResultsByTime = append(cu.ResultsByTime, period(start, end, false, groups...))
+	}
+
+	anomalies := NewDetector().DetectTable(cu.Table(), MetricUnblendedCost)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "AWS Lambda", anomalies[0].Group)
+	assert.Equal(t, "2026-09-18", anomalies[0].Date)
+	assert.InDelta(t, 49, anomalies[0].Impact, 0.001)
+}
@@ -0,0 +1,82 @@
+package costexplorer
+
+import (
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// PricePerRequest is what AWS charges for one Cost Explorer API request, in
+// USD. Every page of a paginated call is a request.
+const PricePerRequest = 0.01
+
+// ErrRequestBudgetExceeded is returned, wrapped, when a RequestBudget refuses
+// a billable request.
+var ErrRequestBudgetExceeded = stderrors.New("cost explorer request budget exceeded")
+
+// RequestBudget caps billable Cost Explorer requests, in total for the life of
+// the process and per UTC day. Share one budget between every repository that
+// should draw from it; a zero limit means no cap. It is safe for concurrent
+// use.
+type RequestBudget struct {
+	mu       sync.Mutex
+	maxTotal int
+	maxDaily int
+	total    int
+	daily    int
+	day      string
+	now      func() time.Time
+}
+
+// NewRequestBudget returns a budget of maxTotal requests per process and
+// maxDaily requests per UTC day.
+func NewRequestBudget(maxTotal, maxDaily int) *RequestBudget {
+	return &RequestBudget{maxTotal: maxTotal, maxDaily: maxDaily, now: time.Now}
+}
+
+// Reserve takes one request from the budget, or fails without taking any when
+// either cap is reached.
+func (b *RequestBudget) Reserve() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover()
+
+	if b.maxTotal > 0 && b.total >= b.maxTotal {
+		return errors.Errorf("%w: %d of %d requests for this process used", ErrRequestBudgetExceeded, b.total, b.maxTotal)
+	}
+
+	if b.maxDaily > 0 && b.daily >= b.maxDaily {
+		return errors.Errorf("%w: %d of %d requests for %s used", ErrRequestBudgetExceeded, b.daily, b.maxDaily, b.day)
+	}
+
+	b.total++
+	b.daily++
+
+	return nil
+}
+
+// Used returns the requests taken in total and today.
+func (b *RequestBudget) Used() (total, today int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rollover()
+
+	return b.total, b.daily
+}
+
+// Spent estimates the USD spent on the requests taken in total.
+func (b *RequestBudget) Spent() float64 {
+	total, _ := b.Used()
+	return float64(total) * PricePerRequest
+}
+
+func (b *RequestBudget) rollover() {
+	if day := b.now().UTC().Format(dateLayout); day != b.day {
+		b.day = day
+		b.daily = 0
+	}
+}
@@ -0,0 +1,42 @@
+package costexplorer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBudget(t *testing.T) {
+	now := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+	budget := NewRequestBudget(5, 3)
+	budget.now = func() time.Time { return now }
+
+	for range 3 {
+		require.NoError(t, budget.Reserve())
+	}
+
+	err := budget.Reserve()
+	assert.True(t, errors.Is(err, ErrRequestBudgetExceeded))
+	assert.ErrorContains(t, err, "2026-10-19")
+
+	// a new UTC day resets the daily cap, not the process cap
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, budget.Reserve())
+	require.NoError(t, budget.Reserve())
+	assert.ErrorContains(t, budget.Reserve(), "for this process")
+
+	total, today := budget.Used()
+	assert.Equal(t, 5, total)
+	assert.Equal(t, 2, today)
+	assert.InDelta(t, 0.05, budget.Spent(), 1e-9)
+}
+
+func TestRequestBudget_Unlimited(t *testing.T) {
+	budget := NewRequestBudget(0, 0)
+	for range 100 {
+		require.NoError(t, budget.Reserve())
+	}
+}
@@ -26,6 +26,34 @@ func (r *CostExplorerRepository) WithCache(dc *cache.DataCache) *CostExplorerRep
 	}
 }
 
+// GetAnomalies returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetAnomalies(query *awsce.GetAnomaliesInput) ([]types.Anomaly, error) {
+	cacheKey := cache.Key("GetAnomalies", query)
+	var cached []types.Anomaly
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetAnomalies(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetAnomaliesByPeriod returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetAnomaliesByPeriod(start time.Time, end time.Time, monitorArn string) ([]types.Anomaly, error) {
+	cacheKey := cache.Key("GetAnomaliesByPeriod", start, end, monitorArn)
+	var cached []types.Anomaly
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetAnomaliesByPeriod(start, end, monitorArn)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // GetCostAndUsage returns cached results when available, otherwise delegates to the underlying repository.
 func (c *CostExplorerRepositoryCached) GetCostAndUsage(query *awsce.GetCostAndUsageInput) (*CostAndUsage, error) {
 	cacheKey := cache.Key("GetCostAndUsage", query)
@@ -95,3 +123,213 @@ func (c *CostExplorerRepositoryCached) GetDimensionValues(query *awsce.GetDimens
 	}
 	return r0, r1
 }
+
+// GetReservationCoverage returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationCoverage(query *awsce.GetReservationCoverageInput) (*ReservationCoverage, error) {
+	cacheKey := cache.Key("GetReservationCoverage", query)
+	var cached *ReservationCoverage
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationCoverage(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetReservationCoverageByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationCoverageByQuery(q CommitmentQuery) (*ReservationCoverage, error) {
+	cacheKey := cache.Key("GetReservationCoverageByQuery", q)
+	var cached *ReservationCoverage
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationCoverageByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetReservationPurchaseRecommendation returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationPurchaseRecommendation(query *awsce.GetReservationPurchaseRecommendationInput) (*ReservationRecommendations, error) {
+	cacheKey := cache.Key("GetReservationPurchaseRecommendation", query)
+	var cached *ReservationRecommendations
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationPurchaseRecommendation(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetReservationPurchaseRecommendationByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationPurchaseRecommendationByQuery(q ReservationRecommendationQuery) (*ReservationRecommendations, error) {
+	cacheKey := cache.Key("GetReservationPurchaseRecommendationByQuery", q)
+	var cached *ReservationRecommendations
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationPurchaseRecommendationByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetReservationUtilization returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationUtilization(query *awsce.GetReservationUtilizationInput) (*ReservationUtilization, error) {
+	cacheKey := cache.Key("GetReservationUtilization", query)
+	var cached *ReservationUtilization
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationUtilization(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetReservationUtilizationByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetReservationUtilizationByQuery(q CommitmentQuery) (*ReservationUtilization, error) {
+	cacheKey := cache.Key("GetReservationUtilizationByQuery", q)
+	var cached *ReservationUtilization
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetReservationUtilizationByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetRightsizingRecommendation returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetRightsizingRecommendation(query *awsce.GetRightsizingRecommendationInput) (*RightsizingRecommendations, error) {
+	cacheKey := cache.Key("GetRightsizingRecommendation", query)
+	var cached *RightsizingRecommendations
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetRightsizingRecommendation(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetRightsizingRecommendationByTarget returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetRightsizingRecommendationByTarget(target types.RecommendationTarget, benefitsConsidered bool) (*RightsizingRecommendations, error) {
+	cacheKey := cache.Key("GetRightsizingRecommendationByTarget", target, benefitsConsidered)
+	var cached *RightsizingRecommendations
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetRightsizingRecommendationByTarget(target, benefitsConsidered)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansCoverage returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansCoverage(query *awsce.GetSavingsPlansCoverageInput) ([]types.SavingsPlansCoverage, error) {
+	cacheKey := cache.Key("GetSavingsPlansCoverage", query)
+	var cached []types.SavingsPlansCoverage
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansCoverage(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansCoverageByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansCoverageByQuery(q CommitmentQuery) ([]types.SavingsPlansCoverage, error) {
+	cacheKey := cache.Key("GetSavingsPlansCoverageByQuery", q)
+	var cached []types.SavingsPlansCoverage
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansCoverageByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansPurchaseRecommendation returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansPurchaseRecommendation(query *awsce.GetSavingsPlansPurchaseRecommendationInput) (*SavingsPlansRecommendation, error) {
+	cacheKey := cache.Key("GetSavingsPlansPurchaseRecommendation", query)
+	var cached *SavingsPlansRecommendation
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansPurchaseRecommendation(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansPurchaseRecommendationByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansPurchaseRecommendationByQuery(q SavingsPlansRecommendationQuery) (*SavingsPlansRecommendation, error) {
+	cacheKey := cache.Key("GetSavingsPlansPurchaseRecommendationByQuery", q)
+	var cached *SavingsPlansRecommendation
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansPurchaseRecommendationByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansUtilization returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansUtilization(query *awsce.GetSavingsPlansUtilizationInput) (*SavingsPlansUtilization, error) {
+	cacheKey := cache.Key("GetSavingsPlansUtilization", query)
+	var cached *SavingsPlansUtilization
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansUtilization(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansUtilizationByQuery returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansUtilizationByQuery(q CommitmentQuery) (*SavingsPlansUtilization, error) {
+	cacheKey := cache.Key("GetSavingsPlansUtilizationByQuery", q)
+	var cached *SavingsPlansUtilization
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansUtilizationByQuery(q)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetSavingsPlansUtilizationDetails returns cached results when available, otherwise delegates to the underlying repository.
+func (c *CostExplorerRepositoryCached) GetSavingsPlansUtilizationDetails(query *awsce.GetSavingsPlansUtilizationDetailsInput) (*SavingsPlansUtilizationDetails, error) {
+	cacheKey := cache.Key("GetSavingsPlansUtilizationDetails", query)
+	var cached *SavingsPlansUtilizationDetails
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetSavingsPlansUtilizationDetails(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
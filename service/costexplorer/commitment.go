@@ -0,0 +1,357 @@
+package costexplorer
+
+import (
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/cache"
+	"github.com/shopspring/decimal"
+)
+
+// CommitmentReport names one of the Savings Plans and Reserved Instance
+// coverage and utilization reports. Each accepts a different subset of
+// granularities, groupings and metrics; CommitmentQuery.Validate checks them.
+type CommitmentReport string
+
+const (
+	ReportSavingsPlansCoverage    CommitmentReport = "GetSavingsPlansCoverage"
+	ReportSavingsPlansUtilization CommitmentReport = "GetSavingsPlansUtilization"
+	ReportReservationCoverage     CommitmentReport = "GetReservationCoverage"
+	ReportReservationUtilization  CommitmentReport = "GetReservationUtilization"
+)
+
+// Metrics accepted by the coverage reports.
+const (
+	MetricSpendCoveredBySavingsPlans = "SpendCoveredBySavingsPlans"
+	MetricCoverageHour               = "Hour"
+	MetricCoverageUnit               = "Unit"
+	MetricCoverageCost               = "Cost"
+)
+
+// DimensionInstanceFamily is the grouping GetSavingsPlansCoverage takes for
+// instance families; the SDK has no constant for it.
+const DimensionInstanceFamily types.Dimension = "INSTANCE_FAMILY"
+
+// commitmentRules is what one report accepts beyond the common window checks.
+type commitmentRules struct {
+	groupBy []types.Dimension
+	metrics []string
+
+	// groupByWithoutGranularity: the API refuses a Granularity once GroupBy is
+	// set, returning the whole window as one period.
+	groupByWithoutGranularity bool
+}
+
+var commitmentReportRules = map[CommitmentReport]commitmentRules{
+	ReportSavingsPlansCoverage: {
+		groupBy:                   []types.Dimension{DimensionInstanceFamily, types.DimensionRegion, types.DimensionService},
+		metrics:                   []string{MetricSpendCoveredBySavingsPlans},
+		groupByWithoutGranularity: true,
+	},
+	ReportSavingsPlansUtilization: {},
+	ReportReservationCoverage: {
+		groupBy: []types.Dimension{
+			types.DimensionAz, types.DimensionCacheEngine, types.DimensionDatabaseEngine,
+			types.DimensionDeploymentOption, types.DimensionInstanceType, types.DimensionInvoicingEntity,
+			types.DimensionLinkedAccount, types.DimensionOperatingSystem, types.DimensionPlatform,
+			types.DimensionRegion, types.DimensionTenancy,
+		},
+		metrics: []string{MetricCoverageHour, MetricCoverageUnit, MetricCoverageCost},
+	},
+	ReportReservationUtilization: {
+		groupBy:                   []types.Dimension{types.DimensionSubscriptionId},
+		groupByWithoutGranularity: true,
+	},
+}
+
+// CommitmentQuery describes a Savings Plans or Reserved Instance coverage or
+// utilization request the way CostQuery describes a cost request: a window, a
+// granularity, filter rows AND-ed together and optional groupings. The window
+// is rounded and refused while open exactly as for CostQuery.
+type CommitmentQuery struct {
+	// Start is inclusive, End is exclusive.
+	Start       time.Time
+	End         time.Time
+	Granularity types.Granularity
+
+	// Metrics applies to the coverage reports only; left empty, the API
+	// returns its defaults.
+	Metrics []string
+
+	Filters []Filter
+	Filter  *types.Expression
+
+	// GroupBy takes dimension groupings only, each report its own subset.
+	GroupBy []types.GroupDefinition
+
+	AllowOpenWindow bool
+}
+
+// Rounded returns the query with its window widened like CostQuery.Rounded.
+func (q CommitmentQuery) Rounded() CommitmentQuery {
+	q.Start = roundDown(q.Granularity, q.Start)
+	q.End = roundUp(q.Granularity, q.End)
+
+	return q
+}
+
+type commitmentQueryKey CommitmentQuery
+
+// Hash implements cache.Hashable: the key is built from the rounded query.
+func (q CommitmentQuery) Hash() string {
+	return cache.Key("CommitmentQuery", commitmentQueryKey(q.Rounded()))
+}
+
+func (q CommitmentQuery) buildFilter() *types.Expression {
+	var exprs []*types.Expression
+	for _, f := range q.Filters {
+		exprs = append(exprs, f.Expression())
+	}
+
+	return And(append(exprs, q.Filter)...)
+}
+
+func (q CommitmentQuery) timePeriod() *types.DateInterval {
+	return dateInterval(q.Granularity, q.Start, q.End)
+}
+
+// granularity is the Granularity to send: none when the report refuses one
+// alongside GroupBy.
+func (q CommitmentQuery) granularity(report CommitmentReport) types.Granularity {
+	if commitmentReportRules[report].groupByWithoutGranularity && len(q.GroupBy) > 0 {
+		return ""
+	}
+
+	return q.Granularity
+}
+
+// Validate reports what the given report would reject: a malformed window,
+// HOURLY granularity, groupings or metrics the report does not take, and
+// filter match options the API does not accept.
+func (q CommitmentQuery) Validate(report CommitmentReport) error {
+	rules, ok := commitmentReportRules[report]
+	if !ok {
+		return errors.Errorf("unknown commitment report %q", report)
+	}
+
+	if q.Start.IsZero() || q.End.IsZero() {
+		return errors.Errorf("%s query needs both Start and End", report)
+	}
+
+	if !q.End.After(q.Start) {
+		return errors.Errorf("%s query End (%s) must be after Start (%s)", report, q.End, q.Start)
+	}
+
+	if q.Granularity == types.GranularityHourly {
+		return errors.Errorf("%s supports DAILY and MONTHLY granularity only", report)
+	}
+
+	if len(q.GroupBy) > 0 && rules.groupBy == nil {
+		return errors.Errorf("%s does not support GroupBy", report)
+	}
+
+	for _, g := range q.GroupBy {
+		if g.Type != types.GroupDefinitionTypeDimension || !slices.Contains(rules.groupBy, types.Dimension(aws.ToString(g.Key))) {
+			return errors.Errorf("%s cannot group by %s %q (allowed dimensions: %v)", report, g.Type, aws.ToString(g.Key), rules.groupBy)
+		}
+	}
+
+	for _, metric := range q.Metrics {
+		if !slices.Contains(rules.metrics, metric) {
+			return errors.Errorf("%s does not report metric %q (allowed: %v)", report, metric, rules.metrics)
+		}
+	}
+
+	for _, f := range q.Filters {
+		if err := f.validateForCostAndUsage(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reservation services accepted by GetReservationPurchaseRecommendation.
+const (
+	ReservationServiceEC2         = "Amazon Elastic Compute Cloud - Compute"
+	ReservationServiceRDS         = "Amazon Relational Database Service"
+	ReservationServiceRedshift    = "Amazon Redshift"
+	ReservationServiceElastiCache = "Amazon ElastiCache"
+	ReservationServiceOpenSearch  = "Amazon OpenSearch Service"
+	ReservationServiceMemoryDB    = "Amazon MemoryDB"
+)
+
+// SavingsPlansRecommendationQuery describes a Savings Plans purchase
+// recommendation request. Every field but AccountScope and AccountIDs is
+// required by the API.
+type SavingsPlansRecommendationQuery struct {
+	Type          types.SupportedSavingsPlansType
+	Term          types.TermInYears
+	PaymentOption types.PaymentOption
+	Lookback      types.LookbackPeriodInDays
+
+	// AccountScope defaults to PAYER; AccountIDs narrows a PAYER scope to
+	// member accounts.
+	AccountScope types.AccountScope
+	AccountIDs   []string
+}
+
+func (q SavingsPlansRecommendationQuery) Validate() error {
+	if q.Type == "" || q.Term == "" || q.PaymentOption == "" || q.Lookback == "" {
+		return errors.Errorf("savings plans recommendation query needs Type, Term, PaymentOption and Lookback")
+	}
+
+	return nil
+}
+
+// ReservationRecommendationQuery describes a Reserved Instance purchase
+// recommendation request for one service. Term, PaymentOption and Lookback
+// fall back to the API defaults when empty.
+type ReservationRecommendationQuery struct {
+	Service       string
+	Term          types.TermInYears
+	PaymentOption types.PaymentOption
+	Lookback      types.LookbackPeriodInDays
+	AccountScope  types.AccountScope
+	AccountID     string
+
+	// OfferingClass chooses standard or convertible EC2 reservations; it is
+	// only valid for ReservationServiceEC2.
+	OfferingClass types.OfferingClass
+}
+
+func (q ReservationRecommendationQuery) Validate() error {
+	if q.Service == "" {
+		return errors.Errorf("reservation recommendation query needs a Service, e.g. %q", ReservationServiceEC2)
+	}
+
+	if q.OfferingClass != "" && q.Service != ReservationServiceEC2 {
+		return errors.Errorf("reservation recommendation OfferingClass applies to %q only", ReservationServiceEC2)
+	}
+
+	return nil
+}
+
+// SavingsPlansUtilization is the accumulated GetSavingsPlansUtilization
+// result: one entry per period plus the window total.
+type SavingsPlansUtilization struct {
+	Total  *types.SavingsPlansUtilizationAggregates
+	ByTime []types.SavingsPlansUtilizationByTime
+}
+
+// UtilizationPercentage returns the share of the commitment used over the
+// window, zero when the window has no total.
+func (u SavingsPlansUtilization) UtilizationPercentage() decimal.Decimal {
+	if u.Total == nil || u.Total.Utilization == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(u.Total.Utilization.UtilizationPercentage)
+}
+
+// NetSavings returns what the plans saved over the window against On-Demand,
+// net of their commitment.
+func (u SavingsPlansUtilization) NetSavings() decimal.Decimal {
+	if u.Total == nil || u.Total.Savings == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(u.Total.Savings.NetSavings)
+}
+
+// SavingsPlansUtilizationDetails is the accumulated
+// GetSavingsPlansUtilizationDetails result: one entry per plan.
+type SavingsPlansUtilizationDetails struct {
+	Total   *types.SavingsPlansUtilizationAggregates
+	Details []types.SavingsPlansUtilizationDetail
+}
+
+// ReservationCoverage is the accumulated GetReservationCoverage result.
+type ReservationCoverage struct {
+	Total           *types.Coverage
+	CoveragesByTime []types.CoverageByTime
+}
+
+// CoverageHoursPercentage returns the share of running hours covered by
+// reservations over the window.
+func (c ReservationCoverage) CoverageHoursPercentage() decimal.Decimal {
+	if c.Total == nil || c.Total.CoverageHours == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(c.Total.CoverageHours.CoverageHoursPercentage)
+}
+
+// ReservationUtilization is the accumulated GetReservationUtilization result.
+type ReservationUtilization struct {
+	Total              *types.ReservationAggregates
+	UtilizationsByTime []types.UtilizationByTime
+}
+
+// UtilizationPercentage returns the share of purchased reservation hours used
+// over the window.
+func (u ReservationUtilization) UtilizationPercentage() decimal.Decimal {
+	if u.Total == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(u.Total.UtilizationPercentage)
+}
+
+// NetSavings returns what the reservations saved over the window.
+func (u ReservationUtilization) NetSavings() decimal.Decimal {
+	if u.Total == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(u.Total.NetRISavings)
+}
+
+// SavingsPlansRecommendation is the accumulated
+// GetSavingsPlansPurchaseRecommendation result; the details of every page are
+// collected into Recommendation.
+type SavingsPlansRecommendation struct {
+	Metadata       *types.SavingsPlansPurchaseRecommendationMetadata
+	Recommendation *types.SavingsPlansPurchaseRecommendation
+}
+
+// EstimatedMonthlySavings returns the savings the recommended purchase is
+// expected to yield per month.
+func (r SavingsPlansRecommendation) EstimatedMonthlySavings() decimal.Decimal {
+	if r.Recommendation == nil || r.Recommendation.SavingsPlansPurchaseRecommendationSummary == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(r.Recommendation.SavingsPlansPurchaseRecommendationSummary.EstimatedMonthlySavingsAmount)
+}
+
+// ReservationRecommendations is the accumulated
+// GetReservationPurchaseRecommendation result.
+type ReservationRecommendations struct {
+	Metadata        *types.ReservationPurchaseRecommendationMetadata
+	Recommendations []types.ReservationPurchaseRecommendation
+}
+
+// SavingsPlansCoveragePercentage returns the coverage percentage of one
+// GetSavingsPlansCoverage entry.
+func SavingsPlansCoveragePercentage(c types.SavingsPlansCoverage) decimal.Decimal {
+	if c.Coverage == nil {
+		return decimal.Zero
+	}
+
+	return parseDecimal(c.Coverage.CoveragePercentage)
+}
+
+// parseDecimal reads a Cost Explorer numeric string; missing or malformed
+// values read as zero.
+func parseDecimal(value *string) decimal.Decimal {
+	parsed, err := decimal.NewFromString(aws.ToString(value))
+	if err != nil {
+		return decimal.Zero
+	}
+
+	return parsed
+}
@@ -0,0 +1,535 @@
+package costexplorer
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsce "github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// GetSavingsPlansCoverage retrieves the share of eligible spend covered by
+// Savings Plans, following NextToken pagination.
+func (r *CostExplorerRepository) GetSavingsPlansCoverage(query *awsce.GetSavingsPlansCoverageInput) ([]types.SavingsPlansCoverage, error) {
+	start := time.Now()
+
+	result := []types.SavingsPlansCoverage{}
+	nextToken := query.NextToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetSavingsPlansCoverage"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetSavingsPlansCoverage", ccfg.ResourceTypeSavingsPlansCoverage)).Inc()
+		}
+
+		page := *query
+		page.NextToken = nextToken
+
+		output, err := r.costExplorerClient().GetSavingsPlansCoverage(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetSavingsPlansCoverage", ccfg.ResourceTypeSavingsPlansCoverage)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result = append(result, output.SavingsPlansCoverages...)
+
+		if output.NextToken == nil {
+			break
+		}
+
+		nextToken = output.NextToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetSavingsPlansCoverage", ccfg.ResourceTypeSavingsPlansCoverage)).
+			Add(float64(len(result)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetSavingsPlansCoverage", ccfg.ResourceTypeSavingsPlansCoverage)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetSavingsPlansUtilizationDetails retrieves utilization per Savings Plan,
+// following NextToken pagination.
+func (r *CostExplorerRepository) GetSavingsPlansUtilizationDetails(query *awsce.GetSavingsPlansUtilizationDetailsInput) (*SavingsPlansUtilizationDetails, error) {
+	start := time.Now()
+
+	result := &SavingsPlansUtilizationDetails{}
+	nextToken := query.NextToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetSavingsPlansUtilizationDetails"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetSavingsPlansUtilizationDetails", ccfg.ResourceTypeSavingsPlansUtilization)).Inc()
+		}
+
+		page := *query
+		page.NextToken = nextToken
+
+		output, err := r.costExplorerClient().GetSavingsPlansUtilizationDetails(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetSavingsPlansUtilizationDetails", ccfg.ResourceTypeSavingsPlansUtilization)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Total = output.Total
+		result.Details = append(result.Details, output.SavingsPlansUtilizationDetails...)
+
+		if output.NextToken == nil {
+			break
+		}
+
+		nextToken = output.NextToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetSavingsPlansUtilizationDetails", ccfg.ResourceTypeSavingsPlansUtilization)).
+			Add(float64(len(result.Details)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetSavingsPlansUtilizationDetails", ccfg.ResourceTypeSavingsPlansUtilization)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetReservationCoverage retrieves the share of running instance hours covered
+// by reservations, following NextPageToken pagination.
+func (r *CostExplorerRepository) GetReservationCoverage(query *awsce.GetReservationCoverageInput) (*ReservationCoverage, error) {
+	start := time.Now()
+
+	result := &ReservationCoverage{}
+	nextToken := query.NextPageToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetReservationCoverage"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetReservationCoverage", ccfg.ResourceTypeReservationCoverage)).Inc()
+		}
+
+		page := *query
+		page.NextPageToken = nextToken
+
+		output, err := r.costExplorerClient().GetReservationCoverage(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetReservationCoverage", ccfg.ResourceTypeReservationCoverage)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Total = output.Total
+		result.CoveragesByTime = append(result.CoveragesByTime, output.CoveragesByTime...)
+
+		if output.NextPageToken == nil {
+			break
+		}
+
+		nextToken = output.NextPageToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetReservationCoverage", ccfg.ResourceTypeReservationCoverage)).
+			Add(float64(len(result.CoveragesByTime)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetReservationCoverage", ccfg.ResourceTypeReservationCoverage)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetReservationUtilization retrieves how much of the purchased reservations
+// was used, following NextPageToken pagination.
+func (r *CostExplorerRepository) GetReservationUtilization(query *awsce.GetReservationUtilizationInput) (*ReservationUtilization, error) {
+	start := time.Now()
+
+	result := &ReservationUtilization{}
+	nextToken := query.NextPageToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetReservationUtilization"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetReservationUtilization", ccfg.ResourceTypeReservationUtilization)).Inc()
+		}
+
+		page := *query
+		page.NextPageToken = nextToken
+
+		output, err := r.costExplorerClient().GetReservationUtilization(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetReservationUtilization", ccfg.ResourceTypeReservationUtilization)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Total = output.Total
+		result.UtilizationsByTime = append(result.UtilizationsByTime, output.UtilizationsByTime...)
+
+		if output.NextPageToken == nil {
+			break
+		}
+
+		nextToken = output.NextPageToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetReservationUtilization", ccfg.ResourceTypeReservationUtilization)).
+			Add(float64(len(result.UtilizationsByTime)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetReservationUtilization", ccfg.ResourceTypeReservationUtilization)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetSavingsPlansPurchaseRecommendation retrieves a Savings Plans purchase
+// recommendation, following NextPageToken pagination and collecting the
+// details of every page into one recommendation.
+func (r *CostExplorerRepository) GetSavingsPlansPurchaseRecommendation(query *awsce.GetSavingsPlansPurchaseRecommendationInput) (*SavingsPlansRecommendation, error) {
+	start := time.Now()
+
+	result := &SavingsPlansRecommendation{}
+	nextToken := query.NextPageToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetSavingsPlansPurchaseRecommendation"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetSavingsPlansPurchaseRecommendation", ccfg.ResourceTypeSavingsPlansRecommendation)).Inc()
+		}
+
+		page := *query
+		page.NextPageToken = nextToken
+
+		output, err := r.costExplorerClient().GetSavingsPlansPurchaseRecommendation(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetSavingsPlansPurchaseRecommendation", ccfg.ResourceTypeSavingsPlansRecommendation)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Metadata = output.Metadata
+		if rec := output.SavingsPlansPurchaseRecommendation; rec != nil {
+			if result.Recommendation == nil {
+				result.Recommendation = rec
+			} else {
+				result.Recommendation.SavingsPlansPurchaseRecommendationDetails = append(
+					result.Recommendation.SavingsPlansPurchaseRecommendationDetails,
+					rec.SavingsPlansPurchaseRecommendationDetails...,
+				)
+			}
+		}
+
+		if output.NextPageToken == nil {
+			break
+		}
+
+		nextToken = output.NextPageToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetSavingsPlansPurchaseRecommendation", ccfg.ResourceTypeSavingsPlansRecommendation)).
+			Add(float64(recommendationDetails(result)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetSavingsPlansPurchaseRecommendation", ccfg.ResourceTypeSavingsPlansRecommendation)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetReservationPurchaseRecommendation retrieves Reserved Instance purchase
+// recommendations, following NextPageToken pagination.
+func (r *CostExplorerRepository) GetReservationPurchaseRecommendation(query *awsce.GetReservationPurchaseRecommendationInput) (*ReservationRecommendations, error) {
+	start := time.Now()
+
+	result := &ReservationRecommendations{}
+	nextToken := query.NextPageToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetReservationPurchaseRecommendation"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetReservationPurchaseRecommendation", ccfg.ResourceTypeReservationRecommendation)).Inc()
+		}
+
+		page := *query
+		page.NextPageToken = nextToken
+
+		output, err := r.costExplorerClient().GetReservationPurchaseRecommendation(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetReservationPurchaseRecommendation", ccfg.ResourceTypeReservationRecommendation)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Metadata = output.Metadata
+		result.Recommendations = append(result.Recommendations, output.Recommendations...)
+
+		if output.NextPageToken == nil {
+			break
+		}
+
+		nextToken = output.NextPageToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetReservationPurchaseRecommendation", ccfg.ResourceTypeReservationRecommendation)).
+			Add(float64(len(result.Recommendations)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetReservationPurchaseRecommendation", ccfg.ResourceTypeReservationRecommendation)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+func recommendationDetails(result *SavingsPlansRecommendation) int {
+	if result.Recommendation == nil {
+		return 0
+	}
+
+	return len(result.Recommendation.SavingsPlansPurchaseRecommendationDetails)
+}
+
+// GetSavingsPlansUtilization retrieves how much of the Savings Plans
+// commitment was used per period. The API does not paginate.
+func (r *CostExplorerRepository) GetSavingsPlansUtilization(query *awsce.GetSavingsPlansUtilizationInput) (*SavingsPlansUtilization, error) {
+	start := time.Now()
+
+	if err := r.billable("GetSavingsPlansUtilization"); err != nil {
+		return nil, err
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("GetSavingsPlansUtilization", ccfg.ResourceTypeSavingsPlansUtilization)).Inc()
+	}
+
+	output, err := r.costExplorerClient().GetSavingsPlansUtilization(r.ctx, query)
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("GetSavingsPlansUtilization", ccfg.ResourceTypeSavingsPlansUtilization)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetSavingsPlansUtilization", ccfg.ResourceTypeSavingsPlansUtilization)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return &SavingsPlansUtilization{Total: output.Total, ByTime: output.SavingsPlansUtilizationsByTime}, nil
+}
+
+// prepare refuses open windows, judged before rounding, then rounds and
+// validates a commitment query for the given report, as
+// GetCostAndUsageByQuery does.
+func (q CommitmentQuery) prepare(report CommitmentReport) (CommitmentQuery, error) {
+	if !q.AllowOpenWindow && windowOpen(q.Granularity, q.End, time.Now()) {
+		return q, errors.Errorf("%s window ends %s, in a period that has not closed yet; set AllowOpenWindow to query it anyway", report, q.End.UTC().Format(time.RFC3339))
+	}
+
+	q = q.Rounded()
+	if err := q.Validate(report); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// GetSavingsPlansCoverageByQuery runs GetSavingsPlansCoverage for a validated
+// CommitmentQuery.
+func (r *CostExplorerRepository) GetSavingsPlansCoverageByQuery(q CommitmentQuery) ([]types.SavingsPlansCoverage, error) {
+	q, err := q.prepare(ReportSavingsPlansCoverage)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetSavingsPlansCoverage(&awsce.GetSavingsPlansCoverageInput{
+		TimePeriod:  q.timePeriod(),
+		Granularity: q.granularity(ReportSavingsPlansCoverage),
+		Metrics:     q.Metrics,
+		GroupBy:     q.GroupBy,
+		Filter:      q.buildFilter(),
+	})
+}
+
+// GetSavingsPlansUtilizationByQuery runs GetSavingsPlansUtilization for a
+// validated CommitmentQuery.
+func (r *CostExplorerRepository) GetSavingsPlansUtilizationByQuery(q CommitmentQuery) (*SavingsPlansUtilization, error) {
+	q, err := q.prepare(ReportSavingsPlansUtilization)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetSavingsPlansUtilization(&awsce.GetSavingsPlansUtilizationInput{
+		TimePeriod:  q.timePeriod(),
+		Granularity: q.Granularity,
+		Filter:      q.buildFilter(),
+	})
+}
+
+// GetReservationCoverageByQuery runs GetReservationCoverage for a validated
+// CommitmentQuery.
+func (r *CostExplorerRepository) GetReservationCoverageByQuery(q CommitmentQuery) (*ReservationCoverage, error) {
+	q, err := q.prepare(ReportReservationCoverage)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReservationCoverage(&awsce.GetReservationCoverageInput{
+		TimePeriod:  q.timePeriod(),
+		Granularity: q.granularity(ReportReservationCoverage),
+		Metrics:     q.Metrics,
+		GroupBy:     q.GroupBy,
+		Filter:      q.buildFilter(),
+	})
+}
+
+// GetReservationUtilizationByQuery runs GetReservationUtilization for a
+// validated CommitmentQuery. Grouped by subscription, the window is reported
+// as one period.
+func (r *CostExplorerRepository) GetReservationUtilizationByQuery(q CommitmentQuery) (*ReservationUtilization, error) {
+	q, err := q.prepare(ReportReservationUtilization)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetReservationUtilization(&awsce.GetReservationUtilizationInput{
+		TimePeriod:  q.timePeriod(),
+		Granularity: q.granularity(ReportReservationUtilization),
+		GroupBy:     q.GroupBy,
+		Filter:      q.buildFilter(),
+	})
+}
+
+// GetSavingsPlansPurchaseRecommendationByQuery runs
+// GetSavingsPlansPurchaseRecommendation for a validated query.
+func (r *CostExplorerRepository) GetSavingsPlansPurchaseRecommendationByQuery(q SavingsPlansRecommendationQuery) (*SavingsPlansRecommendation, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	return r.GetSavingsPlansPurchaseRecommendation(&awsce.GetSavingsPlansPurchaseRecommendationInput{
+		SavingsPlansType:     q.Type,
+		TermInYears:          q.Term,
+		PaymentOption:        q.PaymentOption,
+		LookbackPeriodInDays: q.Lookback,
+		AccountScope:         q.AccountScope,
+		Filter:               FilterByLinkedAccount(q.AccountIDs...),
+	})
+}
+
+// GetReservationPurchaseRecommendationByQuery runs
+// GetReservationPurchaseRecommendation for a validated query.
+func (r *CostExplorerRepository) GetReservationPurchaseRecommendationByQuery(q ReservationRecommendationQuery) (*ReservationRecommendations, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := &awsce.GetReservationPurchaseRecommendationInput{
+		Service:              aws.String(q.Service),
+		TermInYears:          q.Term,
+		PaymentOption:        q.PaymentOption,
+		LookbackPeriodInDays: q.Lookback,
+		AccountScope:         q.AccountScope,
+	}
+
+	if q.AccountID != "" {
+		query.AccountId = aws.String(q.AccountID)
+	}
+
+	if q.OfferingClass != "" {
+		query.ServiceSpecification = &types.ServiceSpecification{
+			EC2Specification: &types.EC2Specification{OfferingClass: q.OfferingClass},
+		}
+	}
+
+	return r.GetReservationPurchaseRecommendation(query)
+}
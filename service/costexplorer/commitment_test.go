@@ -0,0 +1,117 @@
+package costexplorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validCommitmentQuery() CommitmentQuery {
+	return CommitmentQuery{
+		Start:       time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+		Granularity: types.GranularityMonthly,
+	}
+}
+
+func TestCommitmentQueryValidate(t *testing.T) {
+	for _, report := range []CommitmentReport{
+		ReportSavingsPlansCoverage, ReportSavingsPlansUtilization,
+		ReportReservationCoverage, ReportReservationUtilization,
+	} {
+		assert.NoError(t, validCommitmentQuery().Validate(report), report)
+	}
+
+	hourly := validCommitmentQuery()
+	hourly.Granularity = types.GranularityHourly
+	assert.ErrorContains(t, hourly.Validate(ReportReservationCoverage), "DAILY and MONTHLY")
+
+	grouped := validCommitmentQuery()
+	grouped.GroupBy = []types.GroupDefinition{GroupByDimension(DimensionInstanceFamily)}
+	assert.NoError(t, grouped.Validate(ReportSavingsPlansCoverage))
+	assert.ErrorContains(t, grouped.Validate(ReportReservationCoverage), "cannot group by")
+	assert.ErrorContains(t, grouped.Validate(ReportSavingsPlansUtilization), "does not support GroupBy")
+
+	tagged := validCommitmentQuery()
+	tagged.GroupBy = []types.GroupDefinition{GroupByTag("team")}
+	assert.ErrorContains(t, tagged.Validate(ReportReservationCoverage), "cannot group by TAG")
+
+	metric := validCommitmentQuery()
+	metric.Metrics = []string{MetricCoverageHour}
+	assert.NoError(t, metric.Validate(ReportReservationCoverage))
+	assert.ErrorContains(t, metric.Validate(ReportSavingsPlansCoverage), `metric "Hour"`)
+
+	filtered := validCommitmentQuery()
+	filtered.Filters = []Filter{ByDimension(types.DimensionRegion, "eu").Matching(types.MatchOptionStartsWith)}
+	assert.ErrorContains(t, filtered.Validate(ReportReservationUtilization), "STARTS_WITH")
+
+	assert.ErrorContains(t, validCommitmentQuery().Validate("GetSomethingElse"), "unknown")
+}
+
+func TestCommitmentQueryGranularity(t *testing.T) {
+	q := validCommitmentQuery()
+	assert.Equal(t, types.GranularityMonthly, q.granularity(ReportReservationUtilization))
+
+	q.GroupBy = []types.GroupDefinition{GroupByDimension(types.DimensionSubscriptionId)}
+	assert.Empty(t, q.granularity(ReportReservationUtilization), "the API refuses Granularity with GroupBy")
+
+	q.GroupBy = []types.GroupDefinition{GroupByRegion()}
+	assert.Equal(t, types.GranularityMonthly, q.granularity(ReportReservationCoverage))
+}
+
+func TestCommitmentQueryPrepare(t *testing.T) {
+	q := validCommitmentQuery()
+	q.End = q.End.Add(7 * time.Hour)
+
+	prepared, err := q.prepare(ReportSavingsPlansUtilization)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-09-01", aws.ToString(prepared.timePeriod().End), "the month End falls in is kept")
+	assert.Equal(t, q.Hash(), prepared.Hash())
+
+	open := validCommitmentQuery()
+	open.End = time.Now().AddDate(0, 0, 2)
+	_, err = open.prepare(ReportSavingsPlansUtilization)
+	assert.ErrorContains(t, err, "AllowOpenWindow")
+
+	open.AllowOpenWindow = true
+	_, err = open.prepare(ReportSavingsPlansUtilization)
+	assert.NoError(t, err)
+}
+
+func TestRecommendationQueriesValidate(t *testing.T) {
+	sp := SavingsPlansRecommendationQuery{
+		Type:          types.SupportedSavingsPlansTypeComputeSp,
+		Term:          types.TermInYearsOneYear,
+		PaymentOption: types.PaymentOptionNoUpfront,
+		Lookback:      types.LookbackPeriodInDaysThirtyDays,
+	}
+	assert.NoError(t, sp.Validate())
+
+	sp.Lookback = ""
+	assert.ErrorContains(t, sp.Validate(), "Lookback")
+
+	ri := ReservationRecommendationQuery{Service: ReservationServiceRDS}
+	assert.NoError(t, ri.Validate())
+
+	ri.OfferingClass = types.OfferingClassConvertible
+	assert.ErrorContains(t, ri.Validate(), "OfferingClass")
+
+	assert.ErrorContains(t, ReservationRecommendationQuery{}.Validate(), "needs a Service")
+}
+
+func TestCommitmentResultAccessors(t *testing.T) {
+	u := SavingsPlansUtilization{Total: &types.SavingsPlansUtilizationAggregates{
+		Utilization: &types.SavingsPlansUtilization{UtilizationPercentage: aws.String("87.5")},
+		Savings:     &types.SavingsPlansSavings{NetSavings: aws.String("1234.56")},
+	}}
+	assert.Equal(t, "87.5", u.UtilizationPercentage().String())
+	assert.Equal(t, "1234.56", u.NetSavings().String())
+
+	assert.True(t, SavingsPlansUtilization{}.UtilizationPercentage().IsZero())
+	assert.True(t, ReservationCoverage{}.CoverageHoursPercentage().IsZero())
+	assert.True(t, SavingsPlansRecommendation{}.EstimatedMonthlySavings().IsZero())
+}
@@ -0,0 +1,229 @@
+package costexplorer
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Window is a cost query time period: Start inclusive, End exclusive.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Days returns the number of whole days the window spans.
+func (w Window) Days() int {
+	return int(w.End.Sub(w.Start).Hours() / 24)
+}
+
+func today(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// WeekOverWeek returns the seven days before today and the seven days before
+// those. Today is left out: its cost data is still coming in.
+func WeekOverWeek(now time.Time) (current, previous Window) {
+	end := today(now)
+	current = Window{Start: end.AddDate(0, 0, -7), End: end}
+	previous = Window{Start: end.AddDate(0, 0, -14), End: current.Start}
+
+	return current, previous
+}
+
+// MonthOverMonth returns the last complete calendar month and the month before
+// it.
+func MonthOverMonth(now time.Time) (current, previous Window) {
+	end := firstOfMonth(today(now))
+	current = Window{Start: end.AddDate(0, -1, 0), End: end}
+	previous = Window{Start: end.AddDate(0, -2, 0), End: current.Start}
+
+	return current, previous
+}
+
+// MonthToDate returns the current month up to, not including, today and the
+// same number of days at the start of the previous month, cut at that month's
+// end. On the first of a month the current window is empty and a query over it
+// fails validation.
+func MonthToDate(now time.Time) (current, previous Window) {
+	end := today(now)
+	current = Window{Start: firstOfMonth(end), End: end}
+
+	prevStart := current.Start.AddDate(0, -1, 0)
+	prevEnd := prevStart.AddDate(0, 0, current.Days())
+	if prevEnd.After(current.Start) {
+		prevEnd = current.Start
+	}
+	previous = Window{Start: prevStart, End: prevEnd}
+
+	return current, previous
+}
+
+// DeltaStatus tells how a group changed between the compared windows.
+type DeltaStatus string
+
+const (
+	DeltaChanged   DeltaStatus = "changed"
+	DeltaUnchanged DeltaStatus = "unchanged"
+	DeltaNew       DeltaStatus = "new"      // no cost in the previous window
+	DeltaVanished  DeltaStatus = "vanished" // no cost in the current window
+)
+
+// GroupDelta is the change of one group's cost between two windows, each
+// summed over all of its periods. Percent is relative to Previous and is zero
+// for new groups, where it is undefined.
+type GroupDelta struct {
+	Group    string          `json:"group"`
+	Keys     []string        `json:"keys,omitempty"`
+	Previous decimal.Decimal `json:"previous"`
+	Current  decimal.Decimal `json:"current"`
+	Delta    decimal.Decimal `json:"delta"`
+	Percent  decimal.Decimal `json:"percent"`
+	Status   DeltaStatus     `json:"status"`
+}
+
+// Comparison is the result of running one cost query over two windows.
+// Groups are ordered by the size of their change, largest first.
+type Comparison struct {
+	Metric   string       `json:"metric"`
+	Unit     string       `json:"unit"`
+	Current  Window       `json:"current"`
+	Previous Window       `json:"previous"`
+	Groups   []GroupDelta `json:"groups"`
+}
+
+// Total returns the change of the metric summed over every group.
+func (c Comparison) Total() GroupDelta {
+	var total GroupDelta
+	for _, g := range c.Groups {
+		total.Previous = total.Previous.Add(g.Previous)
+		total.Current = total.Current.Add(g.Current)
+	}
+
+	return delta(total)
+}
+
+// ByStatus returns the groups with the given status, e.g. DeltaNew.
+func (c Comparison) ByStatus(status DeltaStatus) []GroupDelta {
+	var groups []GroupDelta
+	for _, g := range c.Groups {
+		if g.Status == status {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// CompareTables aligns the groups of two tables of the same query by their
+// keys and reports the change of a metric for each.
+func CompareTables(metric string, previous, current CostTable) Comparison {
+	deltas := map[string]*GroupDelta{}
+	var order []string
+
+	add := func(table CostTable, isCurrent bool) {
+		for _, row := range table.Rows {
+			amount, ok := row.Metrics[metric]
+			if !ok {
+				continue
+			}
+
+			label := row.Group()
+			d, found := deltas[label]
+			if !found {
+				d = &GroupDelta{Group: label, Keys: row.Keys}
+				deltas[label] = d
+				order = append(order, label)
+			}
+
+			if isCurrent {
+				d.Current = d.Current.Add(amount.Value)
+			} else {
+				d.Previous = d.Previous.Add(amount.Value)
+			}
+		}
+	}
+	add(previous, false)
+	add(current, true)
+
+	c := Comparison{Metric: metric, Unit: current.Total(metric).Add(previous.Total(metric)).Unit}
+	for _, label := range order {
+		c.Groups = append(c.Groups, delta(*deltas[label]))
+	}
+
+	sort.SliceStable(c.Groups, func(i, j int) bool {
+		return c.Groups[i].Delta.Abs().GreaterThan(c.Groups[j].Delta.Abs())
+	})
+
+	return c
+}
+
+func delta(d GroupDelta) GroupDelta {
+	d.Delta = d.Current.Sub(d.Previous)
+	d.Percent = decimal.Zero
+
+	switch {
+	case d.Previous.IsZero() && !d.Current.IsZero():
+		d.Status = DeltaNew
+	case d.Current.IsZero() && !d.Previous.IsZero():
+		d.Status = DeltaVanished
+	case d.Delta.IsZero():
+		d.Status = DeltaUnchanged
+	default:
+		d.Status = DeltaChanged
+	}
+
+	if !d.Previous.IsZero() {
+		d.Percent = d.Delta.Div(d.Previous).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+
+	return d
+}
+
+// CostSource runs cost queries; both CostExplorerRepository and its cached
+// variant satisfy it.
+type CostSource interface {
+	GetCostAndUsageByQuery(q CostQuery) (*CostAndUsage, error)
+}
+
+// Compare runs the same query over two windows, overriding its Start and End,
+// and compares a metric per group. The metric is added to the query when it
+// does not request it already. With a cached source, the previous window of a
+// rolling comparison is usually a cache hit.
+func Compare(source CostSource, q CostQuery, metric string, current, previous Window) (*Comparison, error) {
+	if !slices.Contains(q.Metrics, metric) {
+		q.Metrics = append(slices.Clone(q.Metrics), metric)
+	}
+
+	run := func(w Window) (CostTable, error) {
+		q.Start, q.End = w.Start, w.End
+
+		result, err := source.GetCostAndUsageByQuery(q)
+		if err != nil {
+			return CostTable{}, err
+		}
+
+		return result.Table(), nil
+	}
+
+	previousTable, err := run(previous)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTable, err := run(current)
+	if err != nil {
+		return nil, err
+	}
+
+	c := CompareTables(metric, previousTable, currentTable)
+	c.Current, c.Previous = current, previous
+
+	return &c, nil
+}
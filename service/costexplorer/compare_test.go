@@ -0,0 +1,108 @@
+package costexplorer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparisonWindows(t *testing.T) {
+	now := time.Date(2026, 3, 31, 15, 4, 0, 0, time.UTC)
+
+	current, previous := WeekOverWeek(now)
+	assert.Equal(t, time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC), current.Start)
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), current.End)
+	assert.Equal(t, current.Start, previous.End)
+	assert.Equal(t, 7, previous.Days())
+
+	current, previous = MonthOverMonth(now)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), current.Start)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), previous.Start)
+	assert.Equal(t, current.Start, previous.End)
+
+	// 30 days into March against February, which has only 28
+	current, previous = MonthToDate(now)
+	assert.Equal(t, 30, current.Days())
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), previous.Start)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), previous.End)
+
+	current, previous = MonthToDate(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 9, current.Days())
+	assert.Equal(t, 9, previous.Days())
+}
+
+func TestCompareTables(t *testing.T) {
+	previous := CostAndUsage{
+		GroupDefinitions: []types.GroupDefinition{GroupByService()},
+		ResultsByTime: []types.ResultByTime{period("2026-08-01", "2026-09-01", false,
+			group("100", "Amazon EC2"),
+			group("10", "Amazon S3"),
+			group("5", "Amazon SQS"),
+		)},
+	}.Table()
+	current := CostAndUsage{
+		GroupDefinitions: []types.GroupDefinition{GroupByService()},
+		ResultsByTime: []types.ResultByTime{period("2026-09-01", "2026-10-01", true,
+			group("150", "Amazon EC2"),
+			group("10", "Amazon S3"),
+			group("7.5", "AWS Lambda"),
+		)},
+	}.Table()
+
+	c := CompareTables(MetricUnblendedCost, previous, current)
+	require.Len(t, c.Groups, 4)
+	assert.Equal(t, "USD", c.Unit)
+
+	ec2 := c.Groups[0]
+	assert.Equal(t, "Amazon EC2", ec2.Group)
+	assert.Equal(t, DeltaChanged, ec2.Status)
+	assert.True(t, d("50").Equal(ec2.Delta))
+	assert.True(t, d("50").Equal(ec2.Percent))
+
+	assert.Equal(t, "AWS Lambda", c.ByStatus(DeltaNew)[0].Group)
+	assert.True(t, c.ByStatus(DeltaNew)[0].Percent.IsZero())
+	assert.Equal(t, "Amazon SQS", c.ByStatus(DeltaVanished)[0].Group)
+	assert.Equal(t, "Amazon S3", c.ByStatus(DeltaUnchanged)[0].Group)
+
+	total := c.Total()
+	assert.True(t, d("52.5").Equal(total.Delta))
+	assert.True(t, d("45.65").Equal(total.Percent), total.Percent.String())
+}
+
+type fakeCostSource struct {
+	queries []CostQuery
+	results []*CostAndUsage
+}
+
+func (f *fakeCostSource) GetCostAndUsageByQuery(q CostQuery) (*CostAndUsage, error) {
+	f.queries = append(f.queries, q)
+	result := f.results[0]
+	f.results = f.results[1:]
+
+	return result, nil
+}
+
+func TestCompare(t *testing.T) {
+	source := &fakeCostSource{results: []*CostAndUsage{
+		{ResultsByTime: []types.ResultByTime{resultByTime("80", "USD")}},
+		{ResultsByTime: []types.ResultByTime{resultByTime("100", "USD")}},
+	}}
+
+	current, previous := WeekOverWeek(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	q := CostQuery{Granularity: types.GranularityDaily, Metrics: []string{MetricAmortizedCost}}
+
+	c, err := Compare(source, q, MetricUnblendedCost, current, previous)
+	require.NoError(t, err)
+
+	require.Len(t, source.queries, 2)
+	assert.Equal(t, previous.Start, source.queries[0].Start)
+	assert.Equal(t, current.End, source.queries[1].End)
+	assert.Equal(t, []string{MetricAmortizedCost, MetricUnblendedCost}, source.queries[0].Metrics)
+	assert.Equal(t, []string{MetricAmortizedCost}, q.Metrics, "the caller's query is left alone")
+
+	assert.Equal(t, current, c.Current)
+	assert.True(t, d("25").Equal(c.Total().Percent))
+}
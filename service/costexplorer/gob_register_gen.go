@@ -7,9 +7,29 @@ import "encoding/gob"
 // init registers this package's types with encoding/gob so they can be
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
+	gob.Register(Amount{})
+	gob.Register(CommitmentQuery{})
+	gob.Register(Comparison{})
 	gob.Register(CostAndUsage{})
 	gob.Register(CostCategoryFilter{})
 	gob.Register(CostQuery{})
+	gob.Register(CostRow{})
+	gob.Register(CostTable{})
+	gob.Register(Detector{})
 	gob.Register(DimensionFilter{})
+	gob.Register(GroupDelta{})
+	gob.Register(Pivot{})
+	gob.Register(RequestBudget{})
+	gob.Register(ReservationCoverage{})
+	gob.Register(ReservationRecommendationQuery{})
+	gob.Register(ReservationRecommendations{})
+	gob.Register(ReservationUtilization{})
+	gob.Register(RightsizingRecommendations{})
+	gob.Register(SavingsPlansRecommendation{})
+	gob.Register(SavingsPlansRecommendationQuery{})
+	gob.Register(SavingsPlansUtilization{})
+	gob.Register(SavingsPlansUtilizationDetails{})
+	gob.Register(SpendAnomaly{})
 	gob.Register(TagFilter{})
+	gob.Register(Window{})
 }
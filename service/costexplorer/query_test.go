@@ -1,10 +1,12 @@
 package costexplorer
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/imunhatep/awslib/cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -149,3 +151,70 @@ func TestCostQueryValidate_MatchOptions(t *testing.T) {
 	category.Filters = []Filter{ByCostCategory("Team", "x").Matching(types.MatchOptionEndsWith)}
 	assert.ErrorContains(t, category.Validate(), "ENDS_WITH")
 }
+
+func TestCostQueryRounded(t *testing.T) {
+	q := validQuery()
+	q.Start = time.Date(2026, 7, 1, 9, 15, 0, 0, time.UTC)
+	q.End = time.Date(2026, 8, 1, 17, 45, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	// monthly windows cover whole months, the one End falls in included
+	rounded := q.Rounded()
+	assert.Equal(t, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), rounded.Start)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), rounded.End)
+
+	daily := q
+	daily.Granularity = types.GranularityDaily
+	assert.Equal(t, time.Date(2026, 8, 2, 0, 0, 0, 0, time.UTC), daily.Rounded().End)
+
+	hourly := q
+	hourly.Granularity = types.GranularityHourly
+	assert.Equal(t, time.Date(2026, 8, 1, 16, 0, 0, 0, time.UTC), hourly.Rounded().End)
+
+	// boundaries stay put
+	assert.Equal(t, validQuery(), validQuery().Rounded())
+
+	// two queries built from time.Now() a few minutes apart share a cache key
+	later := q
+	later.End = q.End.Add(5 * time.Minute)
+	assert.Equal(t, q.Hash(), later.Hash())
+	assert.Equal(t, cache.Key("GetCostAndUsageByQuery", q), cache.Key("GetCostAndUsageByQuery", later))
+
+	other := q
+	other.Metrics = []string{MetricAmortizedCost}
+	assert.NotEqual(t, q.Hash(), other.Hash())
+}
+
+func TestCostQueryOpen(t *testing.T) {
+	now := time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC)
+
+	q := validQuery()
+	q.Granularity = types.GranularityDaily
+	q.Start = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	q.End = now
+	assert.True(t, q.open(now), "a window ending now includes today")
+
+	q.End = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	assert.False(t, q.open(now), "a window ending at midnight is closed")
+
+	q.Granularity = types.GranularityMonthly
+	assert.True(t, q.open(now), "a monthly window ending mid-month includes the current month")
+
+	q.End = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	assert.False(t, q.open(now))
+
+	q.Granularity = types.GranularityHourly
+	q.End = now.Add(30 * time.Minute)
+	assert.True(t, q.open(now))
+}
+
+func TestGetCostAndUsageByQueryRefusesOpenWindow(t *testing.T) {
+	q := validQuery()
+	q.Granularity = types.GranularityDaily
+	q.Start = time.Now().AddDate(0, 0, -7)
+	q.End = time.Now()
+
+	// refused before any call is made
+	_, err := NewCostExplorerRepository(context.Background(), nil).GetCostAndUsageByQuery(q)
+	assert.ErrorContains(t, err, "AllowOpenWindow")
+}
@@ -9,6 +9,7 @@ import (
 	awsce "github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
 	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/cache"
 	"github.com/imunhatep/awslib/metrics"
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
@@ -24,9 +25,13 @@ const (
 	dateTimeLayout = "2006-01-02T15:04:05Z"
 )
 
+// CostExplorerRepository wraps the Cost Explorer API. Unlike almost every other
+// AWS API it is billed per request (see PricePerRequest); WithBudget caps how
+// many requests a repository may make.
 type CostExplorerRepository struct {
 	ctx    context.Context
 	client *v3.Client
+	budget *RequestBudget
 }
 
 func NewCostExplorerRepository(ctx context.Context, client *v3.Client) *CostExplorerRepository {
@@ -38,6 +43,41 @@ func NewCostExplorerRepository(ctx context.Context, client *v3.Client) *CostExpl
 	return repo
 }
 
+// WithBudget returns a repository that takes every billable request from the
+// given budget and fails with ErrRequestBudgetExceeded once it is spent. A
+// paginated call that runs out half-way fails as a whole: the pages already
+// fetched are paid for, but neither returned nor cached.
+func (r *CostExplorerRepository) WithBudget(budget *RequestBudget) *CostExplorerRepository {
+	return &CostExplorerRepository{
+		ctx:    r.ctx,
+		client: r.client,
+		budget: budget,
+	}
+}
+
+// billable is called before every Cost Explorer request: it draws on the
+// budget, if any, and counts the request and its price.
+func (r *CostExplorerRepository) billable(method string) error {
+	labels := prometheus.Labels{"account_id": r.client.GetAccountID().String(), "method": method}
+
+	if r.budget != nil {
+		if err := r.budget.Reserve(); err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsCostExplorerBudgetRejected.With(labels).Inc()
+			}
+
+			return err
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsCostExplorerRequests.With(labels).Inc()
+		metrics.AwsCostExplorerSpend.With(labels).Add(PricePerRequest)
+	}
+
+	return nil
+}
+
 func (r *CostExplorerRepository) costExplorerClient() *awsce.Client {
 	return costexplorer.GetClient(r.client)
 }
@@ -70,6 +110,10 @@ func (r *CostExplorerRepository) GetCostAndUsage(query *awsce.GetCostAndUsageInp
 		default:
 		}
 
+		if err := r.billable("GetCostAndUsage"); err != nil {
+			return nil, err
+		}
+
 		if metrics.AwsMetricsEnabled {
 			metrics.AwsApiRequests.With(r.promLabels("GetCostAndUsage", ccfg.ResourceTypeCostAndUsage)).Inc()
 		}
@@ -149,6 +193,78 @@ type CostQuery struct {
 
 	// BillingViewArn optionally scopes the query to a billing view.
 	BillingViewArn string
+
+	// AllowOpenWindow lets the window reach into the current hour (HOURLY),
+	// day (DAILY) or month (MONTHLY). Such a result changes until the period closes, so
+	// it is refused by default: cached it goes stale, uncached it is billed on
+	// every refresh.
+	AllowOpenWindow bool
+}
+
+// Rounded returns the query with its window widened to whole periods of the
+// granularity: hours for HOURLY, UTC days for DAILY and months for MONTHLY.
+// Start is rounded down and End up, so the period End falls in is kept. The
+// API reads the window at that resolution anyway; rounding makes queries built
+// from time.Now() share a cache key until the boundary passes.
+func (q CostQuery) Rounded() CostQuery {
+	q.Start = roundDown(q.Granularity, q.Start)
+	q.End = roundUp(q.Granularity, q.End)
+
+	return q
+}
+
+// roundDown truncates t to the hour for HOURLY granularity, to the month for
+// MONTHLY and to the UTC day otherwise.
+func roundDown(granularity types.Granularity, t time.Time) time.Time {
+	t = t.UTC()
+
+	switch granularity {
+	case types.GranularityHourly:
+		return t.Truncate(time.Hour)
+	case types.GranularityMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return t.Truncate(24 * time.Hour)
+}
+
+// roundUp returns t when it is on a boundary of the granularity, else the
+// next boundary.
+func roundUp(granularity types.Granularity, t time.Time) time.Time {
+	down := roundDown(granularity, t)
+	if down.Equal(t) {
+		return down
+	}
+
+	switch granularity {
+	case types.GranularityHourly:
+		return down.Add(time.Hour)
+	case types.GranularityMonthly:
+		return down.AddDate(0, 1, 0)
+	}
+
+	return down.AddDate(0, 0, 1)
+}
+
+// windowOpen reports whether a window ending at end, before rounding, reaches
+// into the hour (HOURLY), month (MONTHLY) or day that has not closed yet.
+func windowOpen(granularity types.Granularity, end, now time.Time) bool {
+	return end.After(roundDown(granularity, now))
+}
+
+// costQueryKey has the fields of CostQuery without its Hash method, so Hash
+// can render it structurally without recursing.
+type costQueryKey CostQuery
+
+// Hash implements cache.Hashable: the key is built from the rounded query.
+func (q CostQuery) Hash() string {
+	return cache.Key("CostQuery", costQueryKey(q.Rounded()))
+}
+
+// open reports whether the window, before rounding, ends after the start of
+// the current period, i.e. covers a period that has not closed yet.
+func (q CostQuery) open(now time.Time) bool {
+	return windowOpen(q.Granularity, q.End, now)
 }
 
 // buildFilter combines the Services, Tags, Filters rows and explicit Filter of
@@ -169,14 +285,18 @@ func (q CostQuery) buildFilter() *types.Expression {
 // timePeriod renders the query window in the layout the requested granularity
 // expects: HOURLY takes a timestamp, DAILY and MONTHLY take a plain date.
 func (q CostQuery) timePeriod() *types.DateInterval {
+	return dateInterval(q.Granularity, q.Start, q.End)
+}
+
+func dateInterval(granularity types.Granularity, start, end time.Time) *types.DateInterval {
 	layout := dateLayout
-	if q.Granularity == types.GranularityHourly {
+	if granularity == types.GranularityHourly {
 		layout = dateTimeLayout
 	}
 
 	return &types.DateInterval{
-		Start: aws.String(q.Start.UTC().Format(layout)),
-		End:   aws.String(q.End.UTC().Format(layout)),
+		Start: aws.String(start.UTC().Format(layout)),
+		End:   aws.String(end.UTC().Format(layout)),
 	}
 }
 
@@ -215,9 +335,16 @@ func (q CostQuery) Validate() error {
 	return nil
 }
 
-// GetCostAndUsageByQuery validates the high-level CostQuery, builds a
-// GetCostAndUsageInput from it and delegates to GetCostAndUsage.
+// GetCostAndUsageByQuery rounds and validates the high-level CostQuery, builds
+// a GetCostAndUsageInput from it and delegates to GetCostAndUsage. Windows that
+// have not closed yet are refused unless the query sets AllowOpenWindow; the
+// cached repository answers a query it holds before reaching this check.
 func (r *CostExplorerRepository) GetCostAndUsageByQuery(q CostQuery) (*CostAndUsage, error) {
+	if !q.AllowOpenWindow && q.open(time.Now()) {
+		return nil, errors.Errorf("cost query window ends %s, in a period that has not closed yet; set AllowOpenWindow to query it anyway", q.End.UTC().Format(time.RFC3339))
+	}
+
+	q = q.Rounded()
 	if err := q.Validate(); err != nil {
 		return nil, err
 	}
@@ -260,6 +387,10 @@ func (r *CostExplorerRepository) GetCostAndUsageByPeriod(
 func (r *CostExplorerRepository) GetCostForecast(query *awsce.GetCostForecastInput) (*awsce.GetCostForecastOutput, error) {
 	start := time.Now()
 
+	if err := r.billable("GetCostForecast"); err != nil {
+		return nil, err
+	}
+
 	if metrics.AwsMetricsEnabled {
 		metrics.AwsApiRequests.With(r.promLabels("GetCostForecast", ccfg.ResourceTypeCostForecast)).Inc()
 	}
@@ -298,6 +429,10 @@ func (r *CostExplorerRepository) GetDimensionValues(query *awsce.GetDimensionVal
 		default:
 		}
 
+		if err := r.billable("GetDimensionValues"); err != nil {
+			return nil, err
+		}
+
 		if metrics.AwsMetricsEnabled {
 			metrics.AwsApiRequests.With(r.promLabels("GetDimensionValues", ccfg.ResourceTypeCostDimensionValue)).Inc()
 		}
@@ -335,3 +470,134 @@ func (r *CostExplorerRepository) GetDimensionValues(query *awsce.GetDimensionVal
 
 	return result, nil
 }
+
+// GetAnomalies retrieves the anomalies Cost Anomaly Detection found in the
+// requested date interval, following NextPageToken pagination.
+func (r *CostExplorerRepository) GetAnomalies(query *awsce.GetAnomaliesInput) ([]types.Anomaly, error) {
+	start := time.Now()
+	var result []types.Anomaly
+
+	p := awsce.NewGetAnomaliesPaginator(r.costExplorerClient(), query)
+	for p.HasMorePages() {
+		if err := r.billable("GetAnomalies"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetAnomalies", ccfg.ResourceTypeCostAnomaly)).Inc()
+		}
+
+		output, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetAnomalies", ccfg.ResourceTypeCostAnomaly)).Inc()
+			}
+
+			return result, errors.New(err)
+		}
+
+		result = append(result, output.Anomalies...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetAnomalies", ccfg.ResourceTypeCostAnomaly)).
+			Add(float64(len(result)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetAnomalies", ccfg.ResourceTypeCostAnomaly)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetAnomaliesByPeriod returns the anomalies detected between start and end,
+// optionally restricted to one anomaly monitor.
+func (r *CostExplorerRepository) GetAnomaliesByPeriod(start, end time.Time, monitorArn string) ([]types.Anomaly, error) {
+	query := &awsce.GetAnomaliesInput{
+		DateInterval: &types.AnomalyDateInterval{
+			StartDate: aws.String(start.UTC().Format(dateLayout)),
+			EndDate:   aws.String(end.UTC().Format(dateLayout)),
+		},
+	}
+
+	if monitorArn != "" {
+		query.MonitorArn = aws.String(monitorArn)
+	}
+
+	return r.GetAnomalies(query)
+}
+
+// GetRightsizingRecommendation retrieves EC2 rightsizing recommendations,
+// following NextPageToken pagination.
+func (r *CostExplorerRepository) GetRightsizingRecommendation(query *awsce.GetRightsizingRecommendationInput) (*RightsizingRecommendations, error) {
+	start := time.Now()
+
+	result := &RightsizingRecommendations{}
+	nextToken := query.NextPageToken
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		default:
+		}
+
+		if err := r.billable("GetRightsizingRecommendation"); err != nil {
+			return nil, err
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetRightsizingRecommendation", ccfg.ResourceTypeRightsizingRecommendation)).Inc()
+		}
+
+		page := *query
+		page.NextPageToken = nextToken
+
+		output, err := r.costExplorerClient().GetRightsizingRecommendation(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetRightsizingRecommendation", ccfg.ResourceTypeRightsizingRecommendation)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		result.Configuration = output.Configuration
+		result.Metadata = output.Metadata
+		result.Summary = output.Summary
+		result.Recommendations = append(result.Recommendations, output.RightsizingRecommendations...)
+
+		if output.NextPageToken == nil {
+			break
+		}
+
+		nextToken = output.NextPageToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetRightsizingRecommendation", ccfg.ResourceTypeRightsizingRecommendation)).
+			Add(float64(len(result.Recommendations)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetRightsizingRecommendation", ccfg.ResourceTypeRightsizingRecommendation)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
+
+// GetRightsizingRecommendationByTarget retrieves EC2 rightsizing
+// recommendations within the same instance family or across families. With
+// benefitsConsidered, savings account for Savings Plans and reservations.
+func (r *CostExplorerRepository) GetRightsizingRecommendationByTarget(target types.RecommendationTarget, benefitsConsidered bool) (*RightsizingRecommendations, error) {
+	return r.GetRightsizingRecommendation(&awsce.GetRightsizingRecommendationInput{
+		Service: aws.String(RightsizingService),
+		Configuration: &types.RightsizingRecommendationConfiguration{
+			RecommendationTarget: target,
+			BenefitsConsidered:   benefitsConsidered,
+		},
+	})
+}
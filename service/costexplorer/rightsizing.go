@@ -0,0 +1,95 @@
+package costexplorer
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/shopspring/decimal"
+)
+
+// RightsizingService is the only service GetRightsizingRecommendation covers.
+const RightsizingService = "AmazonEC2"
+
+// RightsizingRecommendations is the accumulated GetRightsizingRecommendation
+// result.
+type RightsizingRecommendations struct {
+	Configuration   *types.RightsizingRecommendationConfiguration
+	Metadata        *types.RightsizingRecommendationMetadata
+	Summary         *types.RightsizingRecommendationSummary
+	Recommendations []types.RightsizingRecommendation
+}
+
+// RightsizingTarget returns the instance a Modify recommendation moves to: its
+// default target, or the first one when none is marked default. It reports
+// false for Terminate recommendations.
+func RightsizingTarget(rec types.RightsizingRecommendation) (types.TargetInstance, bool) {
+	if rec.ModifyRecommendationDetail == nil {
+		return types.TargetInstance{}, false
+	}
+
+	targets := rec.ModifyRecommendationDetail.TargetInstances
+	for _, target := range targets {
+		if target.DefaultTargetInstance {
+			return target, true
+		}
+	}
+
+	if len(targets) > 0 {
+		return targets[0], true
+	}
+
+	return types.TargetInstance{}, false
+}
+
+// RightsizingSaving returns the estimated monthly saving of a recommendation
+// and its currency, from the default target for Modify and from the terminate
+// detail otherwise.
+func RightsizingSaving(rec types.RightsizingRecommendation) (decimal.Decimal, string) {
+	if target, ok := RightsizingTarget(rec); ok {
+		return parseDecimal(target.EstimatedMonthlySavings), aws.ToString(target.CurrencyCode)
+	}
+
+	if rec.TerminateRecommendationDetail != nil {
+		return parseDecimal(rec.TerminateRecommendationDetail.EstimatedMonthlySavings),
+			aws.ToString(rec.TerminateRecommendationDetail.CurrencyCode)
+	}
+
+	return decimal.Zero, ""
+}
+
+// InstanceType returns the EC2 instance type of resource details, "" when the
+// details are not about EC2.
+func InstanceType(details *types.ResourceDetails) string {
+	if details == nil || details.EC2ResourceDetails == nil {
+		return ""
+	}
+
+	return aws.ToString(details.EC2ResourceDetails.InstanceType)
+}
+
+// InstanceRegion returns the region of EC2 resource details, "" when the
+// details are not about EC2 or name an unknown region. Cost Explorer reports
+// the region by its description, e.g. "EU (Ireland)", rather than its code.
+func InstanceRegion(details *types.ResourceDetails) ptypes.AwsRegion {
+	if details == nil || details.EC2ResourceDetails == nil {
+		return ""
+	}
+
+	name := aws.ToString(details.EC2ResourceDetails.Region)
+	if name == "" {
+		return ""
+	}
+
+	// the price list and Cost Explorer still say "EU" where the console says
+	// "Europe"
+	normalized := strings.Replace(name, "EU (", "Europe (", 1)
+	for region, data := range ptypes.GetAwsRegionData() {
+		if region.String() == name || data["description"] == normalized {
+			return region
+		}
+	}
+
+	return ""
+}
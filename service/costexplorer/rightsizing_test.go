@@ -0,0 +1,23 @@
+package costexplorer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceRegion(t *testing.T) {
+	details := func(region string) *types.ResourceDetails {
+		return &types.ResourceDetails{EC2ResourceDetails: &types.EC2ResourceDetails{Region: aws.String(region)}}
+	}
+
+	assert.Equal(t, ptypes.AwsRegion("eu-west-1"), InstanceRegion(details("EU (Ireland)")))
+	assert.Equal(t, ptypes.AwsRegion("eu-west-1"), InstanceRegion(details("Europe (Ireland)")))
+	assert.Equal(t, ptypes.AwsRegion("us-east-1"), InstanceRegion(details("US East (N. Virginia)")))
+	assert.Equal(t, ptypes.AwsRegion("us-west-2"), InstanceRegion(details("us-west-2")))
+	assert.Empty(t, InstanceRegion(details("Nowhere (Atlantis)")))
+	assert.Empty(t, InstanceRegion(nil))
+}
@@ -0,0 +1,455 @@
+package costexplorer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/go-errors/errors"
+	"github.com/shopspring/decimal"
+)
+
+// OthersKey is the group key TopNWithOthers gives the bucket that collects
+// every group outside the top N.
+const OthersKey = "Others"
+
+// Amount is one metric value as Cost Explorer reports it: an exact decimal and
+// its unit ("USD", "Hrs", "N/A", ...).
+type Amount struct {
+	Value decimal.Decimal `json:"value"`
+	Unit  string          `json:"unit"`
+}
+
+// NewAmount parses a Cost Explorer metric value. A missing or malformed amount
+// reads as zero.
+func NewAmount(v types.MetricValue) Amount {
+	value, err := decimal.NewFromString(aws.ToString(v.Amount))
+	if err != nil {
+		value = decimal.Zero
+	}
+
+	return Amount{Value: value, Unit: aws.ToString(v.Unit)}
+}
+
+// Add sums two amounts. The unit of a zero-valued side gives way to the other,
+// so summing into an empty Amount keeps the reported unit.
+func (a Amount) Add(b Amount) Amount {
+	unit := a.Unit
+	if unit == "" {
+		unit = b.Unit
+	}
+
+	return Amount{Value: a.Value.Add(b.Value), Unit: unit}
+}
+
+// CostRow is one (period, group) cell of a GetCostAndUsage result. Keys holds
+// one value per CostTable.GroupBy entry, in the same order, with the "Key$"
+// prefix of tag and cost category groups removed; an empty value means the
+// resource carried no such tag or category. Rows of an ungrouped query have no
+// keys and carry the period totals.
+type CostRow struct {
+	Start     string            `json:"start"`
+	End       string            `json:"end"`
+	Keys      []string          `json:"keys,omitempty"`
+	Metrics   map[string]Amount `json:"metrics"`
+	Estimated bool              `json:"estimated"`
+}
+
+// Group returns the row's keys joined into a single label, e.g.
+// "Amazon EC2 / prod".
+func (r CostRow) Group() string {
+	return strings.Join(r.Keys, " / ")
+}
+
+// Metric returns the amount of a metric, zero when the row does not carry it.
+func (r CostRow) Metric(metric string) Amount {
+	return r.Metrics[metric]
+}
+
+// CostTable is the typed form of CostAndUsage: one row per period and group,
+// ordered as the API returned them.
+type CostTable struct {
+	GroupBy []types.GroupDefinition `json:"-"`
+	Rows    []CostRow               `json:"rows"`
+}
+
+// Table converts the raw result into a CostTable. Grouped periods yield one row
+// per group; periods without groups yield a single row holding their totals.
+func (c CostAndUsage) Table() CostTable {
+	table := CostTable{GroupBy: c.GroupDefinitions}
+
+	for _, result := range c.ResultsByTime {
+		var start, end string
+		if result.TimePeriod != nil {
+			start = aws.ToString(result.TimePeriod.Start)
+			end = aws.ToString(result.TimePeriod.End)
+		}
+
+		if len(result.Groups) == 0 {
+			if len(result.Total) > 0 {
+				table.Rows = append(table.Rows, CostRow{
+					Start:     start,
+					End:       end,
+					Metrics:   metricAmounts(result.Total),
+					Estimated: result.Estimated,
+				})
+			}
+			continue
+		}
+
+		for _, group := range result.Groups {
+			table.Rows = append(table.Rows, CostRow{
+				Start:     start,
+				End:       end,
+				Keys:      groupKeys(c.GroupDefinitions, group.Keys),
+				Metrics:   metricAmounts(group.Metrics),
+				Estimated: result.Estimated,
+			})
+		}
+	}
+
+	return table
+}
+
+func metricAmounts(values map[string]types.MetricValue) map[string]Amount {
+	amounts := make(map[string]Amount, len(values))
+	for metric, v := range values {
+		amounts[metric] = NewAmount(v)
+	}
+
+	return amounts
+}
+
+// groupKeys strips the "Key$" prefix the API puts on tag and cost category
+// group keys, leaving dimension values as they are.
+func groupKeys(definitions []types.GroupDefinition, keys []string) []string {
+	out := make([]string, len(keys))
+	for i, key := range keys {
+		out[i] = key
+		if i >= len(definitions) || definitions[i].Type == types.GroupDefinitionTypeDimension {
+			continue
+		}
+
+		if _, value, found := strings.Cut(key, "$"); found {
+			out[i] = value
+		}
+	}
+
+	return out
+}
+
+// Columns returns the group column names: the GroupBy keys, e.g. "SERVICE",
+// "Env".
+func (t CostTable) Columns() []string {
+	columns := make([]string, len(t.GroupBy))
+	for i, definition := range t.GroupBy {
+		columns[i] = aws.ToString(definition.Key)
+	}
+
+	return columns
+}
+
+// Periods returns the distinct period starts in table order.
+func (t CostTable) Periods() []string {
+	var periods []string
+	seen := map[string]bool{}
+	for _, row := range t.Rows {
+		if !seen[row.Start] {
+			seen[row.Start] = true
+			periods = append(periods, row.Start)
+		}
+	}
+
+	return periods
+}
+
+// Groups returns the distinct group labels in table order.
+func (t CostTable) Groups() []string {
+	var groups []string
+	seen := map[string]bool{}
+	for _, row := range t.Rows {
+		if label := row.Group(); !seen[label] {
+			seen[label] = true
+			groups = append(groups, label)
+		}
+	}
+
+	return groups
+}
+
+// Total sums a metric over every row.
+func (t CostTable) Total(metric string) Amount {
+	var total Amount
+	for _, row := range t.Rows {
+		total = total.Add(row.Metric(metric))
+	}
+
+	return total
+}
+
+// Estimated reports whether any period of the table is not final yet.
+func (t CostTable) Estimated() bool {
+	for _, row := range t.Rows {
+		if row.Estimated {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rank orders the group labels by their total of a metric over all periods,
+// highest first; ties keep table order.
+func (t CostTable) rank(metric string) []string {
+	totals := map[string]decimal.Decimal{}
+	for _, row := range t.Rows {
+		label := row.Group()
+		totals[label] = totals[label].Add(row.Metric(metric).Value)
+	}
+
+	groups := t.Groups()
+	sort.SliceStable(groups, func(i, j int) bool {
+		return totals[groups[i]].GreaterThan(totals[groups[j]])
+	})
+
+	return groups
+}
+
+// TopN keeps the rows of the n groups with the highest total of a metric over
+// all periods and drops the rest.
+func (t CostTable) TopN(metric string, n int) CostTable {
+	keep := t.top(metric, n)
+
+	out := CostTable{GroupBy: t.GroupBy}
+	for _, row := range t.Rows {
+		if keep[row.Group()] {
+			out.Rows = append(out.Rows, row)
+		}
+	}
+
+	return out
+}
+
+// TopNWithOthers is TopN, except the groups outside the top n are not dropped
+// but summed, per period and for every metric, into one row whose keys are all
+// OthersKey. The table total is therefore unchanged.
+func (t CostTable) TopNWithOthers(metric string, n int) CostTable {
+	keep := t.top(metric, n)
+
+	out := CostTable{GroupBy: t.GroupBy}
+	others := map[string]int{}
+	for _, row := range t.Rows {
+		if keep[row.Group()] {
+			out.Rows = append(out.Rows, row)
+			continue
+		}
+
+		i, found := others[row.Start]
+		if !found {
+			keys := make([]string, len(row.Keys))
+			for k := range keys {
+				keys[k] = OthersKey
+			}
+
+			out.Rows = append(out.Rows, CostRow{Start: row.Start, End: row.End, Keys: keys, Metrics: map[string]Amount{}})
+			i = len(out.Rows) - 1
+			others[row.Start] = i
+		}
+
+		bucket := &out.Rows[i]
+		for name, amount := range row.Metrics {
+			bucket.Metrics[name] = bucket.Metrics[name].Add(amount)
+		}
+		bucket.Estimated = bucket.Estimated || row.Estimated
+	}
+
+	return out
+}
+
+func (t CostTable) top(metric string, n int) map[string]bool {
+	ranked := t.rank(metric)
+	if n < len(ranked) {
+		ranked = ranked[:max(n, 0)]
+	}
+
+	keep := make(map[string]bool, len(ranked))
+	for _, group := range ranked {
+		keep[group] = true
+	}
+
+	return keep
+}
+
+// Pivot is a two-dimensional view of one metric: Values[i][j] is the amount of
+// Rows[i] in Columns[j], zero where the table has no such cell.
+type Pivot struct {
+	Metric  string              `json:"metric"`
+	Unit    string              `json:"unit"`
+	Rows    []string            `json:"rows"`
+	Columns []string            `json:"columns"`
+	Values  [][]decimal.Decimal `json:"values"`
+}
+
+// ByPeriod pivots a metric with one row per period and one column per group.
+func (t CostTable) ByPeriod(metric string) Pivot {
+	return t.pivot(metric, t.Periods(), t.Groups(), func(row CostRow) (string, string) {
+		return row.Start, row.Group()
+	})
+}
+
+// ByGroup pivots a metric with one row per group and one column per period.
+func (t CostTable) ByGroup(metric string) Pivot {
+	return t.pivot(metric, t.Groups(), t.Periods(), func(row CostRow) (string, string) {
+		return row.Group(), row.Start
+	})
+}
+
+func (t CostTable) pivot(metric string, rows, columns []string, cell func(CostRow) (string, string)) Pivot {
+	rowIndex := index(rows)
+	columnIndex := index(columns)
+
+	p := Pivot{Metric: metric, Rows: rows, Columns: columns, Values: make([][]decimal.Decimal, len(rows))}
+	for i := range p.Values {
+		p.Values[i] = make([]decimal.Decimal, len(columns))
+	}
+
+	for _, row := range t.Rows {
+		amount, ok := row.Metrics[metric]
+		if !ok {
+			continue
+		}
+
+		r, c := cell(row)
+		i, j := rowIndex[r], columnIndex[c]
+		p.Values[i][j] = p.Values[i][j].Add(amount.Value)
+		if p.Unit == "" {
+			p.Unit = amount.Unit
+		}
+	}
+
+	return p
+}
+
+func index(values []string) map[string]int {
+	out := make(map[string]int, len(values))
+	for i, v := range values {
+		out[v] = i
+	}
+
+	return out
+}
+
+// RowTotals sums each pivot row across its columns.
+func (p Pivot) RowTotals() []decimal.Decimal {
+	totals := make([]decimal.Decimal, len(p.Rows))
+	for i, values := range p.Values {
+		totals[i] = decimal.Sum(decimal.Zero, values...)
+	}
+
+	return totals
+}
+
+// WriteCSV writes the pivot as a grid: a header of Columns, then one line per
+// row starting with its label.
+func (p Pivot) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(append([]string{""}, p.Columns...)); err != nil {
+		return errors.New(err)
+	}
+
+	for i, label := range p.Rows {
+		line := make([]string, 0, len(p.Columns)+1)
+		line = append(line, label)
+		for _, v := range p.Values[i] {
+			line = append(line, v.String())
+		}
+
+		if err := out.Write(line); err != nil {
+			return errors.New(err)
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// metrics returns the metric names present in the table, sorted.
+func (t CostTable) metrics() []string {
+	seen := map[string]bool{}
+	for _, row := range t.Rows {
+		for name := range row.Metrics {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WriteCSV writes the table in long form, one line per row and metric:
+// start, end, one column per group key, metric, amount, unit, estimated.
+// Without metric names every metric present in the table is written.
+func (t CostTable) WriteCSV(w io.Writer, metrics ...string) error {
+	if len(metrics) == 0 {
+		metrics = t.metrics()
+	}
+
+	out := csv.NewWriter(w)
+
+	header := append([]string{"start", "end"}, t.Columns()...)
+	header = append(header, "metric", "amount", "unit", "estimated")
+	if err := out.Write(header); err != nil {
+		return errors.New(err)
+	}
+
+	for _, row := range t.Rows {
+		for _, metric := range metrics {
+			amount, ok := row.Metrics[metric]
+			if !ok {
+				continue
+			}
+
+			line := append([]string{row.Start, row.End}, row.Keys...)
+			line = append(line, metric, amount.Value.String(), amount.Unit, strconv.FormatBool(row.Estimated))
+			if err := out.Write(line); err != nil {
+				return errors.New(err)
+			}
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// MarshalJSON adds the group column names to the rows, so the keys of each row
+// can be read without the GroupDefinitions. Amounts are encoded as decimal
+// strings to keep their precision.
+func (t CostTable) MarshalJSON() ([]byte, error) {
+	rows := t.Rows
+	if rows == nil {
+		rows = []CostRow{}
+	}
+
+	return json.Marshal(struct {
+		Columns []string  `json:"columns"`
+		Rows    []CostRow `json:"rows"`
+	}{t.Columns(), rows})
+}
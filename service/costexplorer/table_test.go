@@ -0,0 +1,135 @@
+package costexplorer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func group(amount string, keys ...string) types.Group {
+	return types.Group{
+		Keys: keys,
+		Metrics: map[string]types.MetricValue{
+			MetricUnblendedCost: {Amount: aws.String(amount), Unit: aws.String("USD")},
+		},
+	}
+}
+
+func period(start, end string, estimated bool, groups ...types.Group) types.ResultByTime {
+	return types.ResultByTime{
+		TimePeriod: &types.DateInterval{Start: aws.String(start), End: aws.String(end)},
+		Estimated:  estimated,
+		Groups:     groups,
+	}
+}
+
+func groupedCostAndUsage() CostAndUsage {
+	return CostAndUsage{
+		GroupDefinitions: []types.GroupDefinition{GroupByService(), GroupByTag("Env")},
+		ResultsByTime: []types.ResultByTime{
+			period("2026-07-01", "2026-08-01", false,
+				group("100.10", "Amazon EC2", "Env$prod"),
+				group("20.20", "Amazon S3", "Env$"),
+				group("0.30", "AWS Lambda", "Env$prod"),
+			),
+			period("2026-08-01", "2026-09-01", true,
+				group("110.00", "Amazon EC2", "Env$prod"),
+				group("5.05", "AWS Lambda", "Env$prod"),
+			),
+		},
+	}
+}
+
+func d(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestCostAndUsageTable(t *testing.T) {
+	table := groupedCostAndUsage().Table()
+
+	require.Len(t, table.Rows, 5)
+	assert.Equal(t, []string{"SERVICE", "Env"}, table.Columns())
+	assert.Equal(t, []string{"Amazon EC2", "prod"}, table.Rows[0].Keys)
+	assert.Equal(t, []string{"Amazon S3", ""}, table.Rows[1].Keys)
+	assert.Equal(t, "2026-08-01", table.Rows[3].Start)
+	assert.True(t, table.Rows[3].Estimated)
+	assert.True(t, table.Estimated())
+
+	total := table.Total(MetricUnblendedCost)
+	assert.True(t, d("235.65").Equal(total.Value), total.Value.String())
+	assert.Equal(t, "USD", total.Unit)
+}
+
+func TestCostAndUsageTable_Ungrouped(t *testing.T) {
+	cu := CostAndUsage{ResultsByTime: []types.ResultByTime{resultByTime("10.50", "USD"), resultByTime("4.25", "USD")}}
+
+	table := cu.Table()
+	require.Len(t, table.Rows, 2)
+	assert.Empty(t, table.Rows[0].Keys)
+	assert.True(t, d("14.75").Equal(table.Total(MetricUnblendedCost).Value))
+}
+
+func TestCostTablePivots(t *testing.T) {
+	table := groupedCostAndUsage().Table()
+
+	byPeriod := table.ByPeriod(MetricUnblendedCost)
+	assert.Equal(t, []string{"2026-07-01", "2026-08-01"}, byPeriod.Rows)
+	assert.Equal(t, []string{"Amazon EC2 / prod", "Amazon S3 / ", "AWS Lambda / prod"}, byPeriod.Columns)
+	assert.True(t, decimal.Zero.Equal(byPeriod.Values[1][1]), "S3 has no August cell")
+	assert.True(t, d("115.05").Equal(byPeriod.RowTotals()[1]))
+
+	byGroup := table.ByGroup(MetricUnblendedCost)
+	assert.Equal(t, byPeriod.Columns, byGroup.Rows)
+	assert.True(t, d("210.10").Equal(byGroup.RowTotals()[0]))
+	assert.Equal(t, "USD", byGroup.Unit)
+}
+
+func TestCostTableTopN(t *testing.T) {
+	table := groupedCostAndUsage().Table()
+
+	top := table.TopN(MetricUnblendedCost, 1)
+	assert.Equal(t, []string{"Amazon EC2 / prod"}, top.Groups())
+
+	// S3 (20.20) outranks Lambda (5.35) over both periods
+	withOthers := table.TopNWithOthers(MetricUnblendedCost, 2)
+	assert.Equal(t, []string{"Amazon EC2 / prod", "Amazon S3 / ", "Others / Others"}, withOthers.Groups())
+	assert.True(t, table.Total(MetricUnblendedCost).Value.Equal(withOthers.Total(MetricUnblendedCost).Value))
+
+	august := withOthers.ByPeriod(MetricUnblendedCost)
+	assert.True(t, d("5.05").Equal(august.Values[1][2]))
+}
+
+func TestCostTableWriteCSV(t *testing.T) {
+	cu := groupedCostAndUsage()
+	cu.ResultsByTime = cu.ResultsByTime[:1]
+
+	var buf bytes.Buffer
+	require.NoError(t, cu.Table().TopN(MetricUnblendedCost, 1).WriteCSV(&buf))
+
+	assert.Equal(t,
+		"start,end,SERVICE,Env,metric,amount,unit,estimated\n"+
+			"2026-07-01,2026-08-01,Amazon EC2,prod,UnblendedCost,100.1,USD,false\n",
+		buf.String())
+}
+
+func TestCostTableMarshalJSON(t *testing.T) {
+	data, err := json.Marshal(groupedCostAndUsage().Table().TopN(MetricUnblendedCost, 1))
+	require.NoError(t, err)
+
+	var decoded struct {
+		Columns []string
+		Rows    []CostRow
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	assert.Equal(t, []string{"SERVICE", "Env"}, decoded.Columns)
+	require.Len(t, decoded.Rows, 2)
+	assert.True(t, d("100.10").Equal(decoded.Rows[0].Metric(MetricUnblendedCost).Value))
+	assert.Contains(t, string(data), `"value":"100.1"`)
+}
@@ -13,6 +13,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/dynamodb"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -93,7 +94,10 @@ func (r *DynamoDBRepository) ListTablesByInput(query *awsdynamo.ListTablesInput)
 			return tables, errors.New(err)
 		}
 
-		tags, _ := r.GetTableTags(tableOutput.Table)
+		var tags []types.Tag
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetTableTags(tableOutput.Table)
+		}
 		table := NewTable(r.client, tableOutput.Table, tags)
 		tables = append(tables, table)
 	}
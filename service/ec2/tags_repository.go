@@ -0,0 +1,83 @@
+package ec2
+
+import (
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+)
+
+// CreateResourceTags adds or overwrites tags on any EC2 resource ID. The
+// resource type only labels the metrics; CreateTags itself accepts mixed IDs.
+func (r *Ec2Repository) CreateResourceTags(resourceType cfg.ResourceType, tagsInput *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	return r.createTags("CreateResourceTags", resourceType, tagsInput)
+}
+
+// DeleteResourceTags removes tags from any EC2 resource ID. A tag given without
+// a value is removed whatever its current value.
+func (r *Ec2Repository) DeleteResourceTags(resourceType cfg.ResourceType, tagsInput *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput, error) {
+	return r.deleteTags("DeleteResourceTags", resourceType, tagsInput)
+}
+
+// createTags is the CreateTags call behind CreateResourceTags; method names
+// the caller in the duration metric.
+func (r *Ec2Repository) createTags(method string, resourceType cfg.ResourceType, tagsInput *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	if tagsInput == nil {
+		return &ec2.CreateTagsOutput{}, nil
+	}
+
+	start := time.Now()
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("CreateTags", resourceType)).Inc()
+	}
+
+	output, err := r.ec2Client().CreateTags(r.ctx, tagsInput)
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("CreateTags", resourceType)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels(method, resourceType)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return output, nil
+}
+
+// deleteTags is the DeleteTags call behind DeleteResourceTags.
+func (r *Ec2Repository) deleteTags(method string, resourceType cfg.ResourceType, tagsInput *ec2.DeleteTagsInput) (*ec2.DeleteTagsOutput, error) {
+	if tagsInput == nil {
+		return &ec2.DeleteTagsOutput{}, nil
+	}
+
+	start := time.Now()
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("DeleteTags", resourceType)).Inc()
+	}
+
+	output, err := r.ec2Client().DeleteTags(r.ctx, tagsInput)
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("DeleteTags", resourceType)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels(method, resourceType)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return output, nil
+}
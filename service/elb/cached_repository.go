@@ -39,6 +39,34 @@ func (c *LoadBalancerRepositoryCached) GetLoadBalancerTags(lb types.LoadBalancer
 	return r0, r1
 }
 
+// GetLoadBalancerTargetCount returns cached results when available, otherwise delegates to the underlying repository.
+func (c *LoadBalancerRepositoryCached) GetLoadBalancerTargetCount(loadBalancerArn string) (int, error) {
+	cacheKey := cache.Key("GetLoadBalancerTargetCount", loadBalancerArn)
+	var cached int
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetLoadBalancerTargetCount(loadBalancerArn)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetTargetHealth returns cached results when available, otherwise delegates to the underlying repository.
+func (c *LoadBalancerRepositoryCached) GetTargetHealth(targetGroupArn string) ([]types.TargetHealthDescription, error) {
+	cacheKey := cache.Key("GetTargetHealth", targetGroupArn)
+	var cached []types.TargetHealthDescription
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetTargetHealth(targetGroupArn)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListLoadBalancersAll returns cached results when available, otherwise delegates to the underlying repository.
 func (c *LoadBalancerRepositoryCached) ListLoadBalancersAll() ([]LoadBalancer, error) {
 	cacheKey := cache.Key("ListLoadBalancersAll")
@@ -66,3 +94,17 @@ func (c *LoadBalancerRepositoryCached) ListLoadBalancersByInput(query *awselbv2.
 	}
 	return r0, r1
 }
+
+// ListTargetGroupsByLoadBalancer returns cached results when available, otherwise delegates to the underlying repository.
+func (c *LoadBalancerRepositoryCached) ListTargetGroupsByLoadBalancer(loadBalancerArn string) ([]types.TargetGroup, error) {
+	cacheKey := cache.Key("ListTargetGroupsByLoadBalancer", loadBalancerArn)
+	var cached []types.TargetGroup
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListTargetGroupsByLoadBalancer(loadBalancerArn)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -13,6 +13,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/elasticloadbalancingv2"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/imunhatep/gocollection/slice"
 	"github.com/prometheus/client_golang/prometheus"
@@ -77,7 +78,10 @@ func (r *LoadBalancerRepository) ListLoadBalancersByInput(query *awselbv2.Descri
 	}
 
 	for _, v := range resp.LoadBalancers {
-		tags, _ := r.GetLoadBalancerTags(v)
+		var tags []types.Tag
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetLoadBalancerTags(v)
+		}
 
 		bucket := NewLoadBalancer(r.client, v, tags)
 		balancers = append(balancers, bucket)
@@ -0,0 +1,97 @@
+package elb
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awselbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+)
+
+// ListTargetGroupsByLoadBalancer returns the target groups a load balancer
+// forwards to.
+func (r *LoadBalancerRepository) ListTargetGroupsByLoadBalancer(loadBalancerArn string) ([]types.TargetGroup, error) {
+	start := time.Now()
+	var groups []types.TargetGroup
+
+	query := &awselbv2.DescribeTargetGroupsInput{LoadBalancerArn: aws.String(loadBalancerArn)}
+
+	p := awselbv2.NewDescribeTargetGroupsPaginator(r.elbv2Client(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeTargetGroups", cfg.ResourceTypeLoadBalancerV2)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeTargetGroups", cfg.ResourceTypeLoadBalancerV2)).Inc()
+			}
+
+			return groups, errors.New(err)
+		}
+
+		groups = append(groups, resp.TargetGroups...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListTargetGroupsByLoadBalancer", cfg.ResourceTypeLoadBalancerV2)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return groups, nil
+}
+
+// GetTargetHealth returns the targets registered with a target group and their
+// health state.
+func (r *LoadBalancerRepository) GetTargetHealth(targetGroupArn string) ([]types.TargetHealthDescription, error) {
+	start := time.Now()
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("DescribeTargetHealth", cfg.ResourceTypeLoadBalancerV2)).Inc()
+	}
+
+	resp, err := r.elbv2Client().DescribeTargetHealth(r.ctx, &awselbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(targetGroupArn),
+	})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("DescribeTargetHealth", cfg.ResourceTypeLoadBalancerV2)).Inc()
+		}
+
+		return []types.TargetHealthDescription{}, errors.New(err)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetTargetHealth", cfg.ResourceTypeLoadBalancerV2)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return resp.TargetHealthDescriptions, nil
+}
+
+// GetLoadBalancerTargetCount returns how many targets are registered across all
+// target groups of a load balancer. Zero means the balancer forwards nowhere.
+func (r *LoadBalancerRepository) GetLoadBalancerTargetCount(loadBalancerArn string) (int, error) {
+	groups, err := r.ListTargetGroupsByLoadBalancer(loadBalancerArn)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, group := range groups {
+		targets, err := r.GetTargetHealth(aws.ToString(group.TargetGroupArn))
+		if err != nil {
+			return count, err
+		}
+
+		count += len(targets)
+	}
+
+	return count, nil
+}
@@ -8,4 +8,5 @@ import "encoding/gob"
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
 	gob.Register(AbstractResource{})
+	gob.Register(ResourceIndex{})
 }
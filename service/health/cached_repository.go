@@ -25,6 +25,90 @@ func (r *HealthRepository) WithCache(dc *cache.DataCache) *HealthRepositoryCache
 	}
 }
 
+// ListAffectedAccountsForOrganization returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListAffectedAccountsForOrganization(eventArn string) ([]string, error) {
+	cacheKey := cache.Key("ListAffectedAccountsForOrganization", eventArn)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAffectedAccountsForOrganization(eventArn)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAffectedEntitiesByEvents returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListAffectedEntitiesByEvents(events []Event) ([]Event, error) {
+	cacheKey := cache.Key("ListAffectedEntitiesByEvents", events)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAffectedEntitiesByEvents(events)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAffectedEntitiesByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListAffectedEntitiesByInput(query *awshealth.DescribeAffectedEntitiesInput) ([]types.AffectedEntity, error) {
+	cacheKey := cache.Key("ListAffectedEntitiesByInput", query)
+	var cached []types.AffectedEntity
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAffectedEntitiesByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAffectedEntitiesForOrganizationByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListAffectedEntitiesForOrganizationByInput(query *awshealth.DescribeAffectedEntitiesForOrganizationInput) ([]types.AffectedEntity, error) {
+	cacheKey := cache.Key("ListAffectedEntitiesForOrganizationByInput", query)
+	var cached []types.AffectedEntity
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAffectedEntitiesForOrganizationByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListEventsAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListEventsAll() ([]Event, error) {
+	cacheKey := cache.Key("ListEventsAll")
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListEventsAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListEventsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListEventsByInput(query *awshealth.DescribeEventsInput) ([]Event, error) {
+	cacheKey := cache.Key("ListEventsByInput", query)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListEventsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListEventsDetailsByInput returns cached results when available, otherwise delegates to the underlying repository.
 func (c *HealthRepositoryCached) ListEventsDetailsByInput(query *awshealth.DescribeEventsInput) ([]types.EventDetails, error) {
 	cacheKey := cache.Key("ListEventsDetailsByInput", query)
@@ -38,3 +122,59 @@ func (c *HealthRepositoryCached) ListEventsDetailsByInput(query *awshealth.Descr
 	}
 	return r0, r1
 }
+
+// ListEventsOpen returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListEventsOpen() ([]Event, error) {
+	cacheKey := cache.Key("ListEventsOpen")
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListEventsOpen()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListOrganizationAffectedEntitiesByEvents returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListOrganizationAffectedEntitiesByEvents(events []Event) ([]Event, error) {
+	cacheKey := cache.Key("ListOrganizationAffectedEntitiesByEvents", events)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListOrganizationAffectedEntitiesByEvents(events)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListOrganizationEventsAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListOrganizationEventsAll() ([]Event, error) {
+	cacheKey := cache.Key("ListOrganizationEventsAll")
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListOrganizationEventsAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListOrganizationEventsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *HealthRepositoryCached) ListOrganizationEventsByInput(query *awshealth.DescribeEventsForOrganizationInput) ([]Event, error) {
+	cacheKey := cache.Key("ListOrganizationEventsByInput", query)
+	var cached []Event
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListOrganizationEventsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/health/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Event is an AWS Health event as seen by one account. Events of the
+// organizational view are split per affected account, so that AccountID is
+// always the account the event applies to.
+type Event struct {
+	service.AbstractResource
+	types.Event
+	Description string
+	Metadata    map[string]string
+	// Entities are the resources the event affects, when they were requested.
+	Entities []types.AffectedEntity
+}
+
+func NewEvent(client AwsClient, event types.Event) Event {
+	return newEvent(client.GetAccountID(), event)
+}
+
+// NewOrganizationEvent converts an event of the organizational view, as it
+// applies to accountID.
+func NewOrganizationEvent(accountID ptypes.AwsAccountID, event types.OrganizationEvent) Event {
+	return newEvent(accountID, types.Event{
+		Actionability:     event.Actionability,
+		Arn:               event.Arn,
+		EndTime:           event.EndTime,
+		EventScopeCode:    event.EventScopeCode,
+		EventTypeCategory: event.EventTypeCategory,
+		EventTypeCode:     event.EventTypeCode,
+		LastUpdatedTime:   event.LastUpdatedTime,
+		Personas:          event.Personas,
+		Region:            event.Region,
+		Service:           event.Service,
+		StartTime:         event.StartTime,
+		StatusCode:        event.StatusCode,
+	})
+}
+
+func newEvent(accountID ptypes.AwsAccountID, event types.Event) Event {
+	eventArn, _ := arn.Parse(aws.ToString(event.Arn))
+
+	return Event{
+		AbstractResource: service.AbstractResource{
+			AccountID: accountID,
+			Region:    ptypes.AwsRegion(aws.ToString(event.Region)),
+			ID:        aws.ToString(event.Arn),
+			ARN:       &eventArn,
+			CreatedAt: aws.ToTime(event.StartTime),
+			Type:      ccfg.ResourceTypeHealthEvent,
+		},
+		Event: event,
+	}
+}
+
+// WithDetails returns a copy carrying the description and metadata of
+// DescribeEventDetails.
+func (e Event) WithDetails(details types.EventDetails) Event {
+	if details.EventDescription != nil {
+		e.Description = aws.ToString(details.EventDescription.LatestDescription)
+	}
+	e.Metadata = details.EventMetadata
+
+	return e
+}
+
+// WithEntities returns a copy carrying the given affected entities.
+func (e Event) WithEntities(entities []types.AffectedEntity) Event {
+	e.Entities = entities
+	return e
+}
+
+// GetName returns the event type code, e.g. AWS_EC2_INSTANCE_RETIREMENT_SCHEDULED.
+func (e Event) GetName() string {
+	return aws.ToString(e.Event.EventTypeCode)
+}
+
+// GetTags returns no tags: Health events are not taggable.
+func (e Event) GetTags() map[string]string {
+	return map[string]string{}
+}
+
+func (e Event) GetService() string {
+	return aws.ToString(e.Event.Service)
+}
+
+func (e Event) GetEndTime() time.Time {
+	return aws.ToTime(e.Event.EndTime)
+}
+
+func (e Event) GetLastUpdatedTime() time.Time {
+	return aws.ToTime(e.Event.LastUpdatedTime)
+}
+
+// IsOpen reports an event that is ongoing or scheduled.
+func (e Event) IsOpen() bool {
+	return e.StatusCode == types.EventStatusCodeOpen || e.StatusCode == types.EventStatusCodeUpcoming
+}
+
+// IsAccountSpecific reports an event about resources of the account, as
+// opposed to a public event about a service in a region.
+func (e Event) IsAccountSpecific() bool {
+	return e.EventScopeCode == types.EventScopeCodeAccountSpecific
+}
+
+// GetAffectedEntityValues returns the value of each affected entity: a
+// resource ID such as an instance ID, or a resource ARN. EntityArn names the
+// Health entity record, not the resource.
+func (e Event) GetAffectedEntityValues() []string {
+	values := make([]string, 0, len(e.Entities))
+	for _, entity := range e.Entities {
+		values = append(values, aws.ToString(entity.EntityValue))
+	}
+
+	return values
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/health/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "us-east-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+const retirementArn = "arn:aws:health:eu-central-1::event/EC2/AWS_EC2_INSTANCE_RETIREMENT_SCHEDULED/AWS_EC2_INSTANCE_RETIREMENT_SCHEDULED_abc"
+
+func retirement() types.OrganizationEvent {
+	return types.OrganizationEvent{
+		Arn:            aws.String(retirementArn),
+		EventScopeCode: types.EventScopeCodeAccountSpecific,
+		EventTypeCode:  aws.String("AWS_EC2_INSTANCE_RETIREMENT_SCHEDULED"),
+		Region:         aws.String("eu-central-1"),
+		Service:        aws.String("EC2"),
+		StartTime:      aws.Time(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)),
+		StatusCode:     types.EventStatusCodeUpcoming,
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	event := NewEvent(mockClient{}, types.Event{
+		Arn:            aws.String(retirementArn),
+		EventScopeCode: types.EventScopeCodePublic,
+		EventTypeCode:  aws.String("AWS_EC2_OPERATIONAL_ISSUE"),
+		Region:         aws.String("eu-central-1"),
+		StatusCode:     types.EventStatusCodeClosed,
+	})
+
+	assert.Equal(t, ptypes.AwsAccountID("123456789012"), event.GetAccountID())
+	assert.Equal(t, ptypes.AwsRegion("eu-central-1"), event.GetRegion())
+	assert.Equal(t, ccfg.ResourceTypeHealthEvent, event.GetType())
+	assert.Equal(t, retirementArn, event.GetArn())
+	assert.Equal(t, "AWS_EC2_OPERATIONAL_ISSUE", event.GetName())
+	assert.Empty(t, event.GetTags())
+	assert.False(t, event.IsOpen())
+	assert.False(t, event.IsAccountSpecific())
+
+	var _ service.ResourceInterface = event
+}
+
+func TestNewOrganizationEvent(t *testing.T) {
+	event := NewOrganizationEvent("210987654321", retirement()).WithDetails(types.EventDetails{
+		EventDescription: &types.EventDescription{LatestDescription: aws.String("instance is scheduled for retirement")},
+		EventMetadata:    map[string]string{"deprecated_versions": ""},
+	})
+
+	assert.Equal(t, ptypes.AwsAccountID("210987654321"), event.GetAccountID())
+	assert.Equal(t, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), event.GetCreatedAt())
+	assert.Equal(t, "EC2", event.GetService())
+	assert.Equal(t, "instance is scheduled for retirement", event.Description)
+	assert.True(t, event.IsOpen())
+	assert.True(t, event.IsAccountSpecific())
+}
+
+func TestAttachEntities(t *testing.T) {
+	a := NewOrganizationEvent("111111111111", retirement())
+	b := NewOrganizationEvent("222222222222", retirement())
+
+	attached := attachEntities([]Event{a, b}, []types.AffectedEntity{
+		{EventArn: aws.String(retirementArn), AwsAccountId: aws.String("111111111111"), EntityValue: aws.String("i-0aaa")},
+		{EventArn: aws.String(retirementArn), AwsAccountId: aws.String("222222222222"), EntityValue: aws.String("i-0bbb")},
+		{EventArn: aws.String("arn:aws:health:eu-central-1::event/other"), EntityValue: aws.String("i-0ccc")},
+	})
+
+	require.Len(t, attached, 2)
+	assert.Equal(t, []string{"i-0aaa"}, attached[0].GetAffectedEntityValues())
+	assert.Equal(t, []string{"i-0bbb"}, attached[1].GetAffectedEntityValues())
+}
+
+type inventoryResource struct {
+	service.AbstractResource
+}
+
+func (e inventoryResource) GetName() string            { return e.ID }
+func (e inventoryResource) GetTags() map[string]string { return nil }
+
+func TestJoinAffectedResources(t *testing.T) {
+	bucketArn, _ := arn.Parse("arn:aws:s3:::reports")
+
+	instance := inventoryResource{service.AbstractResource{AccountID: "111111111111", ID: "i-0aaa"}}
+	sameIdElsewhere := inventoryResource{service.AbstractResource{AccountID: "222222222222", ID: "i-0aaa"}}
+	bucket := inventoryResource{service.AbstractResource{AccountID: "111111111111", ID: "reports", ARN: &bucketArn}}
+
+	event := NewOrganizationEvent("111111111111", retirement()).WithEntities([]types.AffectedEntity{
+		{EventArn: aws.String(retirementArn), EntityValue: aws.String("i-0aaa")},
+		{EventArn: aws.String(retirementArn), EntityValue: aws.String("arn:aws:s3:::reports")},
+		{EventArn: aws.String(retirementArn), EntityValue: aws.String("i-0gone")},
+	})
+
+	joined := JoinAffectedResources([]Event{event}, []service.ResourceInterface{instance, sameIdElsewhere, bucket})
+
+	require.Len(t, joined, 2)
+	assert.Equal(t, instance, joined[0].Resource)
+	assert.Equal(t, bucket, joined[1].Resource)
+	assert.Equal(t, "i-0aaa", aws.ToString(joined[0].Entity.EntityValue))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
 	awshealth "github.com/aws/aws-sdk-go-v2/service/health"
 	"github.com/aws/aws-sdk-go-v2/service/health/types"
@@ -19,11 +20,19 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// healthFilterSize is the most events DescribeEventDetails and the affected
+// entity filters accept per call.
+const healthFilterSize = 10
+
 type AwsClient interface {
 	GetRegion() ptypes.AwsRegion
 	GetAccountID() ptypes.AwsAccountID
 }
 
+// HealthRepository lists AWS Health events. The Health API is served from
+// us-east-1 for every region: use a us-east-1 client, once per account. The
+// organizational view needs the management or a delegated administrator
+// account, with organizational view enabled.
 type HealthRepository struct {
 	ctx    context.Context
 	client *v3.Client
@@ -55,70 +64,459 @@ func (r *HealthRepository) promLabels(method string, resourceType cfg.ResourceTy
 	}
 }
 
+// ListEventsAll returns every event of the account, of every region, from the
+// last 90 days and upcoming.
+func (r *HealthRepository) ListEventsAll() ([]Event, error) {
+	return r.ListEventsByInput(&awshealth.DescribeEventsInput{})
+}
+
+// ListEventsOpen returns ongoing and scheduled events.
+func (r *HealthRepository) ListEventsOpen() ([]Event, error) {
+	return r.ListEventsByInput(&awshealth.DescribeEventsInput{
+		Filter: &types.EventFilter{
+			EventStatusCodes: []types.EventStatusCode{types.EventStatusCodeOpen, types.EventStatusCodeUpcoming},
+		},
+	})
+}
+
+// ListEventsByInput returns the events of every page, with their description
+// and metadata.
+func (r *HealthRepository) ListEventsByInput(query *awshealth.DescribeEventsInput) ([]Event, error) {
+	start := time.Now()
+	var events []Event
+
+	p := awshealth.NewDescribeEventsPaginator(r.healthClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return events, errors.New(err)
+		}
+
+		for _, v := range resp.Events {
+			events = append(events, NewEvent(r.client, v))
+		}
+	}
+
+	details, err := r.describeEventDetails(slice.Map(events, func(e Event) string { return e.GetArn() }))
+	if err != nil {
+		return events, err
+	}
+
+	for i, event := range events {
+		if d, ok := details[event.GetArn()]; ok {
+			events[i] = event.WithDetails(d)
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).
+			Add(float64(len(events)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListEventsByInput", ccfg.ResourceTypeHealthEvent)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return events, nil
+}
+
+// ListEventsDetailsByInput returns the raw event details of every page.
+// Events whose details cannot be described are logged and left out.
+//
+// Deprecated: use ListEventsByInput, which returns typed events.
 func (r *HealthRepository) ListEventsDetailsByInput(query *awshealth.DescribeEventsInput) ([]types.EventDetails, error) {
 	start := time.Now()
 	var eventDetails []types.EventDetails
 
+	p := awshealth.NewDescribeEventsPaginator(r.healthClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return eventDetails, errors.New(err)
+		}
+
+		// request details by chunks
+		for _, events := range service.ChunkSlice(resp.Events, healthFilterSize) {
+			eventArns := slice.Map(events, func(e types.Event) string { return aws.ToString(e.Arn) })
+
+			details, err := r.describeEventDetails(eventArns)
+			if err != nil {
+				log.Error().Err(err).Msg("[HealthRepository.ListEventsDetailsByInput] failed to describe event details")
+
+				continue
+			}
+
+			for _, arn := range eventArns {
+				if d, ok := details[arn]; ok {
+					eventDetails = append(eventDetails, d)
+				}
+			}
+		}
+	}
+
 	if metrics.AwsMetricsEnabled {
-		metrics.AwsApiRequests.
+		metrics.AwsApiResourcesFetched.
 			With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).
-			Inc()
-	}
+			Add(float64(len(eventDetails)))
 
-	output, err := r.healthClient().DescribeEvents(r.ctx, query)
-	if err != nil {
-		return eventDetails, errors.New(err)
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListEventsDetailsByInput", ccfg.ResourceTypeHealthEvent)).
+			Observe(time.Since(start).Seconds())
 	}
 
-	// request details by chunks
-	for _, events := range service.ChunkSlice(output.Events, 20) {
-		eventArns := slice.Map(events, func(e types.Event) string { return *e.Arn })
+	return eventDetails, nil
+}
+
+// ListAffectedEntitiesByInput returns the affected entities of every page.
+func (r *HealthRepository) ListAffectedEntitiesByInput(query *awshealth.DescribeAffectedEntitiesInput) ([]types.AffectedEntity, error) {
+	start := time.Now()
+	var entities []types.AffectedEntity
 
-		// Define the input parameters for the DescribeEventDetails operation
-		detailsInput := &awshealth.DescribeEventDetailsInput{EventArns: eventArns}
+	p := awshealth.NewDescribeAffectedEntitiesPaginator(r.healthClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeAffectedEntities", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
 
-		// Call the DescribeEventDetails operation
-		details, err := r.healthClient().DescribeEventDetails(r.ctx, detailsInput)
+		resp, err := p.NextPage(r.ctx)
 		if err != nil {
 			if metrics.AwsMetricsEnabled {
-				metrics.AwsApiRequestErrors.
-					With(r.promLabels("DescribeEventDetails", ccfg.ResourceTypeHealthEvent)).
-					Inc()
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeAffectedEntities", ccfg.ResourceTypeHealthEvent)).Inc()
 			}
 
-			log.Error().Err(err).Msg("[ListEventsByInput] failed to describe event details")
+			return entities, errors.New(err)
+		}
 
-			continue
+		entities = append(entities, resp.Entities...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListAffectedEntitiesByInput", ccfg.ResourceTypeHealthEvent)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return entities, nil
+}
+
+// ListAffectedEntitiesByEvents returns the events with their affected
+// entities attached, asking for up to 10 events per call.
+func (r *HealthRepository) ListAffectedEntitiesByEvents(events []Event) ([]Event, error) {
+	var entities []types.AffectedEntity
+
+	for _, chunk := range service.ChunkSlice(events, healthFilterSize) {
+		found, err := r.ListAffectedEntitiesByInput(&awshealth.DescribeAffectedEntitiesInput{
+			Filter: &types.EntityFilter{EventArns: slice.Map(chunk, func(e Event) string { return e.GetArn() })},
+		})
+		if err != nil {
+			return events, err
 		}
 
-		for _, eventInfo := range details.SuccessfulSet {
-			eventDetails = append(eventDetails, eventInfo)
+		entities = append(entities, found...)
+	}
+
+	return attachEntities(events, entities), nil
+}
+
+// ListOrganizationEventsAll returns every event of the organization, split
+// per affected account.
+func (r *HealthRepository) ListOrganizationEventsAll() ([]Event, error) {
+	return r.ListOrganizationEventsByInput(&awshealth.DescribeEventsForOrganizationInput{})
+}
+
+// ListOrganizationEventsByInput returns the events of the organizational view
+// with their description and metadata. An account-specific event is returned
+// once per affected account; a public event once, for the calling account.
+func (r *HealthRepository) ListOrganizationEventsByInput(query *awshealth.DescribeEventsForOrganizationInput) ([]Event, error) {
+	start := time.Now()
+	var events []Event
+
+	p := awshealth.NewDescribeEventsForOrganizationPaginator(r.healthClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeEventsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
 		}
 
-		for _, failedEvent := range details.FailedSet {
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
 			if metrics.AwsMetricsEnabled {
-				metrics.AwsApiRequestErrors.
-					With(r.promLabels("DescribeEventDetails", ccfg.ResourceTypeHealthEvent)).
-					Inc()
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEventsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
 			}
 
-			log.Error().
-				Str("arn", *failedEvent.EventArn).
-				Str("event", *failedEvent.ErrorName).
-				Str("message", *failedEvent.ErrorMessage).
-				Msg("[ListEventsByInput] failed to describe event details")
+			return events, errors.New(err)
 		}
+
+		for _, v := range resp.Events {
+			if v.EventScopeCode != types.EventScopeCodeAccountSpecific {
+				events = append(events, NewOrganizationEvent(r.client.GetAccountID(), v))
+				continue
+			}
+
+			accounts, err := r.ListAffectedAccountsForOrganization(aws.ToString(v.Arn))
+			if err != nil {
+				return events, err
+			}
+
+			for _, accountID := range accounts {
+				events = append(events, NewOrganizationEvent(ptypes.AwsAccountID(accountID), v))
+			}
+		}
+	}
+
+	if err := r.describeOrganizationEventDetails(events); err != nil {
+		return events, err
 	}
 
 	if metrics.AwsMetricsEnabled {
 		metrics.AwsApiResourcesFetched.
-			With(r.promLabels("DescribeEvents", ccfg.ResourceTypeHealthEvent)).
-			Add(float64(len(eventDetails)))
+			With(r.promLabels("DescribeEventsForOrganization", ccfg.ResourceTypeHealthEvent)).
+			Add(float64(len(events)))
 
 		metrics.AwsRepoCallDuration.
-			With(r.promLabels("ListEventsDetailsByInput", ccfg.ResourceTypeHealthEvent)).
+			With(r.promLabels("ListOrganizationEventsByInput", ccfg.ResourceTypeHealthEvent)).
 			Observe(time.Since(start).Seconds())
 	}
 
-	return eventDetails, nil
+	return events, nil
+}
+
+// ListAffectedAccountsForOrganization returns the IDs of the accounts an
+// event affects.
+func (r *HealthRepository) ListAffectedAccountsForOrganization(eventArn string) ([]string, error) {
+	var accounts []string
+
+	p := awshealth.NewDescribeAffectedAccountsForOrganizationPaginator(r.healthClient(), &awshealth.DescribeAffectedAccountsForOrganizationInput{
+		EventArn: aws.String(eventArn),
+	})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeAffectedAccountsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeAffectedAccountsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return accounts, errors.New(err)
+		}
+
+		accounts = append(accounts, resp.AffectedAccounts...)
+	}
+
+	return accounts, nil
+}
+
+// ListAffectedEntitiesForOrganizationByInput returns the affected entities of
+// every page. Per-account failures are logged and skipped.
+func (r *HealthRepository) ListAffectedEntitiesForOrganizationByInput(query *awshealth.DescribeAffectedEntitiesForOrganizationInput) ([]types.AffectedEntity, error) {
+	start := time.Now()
+	var entities []types.AffectedEntity
+
+	p := awshealth.NewDescribeAffectedEntitiesForOrganizationPaginator(r.healthClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeAffectedEntitiesForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeAffectedEntitiesForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return entities, errors.New(err)
+		}
+
+		entities = append(entities, resp.Entities...)
+
+		for _, failed := range resp.FailedSet {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeAffectedEntitiesForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			log.Error().
+				Str("arn", aws.ToString(failed.EventArn)).
+				Str("accountID", aws.ToString(failed.AwsAccountId)).
+				Str("event", aws.ToString(failed.ErrorName)).
+				Str("message", aws.ToString(failed.ErrorMessage)).
+				Msg("[ListAffectedEntitiesForOrganizationByInput] failed to describe affected entities")
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListAffectedEntitiesForOrganizationByInput", ccfg.ResourceTypeHealthEvent)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return entities, nil
+}
+
+// ListOrganizationAffectedEntitiesByEvents returns organizational view events
+// with the entities of their account attached. Public events have no
+// entities and are returned as they are.
+func (r *HealthRepository) ListOrganizationAffectedEntitiesByEvents(events []Event) ([]Event, error) {
+	var entities []types.AffectedEntity
+
+	specific := slice.Filter(events, Event.IsAccountSpecific)
+	for _, chunk := range service.ChunkSlice(specific, healthFilterSize) {
+		found, err := r.ListAffectedEntitiesForOrganizationByInput(&awshealth.DescribeAffectedEntitiesForOrganizationInput{
+			OrganizationEntityAccountFilters: slice.Map(chunk, func(e Event) types.EntityAccountFilter {
+				return types.EntityAccountFilter{EventArn: aws.String(e.GetArn()), AwsAccountId: aws.String(e.GetAccountID().String())}
+			}),
+		})
+		if err != nil {
+			return events, err
+		}
+
+		entities = append(entities, found...)
+	}
+
+	return attachEntities(events, entities), nil
+}
+
+// describeEventDetails returns the details of the given events by event ARN.
+// Events whose details fail are logged and left without.
+func (r *HealthRepository) describeEventDetails(eventArns []string) (map[string]types.EventDetails, error) {
+	details := map[string]types.EventDetails{}
+
+	for _, chunk := range service.ChunkSlice(eventArns, healthFilterSize) {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeEventDetails", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		output, err := r.healthClient().DescribeEventDetails(r.ctx, &awshealth.DescribeEventDetailsInput{EventArns: chunk})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEventDetails", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return details, errors.New(err)
+		}
+
+		for _, eventInfo := range output.SuccessfulSet {
+			details[aws.ToString(eventInfo.Event.Arn)] = eventInfo
+		}
+
+		for _, failedEvent := range output.FailedSet {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEventDetails", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			log.Error().
+				Str("arn", aws.ToString(failedEvent.EventArn)).
+				Str("event", aws.ToString(failedEvent.ErrorName)).
+				Str("message", aws.ToString(failedEvent.ErrorMessage)).
+				Msg("[describeEventDetails] failed to describe event details")
+		}
+	}
+
+	return details, nil
+}
+
+// describeOrganizationEventDetails sets the details of each event, as it
+// applies to the event's account.
+func (r *HealthRepository) describeOrganizationEventDetails(events []Event) error {
+	type key struct{ arn, account string }
+
+	details := map[key]types.OrganizationEventDetails{}
+	for _, chunk := range service.ChunkSlice(events, healthFilterSize) {
+		filters := slice.Map(chunk, func(e Event) types.EventAccountFilter {
+			filter := types.EventAccountFilter{EventArn: aws.String(e.GetArn())}
+			// public events are described without an account
+			if e.IsAccountSpecific() {
+				filter.AwsAccountId = aws.String(e.GetAccountID().String())
+			}
+
+			return filter
+		})
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeEventDetailsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+		}
+
+		output, err := r.healthClient().DescribeEventDetailsForOrganization(r.ctx, &awshealth.DescribeEventDetailsForOrganizationInput{
+			OrganizationEventDetailFilters: filters,
+		})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEventDetailsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			return errors.New(err)
+		}
+
+		for _, eventInfo := range output.SuccessfulSet {
+			details[key{aws.ToString(eventInfo.Event.Arn), aws.ToString(eventInfo.AwsAccountId)}] = eventInfo
+		}
+
+		for _, failedEvent := range output.FailedSet {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeEventDetailsForOrganization", ccfg.ResourceTypeHealthEvent)).Inc()
+			}
+
+			log.Error().
+				Str("arn", aws.ToString(failedEvent.EventArn)).
+				Str("accountID", aws.ToString(failedEvent.AwsAccountId)).
+				Str("event", aws.ToString(failedEvent.ErrorName)).
+				Str("message", aws.ToString(failedEvent.ErrorMessage)).
+				Msg("[describeOrganizationEventDetails] failed to describe event details")
+		}
+	}
+
+	for i, event := range events {
+		account := ""
+		if event.IsAccountSpecific() {
+			account = event.GetAccountID().String()
+		}
+
+		if d, ok := details[key{event.GetArn(), account}]; ok {
+			events[i] = event.WithDetails(types.EventDetails{EventDescription: d.EventDescription, EventMetadata: d.EventMetadata})
+		}
+	}
+
+	return nil
+}
+
+// attachEntities sets on each event the entities of its ARN and, when the
+// entity names one, its account.
+func attachEntities(events []Event, entities []types.AffectedEntity) []Event {
+	attached := make([]Event, len(events))
+	for i, event := range events {
+		var own []types.AffectedEntity
+		for _, entity := range entities {
+			if aws.ToString(entity.EventArn) != event.GetArn() {
+				continue
+			}
+
+			if account := aws.ToString(entity.AwsAccountId); account != "" && account != event.GetAccountID().String() {
+				continue
+			}
+
+			own = append(own, entity)
+		}
+
+		attached[i] = event.WithEntities(own)
+	}
+
+	return attached
 }
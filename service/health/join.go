@@ -0,0 +1,42 @@
+package health
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/health/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+// AffectedResource is an inventory resource a Health event names as affected.
+type AffectedResource struct {
+	Event    Event
+	Entity   types.AffectedEntity
+	Resource service.ResourceInterface
+}
+
+// JoinAffectedResources matches the entities of the events, as attached by
+// ListAffectedEntitiesByEvents, to inventory resources. An entity value
+// matches a resource ARN, or a resource ID of the same account: Health reports
+// most entities by ID, e.g. an instance or volume ID. Entities matching no
+// resource are left out.
+func JoinAffectedResources(events []Event, inventory []service.ResourceInterface) []AffectedResource {
+	index := service.NewResourceIndex(inventory)
+
+	var joined []AffectedResource
+	for _, event := range events {
+		for _, entity := range event.Entities {
+			value := aws.ToString(entity.EntityValue)
+
+			account := event.GetAccountID()
+			if entityAccount := aws.ToString(entity.AwsAccountId); entityAccount != "" {
+				account = ptypes.AwsAccountID(entityAccount)
+			}
+
+			if resource, ok := index.Find(account, value); ok {
+				joined = append(joined, AffectedResource{Event: event, Entity: entity, Resource: resource})
+			}
+		}
+	}
+
+	return joined
+}
@@ -0,0 +1,65 @@
+package iam
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// AccessKey is an access key of a user with when it was last used. It
+// carries the key id only, never the secret.
+type AccessKey struct {
+	types.AccessKeyMetadata
+	LastUsed *types.AccessKeyLastUsed
+}
+
+func (k AccessKey) GetId() string {
+	return aws.ToString(k.AccessKeyId)
+}
+
+func (k AccessKey) IsActive() bool {
+	return k.Status == types.StatusTypeActive
+}
+
+func (k AccessKey) GetAge(now time.Time) time.Duration {
+	return now.Sub(aws.ToTime(k.CreateDate))
+}
+
+// GetLastUsedDate returns when the key was last used, or nil for a key
+// never used.
+func (k AccessKey) GetLastUsedDate() *time.Time {
+	if k.LastUsed == nil {
+		return nil
+	}
+
+	return k.LastUsed.LastUsedDate
+}
+
+// UserCredentials is a user with its credentials and policy counts.
+type UserCredentials struct {
+	User            User
+	AccessKeys      []AccessKey
+	ConsoleAccess   bool
+	MfaDevices      int
+	InlinePolicies  int
+	ManagedPolicies int
+}
+
+// RoleUsage is a role, as GetRole returns it with RoleLastUsed, and its
+// policy counts.
+type RoleUsage struct {
+	Role            Role
+	InlinePolicies  int
+	ManagedPolicies int
+}
+
+// GetLastUsedDate returns when the role was last assumed, or nil when IAM
+// has no record of it within its tracking period.
+func (u RoleUsage) GetLastUsedDate() *time.Time {
+	if u.Role.RoleLastUsed == nil {
+		return nil
+	}
+
+	return u.Role.RoleLastUsed.LastUsedDate
+}
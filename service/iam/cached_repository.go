@@ -26,6 +26,20 @@ func (r *IamRepository) WithCache(dc *cache.DataCache) *IamRepositoryCached {
 	}
 }
 
+// ListAccessKeysByUser returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListAccessKeysByUser(user User) ([]AccessKey, error) {
+	cacheKey := cache.Key("ListAccessKeysByUser", user)
+	var cached []AccessKey
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAccessKeysByUser(user)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListAssumedRoleArn returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListAssumedRoleArn(policyVersion PolicyVersion) []ptypes.RoleArn {
 	cacheKey := cache.Key("ListAssumedRoleArn", policyVersion)
@@ -37,6 +51,34 @@ func (c *IamRepositoryCached) ListAssumedRoleArn(policyVersion PolicyVersion) []
 	return r0
 }
 
+// ListAttachedPoliciesByRole returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListAttachedPoliciesByRole(role Role) ([]types.AttachedPolicy, error) {
+	cacheKey := cache.Key("ListAttachedPoliciesByRole", role)
+	var cached []types.AttachedPolicy
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAttachedPoliciesByRole(role)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListAttachedPoliciesByUser returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListAttachedPoliciesByUser(user User) ([]types.AttachedPolicy, error) {
+	cacheKey := cache.Key("ListAttachedPoliciesByUser", user)
+	var cached []types.AttachedPolicy
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListAttachedPoliciesByUser(user)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListAttachedRolePoliciesByInput returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListAttachedRolePoliciesByInput(query *awsiam.ListAttachedRolePoliciesInput) ([]Policy, error) {
 	cacheKey := cache.Key("ListAttachedRolePoliciesByInput", query)
@@ -107,6 +149,20 @@ func (c *IamRepositoryCached) ListAttachedRolePolicyVersionsByRoleName(name stri
 	return r0, r1
 }
 
+// ListMfaDevicesByUser returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListMfaDevicesByUser(user User) ([]types.MFADevice, error) {
+	cacheKey := cache.Key("ListMfaDevicesByUser", user)
+	var cached []types.MFADevice
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListMfaDevicesByUser(user)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListPoliciesAll returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListPoliciesAll() ([]Policy, error) {
 	cacheKey := cache.Key("ListPoliciesAll")
@@ -149,6 +205,34 @@ func (c *IamRepositoryCached) ListPolicyTags(policy types.Policy) ([]types.Tag,
 	return r0, r1
 }
 
+// ListRoleInlinePolicyDocumentsByRole returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListRoleInlinePolicyDocumentsByRole(role Role) ([]PolicyDocument, error) {
+	cacheKey := cache.Key("ListRoleInlinePolicyDocumentsByRole", role)
+	var cached []PolicyDocument
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListRoleInlinePolicyDocumentsByRole(role)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListRolePolicyNamesByRole returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListRolePolicyNamesByRole(role Role) ([]string, error) {
+	cacheKey := cache.Key("ListRolePolicyNamesByRole", role)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListRolePolicyNamesByRole(role)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListRoleTags returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListRoleTags(role types.Role) ([]types.Tag, error) {
 	cacheKey := cache.Key("ListRoleTags", role)
@@ -163,6 +247,20 @@ func (c *IamRepositoryCached) ListRoleTags(role types.Role) ([]types.Tag, error)
 	return r0, r1
 }
 
+// ListRoleUsageAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListRoleUsageAll() ([]RoleUsage, error) {
+	cacheKey := cache.Key("ListRoleUsageAll")
+	var cached []RoleUsage
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListRoleUsageAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListRolesAll returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListRolesAll() ([]Role, error) {
 	cacheKey := cache.Key("ListRolesAll")
@@ -191,6 +289,34 @@ func (c *IamRepositoryCached) ListRolesByInput(query *awsiam.ListRolesInput) ([]
 	return r0, r1
 }
 
+// ListUserCredentialsAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListUserCredentialsAll() ([]UserCredentials, error) {
+	cacheKey := cache.Key("ListUserCredentialsAll")
+	var cached []UserCredentials
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListUserCredentialsAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListUserPolicyNamesByUser returns cached results when available, otherwise delegates to the underlying repository.
+func (c *IamRepositoryCached) ListUserPolicyNamesByUser(user User) ([]string, error) {
+	cacheKey := cache.Key("ListUserPolicyNamesByUser", user)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListUserPolicyNamesByUser(user)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // ListUserTags returns cached results when available, otherwise delegates to the underlying repository.
 func (c *IamRepositoryCached) ListUserTags(user types.User) ([]types.Tag, error) {
 	cacheKey := cache.Key("ListUserTags", user)
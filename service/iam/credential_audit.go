@@ -0,0 +1,203 @@
+package iam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+type CredentialIssueType string
+
+const (
+	// AccessKeyOld is an active access key older than the rotation age.
+	AccessKeyOld CredentialIssueType = "AccessKeyOld"
+	// AccessKeyUnused is an active access key not used within the unused
+	// window, or never used since created before it.
+	AccessKeyUnused CredentialIssueType = "AccessKeyUnused"
+	// ConsoleWithoutMfa is a console password without an MFA device.
+	ConsoleWithoutMfa CredentialIssueType = "ConsoleWithoutMfa"
+	// UserInactive is a user whose password and keys were all unused within
+	// the unused window.
+	UserInactive CredentialIssueType = "UserInactive"
+	// RoleUnused is a role not assumed within the unused window.
+	RoleUnused CredentialIssueType = "RoleUnused"
+	// InlinePolicies is a user or role carrying inline policies, which cannot
+	// be reused or audited like managed ones.
+	InlinePolicies CredentialIssueType = "InlinePolicies"
+	// RootAccessKey is an active access key of the root account.
+	RootAccessKey CredentialIssueType = "RootAccessKey"
+	// RootWithoutMfa is a root account without MFA.
+	RootWithoutMfa CredentialIssueType = "RootWithoutMfa"
+)
+
+// CredentialFinding is one credential hygiene issue of a principal.
+type CredentialFinding struct {
+	Type          CredentialIssueType `json:"type"`
+	AccountID     ptypes.AwsAccountID `json:"accountId"`
+	PrincipalArn  string              `json:"principalArn"`
+	PrincipalName string              `json:"principalName"`
+	AccessKeyID   string              `json:"accessKeyId,omitempty"`
+	Detail        string              `json:"detail"`
+}
+
+// CredentialAuditor flags credential hygiene issues of users, roles and
+// credential report rows.
+type CredentialAuditor struct {
+	maxKeyAge   time.Duration
+	unusedAfter time.Duration
+}
+
+func NewCredentialAuditor() CredentialAuditor {
+	return CredentialAuditor{
+		maxKeyAge:   90 * 24 * time.Hour,
+		unusedAfter: 90 * 24 * time.Hour,
+	}
+}
+
+// WithMaxKeyAge returns an auditor flagging active keys older than age.
+func (a CredentialAuditor) WithMaxKeyAge(age time.Duration) CredentialAuditor {
+	a.maxKeyAge = age
+	return a
+}
+
+// WithUnusedAfter returns an auditor flagging keys, users and roles unused
+// for longer than d.
+func (a CredentialAuditor) WithUnusedAfter(d time.Duration) CredentialAuditor {
+	a.unusedAfter = d
+	return a
+}
+
+// AuditUser returns the issues of a user.
+func (a CredentialAuditor) AuditUser(credentials UserCredentials, now time.Time) []CredentialFinding {
+	user := credentials.User
+	finding := func(issue CredentialIssueType, keyID, detail string) CredentialFinding {
+		return CredentialFinding{
+			Type:          issue,
+			AccountID:     user.GetAccountID(),
+			PrincipalArn:  user.GetArn(),
+			PrincipalName: user.GetName(),
+			AccessKeyID:   keyID,
+			Detail:        detail,
+		}
+	}
+
+	var findings []CredentialFinding
+
+	lastActivity := user.PasswordLastUsed
+	for _, key := range credentials.AccessKeys {
+		if used := key.GetLastUsedDate(); used != nil && (lastActivity == nil || used.After(*lastActivity)) {
+			lastActivity = used
+		}
+
+		if !key.IsActive() {
+			continue
+		}
+
+		if age := key.GetAge(now); age > a.maxKeyAge {
+			findings = append(findings, finding(AccessKeyOld, key.GetId(), fmt.Sprintf("created %s, %d days ago", formatDate(aws.ToTime(key.CreateDate)), days(age))))
+		}
+
+		if a.unused(key.GetLastUsedDate(), aws.ToTime(key.CreateDate), now) {
+			findings = append(findings, finding(AccessKeyUnused, key.GetId(), "last used "+formatLastUsed(key.GetLastUsedDate())))
+		}
+	}
+
+	if credentials.ConsoleAccess && credentials.MfaDevices == 0 {
+		findings = append(findings, finding(ConsoleWithoutMfa, "", "console password without an MFA device"))
+	}
+
+	if a.unused(lastActivity, aws.ToTime(user.CreateDate), now) {
+		findings = append(findings, finding(UserInactive, "", "last active "+formatLastUsed(lastActivity)))
+	}
+
+	if credentials.InlinePolicies > 0 {
+		findings = append(findings, finding(InlinePolicies, "", fmt.Sprintf("%d inline and %d managed policies", credentials.InlinePolicies, credentials.ManagedPolicies)))
+	}
+
+	return findings
+}
+
+// AuditRole returns the issues of a role. Service-linked roles are only
+// checked for inline policies: AWS assumes them as it needs.
+func (a CredentialAuditor) AuditRole(usage RoleUsage, now time.Time) []CredentialFinding {
+	role := usage.Role
+	finding := func(issue CredentialIssueType, detail string) CredentialFinding {
+		return CredentialFinding{
+			Type:          issue,
+			AccountID:     role.GetAccountID(),
+			PrincipalArn:  role.GetArn(),
+			PrincipalName: role.GetName(),
+			Detail:        detail,
+		}
+	}
+
+	var findings []CredentialFinding
+
+	serviceLinked := strings.HasPrefix(aws.ToString(role.Path), "/aws-service-role/")
+	if !serviceLinked && a.unused(usage.GetLastUsedDate(), aws.ToTime(role.CreateDate), now) {
+		findings = append(findings, finding(RoleUnused, "last used "+formatLastUsed(usage.GetLastUsedDate())))
+	}
+
+	if usage.InlinePolicies > 0 {
+		findings = append(findings, finding(InlinePolicies, fmt.Sprintf("%d inline and %d managed policies", usage.InlinePolicies, usage.ManagedPolicies)))
+	}
+
+	return findings
+}
+
+// AuditCredentialReport returns the root account issues of a credential
+// report; users are better audited with AuditUser, which sees key ids.
+func (a CredentialAuditor) AuditCredentialReport(accountID ptypes.AwsAccountID, rows []CredentialReportRow) []CredentialFinding {
+	var findings []CredentialFinding
+
+	for _, row := range rows {
+		if !row.IsRoot() {
+			continue
+		}
+
+		finding := func(issue CredentialIssueType, detail string) CredentialFinding {
+			return CredentialFinding{Type: issue, AccountID: accountID, PrincipalArn: row.Arn, PrincipalName: row.User, Detail: detail}
+		}
+
+		if !row.MfaActive {
+			findings = append(findings, finding(RootWithoutMfa, "root account without MFA"))
+		}
+
+		for i, key := range row.AccessKeys {
+			if key.Active {
+				findings = append(findings, finding(RootAccessKey, fmt.Sprintf("root access key %d is active, last used %s", i+1, formatLastUsed(key.LastUsedDate))))
+			}
+		}
+	}
+
+	return findings
+}
+
+// unused reports a credential not used within the window, or never used and
+// created before it.
+func (a CredentialAuditor) unused(lastUsed *time.Time, created, now time.Time) bool {
+	if lastUsed == nil {
+		return now.Sub(created) > a.unusedAfter
+	}
+
+	return now.Sub(*lastUsed) > a.unusedAfter
+}
+
+func formatLastUsed(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+
+	return formatDate(*t)
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
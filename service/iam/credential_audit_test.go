@@ -0,0 +1,119 @@
+package iam
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return ptypes.DefaultAwsRegion }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+const credentialReportCsv = `user,arn,user_creation_time,password_enabled,password_last_used,password_last_changed,password_next_rotation,mfa_active,access_key_1_active,access_key_1_last_rotated,access_key_1_last_used_date,access_key_1_last_used_region,access_key_1_last_used_service,access_key_2_active,access_key_2_last_rotated,access_key_2_last_used_date,access_key_2_last_used_region,access_key_2_last_used_service,cert_1_active,cert_1_last_rotated,cert_2_active,cert_2_last_rotated
+<root_account>,arn:aws:iam::123456789012:root,2019-01-01T00:00:00+00:00,not_supported,2026-09-01T10:00:00+00:00,not_supported,not_supported,false,true,2019-02-01T00:00:00+00:00,2026-08-01T00:00:00+00:00,us-east-1,s3,false,N/A,N/A,N/A,N/A,false,N/A,false,N/A
+alice,arn:aws:iam::123456789012:user/alice,2024-03-01T12:00:00+00:00,true,no_information,2024-03-01T12:00:00+00:00,N/A,true,false,N/A,N/A,N/A,N/A,false,N/A,N/A,N/A,N/A,false,N/A,false,N/A
+`
+
+func TestParseCredentialReport(t *testing.T) {
+	rows, err := ParseCredentialReport([]byte(credentialReportCsv))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	root := rows[0]
+	assert.True(t, root.IsRoot())
+	assert.False(t, root.PasswordEnabled)
+	assert.False(t, root.MfaActive)
+	assert.True(t, root.AccessKeys[0].Active)
+	assert.Equal(t, "s3", root.AccessKeys[0].LastUsedService)
+	assert.Equal(t, time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), root.AccessKeys[0].LastUsedDate.UTC())
+	assert.Equal(t, "", root.AccessKeys[1].LastUsedRegion)
+	assert.Nil(t, root.AccessKeys[1].LastRotated)
+
+	alice := rows[1]
+	assert.False(t, alice.IsRoot())
+	assert.True(t, alice.PasswordEnabled)
+	assert.Nil(t, alice.PasswordLastUsed)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), alice.UserCreationTime.UTC())
+
+	findings := NewCredentialAuditor().AuditCredentialReport("123456789012", rows)
+	issues := []CredentialIssueType{}
+	for _, f := range findings {
+		issues = append(issues, f.Type)
+	}
+	assert.ElementsMatch(t, []CredentialIssueType{RootWithoutMfa, RootAccessKey}, issues)
+}
+
+func TestAuditUser(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	user := NewUser(mockClient{}, types.User{
+		UserName:   aws.String("bob"),
+		Arn:        aws.String("arn:aws:iam::123456789012:user/bob"),
+		CreateDate: aws.Time(now.Add(-400 * day)),
+	})
+
+	credentials := UserCredentials{
+		User: user,
+		AccessKeys: []AccessKey{
+			{
+				AccessKeyMetadata: types.AccessKeyMetadata{AccessKeyId: aws.String("AKIAOLD"), Status: types.StatusTypeActive, CreateDate: aws.Time(now.Add(-200 * day))},
+				LastUsed:          &types.AccessKeyLastUsed{LastUsedDate: aws.Time(now.Add(-2 * day))},
+			},
+			{
+				AccessKeyMetadata: types.AccessKeyMetadata{AccessKeyId: aws.String("AKIANEVER"), Status: types.StatusTypeActive, CreateDate: aws.Time(now.Add(-30 * day))},
+			},
+			{
+				AccessKeyMetadata: types.AccessKeyMetadata{AccessKeyId: aws.String("AKIAIDLE"), Status: types.StatusTypeActive, CreateDate: aws.Time(now.Add(-120 * day))},
+			},
+			{
+				AccessKeyMetadata: types.AccessKeyMetadata{AccessKeyId: aws.String("AKIAOFF"), Status: types.StatusTypeInactive, CreateDate: aws.Time(now.Add(-300 * day))},
+			},
+		},
+		ConsoleAccess:  true,
+		InlinePolicies: 2,
+	}
+
+	findings := NewCredentialAuditor().AuditUser(credentials, now)
+
+	got := map[CredentialIssueType][]string{}
+	for _, f := range findings {
+		got[f.Type] = append(got[f.Type], f.AccessKeyID)
+	}
+
+	assert.Equal(t, []string{"AKIAOLD", "AKIAIDLE"}, got[AccessKeyOld])
+	assert.Equal(t, []string{"AKIAIDLE"}, got[AccessKeyUnused])
+	assert.Contains(t, got, ConsoleWithoutMfa)
+	assert.Contains(t, got, InlinePolicies)
+	assert.NotContains(t, got, UserInactive, "a key used two days ago keeps the user active")
+
+	credentials.AccessKeys = nil
+	findings = NewCredentialAuditor().AuditUser(credentials, now)
+	assert.Equal(t, UserInactive, findings[1].Type)
+}
+
+func TestAuditRole(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	role := NewRole(mockClient{}, types.Role{
+		RoleName:     aws.String("old"),
+		Path:         aws.String("/"),
+		Arn:          aws.String("arn:aws:iam::123456789012:role/old"),
+		CreateDate:   aws.Time(now.AddDate(-1, 0, 0)),
+		RoleLastUsed: &types.RoleLastUsed{LastUsedDate: aws.Time(now.AddDate(0, -6, 0))},
+	})
+
+	findings := NewCredentialAuditor().AuditRole(RoleUsage{Role: role, ManagedPolicies: 1}, now)
+	require.Len(t, findings, 1)
+	assert.Equal(t, RoleUnused, findings[0].Type)
+
+	role.Path = aws.String("/aws-service-role/ecs.amazonaws.com/")
+	assert.Empty(t, NewCredentialAuditor().AuditRole(RoleUsage{Role: role}, now))
+}
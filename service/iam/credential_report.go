@@ -0,0 +1,135 @@
+package iam
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// CredentialReportRootUser is the user name of the root account row.
+const CredentialReportRootUser = "<root_account>"
+
+// CredentialReportRow is one row of the IAM credential report. Times the
+// report gives as N/A, no_information or not_supported are nil.
+type CredentialReportRow struct {
+	User                 string
+	Arn                  string
+	UserCreationTime     time.Time
+	PasswordEnabled      bool
+	PasswordLastUsed     *time.Time
+	PasswordLastChanged  *time.Time
+	PasswordNextRotation *time.Time
+	MfaActive            bool
+	AccessKeys           [2]CredentialReportKey
+	Certificates         [2]CredentialReportCert
+}
+
+type CredentialReportKey struct {
+	Active          bool
+	LastRotated     *time.Time
+	LastUsedDate    *time.Time
+	LastUsedRegion  string
+	LastUsedService string
+}
+
+type CredentialReportCert struct {
+	Active      bool
+	LastRotated *time.Time
+}
+
+// IsRoot reports the row of the root account.
+func (r CredentialReportRow) IsRoot() bool {
+	return r.User == CredentialReportRootUser
+}
+
+// ParseCredentialReport parses the CSV content of GetCredentialReport. Columns
+// are looked up by header, so reports gaining columns still parse.
+func ParseCredentialReport(content []byte) ([]CredentialReportRow, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("empty credential report")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+
+	if _, ok := columns["user"]; !ok {
+		return nil, errors.New("credential report without a user column")
+	}
+
+	rows := make([]CredentialReportRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+
+			return ""
+		}
+
+		row := CredentialReportRow{
+			User:                 field("user"),
+			Arn:                  field("arn"),
+			PasswordEnabled:      reportBool(field("password_enabled")),
+			PasswordLastUsed:     reportTime(field("password_last_used")),
+			PasswordLastChanged:  reportTime(field("password_last_changed")),
+			PasswordNextRotation: reportTime(field("password_next_rotation")),
+			MfaActive:            reportBool(field("mfa_active")),
+		}
+
+		if created := reportTime(field("user_creation_time")); created != nil {
+			row.UserCreationTime = *created
+		}
+
+		for i, prefix := range []string{"access_key_1_", "access_key_2_"} {
+			row.AccessKeys[i] = CredentialReportKey{
+				Active:          reportBool(field(prefix + "active")),
+				LastRotated:     reportTime(field(prefix + "last_rotated")),
+				LastUsedDate:    reportTime(field(prefix + "last_used_date")),
+				LastUsedRegion:  reportString(field(prefix + "last_used_region")),
+				LastUsedService: reportString(field(prefix + "last_used_service")),
+			}
+		}
+
+		for i, prefix := range []string{"cert_1_", "cert_2_"} {
+			row.Certificates[i] = CredentialReportCert{
+				Active:      reportBool(field(prefix + "active")),
+				LastRotated: reportTime(field(prefix + "last_rotated")),
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func reportBool(value string) bool {
+	return strings.EqualFold(value, "true")
+}
+
+func reportString(value string) string {
+	if value == "N/A" {
+		return ""
+	}
+
+	return value
+}
+
+func reportTime(value string) *time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+
+	return &parsed
+}
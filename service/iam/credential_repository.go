@@ -0,0 +1,376 @@
+package iam
+
+import (
+	stderrors "errors"
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+)
+
+// credentialReportPoll is how often GenerateCredentialReport asks whether
+// the report is ready; IAM builds it within seconds.
+const credentialReportPoll = 2 * time.Second
+
+// ListUserCredentialsAll returns every user with its access keys, console
+// access, MFA devices and policy counts.
+func (r *IamRepository) ListUserCredentialsAll() ([]UserCredentials, error) {
+	start := time.Now()
+
+	users, err := r.ListUsersAll()
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]UserCredentials, 0, len(users))
+	for _, user := range users {
+		entry := UserCredentials{User: user}
+
+		if entry.AccessKeys, err = r.ListAccessKeysByUser(user); err != nil {
+			return credentials, err
+		}
+
+		if entry.ConsoleAccess, err = r.HasLoginProfile(user); err != nil {
+			return credentials, err
+		}
+
+		devices, err := r.ListMfaDevicesByUser(user)
+		if err != nil {
+			return credentials, err
+		}
+		entry.MfaDevices = len(devices)
+
+		inline, err := r.ListUserPolicyNamesByUser(user)
+		if err != nil {
+			return credentials, err
+		}
+		entry.InlinePolicies = len(inline)
+
+		attached, err := r.ListAttachedPoliciesByUser(user)
+		if err != nil {
+			return credentials, err
+		}
+		entry.ManagedPolicies = len(attached)
+
+		credentials = append(credentials, entry)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListUserCredentialsAll", cfg.ResourceTypeUser)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return credentials, nil
+}
+
+// ListAccessKeysByUser returns the access keys of a user, each with when it
+// was last used.
+func (r *IamRepository) ListAccessKeysByUser(user User) ([]AccessKey, error) {
+	var keys []AccessKey
+
+	p := iam.NewListAccessKeysPaginator(r.iamClient(), &iam.ListAccessKeysInput{UserName: user.UserName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListAccessKeys", cfg.ResourceTypeUser)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListAccessKeys", cfg.ResourceTypeUser)).Inc()
+			}
+
+			return keys, errors.New(err)
+		}
+
+		for _, v := range resp.AccessKeyMetadata {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequests.With(r.promLabels("GetAccessKeyLastUsed", cfg.ResourceTypeUser)).Inc()
+			}
+
+			lastUsed, err := r.iamClient().GetAccessKeyLastUsed(r.ctx, &iam.GetAccessKeyLastUsedInput{AccessKeyId: v.AccessKeyId})
+			if err != nil {
+				if metrics.AwsMetricsEnabled {
+					metrics.AwsApiRequestErrors.With(r.promLabels("GetAccessKeyLastUsed", cfg.ResourceTypeUser)).Inc()
+				}
+
+				return keys, errors.New(err)
+			}
+
+			keys = append(keys, AccessKey{AccessKeyMetadata: v, LastUsed: lastUsed.AccessKeyLastUsed})
+		}
+	}
+
+	return keys, nil
+}
+
+// HasLoginProfile reports whether a user has a console password.
+func (r *IamRepository) HasLoginProfile(user User) (bool, error) {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("GetLoginProfile", cfg.ResourceTypeUser)).Inc()
+	}
+
+	_, err := r.iamClient().GetLoginProfile(r.ctx, &iam.GetLoginProfileInput{UserName: user.UserName})
+	if err != nil {
+		var notFound *types.NoSuchEntityException
+		if stderrors.As(err, &notFound) {
+			return false, nil
+		}
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("GetLoginProfile", cfg.ResourceTypeUser)).Inc()
+		}
+
+		return false, errors.New(err)
+	}
+
+	return true, nil
+}
+
+func (r *IamRepository) ListMfaDevicesByUser(user User) ([]types.MFADevice, error) {
+	var devices []types.MFADevice
+
+	p := iam.NewListMFADevicesPaginator(r.iamClient(), &iam.ListMFADevicesInput{UserName: user.UserName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListMFADevices", cfg.ResourceTypeUser)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListMFADevices", cfg.ResourceTypeUser)).Inc()
+			}
+
+			return devices, errors.New(err)
+		}
+
+		devices = append(devices, resp.MFADevices...)
+	}
+
+	return devices, nil
+}
+
+// ListUserPolicyNamesByUser returns the names of the inline policies of a
+// user.
+func (r *IamRepository) ListUserPolicyNamesByUser(user User) ([]string, error) {
+	var names []string
+
+	p := iam.NewListUserPoliciesPaginator(r.iamClient(), &iam.ListUserPoliciesInput{UserName: user.UserName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListUserPolicies", cfg.ResourceTypeUser)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListUserPolicies", cfg.ResourceTypeUser)).Inc()
+			}
+
+			return names, errors.New(err)
+		}
+
+		names = append(names, resp.PolicyNames...)
+	}
+
+	return names, nil
+}
+
+// ListAttachedPoliciesByUser returns the managed policies attached to a
+// user, by name and ARN only.
+func (r *IamRepository) ListAttachedPoliciesByUser(user User) ([]types.AttachedPolicy, error) {
+	var policies []types.AttachedPolicy
+
+	p := iam.NewListAttachedUserPoliciesPaginator(r.iamClient(), &iam.ListAttachedUserPoliciesInput{UserName: user.UserName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListAttachedUserPolicies", cfg.ResourceTypeUser)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListAttachedUserPolicies", cfg.ResourceTypeUser)).Inc()
+			}
+
+			return policies, errors.New(err)
+		}
+
+		policies = append(policies, resp.AttachedPolicies...)
+	}
+
+	return policies, nil
+}
+
+// ListRoleUsageAll returns every role with when it was last used and its
+// policy counts. ListRoles leaves RoleLastUsed empty, so each role is read
+// again with GetRole.
+func (r *IamRepository) ListRoleUsageAll() ([]RoleUsage, error) {
+	start := time.Now()
+
+	roles, err := r.ListRolesAll()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]RoleUsage, 0, len(roles))
+	for _, role := range roles {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetRole", cfg.ResourceTypeRole)).Inc()
+		}
+
+		resp, err := r.iamClient().GetRole(r.ctx, &iam.GetRoleInput{RoleName: role.RoleName})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetRole", cfg.ResourceTypeRole)).Inc()
+			}
+
+			return usage, errors.New(err)
+		}
+		role.RoleLastUsed = resp.Role.RoleLastUsed
+
+		entry := RoleUsage{Role: role}
+
+		inline, err := r.ListRolePolicyNamesByRole(role)
+		if err != nil {
+			return usage, err
+		}
+		entry.InlinePolicies = len(inline)
+
+		attached, err := r.ListAttachedPoliciesByRole(role)
+		if err != nil {
+			return usage, err
+		}
+		entry.ManagedPolicies = len(attached)
+
+		usage = append(usage, entry)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListRoleUsageAll", cfg.ResourceTypeRole)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return usage, nil
+}
+
+// ListRolePolicyNamesByRole returns the names of the inline policies of a
+// role.
+func (r *IamRepository) ListRolePolicyNamesByRole(role Role) ([]string, error) {
+	var names []string
+
+	p := iam.NewListRolePoliciesPaginator(r.iamClient(), &iam.ListRolePoliciesInput{RoleName: role.RoleName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListRolePolicies", cfg.ResourceTypeRole)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListRolePolicies", cfg.ResourceTypeRole)).Inc()
+			}
+
+			return names, errors.New(err)
+		}
+
+		names = append(names, resp.PolicyNames...)
+	}
+
+	return names, nil
+}
+
+// ListAttachedPoliciesByRole returns the managed policies attached to a
+// role, by name and ARN only; ListAttachedRolePoliciesByRole reads each
+// policy as well.
+func (r *IamRepository) ListAttachedPoliciesByRole(role Role) ([]types.AttachedPolicy, error) {
+	var policies []types.AttachedPolicy
+
+	p := iam.NewListAttachedRolePoliciesPaginator(r.iamClient(), &iam.ListAttachedRolePoliciesInput{RoleName: role.RoleName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListAttachedRolePolicies", cfg.ResourceTypeRole)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListAttachedRolePolicies", cfg.ResourceTypeRole)).Inc()
+			}
+
+			return policies, errors.New(err)
+		}
+
+		policies = append(policies, resp.AttachedPolicies...)
+	}
+
+	return policies, nil
+}
+
+// GenerateCredentialReport asks IAM for a fresh credential report, waits
+// until it is built and returns its rows. IAM serves a report generated
+// within the last four hours instead of building a new one.
+func (r *IamRepository) GenerateCredentialReport() ([]CredentialReportRow, error) {
+	start := time.Now()
+
+	for {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GenerateCredentialReport", cfg.ResourceTypeUser)).Inc()
+		}
+
+		resp, err := r.iamClient().GenerateCredentialReport(r.ctx, &iam.GenerateCredentialReportInput{})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GenerateCredentialReport", cfg.ResourceTypeUser)).Inc()
+			}
+
+			return nil, errors.New(err)
+		}
+
+		if resp.State == types.ReportStateTypeComplete {
+			break
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return nil, errors.New(r.ctx.Err())
+		case <-time.After(credentialReportPoll):
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("GetCredentialReport", cfg.ResourceTypeUser)).Inc()
+	}
+
+	report, err := r.iamClient().GetCredentialReport(r.ctx, &iam.GetCredentialReportInput{})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("GetCredentialReport", cfg.ResourceTypeUser)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	rows, err := ParseCredentialReport(report.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetCredentialReport", cfg.ResourceTypeUser)).
+			Add(float64(len(rows)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GenerateCredentialReport", cfg.ResourceTypeUser)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return rows, nil
+}
@@ -7,13 +7,23 @@ import "encoding/gob"
 // init registers this package's types with encoding/gob so they can be
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
+	gob.Register(AccessKey{})
+	gob.Register(AccessRequest{})
+	gob.Register(CredentialAuditor{})
+	gob.Register(CredentialFinding{})
+	gob.Register(CredentialReportCert{})
+	gob.Register(CredentialReportKey{})
+	gob.Register(CredentialReportRow{})
+	gob.Register(EvaluationResult{})
 	gob.Register(Policy{})
 	gob.Register(PolicyDocument{})
 	gob.Register(PolicyList{})
 	gob.Register(PolicyVersion{})
 	gob.Register(Role{})
 	gob.Register(RoleList{})
+	gob.Register(RoleUsage{})
 	gob.Register(Statement{})
 	gob.Register(User{})
+	gob.Register(UserCredentials{})
 	gob.Register(UserList{})
 }
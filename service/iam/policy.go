@@ -1,6 +1,9 @@
 package iam
 
 import (
+	"bytes"
+	"encoding/json"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
@@ -61,10 +64,51 @@ type PolicyDocument struct {
 	Statement []Statement `json:"Statement"`
 }
 
+// UnmarshalJSON accepts a Statement holding a single object as well as a list.
+func (d *PolicyDocument) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Version   string          `json:"Version"`
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	d.Version = raw.Version
+	d.Statement = nil
+
+	trimmed := bytes.TrimSpace(raw.Statement)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	if trimmed[0] == '{' {
+		var statement Statement
+		if err := json.Unmarshal(trimmed, &statement); err != nil {
+			return err
+		}
+		d.Statement = []Statement{statement}
+
+		return nil
+	}
+
+	return json.Unmarshal(trimmed, &d.Statement)
+}
+
+// Statement is one policy statement. Action, Resource and their Not* forms
+// hold a string or a list of strings; Principal holds "*" or a map of
+// principal type ("AWS", "Service", "Federated", "CanonicalUser") to a string
+// or list.
 type Statement struct {
-	Effect   string      `json:"Effect"`
-	Action   interface{} `json:"Action"`
-	Resource interface{} `json:"Resource"`
+	Sid          string                            `json:"Sid,omitempty"`
+	Effect       string                            `json:"Effect"`
+	Principal    interface{}                       `json:"Principal,omitempty"`
+	NotPrincipal interface{}                       `json:"NotPrincipal,omitempty"`
+	Action       interface{}                       `json:"Action,omitempty"`
+	NotAction    interface{}                       `json:"NotAction,omitempty"`
+	Resource     interface{}                       `json:"Resource,omitempty"`
+	NotResource  interface{}                       `json:"NotResource,omitempty"`
+	Condition    map[string]map[string]interface{} `json:"Condition,omitempty"`
 }
 
 type PolicyVersion struct {
@@ -0,0 +1,238 @@
+package iam
+
+import (
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// conditionMatcher compares one request value with one policy value.
+type conditionMatcher func(requestValue, policyValue string) bool
+
+var conditionMatchers = map[string]conditionMatcher{
+	"StringEquals": func(r, p string) bool { return r == p },
+	"StringEqualsIgnoreCase": func(r, p string) bool {
+		return strings.EqualFold(r, p)
+	},
+	"StringLike": func(r, p string) bool { return wildcardMatch(p, r, false) },
+	"ArnEquals":  func(r, p string) bool { return wildcardMatch(p, r, false) },
+	"ArnLike":    func(r, p string) bool { return wildcardMatch(p, r, false) },
+	"Bool": func(r, p string) bool {
+		return strings.EqualFold(r, p)
+	},
+	"NumericEquals":            numericMatcher(func(r, p float64) bool { return r == p }),
+	"NumericLessThan":          numericMatcher(func(r, p float64) bool { return r < p }),
+	"NumericLessThanEquals":    numericMatcher(func(r, p float64) bool { return r <= p }),
+	"NumericGreaterThan":       numericMatcher(func(r, p float64) bool { return r > p }),
+	"NumericGreaterThanEquals": numericMatcher(func(r, p float64) bool { return r >= p }),
+	"DateEquals":               dateMatcher(func(r, p time.Time) bool { return r.Equal(p) }),
+	"DateLessThan":             dateMatcher(func(r, p time.Time) bool { return r.Before(p) }),
+	"DateLessThanEquals":       dateMatcher(func(r, p time.Time) bool { return !r.After(p) }),
+	"DateGreaterThan":          dateMatcher(func(r, p time.Time) bool { return r.After(p) }),
+	"DateGreaterThanEquals":    dateMatcher(func(r, p time.Time) bool { return !r.Before(p) }),
+	"IpAddress":                ipMatcher,
+}
+
+// negatedConditions maps the negated operators onto the matcher they negate.
+var negatedConditions = map[string]string{
+	"StringNotEquals":           "StringEquals",
+	"StringNotEqualsIgnoreCase": "StringEqualsIgnoreCase",
+	"StringNotLike":             "StringLike",
+	"ArnNotEquals":              "ArnEquals",
+	"ArnNotLike":                "ArnLike",
+	"NumericNotEquals":          "NumericEquals",
+	"DateNotEquals":             "DateEquals",
+	"NotIpAddress":              "IpAddress",
+}
+
+func numericMatcher(compare func(r, p float64) bool) conditionMatcher {
+	return func(r, p string) bool {
+		rv, err := strconv.ParseFloat(r, 64)
+		if err != nil {
+			return false
+		}
+
+		pv, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return false
+		}
+
+		return compare(rv, pv)
+	}
+}
+
+func dateMatcher(compare func(r, p time.Time) bool) conditionMatcher {
+	return func(r, p string) bool {
+		rv, ok := parseConditionDate(r)
+		if !ok {
+			return false
+		}
+
+		pv, ok := parseConditionDate(p)
+		if !ok {
+			return false
+		}
+
+		return compare(rv, pv)
+	}
+}
+
+// parseConditionDate reads the ISO 8601 dates and epoch seconds the Date
+// operators accept.
+func parseConditionDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC(), true
+	}
+
+	return time.Time{}, false
+}
+
+// ipMatcher matches a request address against a CIDR block or single address.
+func ipMatcher(r, p string) bool {
+	addr, err := netip.ParseAddr(r)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	if !strings.Contains(p, "/") {
+		policyAddr, err := netip.ParseAddr(p)
+		return err == nil && policyAddr.Unmap() == addr
+	}
+
+	prefix, err := netip.ParsePrefix(p)
+	if err != nil {
+		return false
+	}
+
+	return prefix.Masked().Contains(addr)
+}
+
+// matchConditions reports whether every condition it can evaluate holds for
+// the request context, and returns the operators it cannot evaluate, sorted.
+// The caller decides what an unsupported condition means for the statement.
+func matchConditions(conditions map[string]map[string]interface{}, context map[string][]string) (bool, []string) {
+	var unsupported []string
+	matched := true
+
+	for operator, keys := range conditions {
+		for key, policyValue := range keys {
+			ok, supported := matchCondition(operator, policyStrings(policyValue), lookupContext(context, key))
+			if !supported {
+				unsupported = append(unsupported, operator)
+
+				continue
+			}
+
+			if !ok {
+				matched = false
+			}
+		}
+	}
+
+	slices.Sort(unsupported)
+
+	return matched, slices.Compact(unsupported)
+}
+
+// lookupContext finds a condition key, whose names are case-insensitive.
+func lookupContext(context map[string][]string, key string) []string {
+	if values, ok := context[key]; ok {
+		return values
+	}
+
+	for name, values := range context {
+		if strings.EqualFold(name, key) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+func matchCondition(operator string, policyValues, requestValues []string) (bool, bool) {
+	qualifier := ""
+	if prefix, rest, ok := strings.Cut(operator, ":"); ok {
+		qualifier, operator = prefix, rest
+	}
+
+	ifExists := false
+	if base, ok := strings.CutSuffix(operator, "IfExists"); ok {
+		operator, ifExists = base, true
+	}
+
+	if operator == "Null" {
+		// Null:true holds when the key is absent
+		want := len(policyValues) > 0 && strings.EqualFold(policyValues[0], "true")
+		return (len(requestValues) == 0) == want, true
+	}
+
+	negated := false
+	if base, ok := negatedConditions[operator]; ok {
+		operator, negated = base, true
+	}
+
+	matcher, ok := conditionMatchers[operator]
+	if !ok {
+		return false, false
+	}
+
+	if len(requestValues) == 0 {
+		switch {
+		case ifExists, qualifier == "ForAllValues":
+			return true, true
+		case qualifier == "ForAnyValue":
+			return false, true
+		}
+
+		// a missing key matches negated operators only
+		return negated, true
+	}
+
+	anyPolicyMatch := func(requestValue string) bool {
+		for _, policyValue := range policyValues {
+			if matcher(requestValue, policyValue) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch qualifier {
+	case "ForAllValues":
+		for _, requestValue := range requestValues {
+			if anyPolicyMatch(requestValue) == negated {
+				return false, true
+			}
+		}
+
+		return true, true
+	case "ForAnyValue":
+		for _, requestValue := range requestValues {
+			if anyPolicyMatch(requestValue) != negated {
+				return true, true
+			}
+		}
+
+		return false, true
+	case "":
+		for _, requestValue := range requestValues {
+			if anyPolicyMatch(requestValue) {
+				return !negated, true
+			}
+		}
+
+		return negated, true
+	}
+
+	return false, false
+}
@@ -0,0 +1,327 @@
+package iam
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+type Decision string
+
+const (
+	DecisionAllow        Decision = "Allow"
+	DecisionExplicitDeny Decision = "ExplicitDeny"
+	DecisionImplicitDeny Decision = "ImplicitDeny"
+)
+
+// AccessRequest is the request a policy is evaluated against. Principal is an
+// IAM ARN, an account id or a service principal such as
+// "lambda.amazonaws.com"; it is only matched by statements naming principals,
+// i.e. resource and trust policies. Context holds the condition keys of the
+// request, e.g. "aws:SourceAccount" or "aws:MultiFactorAuthPresent".
+type AccessRequest struct {
+	Principal string
+	Action    string
+	Resource  string
+	Context   map[string][]string
+}
+
+// EvaluationResult is the outcome of an evaluation, with the statements that
+// decided it. Unsupported lists, sorted, the condition operators the
+// evaluator could not check. A Deny using one is assumed to apply and an
+// Allow not to, so a result carrying any may deny what AWS allows, never the
+// reverse.
+type EvaluationResult struct {
+	Decision    Decision
+	Statements  []Statement
+	Unsupported []string
+}
+
+func (r EvaluationResult) IsAllowed() bool {
+	return r.Decision == DecisionAllow
+}
+
+// Evaluate decides a request offline against the given policies, as AWS does
+// within one account: an explicit Deny in any policy wins, then any Allow,
+// otherwise the request is implicitly denied. Permission boundaries, SCPs
+// and session policies, which intersect rather than add up, are evaluated
+// by calling Evaluate once per layer.
+//
+// Actions match case-insensitively and resources case-sensitively, both with
+// "*" and "?" wildcards. Conditions support the String, Arn, Numeric, Date,
+// Bool, IpAddress and Null operators, their IfExists forms and the
+// ForAllValues and ForAnyValue set qualifiers. Policy variables are not
+// substituted.
+func Evaluate(request AccessRequest, policies ...PolicyDocument) EvaluationResult {
+	var allows, denies []Statement
+	unsupported := map[string]bool{}
+
+	for _, policy := range policies {
+		for _, statement := range policy.Statement {
+			matched, missing := statement.Matches(request)
+			for _, op := range missing {
+				unsupported[op] = true
+			}
+
+			if !matched {
+				continue
+			}
+
+			if strings.EqualFold(statement.Effect, "Deny") {
+				denies = append(denies, statement)
+			} else if strings.EqualFold(statement.Effect, "Allow") {
+				allows = append(allows, statement)
+			}
+		}
+	}
+
+	result := EvaluationResult{Decision: DecisionImplicitDeny}
+	for op := range unsupported {
+		result.Unsupported = append(result.Unsupported, op)
+	}
+	slices.Sort(result.Unsupported)
+
+	switch {
+	case len(denies) > 0:
+		result.Decision, result.Statements = DecisionExplicitDeny, denies
+	case len(allows) > 0:
+		result.Decision, result.Statements = DecisionAllow, allows
+	}
+
+	return result
+}
+
+// Matches reports whether the statement applies to the request and returns
+// the condition operators it could not evaluate. Those conditions are assumed
+// to hold in a Deny and not to hold in any other statement, so an evaluation
+// the library cannot finish fails closed.
+func (s Statement) Matches(request AccessRequest) (bool, []string) {
+	if !s.matchesAction(request.Action) || !s.matchesResource(request.Resource) || !s.matchesPrincipal(request.Principal) {
+		return false, nil
+	}
+
+	matched, unsupported := matchConditions(s.Condition, request.Context)
+	if len(unsupported) > 0 && !strings.EqualFold(s.Effect, "Deny") {
+		matched = false
+	}
+
+	return matched, unsupported
+}
+
+// Actions returns the Action element as a list.
+func (s Statement) Actions() []string {
+	return policyStrings(s.Action)
+}
+
+// Resources returns the Resource element as a list.
+func (s Statement) Resources() []string {
+	return policyStrings(s.Resource)
+}
+
+// Principals returns the Principal element by principal type; "*" is
+// returned as {"AWS": ["*"]}.
+func (s Statement) Principals() map[string][]string {
+	return policyPrincipals(s.Principal)
+}
+
+func (s Statement) matchesAction(action string) bool {
+	if s.NotAction != nil {
+		return !matchAny(policyStrings(s.NotAction), action, true)
+	}
+
+	return matchAny(policyStrings(s.Action), action, true)
+}
+
+// matchesResource treats a statement without Resource, as in trust policies,
+// as applying to the resource the policy is attached to.
+func (s Statement) matchesResource(resource string) bool {
+	switch {
+	case s.NotResource != nil:
+		return !matchAny(policyStrings(s.NotResource), resource, false)
+	case s.Resource != nil:
+		return matchAny(policyStrings(s.Resource), resource, false)
+	}
+
+	return true
+}
+
+// matchesPrincipal applies to resource and trust policies only; identity
+// policy statements name no principal and apply to their holder.
+func (s Statement) matchesPrincipal(principal string) bool {
+	switch {
+	case s.NotPrincipal != nil:
+		return !principalMatches(policyPrincipals(s.NotPrincipal), principal)
+	case s.Principal != nil:
+		return principalMatches(policyPrincipals(s.Principal), principal)
+	}
+
+	return true
+}
+
+func principalMatches(principals map[string][]string, principal string) bool {
+	for _, values := range principals {
+		for _, value := range values {
+			if PrincipalMatches(value, principal) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// PrincipalMatches reports whether a principal named in a policy covers the
+// given principal. An account id or account root ARN covers every principal
+// of that account; STS assumed-role sessions are covered by their role.
+func PrincipalMatches(policyPrincipal, principal string) bool {
+	if policyPrincipal == "*" || policyPrincipal == principal {
+		return true
+	}
+
+	if account := PrincipalAccountID(policyPrincipal); account != "" && isAccountPrincipal(policyPrincipal) {
+		return PrincipalAccountID(principal) == account
+	}
+
+	if roleArn := SessionRoleArn(principal); roleArn != "" && roleArn != principal {
+		return wildcardMatch(policyPrincipal, roleArn, false)
+	}
+
+	return wildcardMatch(policyPrincipal, principal, false)
+}
+
+// PrincipalAccountID returns the account of an account id or IAM/STS ARN, or
+// "" for service and federated principals.
+func PrincipalAccountID(principal string) string {
+	if isAccountID(principal) {
+		return principal
+	}
+
+	if parsed, err := arn.Parse(principal); err == nil {
+		return parsed.AccountID
+	}
+
+	return ""
+}
+
+// SessionRoleArn returns the role ARN of an STS assumed-role session ARN,
+// or "" for any other principal.
+func SessionRoleArn(principal string) string {
+	parsed, err := arn.Parse(principal)
+	if err != nil || parsed.Service != "sts" || !strings.HasPrefix(parsed.Resource, "assumed-role/") {
+		return ""
+	}
+
+	parts := strings.Split(parsed.Resource, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parsed.Partition, parsed.AccountID, parts[1])
+}
+
+func isAccountPrincipal(principal string) bool {
+	if isAccountID(principal) {
+		return true
+	}
+
+	parsed, err := arn.Parse(principal)
+
+	return err == nil && parsed.Service == "iam" && parsed.Resource == "root"
+}
+
+func isAccountID(value string) bool {
+	if len(value) != 12 {
+		return false
+	}
+
+	_, err := strconv.ParseUint(value, 10, 64)
+
+	return err == nil
+}
+
+func matchAny(patterns []string, value string, ignoreCase bool) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, value, ignoreCase) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wildcardMatch matches value against a pattern where "*" is any run of
+// characters and "?" any single one.
+func wildcardMatch(pattern, value string, ignoreCase bool) bool {
+	if ignoreCase {
+		pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	}
+
+	p, v := 0, 0
+	star, mark := -1, 0
+
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// policyStrings normalizes a string-or-list policy element.
+func policyStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+
+		return values
+	}
+
+	return []string{fmt.Sprint(value)}
+}
+
+func policyPrincipals(value interface{}) map[string][]string {
+	principals := map[string][]string{}
+
+	switch v := value.(type) {
+	case string:
+		principals["AWS"] = []string{v}
+	case map[string]interface{}:
+		for principalType, values := range v {
+			principals[principalType] = policyStrings(values)
+		}
+	case map[string][]string:
+		for principalType, values := range v {
+			principals[principalType] = values
+		}
+	}
+
+	return principals
+}
@@ -0,0 +1,154 @@
+package iam
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustPolicy(t *testing.T, doc string) PolicyDocument {
+	t.Helper()
+
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(doc), &policy))
+
+	return policy
+}
+
+func TestPolicyDocumentSingleStatement(t *testing.T) {
+	policy := mustPolicy(t, `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Action":"s3:*","Resource":"*"}}`)
+
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, []string{"s3:*"}, policy.Statement[0].Actions())
+}
+
+func TestEvaluate(t *testing.T) {
+	identity := mustPolicy(t, `{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Action": ["s3:Get*", "s3:List*"], "Resource": "arn:aws:s3:::data/*"},
+			{"Effect": "Allow", "NotAction": "iam:*", "Resource": "arn:aws:ec2:*:*:instance/*"},
+			{"Effect": "Deny", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::data/secret/*"},
+			{"Effect": "Deny", "Action": "*", "NotResource": ["arn:aws:s3:::data/*", "arn:aws:ec2:*"]}
+		]
+	}`)
+
+	cases := []struct {
+		name     string
+		action   string
+		resource string
+		want     Decision
+	}{
+		{"wildcard action", "s3:GetObject", "arn:aws:s3:::data/report.csv", DecisionAllow},
+		{"action is case insensitive", "S3:listBucket", "arn:aws:s3:::data/x", DecisionAllow},
+		{"explicit deny wins", "s3:GetObject", "arn:aws:s3:::data/secret/key", DecisionExplicitDeny},
+		{"not action allows", "ec2:StopInstances", "arn:aws:ec2:eu-west-1:123456789012:instance/i-1", DecisionAllow},
+		{"not action excludes", "iam:PassRole", "arn:aws:ec2:eu-west-1:123456789012:instance/i-1", DecisionImplicitDeny},
+		{"not resource denies", "s3:GetObject", "arn:aws:s3:::other/key", DecisionExplicitDeny},
+		{"no statement allows", "s3:PutObject", "arn:aws:s3:::data/key", DecisionImplicitDeny},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Evaluate(AccessRequest{Action: tc.action, Resource: tc.resource}, identity)
+			assert.Equal(t, tc.want, result.Decision)
+		})
+	}
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	policy := mustPolicy(t, `{
+		"Statement": [{
+			"Effect": "Allow",
+			"Action": "ec2:TerminateInstances",
+			"Resource": "*",
+			"Condition": {
+				"Bool": {"aws:MultiFactorAuthPresent": "true"},
+				"StringLike": {"aws:PrincipalTag/team": ["platform-*"]},
+				"NumericLessThan": {"aws:MultiFactorAuthAge": 3600},
+				"ForAllValues:StringEquals": {"aws:TagKeys": ["env", "owner"]},
+				"StringNotEqualsIfExists": {"aws:RequestedRegion": "us-east-1"}
+			}
+		}]
+	}`)
+
+	request := AccessRequest{
+		Action:   "ec2:TerminateInstances",
+		Resource: "arn:aws:ec2:eu-west-1:123456789012:instance/i-1",
+		Context: map[string][]string{
+			"aws:MultiFactorAuthPresent": {"true"},
+			"aws:principaltag/team":      {"platform-core"},
+			"aws:MultiFactorAuthAge":     {"120"},
+			"aws:TagKeys":                {"env"},
+		},
+	}
+	assert.Equal(t, DecisionAllow, Evaluate(request, policy).Decision)
+
+	request.Context["aws:RequestedRegion"] = []string{"us-east-1"}
+	assert.Equal(t, DecisionImplicitDeny, Evaluate(request, policy).Decision)
+
+	delete(request.Context, "aws:RequestedRegion")
+	request.Context["aws:TagKeys"] = []string{"env", "cost-center"}
+	assert.Equal(t, DecisionImplicitDeny, Evaluate(request, policy).Decision)
+
+	request.Context["aws:TagKeys"] = []string{"env"}
+	request.Context["aws:MultiFactorAuthAge"] = []string{"7200"}
+	assert.Equal(t, DecisionImplicitDeny, Evaluate(request, policy).Decision)
+}
+
+func TestEvaluateUnsupportedCondition(t *testing.T) {
+	policy := mustPolicy(t, `{"Statement": [
+		{"Effect": "Allow", "Action": "s3:*", "Resource": "*", "Condition": {"BinaryEquals": {"s3:x": "AA=="}}},
+		{"Effect": "Deny", "Action": "s3:DeleteObject", "Resource": "*", "Condition": {"StringEqualsTypo": {"aws:PrincipalTag/team": "ops"}}}
+	]}`)
+
+	// an Allow that cannot be checked does not grant
+	result := Evaluate(AccessRequest{Action: "s3:GetObject", Resource: "arn:aws:s3:::b/k"}, policy)
+	assert.Equal(t, DecisionImplicitDeny, result.Decision)
+	assert.Equal(t, []string{"BinaryEquals"}, result.Unsupported)
+
+	// a Deny that cannot be checked applies
+	result = Evaluate(AccessRequest{Action: "s3:DeleteObject", Resource: "arn:aws:s3:::b/k"}, policy)
+	assert.Equal(t, DecisionExplicitDeny, result.Decision)
+	assert.Equal(t, []string{"BinaryEquals", "StringEqualsTypo"}, result.Unsupported)
+}
+
+func TestEvaluateIpAndDateConditions(t *testing.T) {
+	policy := mustPolicy(t, `{"Statement": [
+		{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "*", "Condition": {
+			"IpAddress": {"aws:SourceIp": ["10.0.0.0/8", "2001:db8::/32"]},
+			"DateLessThan": {"aws:CurrentTime": "2026-12-31T00:00:00Z"}
+		}},
+		{"Effect": "Deny", "Action": "s3:GetObject", "Resource": "*", "Condition": {
+			"NotIpAddress": {"aws:SourceIp": "10.0.0.0/16"},
+			"DateGreaterThanEquals": {"aws:CurrentTime": "2026-06-01"}
+		}}
+	]}`)
+
+	evaluate := func(ip, now string) Decision {
+		return Evaluate(AccessRequest{
+			Action:   "s3:GetObject",
+			Resource: "arn:aws:s3:::b/k",
+			Context:  map[string][]string{"aws:SourceIp": {ip}, "aws:CurrentTime": {now}},
+		}, policy).Decision
+	}
+
+	assert.Equal(t, DecisionAllow, evaluate("10.1.2.3", "2026-03-01T00:00:00Z"))
+	assert.Equal(t, DecisionAllow, evaluate("2001:db8::1", "2026-03-01T00:00:00Z"))
+	assert.Equal(t, DecisionImplicitDeny, evaluate("192.0.2.1", "2026-03-01T00:00:00Z"))
+	assert.Equal(t, DecisionImplicitDeny, evaluate("10.0.2.3", "2027-01-01T00:00:00Z"))
+	// outside 10.0.0.0/16 from June on
+	assert.Equal(t, DecisionExplicitDeny, evaluate("10.1.2.3", "2026-07-01T00:00:00Z"))
+	assert.Equal(t, DecisionAllow, evaluate("10.0.2.3", "1782864000"))
+}
+
+func TestPrincipalMatches(t *testing.T) {
+	assert.True(t, PrincipalMatches("*", "arn:aws:iam::111111111111:role/a"))
+	assert.True(t, PrincipalMatches("111111111111", "arn:aws:iam::111111111111:user/bob"))
+	assert.True(t, PrincipalMatches("arn:aws:iam::111111111111:root", "arn:aws:iam::111111111111:role/a"))
+	assert.False(t, PrincipalMatches("arn:aws:iam::111111111111:root", "arn:aws:iam::222222222222:role/a"))
+	assert.True(t, PrincipalMatches("arn:aws:iam::111111111111:role/a", "arn:aws:sts::111111111111:assumed-role/a/session"))
+	assert.False(t, PrincipalMatches("arn:aws:iam::111111111111:role/a", "arn:aws:iam::111111111111:role/b"))
+}
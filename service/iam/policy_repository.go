@@ -146,6 +146,63 @@ func (r *IamRepository) ListAttachedRolePoliciesByInput(query *iam.ListAttachedR
 	return policies, nil
 }
 
+// ListRoleInlinePolicyDocumentsByRole returns the decoded inline policies of
+// a role, which ListAttachedRolePolicies does not cover.
+func (r *IamRepository) ListRoleInlinePolicyDocumentsByRole(role Role) ([]PolicyDocument, error) {
+	start := time.Now()
+	var documents []PolicyDocument
+
+	p := iam.NewListRolePoliciesPaginator(r.iamClient(), &iam.ListRolePoliciesInput{RoleName: role.RoleName})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListRolePolicies", cfg.ResourceTypePolicy)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListRolePolicies", cfg.ResourceTypePolicy)).Inc()
+			}
+
+			return documents, errors.New(err)
+		}
+
+		for _, name := range resp.PolicyNames {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequests.With(r.promLabels("GetRolePolicy", cfg.ResourceTypePolicy)).Inc()
+			}
+
+			policy, err := r.iamClient().GetRolePolicy(r.ctx, &iam.GetRolePolicyInput{RoleName: role.RoleName, PolicyName: aws.String(name)})
+			if err != nil {
+				if metrics.AwsMetricsEnabled {
+					metrics.AwsApiRequestErrors.With(r.promLabels("GetRolePolicy", cfg.ResourceTypePolicy)).Inc()
+				}
+
+				return documents, errors.New(err)
+			}
+
+			document, err := decodePolicyDocument(aws.ToString(policy.PolicyDocument))
+			if err != nil {
+				return documents, err
+			}
+
+			documents = append(documents, document)
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetRolePolicy", cfg.ResourceTypePolicy)).
+			Add(float64(len(documents)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListRoleInlinePolicyDocumentsByRole", cfg.ResourceTypePolicy)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return documents, nil
+}
+
 func (r *IamRepository) ListAttachedRolePolicyVersionsByRoleName(name string) ([]PolicyVersion, error) {
 	return r.ListAttachedRolePolicyVersionsByInput(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String(name)})
 }
@@ -37,6 +37,11 @@ func (e Role) GetName() string {
 	return aws.ToString(e.RoleName)
 }
 
+// GetTrustPolicy decodes the policy naming who may assume the role.
+func (e Role) GetTrustPolicy() (PolicyDocument, error) {
+	return decodePolicyDocument(aws.ToString(e.AssumeRolePolicyDocument))
+}
+
 func (e Role) GetTags() map[string]string {
 	tags := make(map[string]string)
 
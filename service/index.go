@@ -0,0 +1,49 @@
+package service
+
+import (
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+type accountResourceID struct {
+	account ptypes.AwsAccountID
+	id      string
+}
+
+// ResourceIndex looks up inventory resources the way other services name
+// them in their reports: by ARN, or by ID within an account, e.g. an instance
+// or volume ID in a Health event or an AwsAccount resource of a finding.
+type ResourceIndex struct {
+	byArn map[string]ResourceInterface
+	byID  map[accountResourceID]ResourceInterface
+}
+
+func NewResourceIndex(inventory []ResourceInterface) *ResourceIndex {
+	index := &ResourceIndex{
+		byArn: map[string]ResourceInterface{},
+		byID:  map[accountResourceID]ResourceInterface{},
+	}
+
+	for _, resource := range inventory {
+		if resourceArn := resource.GetArn(); resourceArn != "" {
+			index.byArn[resourceArn] = resource
+		}
+
+		if id := resource.GetId(); id != "" {
+			index.byID[accountResourceID{resource.GetAccountID(), id}] = resource
+		}
+	}
+
+	return index
+}
+
+// Find returns the resource whose ARN is value, or else the resource of the
+// account whose ID is value.
+func (x *ResourceIndex) Find(account ptypes.AwsAccountID, value string) (ResourceInterface, bool) {
+	if resource, ok := x.byArn[value]; ok {
+		return resource, true
+	}
+
+	resource, ok := x.byID[accountResourceID{account, value}]
+
+	return resource, ok
+}
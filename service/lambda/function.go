@@ -42,6 +42,12 @@ func (e Function) GetTags() map[string]string {
 	return e.Tags
 }
 
+// WithTags returns a copy carrying the given tags, for bulk tag enrichment.
+func (e Function) WithTags(tags map[string]string) service.ResourceInterface {
+	e.Tags = tags
+	return e
+}
+
 func (e Function) GetTagValue(tag string) string {
 	val, ok := e.Tags[tag]
 	if !ok {
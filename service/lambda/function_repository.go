@@ -13,6 +13,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/lambda"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -75,7 +76,10 @@ func (r *LambdaRepository) ListFunctionsByInput(query *awslambda.ListFunctionsIn
 		}
 
 		for _, v := range resp.Functions {
-			tags, _ := r.ListFunctionTags(v)
+			var tags map[string]string
+			if !service.TagFetchSkipped(r.ctx) {
+				tags, _ = r.ListFunctionTags(v)
+			}
 			secret := NewFunction(r.client, v, tags)
 			functions = append(functions, secret)
 		}
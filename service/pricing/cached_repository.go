@@ -5,7 +5,10 @@ import (
 	"fmt"
 
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/imunhatep/awslib/cache"
 	ptypes "github.com/imunhatep/awslib/provider/types"
 )
@@ -26,6 +29,34 @@ func (r *PricingRepository) WithCache(dc *cache.DataCache) *PricingRepositoryCac
 	}
 }
 
+// GetAttributeValues returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetAttributeValues(serviceCode string, attributeName string) ([]string, error) {
+	cacheKey := cache.Key("GetAttributeValues", serviceCode, attributeName)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetAttributeValues(serviceCode, attributeName)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetDataTransferOutPricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetDataTransferOutPricing(region ptypes.AwsRegion) (*PriceItem, error) {
+	cacheKey := cache.Key("GetDataTransferOutPricing", region)
+	var cached *PriceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetDataTransferOutPricing(region)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
 // GetInstancePricing returns cached results when available, otherwise delegates to the underlying repository.
 func (c *PricingRepositoryCached) GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*Ec2Product, error) {
 	cacheKey := cache.Key("GetInstancePricing", region, instanceType)
@@ -53,3 +84,129 @@ func (c *PricingRepositoryCached) GetInstancePricingByInput(query *awspricing.Ge
 	}
 	return r0, r1
 }
+
+// GetInterRegionTransferPricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetInterRegionTransferPricing(from ptypes.AwsRegion, to ptypes.AwsRegion) (*PriceItem, error) {
+	cacheKey := cache.Key("GetInterRegionTransferPricing", from, to)
+	var cached *PriceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetInterRegionTransferPricing(from, to)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetLambdaPricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetLambdaPricing(region ptypes.AwsRegion, architecture lambdatypes.Architecture) (*LambdaPricing, error) {
+	cacheKey := cache.Key("GetLambdaPricing", region, architecture)
+	var cached *LambdaPricing
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetLambdaPricing(region, architecture)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetLoadBalancerPricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetLoadBalancerPricing(region ptypes.AwsRegion, lbType LoadBalancerType) (*LoadBalancerPricing, error) {
+	cacheKey := cache.Key("GetLoadBalancerPricing", region, lbType)
+	var cached *LoadBalancerPricing
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetLoadBalancerPricing(region, lbType)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetNatGatewayPricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetNatGatewayPricing(region ptypes.AwsRegion) (*NatGatewayPricing, error) {
+	cacheKey := cache.Key("GetNatGatewayPricing", region)
+	var cached *NatGatewayPricing
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetNatGatewayPricing(region)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetProductsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetProductsByInput(query *awspricing.GetProductsInput) ([]PriceItem, error) {
+	cacheKey := cache.Key("GetProductsByInput", query)
+	var cached []PriceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetProductsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetRdsInstancePricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetRdsInstancePricing(region ptypes.AwsRegion, instanceClass string, engine string, deploymentOption string) (*RdsProduct, error) {
+	cacheKey := cache.Key("GetRdsInstancePricing", region, instanceClass, engine, deploymentOption)
+	var cached *RdsProduct
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetRdsInstancePricing(region, instanceClass, engine, deploymentOption)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetS3StoragePricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetS3StoragePricing(region ptypes.AwsRegion, storageClass s3types.StorageClass) (*PriceItem, error) {
+	cacheKey := cache.Key("GetS3StoragePricing", region, storageClass)
+	var cached *PriceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetS3StoragePricing(region, storageClass)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetServices returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetServices(serviceCode string) ([]types.Service, error) {
+	cacheKey := cache.Key("GetServices", serviceCode)
+	var cached []types.Service
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetServices(serviceCode)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetVolumePricing returns cached results when available, otherwise delegates to the underlying repository.
+func (c *PricingRepositoryCached) GetVolumePricing(region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*VolumePricing, error) {
+	cacheKey := cache.Key("GetVolumePricing", region, volumeType)
+	var cached *VolumePricing
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetVolumePricing(region, volumeType)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
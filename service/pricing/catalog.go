@@ -0,0 +1,305 @@
+package pricing
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/rs/zerolog/log"
+)
+
+// snapshotFormat is bumped whenever the snapshot encoding changes.
+const snapshotFormat = 1
+
+// Offer is the content of one bulk price list offer file: every product of a
+// service at one price list version.
+type Offer struct {
+	OfferCode       string
+	Version         string
+	PublicationDate string
+	Items           []PriceItem
+
+	// index maps a normalized attribute name to its values and the positions
+	// of the items having them
+	index map[string]map[string][]int
+}
+
+func (o *Offer) buildIndex() {
+	o.index = map[string]map[string][]int{}
+
+	add := func(name, value string, i int) {
+		key := normalizeAttribute(name)
+		if o.index[key] == nil {
+			o.index[key] = map[string][]int{}
+		}
+		o.index[key][value] = append(o.index[key][value], i)
+	}
+
+	for i, item := range o.Items {
+		add("productFamily", item.Product.ProductFamily, i)
+		add("sku", item.Product.SKU, i)
+		for name, value := range item.Product.Attributes {
+			add(name, value, i)
+		}
+	}
+}
+
+// match returns the positions of the items matching every filter, in file
+// order. Filter fields are compared ignoring case and punctuation, since the
+// CSV and JSON offer files spell attribute names differently.
+func (o *Offer) match(query *awspricing.GetProductsInput) []int {
+	var sets [][]int
+	for _, filter := range query.Filters {
+		positions := o.index[normalizeAttribute(aws.ToString(filter.Field))][aws.ToString(filter.Value)]
+		if len(positions) == 0 {
+			return nil
+		}
+		sets = append(sets, positions)
+	}
+
+	if len(sets) == 0 {
+		all := make([]int, len(o.Items))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+
+	matched := sets[0]
+	for _, set := range sets[1:] {
+		var next []int
+		for _, i := range matched {
+			if _, found := slices.BinarySearch(set, i); found {
+				next = append(next, i)
+			}
+		}
+		matched = next
+	}
+
+	return matched
+}
+
+// Catalog answers price lookups offline from bulk price list offer files. It
+// implements PriceLookup, so it can stand in for PricingRepository wherever
+// estimates must not depend on the Price List API, and Version identifies the
+// prices it holds, so an estimate can name the catalog it was computed with.
+// A Catalog is read-only once built and safe for concurrent use.
+type Catalog struct {
+	offers  map[string]*Offer
+	version string
+}
+
+// NewCatalog indexes offers by their offer code; a later offer replaces an
+// earlier one of the same service.
+func NewCatalog(offers ...*Offer) *Catalog {
+	c := &Catalog{offers: map[string]*Offer{}}
+	for _, offer := range offers {
+		if offer.index == nil {
+			offer.buildIndex()
+		}
+		c.offers[offer.OfferCode] = offer
+	}
+	c.version = c.digest()
+
+	return c
+}
+
+// Offers returns the offers of the catalog ordered by offer code.
+func (c *Catalog) Offers() []*Offer {
+	offers := make([]*Offer, 0, len(c.offers))
+	for _, offer := range c.offers {
+		offers = append(offers, offer)
+	}
+
+	sort.Slice(offers, func(i, j int) bool { return offers[i].OfferCode < offers[j].OfferCode })
+
+	return offers
+}
+
+// Version identifies the catalog content: a digest of the offer codes, the
+// price list versions and the SKUs it holds. The SKUs tell apart catalogs
+// loaded from the same files with different CatalogLoader filters, so two
+// catalogs with the same version return the same prices.
+func (c *Catalog) Version() string {
+	return c.version
+}
+
+func (c *Catalog) digest() string {
+	h := sha256.New()
+	for _, offer := range c.Offers() {
+		fmt.Fprintf(h, "%s@%s:", offer.OfferCode, offer.Version)
+
+		skus := make([]string, 0, len(offer.Items))
+		for _, item := range offer.Items {
+			skus = append(skus, item.Product.SKU)
+		}
+		sort.Strings(skus)
+
+		fmt.Fprintf(h, "%s;", strings.Join(skus, ","))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// GetProductsByInput answers a GetProducts query from the offer of its
+// service code. Querying a service the catalog has no offer for is an error,
+// so a missing offer file is not mistaken for a missing price.
+func (c *Catalog) GetProductsByInput(query *awspricing.GetProductsInput) ([]PriceItem, error) {
+	offer, ok := c.offers[aws.ToString(query.ServiceCode)]
+	if !ok {
+		return nil, errors.Errorf("price catalog %s has no offer for service %q", c.Version(), aws.ToString(query.ServiceCode))
+	}
+
+	var items []PriceItem
+	for _, i := range offer.match(query) {
+		items = append(items, offer.Items[i])
+	}
+
+	return items, nil
+}
+
+// GetInstancePricingByInput mirrors PricingRepository.GetInstancePricingByInput,
+// returning the matching products as price list JSON documents.
+func (c *Catalog) GetInstancePricingByInput(query *awspricing.GetProductsInput) ([]string, error) {
+	items, err := c.GetProductsByInput(query)
+	if err != nil {
+		return []string{}, err
+	}
+
+	priceList := make([]string, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return []string{}, errors.New(err)
+		}
+		priceList = append(priceList, string(data))
+	}
+
+	return priceList, nil
+}
+
+// GetInstancePricing answers PricingRepository.GetInstancePricing offline,
+// with the same Linux, shared tenancy assumptions. Returns nil when not found.
+func (c *Catalog) GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*Ec2Product, error) {
+	priceList, err := c.GetInstancePricingByInput(instancePricingQuery(region, instanceType))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(priceList) > 1 {
+		log.Warn().
+			Str("instanceType", string(instanceType)).
+			Msgf("[Catalog.GetInstancePricing] multiple pricing items found")
+	}
+
+	for _, priceItem := range priceList {
+		return NewEc2Product(priceItem)
+	}
+
+	return nil, nil
+}
+
+func (c *Catalog) GetRdsInstancePricing(region ptypes.AwsRegion, instanceClass, engine, deploymentOption string) (*RdsProduct, error) {
+	return rdsInstancePricing(c, region, instanceClass, engine, deploymentOption)
+}
+
+func (c *Catalog) GetVolumePricing(region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*VolumePricing, error) {
+	return volumePricing(c, region, volumeType)
+}
+
+func (c *Catalog) GetS3StoragePricing(region ptypes.AwsRegion, storageClass s3types.StorageClass) (*PriceItem, error) {
+	return s3StoragePricing(c, region, storageClass)
+}
+
+func (c *Catalog) GetLambdaPricing(region ptypes.AwsRegion, architecture lambdatypes.Architecture) (*LambdaPricing, error) {
+	return lambdaPricing(c, region, architecture)
+}
+
+func (c *Catalog) GetNatGatewayPricing(region ptypes.AwsRegion) (*NatGatewayPricing, error) {
+	return natGatewayPricing(c, region)
+}
+
+func (c *Catalog) GetDataTransferOutPricing(region ptypes.AwsRegion) (*PriceItem, error) {
+	return dataTransferOutPricing(c, region)
+}
+
+func (c *Catalog) GetInterRegionTransferPricing(from, to ptypes.AwsRegion) (*PriceItem, error) {
+	return interRegionTransferPricing(c, from, to)
+}
+
+func (c *Catalog) GetLoadBalancerPricing(region ptypes.AwsRegion, lbType LoadBalancerType) (*LoadBalancerPricing, error) {
+	return loadBalancerPricing(c, region, lbType)
+}
+
+type catalogSnapshot struct {
+	Format int
+	Offers []*Offer
+}
+
+// WriteSnapshot stores the catalog as a gzipped gob, which loads much faster
+// than the offer files it was built from. Keep the snapshot next to the
+// estimates computed with it to reproduce them.
+func (c *Catalog) WriteSnapshot(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	zw.Comment = "awslib price catalog " + c.Version()
+
+	if err := gob.NewEncoder(zw).Encode(catalogSnapshot{Format: snapshotFormat, Offers: c.Offers()}); err != nil {
+		return errors.New(err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// ReadSnapshot loads a catalog written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*Catalog, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	defer zr.Close()
+
+	var snapshot catalogSnapshot
+	if err := gob.NewDecoder(zr).Decode(&snapshot); err != nil {
+		return nil, errors.New(err)
+	}
+
+	if snapshot.Format != snapshotFormat {
+		return nil, errors.Errorf("price catalog snapshot format %d, expected %d", snapshot.Format, snapshotFormat)
+	}
+
+	return NewCatalog(snapshot.Offers...), nil
+}
+
+// normalizeAttribute lowercases an attribute name and drops everything but
+// letters and digits: the JSON offer files say "preInstalledSw", the CSV ones
+// "Pre Installed S/W".
+func normalizeAttribute(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,462 @@
+package pricing
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// csvTermColumns are the CSV offer file columns describing a price rather
+// than a product; every other column after them is a product attribute.
+var csvTermColumns = []string{
+	"SKU", "OfferTermCode", "RateCode", "TermType", "PriceDescription", "EffectiveDate",
+	"StartingRange", "EndingRange", "Unit", "PricePerUnit", "Currency",
+	"LeaseContractLength", "PurchaseOption", "OfferingClass", "Product Family",
+}
+
+// CatalogLoader reads bulk price list offer files, as downloaded from
+// https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/index.json, into
+// Offers. The EC2 offer file runs into gigabytes; restrict the loader to the
+// regions and product families needed to keep the catalog small. Files are
+// streamed, never read into memory whole.
+type CatalogLoader struct {
+	regions  []string
+	families []string
+}
+
+func NewCatalogLoader() *CatalogLoader {
+	return &CatalogLoader{}
+}
+
+// WithRegions keeps the products of the given region codes, plus products
+// without a region such as global data transfer.
+func (l *CatalogLoader) WithRegions(regions ...string) *CatalogLoader {
+	l.regions = regions
+	return l
+}
+
+// WithProductFamilies keeps the products of the given families, e.g.
+// "Compute Instance" and "Storage".
+func (l *CatalogLoader) WithProductFamilies(families ...string) *CatalogLoader {
+	l.families = families
+	return l
+}
+
+// LoadDir loads every .json, .csv, .json.gz and .csv.gz offer file of a
+// directory into one catalog.
+func (l *CatalogLoader) LoadDir(dir string) (*Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	var offers []*Offer
+	for _, entry := range entries {
+		if entry.IsDir() || offerFormat(entry.Name()) == "" {
+			continue
+		}
+
+		offer, err := l.LoadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		offers = append(offers, offer)
+	}
+
+	return NewCatalog(offers...), nil
+}
+
+// LoadFile loads one offer file; the format follows from its extension.
+func (l *CatalogLoader) LoadFile(path string) (*Offer, error) {
+	format := offerFormat(path)
+	if format == "" {
+		return nil, errors.Errorf("%s: not a .json or .csv offer file", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, errors.New(err)
+		}
+		defer zr.Close()
+
+		r = zr
+	}
+
+	var offer *Offer
+	if format == "csv" {
+		offer, err = l.ReadCSV(r)
+	} else {
+		offer, err = l.ReadJSON(r)
+	}
+	if err != nil {
+		return nil, errors.Errorf("%s: %w", path, err)
+	}
+
+	log.Debug().
+		Str("offer", offer.OfferCode).
+		Str("version", offer.Version).
+		Int("products", len(offer.Items)).
+		Msgf("[CatalogLoader.LoadFile] loaded %s", path)
+
+	return offer, nil
+}
+
+// ReadJSON decodes a JSON offer file: a "products" object keyed by SKU
+// followed by a "terms" object keyed by term type, then SKU.
+func (l *CatalogLoader) ReadJSON(r io.Reader) (*Offer, error) {
+	dec := json.NewDecoder(r)
+	offer := &Offer{}
+
+	products := map[string]PriceItemProduct{}
+	var skus []string
+	terms := map[string]*Terms{}
+	productsRead := false
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		key, err := stringToken(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case "offerCode":
+			err = dec.Decode(&offer.OfferCode)
+		case "version":
+			err = dec.Decode(&offer.Version)
+		case "publicationDate":
+			err = dec.Decode(&offer.PublicationDate)
+		case "products":
+			err = eachMember(dec, func(sku string) error {
+				var product PriceItemProduct
+				if err := dec.Decode(&product); err != nil {
+					return errors.New(err)
+				}
+
+				if l.keep(product) {
+					products[sku] = product
+					skus = append(skus, sku)
+				}
+				return nil
+			})
+			productsRead = true
+		case "terms":
+			err = eachMember(dec, func(termType string) error {
+				return eachMember(dec, func(sku string) error {
+					var skuTerms map[string]Term
+					if err := dec.Decode(&skuTerms); err != nil {
+						return errors.New(err)
+					}
+
+					// terms normally follow products; when they do not, keep
+					// them all and drop the unused ones below
+					if _, ok := products[sku]; !ok && productsRead {
+						return nil
+					}
+
+					if terms[sku] == nil {
+						terms[sku] = &Terms{}
+					}
+					switch termType {
+					case "OnDemand":
+						terms[sku].OnDemand = skuTerms
+					case "Reserved":
+						terms[sku].Reserved = skuTerms
+					}
+					return nil
+				})
+			})
+		default:
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+
+		if err != nil {
+			return nil, errors.New(err)
+		}
+	}
+
+	for _, sku := range skus {
+		item := PriceItem{
+			Product:         products[sku],
+			ServiceCode:     offer.OfferCode,
+			Version:         offer.Version,
+			PublicationDate: offer.PublicationDate,
+		}
+		if t, ok := terms[sku]; ok {
+			item.Terms = *t
+		}
+
+		offer.Items = append(offer.Items, item)
+	}
+
+	return offer, nil
+}
+
+// ReadCSV decodes a CSV offer file: metadata lines, a header, then one line
+// per price dimension. Lines of the same SKU are merged into one item. Prices
+// not in USD are skipped, as PricePerUnit only carries USD.
+func (l *CatalogLoader) ReadCSV(r io.Reader) (*Offer, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	offer := &Offer{}
+
+	var header []string
+	for header == nil {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, errors.Errorf("reading offer metadata: %w", err)
+		}
+
+		if len(record) < 2 {
+			continue
+		}
+
+		switch normalizeAttribute(record[0]) {
+		case "sku":
+			header = slices.Clone(record)
+		case "offercode":
+			offer.OfferCode = record[1]
+		case "version":
+			offer.Version = record[1]
+		case "publicationdate":
+			offer.PublicationDate = record[1]
+		}
+	}
+
+	column := map[string]int{}
+	var attributes []int
+	for i, name := range header {
+		column[name] = i
+		if !slices.Contains(csvTermColumns, name) {
+			attributes = append(attributes, i)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := column[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	items := map[string]*PriceItem{}
+	var skus []string
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New(err)
+		}
+
+		sku := field(record, "SKU")
+		item, seen := items[sku]
+		if !seen {
+			product := PriceItemProduct{
+				ProductFamily: field(record, "Product Family"),
+				SKU:           sku,
+				Attributes:    map[string]string{},
+			}
+			for _, i := range attributes {
+				if i < len(record) && record[i] != "" {
+					product.Attributes[csvAttributeName(header[i])] = record[i]
+				}
+			}
+
+			if !l.keep(product) {
+				items[sku] = nil
+				continue
+			}
+
+			item = &PriceItem{
+				Product:         product,
+				ServiceCode:     offer.OfferCode,
+				Version:         offer.Version,
+				PublicationDate: offer.PublicationDate,
+			}
+			items[sku] = item
+			skus = append(skus, sku)
+		}
+
+		if item == nil || field(record, "Currency") != "USD" {
+			continue
+		}
+
+		addCSVPrice(item, field, record)
+	}
+
+	for _, sku := range skus {
+		offer.Items = append(offer.Items, *items[sku])
+	}
+
+	return offer, nil
+}
+
+func addCSVPrice(item *PriceItem, field func([]string, string) string, record []string) {
+	termCode := field(record, "OfferTermCode")
+	termKey := item.Product.SKU + "." + termCode
+
+	terms := &item.Terms.OnDemand
+	if field(record, "TermType") == "Reserved" {
+		terms = &item.Terms.Reserved
+	}
+	if *terms == nil {
+		*terms = map[string]Term{}
+	}
+
+	term, ok := (*terms)[termKey]
+	if !ok {
+		term = Term{
+			SKU:             item.Product.SKU,
+			OfferTermCode:   termCode,
+			EffectiveDate:   field(record, "EffectiveDate"),
+			PriceDimensions: map[string]PriceDimension{},
+			TermAttributes: TermAttributes{
+				LeaseContractLength: field(record, "LeaseContractLength"),
+				OfferingClass:       ec2types.OfferingClassType(field(record, "OfferingClass")),
+				PurchaseOption:      ec2types.OfferingTypeValues(field(record, "PurchaseOption")),
+			},
+		}
+	}
+
+	rateCode := field(record, "RateCode")
+	term.PriceDimensions[rateCode] = PriceDimension{
+		Unit:         field(record, "Unit"),
+		BeginRange:   field(record, "StartingRange"),
+		EndRange:     field(record, "EndingRange"),
+		Description:  field(record, "PriceDescription"),
+		RateCode:     rateCode,
+		PricePerUnit: PricePerUnit{USD: field(record, "PricePerUnit")},
+	}
+
+	(*terms)[termKey] = term
+}
+
+func (l *CatalogLoader) keep(product PriceItemProduct) bool {
+	if len(l.families) > 0 && !slices.Contains(l.families, product.ProductFamily) {
+		return false
+	}
+
+	if len(l.regions) == 0 {
+		return true
+	}
+
+	region := product.Attributes["regionCode"]
+	if region == "" {
+		region = product.Attributes["fromRegionCode"]
+	}
+
+	return region == "" || slices.Contains(l.regions, region)
+}
+
+// csvAttributeName turns a CSV column title into the camel case the JSON
+// files use, e.g. "Instance Type" into "instanceType".
+func csvAttributeName(title string) string {
+	var b strings.Builder
+	for i, word := range strings.Fields(title) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, word)
+		if word == "" {
+			continue
+		}
+
+		if i == 0 {
+			b.WriteString(strings.ToLower(word[:1]) + word[1:])
+		} else {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+
+	return b.String()
+}
+
+func offerFormat(path string) string {
+	name := strings.TrimSuffix(path, ".gz")
+	switch {
+	case strings.HasSuffix(name, ".json"):
+		return "json"
+	case strings.HasSuffix(name, ".csv"):
+		return "csv"
+	}
+
+	return ""
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return errors.New(err)
+	}
+
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return errors.Errorf("offer file: expected %q, got %v", delim, token)
+	}
+
+	return nil
+}
+
+func stringToken(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", errors.New(err)
+	}
+
+	key, ok := token.(string)
+	if !ok {
+		return "", errors.Errorf("offer file: expected an object key, got %v", token)
+	}
+
+	return key, nil
+}
+
+// eachMember calls fn with the key of every member of the object the decoder
+// is at; fn must consume the member's value.
+func eachMember(dec *json.Decoder, fn func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		key, err := stringToken(dec)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
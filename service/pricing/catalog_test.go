@@ -0,0 +1,143 @@
+package pricing
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ec2Offer is an abridged AmazonEC2 JSON offer file: one instance in two
+// regions, and a gp3 volume in one.
+const ec2Offer = `{
+	"formatVersion": "v1.0",
+	"offerCode": "AmazonEC2",
+	"version": "20260101000000",
+	"publicationDate": "2026-01-01T00:00:00Z",
+	"products": {
+		"EU1": {"sku": "EU1", "productFamily": "Compute Instance", "attributes": {
+			"instanceType": "m5.large", "regionCode": "eu-west-1", "operatingSystem": "Linux",
+			"preInstalledSw": "NA", "tenancy": "Shared", "capacitystatus": "Used", "vcpu": "2", "memory": "8 GiB"}},
+		"US1": {"sku": "US1", "productFamily": "Compute Instance", "attributes": {
+			"instanceType": "m5.large", "regionCode": "us-east-1", "operatingSystem": "Linux",
+			"preInstalledSw": "NA", "tenancy": "Shared", "capacitystatus": "Used"}},
+		"EU2": {"sku": "EU2", "productFamily": "Storage", "attributes": {"volumeApiName": "gp3", "regionCode": "eu-west-1"}}
+	},
+	"terms": {
+		"OnDemand": {
+			"EU1": {"EU1.JRTCKXETXF": {"sku": "EU1", "offerTermCode": "JRTCKXETXF", "priceDimensions": {
+				"EU1.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.1070000000"}}}}},
+			"US1": {"US1.JRTCKXETXF": {"sku": "US1", "offerTermCode": "JRTCKXETXF", "priceDimensions": {
+				"US1.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.0960000000"}}}}},
+			"EU2": {"EU2.JRTCKXETXF": {"sku": "EU2", "offerTermCode": "JRTCKXETXF", "priceDimensions": {
+				"EU2.JRTCKXETXF.6YS6EN2CT7": {"unit": "GB-Mo", "beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.0880000000"}}}}}
+		}
+	}
+}`
+
+// s3Offer is an abridged AmazonS3 CSV offer file with a tiered price.
+const s3Offer = `"FormatVersion","v1.0"
+"Disclaimer","This pricing list is for informational purposes only."
+"Publication Date","2026-01-01T00:00:00Z"
+"Version","20260102000000"
+"OfferCode","AmazonS3"
+"SKU","OfferTermCode","RateCode","TermType","PriceDescription","EffectiveDate","StartingRange","EndingRange","Unit","PricePerUnit","Currency","Product Family","serviceCode","Location","Region Code","Volume Type","Storage Class"
+"S3A","JRTCKXETXF","S3A.JRTCKXETXF.1","OnDemand","first 50 TB","2026-01-01","0","51200","GB-Mo","0.023","USD","Storage","AmazonS3","EU (Ireland)","eu-west-1","Standard","General Purpose"
+"S3A","JRTCKXETXF","S3A.JRTCKXETXF.2","OnDemand","over 50 TB","2026-01-01","51200","Inf","GB-Mo","0.022","USD","Storage","AmazonS3","EU (Ireland)","eu-west-1","Standard","General Purpose"
+"S3B","JRTCKXETXF","S3B.JRTCKXETXF.1","OnDemand","standard","2026-01-01","0","Inf","GB-Mo","0.025","USD","Storage","AmazonS3","US East (Ohio)","us-east-2","Standard","General Purpose"
+`
+
+func writeOffers(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AmazonEC2.json"), []byte(ec2Offer), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "AmazonS3.csv"), []byte(s3Offer), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not an offer"), 0o644))
+
+	return dir
+}
+
+func TestCatalogInstancePricing(t *testing.T) {
+	catalog, err := NewCatalogLoader().LoadDir(writeOffers(t))
+	require.NoError(t, err)
+	require.Len(t, catalog.Offers(), 2)
+
+	product, err := catalog.GetInstancePricing("eu-west-1", ec2types.InstanceTypeM5Large)
+	require.NoError(t, err)
+	require.NotNil(t, product)
+	assert.Equal(t, "0.1070000000", product.GetOnDemandPrice())
+	assert.Equal(t, "2", product.GetInstanceVcpu())
+
+	missing, err := catalog.GetInstancePricing("eu-west-1", ec2types.InstanceTypeM5Xlarge)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	volume, err := catalog.GetVolumePricing("eu-west-1", ec2types.VolumeTypeGp3)
+	require.NoError(t, err)
+	require.NotNil(t, volume)
+	price, _ := volume.Storage.OnDemandPrice()
+	assert.Equal(t, "0.088", price.Price.String())
+	assert.Nil(t, volume.Iops, "the fixture has no IOPS price")
+
+	_, err = catalog.GetLambdaPricing("eu-west-1", "x86_64")
+	assert.ErrorContains(t, err, `no offer for service "AWSLambda"`)
+}
+
+func TestCatalogCSVOffer(t *testing.T) {
+	catalog, err := NewCatalogLoader().LoadDir(writeOffers(t))
+	require.NoError(t, err)
+
+	item, err := catalog.GetS3StoragePricing("eu-west-1", "STANDARD")
+	require.NoError(t, err)
+	require.NotNil(t, item)
+
+	assert.Equal(t, "Standard", item.GetAttribute("volumeType"))
+	assert.Equal(t, "eu-west-1", item.GetAttribute("regionCode"))
+	assert.Equal(t, "20260102000000", item.Version)
+	assert.Len(t, item.OnDemandTiers(), 2)
+}
+
+func TestCatalogLoaderRegions(t *testing.T) {
+	dir := writeOffers(t)
+
+	offer, err := NewCatalogLoader().WithRegions("us-east-1").LoadFile(filepath.Join(dir, "AmazonEC2.json"))
+	require.NoError(t, err)
+	require.Len(t, offer.Items, 1)
+	assert.Equal(t, "US1", offer.Items[0].GetSKU())
+	assert.NotEmpty(t, offer.Items[0].Terms.OnDemand)
+
+	offer, err = NewCatalogLoader().WithProductFamilies("Storage").LoadFile(filepath.Join(dir, "AmazonEC2.json"))
+	require.NoError(t, err)
+	require.Len(t, offer.Items, 1)
+	assert.Equal(t, "EU2", offer.Items[0].GetSKU())
+
+	// same files, same price list versions, different content
+	all, err := NewCatalogLoader().LoadDir(dir)
+	require.NoError(t, err)
+	filtered, err := NewCatalogLoader().WithRegions("us-east-1").LoadDir(dir)
+	require.NoError(t, err)
+	assert.NotEqual(t, all.Version(), filtered.Version())
+}
+
+func TestCatalogSnapshot(t *testing.T) {
+	catalog, err := NewCatalogLoader().LoadDir(writeOffers(t))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, catalog.WriteSnapshot(&buf))
+
+	restored, err := ReadSnapshot(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Version(), restored.Version())
+
+	product, err := restored.GetInstancePricing("us-east-1", ec2types.InstanceTypeM5Large)
+	require.NoError(t, err)
+	require.NotNil(t, product)
+	assert.Equal(t, "0.0960000000", product.GetOnDemandPrice())
+
+	newer := NewCatalog(&Offer{OfferCode: "AmazonEC2", Version: "20260201000000"})
+	assert.NotEqual(t, catalog.Version(), newer.Version())
+}
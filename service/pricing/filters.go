@@ -0,0 +1,66 @@
+package pricing
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+// Service codes of the Price List API, as listed by GetServices.
+const (
+	ServiceCodeEC2          = "AmazonEC2"
+	ServiceCodeRDS          = "AmazonRDS"
+	ServiceCodeS3           = "AmazonS3"
+	ServiceCodeLambda       = "AWSLambda"
+	ServiceCodeELB          = "AWSELB"
+	ServiceCodeDataTransfer = "AWSDataTransfer"
+	ServiceCodeVPC          = "AmazonVPC"
+)
+
+// TermMatch builds the only filter type the Price List API supports: an exact
+// match of a product attribute.
+func TermMatch(field, value string) types.Filter {
+	return types.Filter{
+		Type:  types.FilterTypeTermMatch,
+		Field: aws.String(field),
+		Value: aws.String(value),
+	}
+}
+
+// UsageType builds a region's usage type, e.g. "EU-NatGateway-Hours". The
+// price list prefixes usage types with a region code everywhere except
+// us-east-1. ok is false for a region whose prefix is not known: the bare
+// usage type would match the us-east-1 product instead.
+func UsageType(region ptypes.AwsRegion, usage string) (string, bool) {
+	if region == "us-east-1" {
+		return usage, true
+	}
+
+	prefix, ok := usageTypePrefixes[region.String()]
+	if !ok {
+		return "", false
+	}
+
+	return prefix + "-" + usage, true
+}
+
+var usageTypePrefixes = map[string]string{
+	"us-east-2":      "USE2",
+	"us-west-1":      "USW1",
+	"us-west-2":      "USW2",
+	"ca-central-1":   "CAN1",
+	"eu-west-1":      "EU",
+	"eu-west-2":      "EUW2",
+	"eu-west-3":      "EUW3",
+	"eu-central-1":   "EUC1",
+	"eu-central-2":   "EUC2",
+	"eu-north-1":     "EUN1",
+	"eu-south-1":     "EUS1",
+	"ap-south-1":     "APS3",
+	"ap-northeast-1": "APN1",
+	"ap-northeast-2": "APN2",
+	"ap-northeast-3": "APN3",
+	"ap-southeast-1": "APS1",
+	"ap-southeast-2": "APS2",
+	"sa-east-1":      "SAE1",
+}
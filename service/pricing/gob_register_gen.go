@@ -8,11 +8,22 @@ import "encoding/gob"
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
 	gob.Register(Attributes{})
+	gob.Register(Catalog{})
+	gob.Register(CatalogLoader{})
 	gob.Register(Ec2Product{})
+	gob.Register(LambdaPricing{})
+	gob.Register(LoadBalancerPricing{})
+	gob.Register(NatGatewayPricing{})
+	gob.Register(Offer{})
 	gob.Register(PriceDimension{})
+	gob.Register(PriceItem{})
+	gob.Register(PriceItemProduct{})
 	gob.Register(PricePerUnit{})
+	gob.Register(PriceTier{})
 	gob.Register(Product{})
+	gob.Register(RdsProduct{})
 	gob.Register(Term{})
 	gob.Register(TermAttributes{})
 	gob.Register(Terms{})
+	gob.Register(VolumePricing{})
 }
@@ -0,0 +1,271 @@
+package pricing
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-errors/errors"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/rs/zerolog/log"
+)
+
+// ProductSource returns the products matching a GetProducts query, from the
+// Price List API or from an offline Catalog.
+type ProductSource interface {
+	GetProductsByInput(query *awspricing.GetProductsInput) ([]PriceItem, error)
+}
+
+// PriceLookup is the lookup interface PricingRepository, its cached variant
+// and Catalog share, so estimates can be computed online or offline alike.
+type PriceLookup interface {
+	ProductSource
+	GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*Ec2Product, error)
+	GetRdsInstancePricing(region ptypes.AwsRegion, instanceClass, engine, deploymentOption string) (*RdsProduct, error)
+	GetVolumePricing(region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*VolumePricing, error)
+	GetS3StoragePricing(region ptypes.AwsRegion, storageClass s3types.StorageClass) (*PriceItem, error)
+	GetLambdaPricing(region ptypes.AwsRegion, architecture lambdatypes.Architecture) (*LambdaPricing, error)
+	GetNatGatewayPricing(region ptypes.AwsRegion) (*NatGatewayPricing, error)
+	GetDataTransferOutPricing(region ptypes.AwsRegion) (*PriceItem, error)
+	GetInterRegionTransferPricing(from, to ptypes.AwsRegion) (*PriceItem, error)
+	GetLoadBalancerPricing(region ptypes.AwsRegion, lbType LoadBalancerType) (*LoadBalancerPricing, error)
+}
+
+var (
+	_ PriceLookup = (*PricingRepository)(nil)
+	_ PriceLookup = (*PricingRepositoryCached)(nil)
+	_ PriceLookup = (*Catalog)(nil)
+)
+
+// s3VolumeTypes maps S3 storage classes to the price list's "volumeType"
+// attribute of S3 storage products.
+var s3VolumeTypes = map[s3types.StorageClass]string{
+	s3types.StorageClassStandard:           "Standard",
+	s3types.StorageClassStandardIa:         "Standard - Infrequent Access",
+	s3types.StorageClassOnezoneIa:          "One Zone - Infrequent Access",
+	s3types.StorageClassIntelligentTiering: "Intelligent-Tiering Frequent Access",
+	s3types.StorageClassGlacierIr:          "Glacier Instant Retrieval",
+	s3types.StorageClassGlacier:            "Amazon Glacier",
+	s3types.StorageClassDeepArchive:        "Glacier Deep Archive",
+	s3types.StorageClassReducedRedundancy:  "Reduced Redundancy",
+}
+
+// instancePricingQuery selects the on-demand product of a Linux, shared
+// tenancy instance type without pre-installed software.
+func instancePricingQuery(region ptypes.AwsRegion, instanceType ec2types.InstanceType) *awspricing.GetProductsInput {
+	return &awspricing.GetProductsInput{
+		ServiceCode: aws.String(ServiceCodeEC2),
+		Filters: []types.Filter{
+			TermMatch("instanceType", string(instanceType)),
+			TermMatch("regionCode", region.String()),
+			TermMatch("operatingSystem", "Linux"), // Assuming Linux instances, change as needed
+			TermMatch("preInstalledSw", "NA"),
+			TermMatch("tenancy", "Shared"),
+			TermMatch("capacitystatus", "Used"),
+		},
+	}
+}
+
+func rdsInstancePricing(src ProductSource, region ptypes.AwsRegion, instanceClass, engine, deploymentOption string) (*RdsProduct, error) {
+	item, err := findProduct(src, "GetRdsInstancePricing", ServiceCodeRDS,
+		TermMatch("productFamily", "Database Instance"),
+		TermMatch("regionCode", region.String()),
+		TermMatch("instanceType", instanceClass),
+		TermMatch("databaseEngine", RdsDatabaseEngine(engine)),
+		TermMatch("deploymentOption", deploymentOption),
+	)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return &RdsProduct{PriceItem: *item}, nil
+}
+
+func volumePricing(src ProductSource, region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*VolumePricing, error) {
+	storage, err := findProduct(src, "GetVolumePricing", ServiceCodeEC2,
+		TermMatch("productFamily", "Storage"),
+		TermMatch("regionCode", region.String()),
+		TermMatch("volumeApiName", string(volumeType)),
+	)
+	if err != nil || storage == nil {
+		return nil, err
+	}
+
+	prices := &VolumePricing{Storage: storage}
+
+	switch volumeType {
+	case ec2types.VolumeTypeGp3, ec2types.VolumeTypeIo1, ec2types.VolumeTypeIo2:
+		prices.Iops, err = findProduct(src, "GetVolumePricing", ServiceCodeEC2,
+			TermMatch("productFamily", "System Operation"),
+			TermMatch("regionCode", region.String()),
+			TermMatch("volumeApiName", string(volumeType)),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if volumeType == ec2types.VolumeTypeGp3 {
+		prices.Throughput, err = findProduct(src, "GetVolumePricing", ServiceCodeEC2,
+			TermMatch("productFamily", "Provisioned Throughput"),
+			TermMatch("regionCode", region.String()),
+			TermMatch("volumeApiName", string(volumeType)),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return prices, nil
+}
+
+func s3StoragePricing(src ProductSource, region ptypes.AwsRegion, storageClass s3types.StorageClass) (*PriceItem, error) {
+	volumeType, ok := s3VolumeTypes[storageClass]
+	if !ok {
+		return nil, errors.Errorf("no price list volume type for S3 storage class %q", storageClass)
+	}
+
+	return findProduct(src, "GetS3StoragePricing", ServiceCodeS3,
+		TermMatch("productFamily", "Storage"),
+		TermMatch("regionCode", region.String()),
+		TermMatch("volumeType", volumeType),
+	)
+}
+
+func lambdaPricing(src ProductSource, region ptypes.AwsRegion, architecture lambdatypes.Architecture) (*LambdaPricing, error) {
+	suffix := ""
+	if architecture == lambdatypes.ArchitectureArm64 {
+		suffix = "-ARM"
+	}
+
+	duration, err := findProduct(src, "GetLambdaPricing", ServiceCodeLambda,
+		TermMatch("regionCode", region.String()),
+		TermMatch("group", "AWS-Lambda-Duration"+suffix),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := findProduct(src, "GetLambdaPricing", ServiceCodeLambda,
+		TermMatch("regionCode", region.String()),
+		TermMatch("group", "AWS-Lambda-Requests"+suffix),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if duration == nil && requests == nil {
+		return nil, nil
+	}
+
+	return &LambdaPricing{Duration: duration, Requests: requests}, nil
+}
+
+func natGatewayPricing(src ProductSource, region ptypes.AwsRegion) (*NatGatewayPricing, error) {
+	hoursUsage, ok := UsageType(region, "NatGateway-Hours")
+	if !ok {
+		return nil, nil
+	}
+	dataUsage, _ := UsageType(region, "NatGateway-Bytes")
+
+	hours, err := findProduct(src, "GetNatGatewayPricing", ServiceCodeEC2,
+		TermMatch("regionCode", region.String()),
+		TermMatch("productFamily", "NAT Gateway"),
+		TermMatch("usagetype", hoursUsage),
+	)
+	if err != nil || hours == nil {
+		return nil, err
+	}
+
+	data, err := findProduct(src, "GetNatGatewayPricing", ServiceCodeEC2,
+		TermMatch("regionCode", region.String()),
+		TermMatch("productFamily", "NAT Gateway"),
+		TermMatch("usagetype", dataUsage),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NatGatewayPricing{Hours: hours, DataProcessed: data}, nil
+}
+
+func dataTransferOutPricing(src ProductSource, region ptypes.AwsRegion) (*PriceItem, error) {
+	return findProduct(src, "GetDataTransferOutPricing", ServiceCodeDataTransfer,
+		TermMatch("fromRegionCode", region.String()),
+		TermMatch("transferType", "AWS Outbound"),
+	)
+}
+
+func interRegionTransferPricing(src ProductSource, from, to ptypes.AwsRegion) (*PriceItem, error) {
+	return findProduct(src, "GetInterRegionTransferPricing", ServiceCodeDataTransfer,
+		TermMatch("fromRegionCode", from.String()),
+		TermMatch("toRegionCode", to.String()),
+		TermMatch("transferType", "InterRegion Outbound"),
+	)
+}
+
+func loadBalancerPricing(src ProductSource, region ptypes.AwsRegion, lbType LoadBalancerType) (*LoadBalancerPricing, error) {
+	family, ok := loadBalancerFamilies[lbType]
+	if !ok {
+		return nil, errors.Errorf("unknown load balancer type %q", lbType)
+	}
+
+	capacityUsage := "LCUUsage"
+	if lbType == LoadBalancerClassic {
+		capacityUsage = "DataProcessing-Bytes"
+	}
+
+	hoursUsage, ok := UsageType(region, "LoadBalancerUsage")
+	if !ok {
+		return nil, nil
+	}
+	capacityUsage, _ = UsageType(region, capacityUsage)
+
+	hours, err := findProduct(src, "GetLoadBalancerPricing", ServiceCodeELB,
+		TermMatch("regionCode", region.String()),
+		TermMatch("productFamily", family),
+		TermMatch("usagetype", hoursUsage),
+	)
+	if err != nil || hours == nil {
+		return nil, err
+	}
+
+	capacity, err := findProduct(src, "GetLoadBalancerPricing", ServiceCodeELB,
+		TermMatch("regionCode", region.String()),
+		TermMatch("productFamily", family),
+		TermMatch("usagetype", capacityUsage),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoadBalancerPricing{Hours: hours, CapacityUnits: capacity}, nil
+}
+
+// findProduct returns the first product matching the filters, or nil when
+// none does. Several matches mean the filters are not specific enough; the
+// first is used and a warning logged, as GetInstancePricing does.
+func findProduct(src ProductSource, method, serviceCode string, filters ...types.Filter) (*PriceItem, error) {
+	items, err := src.GetProductsByInput(&awspricing.GetProductsInput{
+		ServiceCode: aws.String(serviceCode),
+		Filters:     filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if len(items) > 1 {
+		log.Warn().
+			Str("serviceCode", serviceCode).
+			Int("count", len(items)).
+			Msgf("[pricing.%s] multiple pricing items found", method)
+	}
+
+	return &items[0], nil
+}
@@ -0,0 +1,132 @@
+package pricing
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/go-errors/errors"
+	"github.com/shopspring/decimal"
+)
+
+// PriceItem is one Price List API product of any service. Services name their
+// attributes differently, so they are kept as a map; Ec2Product remains the
+// typed view of EC2 instances.
+type PriceItem struct {
+	Product         PriceItemProduct `json:"product"`
+	ServiceCode     string           `json:"serviceCode"`
+	Terms           Terms            `json:"terms"`
+	Version         string           `json:"version"`
+	PublicationDate string           `json:"publicationDate"`
+}
+
+type PriceItemProduct struct {
+	ProductFamily string            `json:"productFamily"`
+	Attributes    map[string]string `json:"attributes"`
+	SKU           string            `json:"sku"`
+}
+
+// PriceTier is one on-demand price dimension. Begin and End bound the usage
+// the price applies to, in Unit; End is zero for the last, unbounded tier.
+type PriceTier struct {
+	Begin       decimal.Decimal `json:"begin"`
+	End         decimal.Decimal `json:"end"`
+	Unit        string          `json:"unit"`
+	Price       decimal.Decimal `json:"price"`
+	Description string          `json:"description"`
+}
+
+func NewPriceItem(priceList string) (*PriceItem, error) {
+	item := &PriceItem{}
+	err := json.Unmarshal([]byte(priceList), item)
+	if err != nil {
+		return &PriceItem{}, errors.New(err)
+	}
+
+	return item, nil
+}
+
+func (p PriceItem) GetProductFamily() string {
+	return p.Product.ProductFamily
+}
+
+func (p PriceItem) GetSKU() string {
+	return p.Product.SKU
+}
+
+// GetAttribute returns a product attribute, e.g. "instanceType" or
+// "volumeApiName"; GetAttributeValues lists the values a service uses. Names
+// are matched ignoring case and punctuation when there is no exact match, as
+// items loaded from CSV offer files spell them differently.
+func (p PriceItem) GetAttribute(name string) string {
+	if value, ok := p.Product.Attributes[name]; ok {
+		return value
+	}
+
+	key := normalizeAttribute(name)
+	for attribute, value := range p.Product.Attributes {
+		if normalizeAttribute(attribute) == key {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// OnDemandTiers returns the USD on-demand price dimensions ordered by the
+// usage they start at. Flat prices have a single tier.
+func (p PriceItem) OnDemandTiers() []PriceTier {
+	var tiers []PriceTier
+	for _, term := range p.Terms.OnDemand {
+		for _, dimension := range term.PriceDimensions {
+			price, err := decimal.NewFromString(dimension.PricePerUnit.USD)
+			if err != nil {
+				continue
+			}
+
+			tier := PriceTier{Unit: dimension.Unit, Price: price, Description: dimension.Description}
+			tier.Begin, _ = decimal.NewFromString(dimension.BeginRange)
+			if dimension.EndRange != "Inf" {
+				tier.End, _ = decimal.NewFromString(dimension.EndRange)
+			}
+
+			tiers = append(tiers, tier)
+		}
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].Begin.LessThan(tiers[j].Begin)
+	})
+
+	return tiers
+}
+
+// OnDemandPrice returns the price of the first tier, which for flat prices is
+// the only one.
+func (p PriceItem) OnDemandPrice() (PriceTier, bool) {
+	tiers := p.OnDemandTiers()
+	if len(tiers) == 0 {
+		return PriceTier{}, false
+	}
+
+	return tiers[0], true
+}
+
+// OnDemandCost prices a quantity of usage, in the unit of the tiers, walking
+// the tiers so that e.g. S3 storage beyond 50 TB is charged at the lower rate.
+func (p PriceItem) OnDemandCost(quantity decimal.Decimal) decimal.Decimal {
+	cost := decimal.Zero
+	for _, tier := range p.OnDemandTiers() {
+		if quantity.LessThanOrEqual(tier.Begin) {
+			break
+		}
+
+		upper := quantity
+		if !tier.End.IsZero() && tier.End.LessThan(quantity) {
+			upper = tier.End
+		}
+
+		cost = cost.Add(upper.Sub(tier.Begin).Mul(tier.Price))
+	}
+
+	return cost
+}
@@ -0,0 +1,124 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// s3Standard is an abridged AmazonS3 price list item with three storage tiers.
+const s3Standard = `{
+	"product": {"productFamily": "Storage", "sku": "SKU1", "attributes": {"volumeType": "Standard", "regionCode": "eu-west-1"}},
+	"serviceCode": "AmazonS3",
+	"terms": {"OnDemand": {"SKU1.T": {"priceDimensions": {
+		"SKU1.T.2": {"unit": "GB-Mo", "beginRange": "51200", "endRange": "512000", "pricePerUnit": {"USD": "0.022"}},
+		"SKU1.T.1": {"unit": "GB-Mo", "beginRange": "0", "endRange": "51200", "pricePerUnit": {"USD": "0.023"}},
+		"SKU1.T.3": {"unit": "GB-Mo", "beginRange": "512000", "endRange": "Inf", "pricePerUnit": {"USD": "0.021"}}
+	}}}}
+}`
+
+func TestPriceItemTiers(t *testing.T) {
+	item, err := NewPriceItem(s3Standard)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Storage", item.GetProductFamily())
+	assert.Equal(t, "Standard", item.GetAttribute("volumeType"))
+
+	tiers := item.OnDemandTiers()
+	require.Len(t, tiers, 3)
+	assert.Equal(t, "0", tiers[0].Begin.String())
+	assert.Equal(t, "512000", tiers[1].End.String())
+	assert.True(t, tiers[2].End.IsZero(), "the last tier is unbounded")
+
+	first, ok := item.OnDemandPrice()
+	require.True(t, ok)
+	assert.Equal(t, "0.023", first.Price.String())
+	assert.Equal(t, "GB-Mo", first.Unit)
+
+	assert.Equal(t, "23", item.OnDemandCost(decimal.NewFromInt(1000)).String())
+
+	// 51200 GB at 0.023, 460800 GB at 0.022, 87488 GB at 0.021
+	assert.Equal(t, "13152.448", item.OnDemandCost(decimal.NewFromInt(599488)).String())
+	assert.True(t, item.OnDemandCost(decimal.Zero).IsZero())
+}
+
+func TestLambdaPricingCost(t *testing.T) {
+	duration, err := NewPriceItem(`{"terms": {"OnDemand": {"t": {"priceDimensions": {"d": {"beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.0000166667"}}}}}}}`)
+	require.NoError(t, err)
+
+	requests, err := NewPriceItem(`{"terms": {"OnDemand": {"t": {"priceDimensions": {"d": {"beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.0000002"}}}}}}}`)
+	require.NoError(t, err)
+
+	cost := LambdaPricing{Duration: duration, Requests: requests}.Cost(decimal.NewFromInt(100000), decimal.NewFromInt(1000000))
+	assert.Equal(t, "1.86667", cost.String())
+
+	assert.True(t, LambdaPricing{}.Cost(decimal.NewFromInt(1), decimal.NewFromInt(1)).IsZero())
+}
+
+func TestRdsDatabaseEngine(t *testing.T) {
+	assert.Equal(t, "PostgreSQL", RdsDatabaseEngine("postgres"))
+	assert.Equal(t, "Aurora MySQL", RdsDatabaseEngine("aurora-mysql"))
+	assert.Equal(t, "PostgreSQL", RdsDatabaseEngine("PostgreSQL"))
+}
+
+func TestUsageType(t *testing.T) {
+	usage, ok := UsageType("us-east-1", "NatGateway-Hours")
+	assert.True(t, ok)
+	assert.Equal(t, "NatGateway-Hours", usage)
+
+	usage, ok = UsageType("eu-central-1", "NatGateway-Hours")
+	assert.True(t, ok)
+	assert.Equal(t, "EUC1-NatGateway-Hours", usage)
+
+	_, ok = UsageType("xx-nowhere-1", "NatGateway-Hours")
+	assert.False(t, ok, "an unmapped region must not fall back to the us-east-1 usage type")
+}
+
+// recordingSource answers every query with one product and records the
+// filters it was asked for.
+type recordingSource struct {
+	queries []map[string]string
+}
+
+func (s *recordingSource) GetProductsByInput(query *awspricing.GetProductsInput) ([]PriceItem, error) {
+	filters := map[string]string{}
+	for _, f := range query.Filters {
+		filters[aws.ToString(f.Field)] = aws.ToString(f.Value)
+	}
+	s.queries = append(s.queries, filters)
+
+	return []PriceItem{{}}, nil
+}
+
+func TestRegionalLookupsMatchRegion(t *testing.T) {
+	src := &recordingSource{}
+
+	nat, err := natGatewayPricing(src, "eu-central-1")
+	require.NoError(t, err)
+	require.NotNil(t, nat)
+
+	lb, err := loadBalancerPricing(src, "eu-central-1", LoadBalancerApplication)
+	require.NoError(t, err)
+	require.NotNil(t, lb)
+
+	require.Len(t, src.queries, 4)
+	for _, filters := range src.queries {
+		assert.Equal(t, "eu-central-1", filters["regionCode"])
+		assert.Contains(t, filters["usagetype"], "EUC1-")
+	}
+
+	// no prefix known: no price rather than the us-east-1 one
+	src.queries = nil
+	nat, err = natGatewayPricing(src, "xx-nowhere-1")
+	require.NoError(t, err)
+	assert.Nil(t, nat)
+
+	lb, err = loadBalancerPricing(src, "xx-nowhere-1", LoadBalancerNetwork)
+	require.NoError(t, err)
+	assert.Nil(t, lb)
+	assert.Empty(t, src.queries)
+}
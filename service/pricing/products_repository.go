@@ -0,0 +1,175 @@
+package pricing
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// GetProductsByInput returns every product matching the query, following
+// NextToken pagination, parsed into PriceItem.
+func (r *PricingRepository) GetProductsByInput(query *awspricing.GetProductsInput) ([]PriceItem, error) {
+	start := time.Now()
+	var items []PriceItem
+
+	p := awspricing.NewGetProductsPaginator(r.pricingClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetProducts", ccfg.ResourceTypePriceListProduct)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetProducts", ccfg.ResourceTypePriceListProduct)).Inc()
+			}
+
+			return items, errors.New(err)
+		}
+
+		for _, priceItem := range resp.PriceList {
+			item, err := NewPriceItem(priceItem)
+			if err != nil {
+				return items, err
+			}
+
+			items = append(items, *item)
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetProducts", ccfg.ResourceTypePriceListProduct)).
+			Add(float64(len(items)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("GetProductsByInput", ccfg.ResourceTypePriceListProduct)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return items, nil
+}
+
+// GetServices describes a service's product attribute names, or those of every
+// service when serviceCode is empty.
+func (r *PricingRepository) GetServices(serviceCode string) ([]types.Service, error) {
+	query := &awspricing.DescribeServicesInput{}
+	if serviceCode != "" {
+		query.ServiceCode = aws.String(serviceCode)
+	}
+
+	var services []types.Service
+
+	p := awspricing.NewDescribeServicesPaginator(r.pricingClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeServices", ccfg.ResourceTypePriceListService)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeServices", ccfg.ResourceTypePriceListService)).Inc()
+			}
+
+			return services, errors.New(err)
+		}
+
+		services = append(services, resp.Services...)
+	}
+
+	return services, nil
+}
+
+// GetAttributeValues lists the values a service uses for a product attribute,
+// e.g. every "volumeApiName" of AmazonEC2, so filters can be built from them.
+func (r *PricingRepository) GetAttributeValues(serviceCode, attributeName string) ([]string, error) {
+	query := &awspricing.GetAttributeValuesInput{
+		ServiceCode:   aws.String(serviceCode),
+		AttributeName: aws.String(attributeName),
+	}
+
+	var values []string
+
+	p := awspricing.NewGetAttributeValuesPaginator(r.pricingClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetAttributeValues", ccfg.ResourceTypePriceListAttributeValue)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetAttributeValues", ccfg.ResourceTypePriceListAttributeValue)).Inc()
+			}
+
+			return values, errors.New(err)
+		}
+
+		for _, value := range resp.AttributeValues {
+			values = append(values, aws.ToString(value.Value))
+		}
+	}
+
+	return values, nil
+}
+
+// GetRdsInstancePricing returns the on-demand product of an RDS instance
+// class, e.g. "db.r6g.large". The engine is either an RDS API name such as
+// "postgres" or a price list name such as "PostgreSQL"; deploymentOption is
+// DeploymentSingleAZ or DeploymentMultiAZ. Returns nil when not found.
+func (r *PricingRepository) GetRdsInstancePricing(region ptypes.AwsRegion, instanceClass, engine, deploymentOption string) (*RdsProduct, error) {
+	return rdsInstancePricing(r, region, instanceClass, engine, deploymentOption)
+}
+
+// GetVolumePricing returns the storage, IOPS and throughput prices of an EBS
+// volume type. Returns nil when the type has no storage price in the region.
+func (r *PricingRepository) GetVolumePricing(region ptypes.AwsRegion, volumeType ec2types.VolumeType) (*VolumePricing, error) {
+	return volumePricing(r, region, volumeType)
+}
+
+// GetS3StoragePricing returns the per GB-month storage price of an S3 storage
+// class, tiered by the amount stored. Returns nil when not found.
+func (r *PricingRepository) GetS3StoragePricing(region ptypes.AwsRegion, storageClass s3types.StorageClass) (*PriceItem, error) {
+	return s3StoragePricing(r, region, storageClass)
+}
+
+// GetLambdaPricing returns the duration and request prices of an
+// architecture. Returns nil when neither is found.
+func (r *PricingRepository) GetLambdaPricing(region ptypes.AwsRegion, architecture lambdatypes.Architecture) (*LambdaPricing, error) {
+	return lambdaPricing(r, region, architecture)
+}
+
+// GetNatGatewayPricing returns the hourly and per GB processed prices of a NAT
+// gateway. Returns nil when the hourly price is not found.
+func (r *PricingRepository) GetNatGatewayPricing(region ptypes.AwsRegion) (*NatGatewayPricing, error) {
+	return natGatewayPricing(r, region)
+}
+
+// GetDataTransferOutPricing returns the per GB price of data transferred from
+// a region to the internet, tiered by monthly volume. Returns nil when not
+// found.
+func (r *PricingRepository) GetDataTransferOutPricing(region ptypes.AwsRegion) (*PriceItem, error) {
+	return dataTransferOutPricing(r, region)
+}
+
+// GetInterRegionTransferPricing returns the per GB price of data transferred
+// from one region to another. Returns nil when not found.
+func (r *PricingRepository) GetInterRegionTransferPricing(from, to ptypes.AwsRegion) (*PriceItem, error) {
+	return interRegionTransferPricing(r, from, to)
+}
+
+// GetLoadBalancerPricing returns the hourly and capacity unit prices of a load
+// balancer type. Returns nil when the hourly price is not found.
+func (r *PricingRepository) GetLoadBalancerPricing(region ptypes.AwsRegion, lbType LoadBalancerType) (*LoadBalancerPricing, error) {
+	return loadBalancerPricing(r, region, lbType)
+}
@@ -3,11 +3,9 @@ package pricing
 import (
 	"context"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	awspricing "github.com/aws/aws-sdk-go-v2/service/pricing"
-	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
 	"github.com/go-errors/errors"
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
@@ -50,43 +48,7 @@ func (r *PricingRepository) promLabels(method string, resourceType cfg.ResourceT
 
 // GetInstancePricing fetches the pricing for a given instance type using the AWS Pricing API.
 func (r *PricingRepository) GetInstancePricing(region ptypes.AwsRegion, instanceType ec2types.InstanceType) (*Ec2Product, error) {
-
-	// Define the pricing filter for EC2 On-Demand instances in a specific region
-	query := &awspricing.GetProductsInput{
-		ServiceCode: aws.String("AmazonEC2"),
-		Filters: []types.Filter{
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("instanceType"),
-				Value: aws.String(string(instanceType)),
-			},
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("regionCode"),
-				Value: aws.String(region.String()),
-			},
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("operatingSystem"),
-				Value: aws.String("Linux"), // Assuming Linux instances, change as needed
-			},
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("preInstalledSw"),
-				Value: aws.String("NA"),
-			},
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("tenancy"),
-				Value: aws.String("Shared"),
-			},
-			{
-				Type:  types.FilterType("TERM_MATCH"),
-				Field: aws.String("capacitystatus"),
-				Value: aws.String("Used"),
-			},
-		},
-	}
+	query := instancePricingQuery(region, instanceType)
 
 	priceList, err := r.GetInstancePricingByInput(query)
 	if err != nil {
@@ -0,0 +1,131 @@
+package pricing
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// HoursPerMonth is the month length AWS uses to turn hourly prices into
+// monthly ones.
+const HoursPerMonth = 730
+
+// RDS deployment options, as the price list's "deploymentOption" attribute
+// names them.
+const (
+	DeploymentSingleAZ = "Single-AZ"
+	DeploymentMultiAZ  = "Multi-AZ"
+)
+
+// rdsEngines maps RDS API engine names to the price list's "databaseEngine"
+// values.
+var rdsEngines = map[string]string{
+	"mysql":             "MySQL",
+	"postgres":          "PostgreSQL",
+	"mariadb":           "MariaDB",
+	"aurora-mysql":      "Aurora MySQL",
+	"aurora-postgresql": "Aurora PostgreSQL",
+	"oracle-ee":         "Oracle",
+	"oracle-se2":        "Oracle",
+	"sqlserver-ee":      "SQL Server",
+	"sqlserver-se":      "SQL Server",
+	"sqlserver-ex":      "SQL Server",
+	"sqlserver-web":     "SQL Server",
+}
+
+// RdsDatabaseEngine translates an RDS API engine name, e.g. "postgres", to
+// the price list's, e.g. "PostgreSQL". Unknown names are returned unchanged.
+func RdsDatabaseEngine(engine string) string {
+	if name, ok := rdsEngines[engine]; ok {
+		return name
+	}
+
+	return engine
+}
+
+// RdsProduct is an RDS database instance product.
+type RdsProduct struct {
+	PriceItem
+}
+
+func (p RdsProduct) GetInstanceType() string {
+	return p.GetAttribute("instanceType")
+}
+
+func (p RdsProduct) GetDatabaseEngine() string {
+	return p.GetAttribute("databaseEngine")
+}
+
+func (p RdsProduct) GetDeploymentOption() string {
+	return p.GetAttribute("deploymentOption")
+}
+
+func (p RdsProduct) GetInstanceVcpu() string {
+	return p.GetAttribute("vcpu")
+}
+
+func (p RdsProduct) GetInstanceMemory() string {
+	return p.GetAttribute("memory")
+}
+
+// VolumePricing holds the prices an EBS volume type is billed by: storage per
+// GB-month and, for the types that bill them, provisioned IOPS per
+// IOPS-month and throughput per MiBps-month. Prices a type does not bill are
+// nil.
+type VolumePricing struct {
+	Storage    *PriceItem `json:"storage"`
+	Iops       *PriceItem `json:"iops,omitempty"`
+	Throughput *PriceItem `json:"throughput,omitempty"`
+}
+
+// LambdaPricing holds the per GB-second duration price, tiered by monthly
+// usage, and the per request price of one architecture.
+type LambdaPricing struct {
+	Duration *PriceItem `json:"duration"`
+	Requests *PriceItem `json:"requests"`
+}
+
+// Cost prices a month of invocations. Either price may be missing from the
+// price list, in which case it contributes nothing.
+func (p LambdaPricing) Cost(gbSeconds, requests decimal.Decimal) decimal.Decimal {
+	cost := decimal.Zero
+	if p.Duration != nil {
+		cost = cost.Add(p.Duration.OnDemandCost(gbSeconds))
+	}
+	if p.Requests != nil {
+		cost = cost.Add(p.Requests.OnDemandCost(requests))
+	}
+
+	return cost
+}
+
+// NatGatewayPricing holds the hourly price of a NAT gateway and the price per
+// GB of data it processes.
+type NatGatewayPricing struct {
+	Hours         *PriceItem `json:"hours"`
+	DataProcessed *PriceItem `json:"dataProcessed"`
+}
+
+// LoadBalancerType selects the load balancer product family. The values match
+// the elasticloadbalancingv2 LoadBalancerTypeEnum, plus classic.
+type LoadBalancerType string
+
+const (
+	LoadBalancerApplication LoadBalancerType = "application"
+	LoadBalancerNetwork     LoadBalancerType = "network"
+	LoadBalancerGateway     LoadBalancerType = "gateway"
+	LoadBalancerClassic     LoadBalancerType = "classic"
+)
+
+var loadBalancerFamilies = map[LoadBalancerType]string{
+	LoadBalancerApplication: "Load Balancer-Application",
+	LoadBalancerNetwork:     "Load Balancer-Network",
+	LoadBalancerGateway:     "Load Balancer-Gateway",
+	LoadBalancerClassic:     "Load Balancer",
+}
+
+// LoadBalancerPricing holds the hourly price of a load balancer and the price
+// of its capacity units: LCU-hours for application, network and gateway load
+// balancers, GB processed for classic ones.
+type LoadBalancerPricing struct {
+	Hours         *PriceItem `json:"hours"`
+	CapacityUnits *PriceItem `json:"capacityUnits"`
+}
@@ -14,6 +14,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/s3"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -101,7 +102,10 @@ func (r *S3Repository) ListBucketsByInput(query *awss3.ListBucketsInput) ([]Buck
 			continue
 		}
 
-		tags, _ := r.GetTags(v)
+		var tags []types.Tag
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetTags(v)
+		}
 		bucket := NewBucket(r.client, v, tags)
 		buckets = append(buckets, bucket)
 	}
@@ -0,0 +1,67 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package savingsplans
+
+import (
+	"fmt"
+
+	awssp "github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// SavingsPlansRepositoryCached wraps SavingsPlansRepository and caches results of Get*/List* calls.
+type SavingsPlansRepositoryCached struct {
+	repo  *SavingsPlansRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a SavingsPlansRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *SavingsPlansRepository) WithCache(dc *cache.DataCache) *SavingsPlansRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &SavingsPlansRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListSavingsPlansActive returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SavingsPlansRepositoryCached) ListSavingsPlansActive() ([]SavingsPlan, error) {
+	cacheKey := cache.Key("ListSavingsPlansActive")
+	var cached []SavingsPlan
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListSavingsPlansActive()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListSavingsPlansAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SavingsPlansRepositoryCached) ListSavingsPlansAll() ([]SavingsPlan, error) {
+	cacheKey := cache.Key("ListSavingsPlansAll")
+	var cached []SavingsPlan
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListSavingsPlansAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListSavingsPlansByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SavingsPlansRepositoryCached) ListSavingsPlansByInput(query *awssp.DescribeSavingsPlansInput) ([]SavingsPlan, error) {
+	cacheKey := cache.Key("ListSavingsPlansByInput", query)
+	var cached []SavingsPlan
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListSavingsPlansByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -0,0 +1,74 @@
+package savingsplans
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/shopspring/decimal"
+)
+
+type SavingsPlan struct {
+	service.AbstractResource
+	types.SavingsPlan
+}
+
+func NewSavingsPlan(client AwsClient, plan types.SavingsPlan) SavingsPlan {
+	planArn, _ := arn.Parse(aws.ToString(plan.SavingsPlanArn))
+	startedAt, _ := time.Parse(time.RFC3339, aws.ToString(plan.Start))
+
+	return SavingsPlan{
+		AbstractResource: service.AbstractResource{
+			AccountID: client.GetAccountID(),
+			Region:    client.GetRegion(),
+			ID:        aws.ToString(plan.SavingsPlanId),
+			ARN:       &planArn,
+			CreatedAt: startedAt,
+			Type:      ccfg.ResourceTypeSavingsPlan,
+		},
+		SavingsPlan: plan,
+	}
+}
+
+func (e SavingsPlan) GetName() string {
+	return aws.ToString(e.SavingsPlan.SavingsPlanId)
+}
+
+func (e SavingsPlan) GetTags() map[string]string {
+	return e.SavingsPlan.Tags
+}
+
+func (e SavingsPlan) GetTagValue(tag string) string {
+	val, ok := e.GetTags()[tag]
+	if !ok {
+		return ""
+	}
+
+	return val
+}
+
+// GetEnd returns when the plan term ends.
+func (e SavingsPlan) GetEnd() time.Time {
+	end, _ := time.Parse(time.RFC3339, aws.ToString(e.SavingsPlan.End))
+	return end
+}
+
+// HourlyCommitment returns the committed spend per hour, in the plan currency.
+func (e SavingsPlan) HourlyCommitment() decimal.Decimal {
+	commitment, err := decimal.NewFromString(aws.ToString(e.SavingsPlan.Commitment))
+	if err != nil {
+		return decimal.Zero
+	}
+
+	return commitment
+}
+
+// ExpiresWithin reports whether an active plan's term ends within d of now,
+// the window in which a renewal has to be planned.
+func (e SavingsPlan) ExpiresWithin(now time.Time, d time.Duration) bool {
+	end := e.GetEnd()
+	return e.State == types.SavingsPlanStateActive && !end.IsZero() && end.Before(now.Add(d))
+}
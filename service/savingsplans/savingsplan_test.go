@@ -0,0 +1,42 @@
+package savingsplans
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "us-east-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func TestNewSavingsPlan(t *testing.T) {
+	plan := NewSavingsPlan(mockClient{}, types.SavingsPlan{
+		SavingsPlanId:  aws.String("sp-1"),
+		SavingsPlanArn: aws.String("arn:aws:savingsplans::123456789012:savingsplan/sp-1"),
+		Start:          aws.String("2024-11-01T00:00:00Z"),
+		End:            aws.String("2027-11-01T00:00:00Z"),
+		State:          types.SavingsPlanStateActive,
+		Commitment:     aws.String("12.5"),
+		Tags:           map[string]string{"team": "platform"},
+	})
+
+	assert.Equal(t, "sp-1", plan.GetId())
+	assert.Equal(t, ccfg.ResourceTypeSavingsPlan, plan.GetType())
+	assert.Equal(t, time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), plan.GetCreatedAt())
+	assert.Equal(t, "platform", plan.GetTagValue("team"))
+	assert.Equal(t, "12.5", plan.HourlyCommitment().String())
+
+	now := time.Date(2027, 9, 1, 0, 0, 0, 0, time.UTC)
+	assert.True(t, plan.ExpiresWithin(now, 90*24*time.Hour))
+	assert.False(t, plan.ExpiresWithin(now, 30*24*time.Hour))
+
+	plan.State = types.SavingsPlanStateRetired
+	assert.False(t, plan.ExpiresWithin(now, 90*24*time.Hour))
+}
@@ -0,0 +1,121 @@
+package savingsplans
+
+import (
+	"context"
+	"time"
+
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awssp "github.com/aws/aws-sdk-go-v2/service/savingsplans"
+	"github.com/aws/aws-sdk-go-v2/service/savingsplans/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/savingsplans"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// SavingsPlansRepository lists the Savings Plans of an account. The Savings
+// Plans API is global: use a client of any region, e.g. us-east-1, once per
+// account.
+type SavingsPlansRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewSavingsPlansRepository(ctx context.Context, client *v3.Client) *SavingsPlansRepository {
+	repo := &SavingsPlansRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *SavingsPlansRepository) savingsPlansClient() *awssp.Client {
+	return savingsplans.GetClient(r.client)
+}
+
+func (r *SavingsPlansRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *SavingsPlansRepository) GetAccountID() ptypes.AwsAccountID {
+	return r.client.GetAccountID()
+}
+
+func (r *SavingsPlansRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// ListSavingsPlansAll returns every Savings Plan of the account, whatever its
+// state.
+func (r *SavingsPlansRepository) ListSavingsPlansAll() ([]SavingsPlan, error) {
+	return r.ListSavingsPlansByInput(&awssp.DescribeSavingsPlansInput{})
+}
+
+// ListSavingsPlansActive returns the plans currently applying discounts.
+func (r *SavingsPlansRepository) ListSavingsPlansActive() ([]SavingsPlan, error) {
+	return r.ListSavingsPlansByInput(&awssp.DescribeSavingsPlansInput{
+		States: []types.SavingsPlanState{types.SavingsPlanStateActive},
+	})
+}
+
+// ListSavingsPlansByInput follows NextToken pagination; the SDK ships no
+// paginator for DescribeSavingsPlans.
+func (r *SavingsPlansRepository) ListSavingsPlansByInput(query *awssp.DescribeSavingsPlansInput) ([]SavingsPlan, error) {
+	start := time.Now()
+	var plans []SavingsPlan
+
+	nextToken := query.NextToken
+	for {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeSavingsPlans", ccfg.ResourceTypeSavingsPlan)).Inc()
+		}
+
+		page := *query
+		page.NextToken = nextToken
+
+		output, err := r.savingsPlansClient().DescribeSavingsPlans(r.ctx, &page)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeSavingsPlans", ccfg.ResourceTypeSavingsPlan)).Inc()
+			}
+
+			return plans, errors.New(err)
+		}
+
+		for _, plan := range output.SavingsPlans {
+			plans = append(plans, NewSavingsPlan(r.client, plan))
+		}
+
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+
+		nextToken = output.NextToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("DescribeSavingsPlans", ccfg.ResourceTypeSavingsPlan)).
+			Add(float64(len(plans)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListSavingsPlansByInput", ccfg.ResourceTypeSavingsPlan)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return plans, nil
+}
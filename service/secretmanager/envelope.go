@@ -0,0 +1,148 @@
+package secretmanager
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/go-errors/errors"
+)
+
+// dataKeySize is the AES-256 key size, of data keys and local keys alike.
+const dataKeySize = 32
+
+// KeyWrapper encrypts and decrypts the data keys of sealed values. LocalKey
+// is the in-process implementation; one backed by KMS fits the same
+// interface.
+type KeyWrapper interface {
+	Wrap(dataKey []byte) ([]byte, error)
+	Unwrap(wrapped []byte) ([]byte, error)
+}
+
+// LocalKey wraps data keys with AES-256-GCM under a key held in memory.
+type LocalKey struct {
+	aead cipher.AEAD
+}
+
+// NewLocalKey uses the given 32 byte key.
+func NewLocalKey(key []byte) (*LocalKey, error) {
+	if len(key) != dataKeySize {
+		return nil, errors.Errorf("local key must be %d bytes, got %d", dataKeySize, len(key))
+	}
+
+	aead, err := newAead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalKey{aead: aead}, nil
+}
+
+// NewRandomLocalKey generates a key that lives as long as the process: what
+// it seals cannot outlive a restart.
+func NewRandomLocalKey() (*LocalKey, error) {
+	key := make([]byte, dataKeySize)
+	defer clear(key)
+
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return NewLocalKey(key)
+}
+
+func (k *LocalKey) Wrap(dataKey []byte) ([]byte, error) {
+	return seal(k.aead, dataKey)
+}
+
+func (k *LocalKey) Unwrap(wrapped []byte) ([]byte, error) {
+	return open(k.aead, wrapped)
+}
+
+// sealedBox is plaintext encrypted under its own data key, with the data key
+// wrapped by a KeyWrapper.
+type sealedBox struct {
+	wrappedKey []byte
+	ciphertext []byte
+}
+
+// sealEnvelope encrypts plaintext under a fresh data key.
+func sealEnvelope(wrapper KeyWrapper, plaintext []byte) (sealedBox, error) {
+	dataKey := make([]byte, dataKeySize)
+	defer clear(dataKey)
+
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return sealedBox{}, errors.New(err)
+	}
+
+	aead, err := newAead(dataKey)
+	if err != nil {
+		return sealedBox{}, err
+	}
+
+	ciphertext, err := seal(aead, plaintext)
+	if err != nil {
+		return sealedBox{}, err
+	}
+
+	wrappedKey, err := wrapper.Wrap(dataKey)
+	if err != nil {
+		return sealedBox{}, errors.New(err)
+	}
+
+	return sealedBox{wrappedKey: wrappedKey, ciphertext: ciphertext}, nil
+}
+
+// openEnvelope decrypts a sealed box.
+func openEnvelope(wrapper KeyWrapper, box sealedBox) ([]byte, error) {
+	dataKey, err := wrapper.Unwrap(box.wrappedKey)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+	defer clear(dataKey)
+
+	aead, err := newAead(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(aead, box.ciphertext)
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return aead, nil
+}
+
+// seal returns the nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.New(err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed value too short")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New(err)
+	}
+
+	return plaintext, nil
+}
@@ -7,7 +7,12 @@ import "encoding/gob"
 // init registers this package's types with encoding/gob so they can be
 // serialized by the cache handlers. Importing this package is sufficient.
 func init() {
+	gob.Register(LocalKey{})
+	gob.Register(RotationAuditor{})
+	gob.Register(RotationIssue{})
+	gob.Register(SecretAudit{})
 	gob.Register(SecretEntry{})
 	gob.Register(SecretEntryList{})
 	gob.Register(SecretValue{})
+	gob.Register(SecretValueCache{})
 }
@@ -0,0 +1,216 @@
+package secretmanager
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	StageCurrent  = "AWSCURRENT"
+	StagePending  = "AWSPENDING"
+	StagePrevious = "AWSPREVIOUS"
+)
+
+// RotationIssueType names what is wrong with the rotation or use of a secret.
+type RotationIssueType string
+
+const (
+	// RotationDisabled: the secret is never rotated.
+	RotationDisabled RotationIssueType = "RotationDisabled"
+	// RotationOverdue: rotation is enabled, but the last one is older than its
+	// schedule allows.
+	RotationOverdue RotationIssueType = "RotationOverdue"
+	// RotationFailing: a rotation did not complete, or the rotation function
+	// is gone.
+	RotationFailing RotationIssueType = "RotationFailing"
+	// SecretUnused: nothing read the secret for a long time.
+	SecretUnused RotationIssueType = "SecretUnused"
+)
+
+// RotationIssue is one problem found on a secret.
+type RotationIssue struct {
+	Type   RotationIssueType `json:"type"`
+	Detail string            `json:"detail"`
+}
+
+// SecretAudit is a secret with the issues found on it.
+type SecretAudit struct {
+	Secret SecretEntry
+	Issues []RotationIssue
+}
+
+// HasIssue tells whether the audit found an issue of the given type.
+func (a SecretAudit) HasIssue(issueType RotationIssueType) bool {
+	return slices.ContainsFunc(a.Issues, func(i RotationIssue) bool { return i.Type == issueType })
+}
+
+// RotationAuditor checks secrets, as listed by ListSecretsAll, for disabled,
+// overdue and failing rotation and for secrets nobody reads.
+type RotationAuditor struct {
+	grace       time.Duration
+	unusedAfter time.Duration
+	// functions holds the rotation function ARNs known to exist; nil when not
+	// checked.
+	functions map[string]bool
+}
+
+// NewRotationAuditor audits with a day of grace on rotation schedules and
+// flags secrets not read for 90 days.
+func NewRotationAuditor() RotationAuditor {
+	return RotationAuditor{
+		grace:       24 * time.Hour,
+		unusedAfter: 90 * 24 * time.Hour,
+	}
+}
+
+// WithGrace sets how late a scheduled rotation may be before it is overdue.
+func (a RotationAuditor) WithGrace(grace time.Duration) RotationAuditor {
+	a.grace = grace
+	return a
+}
+
+// WithUnusedAfter sets how long a secret may go unread. Secrets Manager
+// records the last access by day only.
+func (a RotationAuditor) WithUnusedAfter(unusedAfter time.Duration) RotationAuditor {
+	a.unusedAfter = unusedAfter
+	return a
+}
+
+// WithFunctions gives the ARNs of the Lambda functions that exist, e.g. from
+// the lambda repository; a secret rotated by a function not among them is
+// failing. Without it, rotation functions are not checked.
+func (a RotationAuditor) WithFunctions(functionArns ...string) RotationAuditor {
+	a.functions = map[string]bool{}
+	for _, functionArn := range functionArns {
+		a.functions[unqualifiedFunctionArn(functionArn)] = true
+	}
+
+	return a
+}
+
+// Audit returns the secrets with at least one issue at the time now. Secrets
+// scheduled for deletion are skipped.
+func (a RotationAuditor) Audit(secrets []SecretEntry, now time.Time) []SecretAudit {
+	var audits []SecretAudit
+	for _, secret := range secrets {
+		if secret.DeletedDate != nil {
+			continue
+		}
+
+		if issues := a.AuditSecret(secret, now); len(issues) > 0 {
+			audits = append(audits, SecretAudit{Secret: secret, Issues: issues})
+		}
+	}
+
+	return audits
+}
+
+// AuditSecret returns the issues of one secret at the time now.
+func (a RotationAuditor) AuditSecret(secret SecretEntry, now time.Time) []RotationIssue {
+	var issues []RotationIssue
+
+	if aws.ToBool(secret.RotationEnabled) {
+		issues = append(issues, a.auditRotation(secret, now)...)
+	} else {
+		issues = append(issues, RotationIssue{
+			Type:   RotationDisabled,
+			Detail: fmt.Sprintf("last changed %s", formatDate(secret.LastChangedDate)),
+		})
+	}
+
+	lastAccessed := aws.ToTime(secret.LastAccessedDate)
+	if lastAccessed.IsZero() {
+		lastAccessed = aws.ToTime(secret.CreatedDate)
+	}
+
+	if !lastAccessed.IsZero() && now.Sub(lastAccessed) > a.unusedAfter {
+		issues = append(issues, RotationIssue{
+			Type:   SecretUnused,
+			Detail: fmt.Sprintf("last accessed %s", formatDate(secret.LastAccessedDate)),
+		})
+	}
+
+	return issues
+}
+
+func (a RotationAuditor) auditRotation(secret SecretEntry, now time.Time) []RotationIssue {
+	var issues []RotationIssue
+
+	lastRotated := aws.ToTime(secret.LastRotatedDate)
+	if lastRotated.IsZero() {
+		lastRotated = aws.ToTime(secret.CreatedDate)
+	}
+
+	if next := aws.ToTime(secret.NextRotationDate); !next.IsZero() && now.Sub(next) > a.grace {
+		issues = append(issues, RotationIssue{
+			Type:   RotationOverdue,
+			Detail: fmt.Sprintf("rotation was due %s, last rotated %s", next.Format(time.DateOnly), formatDate(secret.LastRotatedDate)),
+		})
+	} else if days := rotationDays(secret); days > 0 && now.Sub(lastRotated) > time.Duration(days)*24*time.Hour+a.grace {
+		issues = append(issues, RotationIssue{
+			Type:   RotationOverdue,
+			Detail: fmt.Sprintf("rotated every %d days, last rotated %s", days, formatDate(secret.LastRotatedDate)),
+		})
+	}
+
+	if version, ok := pendingVersion(secret.VersionIdsToStages); ok {
+		issues = append(issues, RotationIssue{
+			Type:   RotationFailing,
+			Detail: fmt.Sprintf("version %s is still %s", version, StagePending),
+		})
+	}
+
+	if fn := aws.ToString(secret.RotationLambdaARN); a.functions != nil && fn != "" && !a.functions[unqualifiedFunctionArn(fn)] {
+		issues = append(issues, RotationIssue{
+			Type:   RotationFailing,
+			Detail: fmt.Sprintf("rotation function %s does not exist", fn),
+		})
+	}
+
+	return issues
+}
+
+// rotationDays is the rotation interval in days, or 0 when the secret rotates
+// on a schedule expression.
+func rotationDays(secret SecretEntry) int64 {
+	if secret.RotationRules == nil {
+		return 0
+	}
+
+	return aws.ToInt64(secret.RotationRules.AutomaticallyAfterDays)
+}
+
+// pendingVersion finds a version labelled AWSPENDING but not AWSCURRENT: a
+// rotation that started and never finished. A completed rotation moves
+// AWSCURRENT onto the pending version.
+func pendingVersion(versions map[string][]string) (string, bool) {
+	for version, stages := range versions {
+		if slices.Contains(stages, StagePending) && !slices.Contains(stages, StageCurrent) {
+			return version, true
+		}
+	}
+
+	return "", false
+}
+
+// unqualifiedFunctionArn drops a version or alias from a function ARN.
+func unqualifiedFunctionArn(functionArn string) string {
+	// arn:aws:lambda:<region>:<account>:function:<name>[:<qualifier>]
+	if parts := strings.Split(functionArn, ":"); len(parts) > 7 {
+		return strings.Join(parts[:7], ":")
+	}
+
+	return functionArn
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "never"
+	}
+
+	return t.Format(time.DateOnly)
+}
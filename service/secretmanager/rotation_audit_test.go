@@ -0,0 +1,104 @@
+package secretmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sm "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-central-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+var auditNow = time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(days int) *time.Time {
+	return aws.Time(auditNow.AddDate(0, 0, -days))
+}
+
+func secret(name string, output sm.DescribeSecretOutput) SecretEntry {
+	output.Name = aws.String(name)
+	output.ARN = aws.String("arn:aws:secretsmanager:eu-central-1:123456789012:secret:" + name)
+	if output.CreatedDate == nil {
+		output.CreatedDate = daysAgo(400)
+	}
+	if output.LastAccessedDate == nil {
+		output.LastAccessedDate = daysAgo(1)
+	}
+
+	return NewSecretEntry(mockClient{}, &output)
+}
+
+func TestRotationAuditor(t *testing.T) {
+	const rotator = "arn:aws:lambda:eu-central-1:123456789012:function:rotate-db"
+
+	secrets := []SecretEntry{
+		secret("healthy", sm.DescribeSecretOutput{
+			RotationEnabled:    aws.Bool(true),
+			RotationRules:      &types.RotationRulesType{AutomaticallyAfterDays: aws.Int64(30)},
+			LastRotatedDate:    daysAgo(10),
+			NextRotationDate:   aws.Time(auditNow.AddDate(0, 0, 20)),
+			RotationLambdaARN:  aws.String(rotator + ":$LATEST"),
+			VersionIdsToStages: map[string][]string{"v2": {StageCurrent}, "v1": {StagePrevious}},
+		}),
+		secret("static", sm.DescribeSecretOutput{LastChangedDate: daysAgo(200)}),
+		secret("overdue", sm.DescribeSecretOutput{
+			RotationEnabled: aws.Bool(true),
+			RotationRules:   &types.RotationRulesType{AutomaticallyAfterDays: aws.Int64(30)},
+			LastRotatedDate: daysAgo(45),
+		}),
+		secret("stuck", sm.DescribeSecretOutput{
+			RotationEnabled:    aws.Bool(true),
+			LastRotatedDate:    daysAgo(3),
+			NextRotationDate:   daysAgo(2),
+			RotationLambdaARN:  aws.String("arn:aws:lambda:eu-central-1:123456789012:function:deleted"),
+			VersionIdsToStages: map[string][]string{"v2": {StagePending}, "v1": {StageCurrent}},
+		}),
+		secret("forgotten", sm.DescribeSecretOutput{
+			RotationEnabled:  aws.Bool(true),
+			LastRotatedDate:  daysAgo(1),
+			LastAccessedDate: daysAgo(120),
+		}),
+		secret("deleted", sm.DescribeSecretOutput{DeletedDate: daysAgo(1)}),
+	}
+
+	audits := NewRotationAuditor().WithFunctions(rotator).Audit(secrets, auditNow)
+
+	found := map[string][]RotationIssueType{}
+	for _, audit := range audits {
+		for _, issue := range audit.Issues {
+			found[audit.Secret.GetName()] = append(found[audit.Secret.GetName()], issue.Type)
+		}
+	}
+
+	assert.Equal(t, map[string][]RotationIssueType{
+		"static":    {RotationDisabled},
+		"overdue":   {RotationOverdue},
+		"stuck":     {RotationOverdue, RotationFailing, RotationFailing},
+		"forgotten": {SecretUnused},
+	}, found)
+
+	require.Len(t, audits, 4)
+	assert.Equal(t, "last changed 2025-11-13", audits[0].Issues[0].Detail)
+	assert.True(t, audits[2].HasIssue(RotationFailing))
+
+	// without known functions, rotation functions are not checked
+	stuck := NewRotationAuditor().AuditSecret(secrets[3], auditNow)
+	assert.Len(t, stuck, 2)
+}
+
+func TestNewSecretEntryFromListKeepsVersionStages(t *testing.T) {
+	entry := NewSecretEntryFromList(mockClient{}, types.SecretListEntry{
+		Name:                   aws.String("db"),
+		SecretVersionsToStages: map[string][]string{"v1": {StageCurrent}},
+	})
+
+	assert.Equal(t, map[string][]string{"v1": {StageCurrent}}, entry.VersionIdsToStages)
+}
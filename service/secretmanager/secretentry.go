@@ -52,6 +52,8 @@ func NewSecretEntryFromList(client AwsClient, secret types2.SecretListEntry) Sec
 		RotationRules:     secret.RotationRules,
 		RotationLambdaARN: secret.RotationLambdaARN,
 		Tags:              secret.Tags,
+
+		VersionIdsToStages: secret.SecretVersionsToStages,
 	}
 
 	return NewSecretEntry(client, describeSecretOutput)
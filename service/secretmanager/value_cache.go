@@ -0,0 +1,274 @@
+package secretmanager
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsarn "github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/go-errors/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const DefaultValueCacheTtl = 5 * time.Minute
+
+// SecretValueFetcher reads a secret value from Secrets Manager;
+// SecretManagerRepository is one.
+type SecretValueFetcher interface {
+	DescribeSecretValueByInput(query *secretsmanager.GetSecretValueInput) (*SecretValue, error)
+}
+
+// SecretValueCache keeps secret values in memory for services reading them
+// on every request. Unlike a DataCache, which gob-encodes to memory or disk
+// in the clear, it holds SecretString and SecretBinary only encrypted, each
+// under its own data key wrapped by a KeyWrapper, and decrypts them on read.
+//
+// Values are cached per secret ARN and version stage, and fetched again once
+// older than the TTL. A secret read by name shares the entries of its ARN.
+type SecretValueCache struct {
+	fetcher SecretValueFetcher
+	wrapper KeyWrapper
+	ttl     time.Duration
+	now     func() time.Time
+
+	mx      *sync.Mutex
+	entries map[valueCacheKey]sealedValue
+	// arns maps the names and ARNs secrets were asked by to the ARN
+	// GetSecretValue returned for them
+	arns map[string]string
+}
+
+// valueCacheKey is a secret ARN and version stage.
+type valueCacheKey struct {
+	secretID string
+	stage    string
+}
+
+// sealedValue is a fetched value with its secret material encrypted.
+type sealedValue struct {
+	value     SecretValue
+	str       *sealedBox
+	binary    *sealedBox
+	fetchedAt time.Time
+}
+
+func NewSecretValueCache(fetcher SecretValueFetcher, wrapper KeyWrapper) *SecretValueCache {
+	return &SecretValueCache{
+		fetcher: fetcher,
+		wrapper: wrapper,
+		ttl:     DefaultValueCacheTtl,
+		now:     time.Now,
+		mx:      &sync.Mutex{},
+		entries: map[valueCacheKey]sealedValue{},
+		arns:    map[string]string{},
+	}
+}
+
+// WithTtl returns a cache refreshing values older than ttl. The copy starts
+// empty.
+func (c *SecretValueCache) WithTtl(ttl time.Duration) *SecretValueCache {
+	return &SecretValueCache{
+		fetcher: c.fetcher,
+		wrapper: c.wrapper,
+		ttl:     ttl,
+		now:     c.now,
+		mx:      &sync.Mutex{},
+		entries: map[valueCacheKey]sealedValue{},
+		arns:    map[string]string{},
+	}
+}
+
+// GetSecretString returns the AWSCURRENT string of a secret, by name or ARN.
+func (c *SecretValueCache) GetSecretString(secretID string) (string, error) {
+	return c.GetSecretStringByStage(secretID, StageCurrent)
+}
+
+// GetSecretStringByStage returns the string of the version holding the given
+// stage, e.g. AWSPENDING while a rotation is under way.
+func (c *SecretValueCache) GetSecretStringByStage(secretID, stage string) (string, error) {
+	value, err := c.GetSecretValueByStage(secretID, stage)
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(value.SecretString), nil
+}
+
+// GetSecretBinaryByStage returns the binary of the version holding the given
+// stage.
+func (c *SecretValueCache) GetSecretBinaryByStage(secretID, stage string) ([]byte, error) {
+	value, err := c.GetSecretValueByStage(secretID, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.SecretBinary, nil
+}
+
+// GetSecretValueByStage returns the value of the version holding the given
+// stage, decrypted into a copy the caller owns.
+func (c *SecretValueCache) GetSecretValueByStage(secretID, stage string) (*SecretValue, error) {
+	c.mx.Lock()
+	entry, ok := c.entries[c.key(secretID, stage)]
+	c.mx.Unlock()
+
+	if !ok || c.now().Sub(entry.fetchedAt) > c.ttl {
+		var err error
+		if entry, err = c.fetch(secretID, stage); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.open(entry)
+}
+
+// Refresh fetches the value of a secret stage again, whatever its age, e.g.
+// when a request using it was refused after a rotation.
+func (c *SecretValueCache) Refresh(secretID, stage string) error {
+	_, err := c.fetch(secretID, stage)
+	return err
+}
+
+// Invalidate drops every cached stage of a secret, whether it is given by
+// name or ARN, and whichever it was read by.
+func (c *SecretValueCache) Invalidate(secretID string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	arn := c.arn(secretID)
+	for key := range c.entries {
+		if key.secretID == arn || secretName(key.secretID) == secretID {
+			delete(c.entries, key)
+			arn = key.secretID
+		}
+	}
+
+	for id, target := range c.arns {
+		if target == arn {
+			delete(c.arns, id)
+		}
+	}
+}
+
+// key returns the entry key of a secret asked by name or ARN. The caller
+// holds the lock.
+func (c *SecretValueCache) key(secretID, stage string) valueCacheKey {
+	return valueCacheKey{secretID: c.arn(secretID), stage: stage}
+}
+
+// arn returns the ARN a secret was last read as, or secretID when it was
+// never read. The caller holds the lock.
+func (c *SecretValueCache) arn(secretID string) string {
+	if arn, ok := c.arns[secretID]; ok {
+		return arn
+	}
+
+	return secretID
+}
+
+func (c *SecretValueCache) fetch(secretID, stage string) (sealedValue, error) {
+	value, err := c.fetcher.DescribeSecretValueByInput(&secretsmanager.GetSecretValueInput{
+		SecretId:     aws.String(secretID),
+		VersionStage: aws.String(stage),
+	})
+	if err != nil {
+		return sealedValue{}, err
+	}
+
+	entry, err := c.seal(*value)
+	if err != nil {
+		return sealedValue{}, err
+	}
+
+	arn := aws.ToString(entry.value.GetSecretValueOutput.ARN)
+	if arn == "" {
+		arn = secretID
+	}
+
+	c.mx.Lock()
+	c.arns[secretID] = arn
+	c.arns[arn] = arn
+	c.entries[valueCacheKey{secretID: arn, stage: stage}] = entry
+	c.mx.Unlock()
+
+	log.Debug().Str("secret", arn).Str("stage", stage).Msg("[SecretValueCache.fetch] secret value cached")
+
+	return entry, nil
+}
+
+// secretName returns the name of a secret ARN, without the six character
+// suffix Secrets Manager appends, or "" for anything else.
+func secretName(secretArn string) string {
+	parsed, err := awsarn.Parse(secretArn)
+	if err != nil || !strings.HasPrefix(parsed.Resource, "secret:") {
+		return ""
+	}
+
+	name := strings.TrimPrefix(parsed.Resource, "secret:")
+	if i := strings.LastIndex(name, "-"); i >= 0 && len(name)-i == 7 {
+		name = name[:i]
+	}
+
+	return name
+}
+
+// seal encrypts the secret material of a value and keeps the rest in the
+// clear.
+func (c *SecretValueCache) seal(value SecretValue) (sealedValue, error) {
+	if value.GetSecretValueOutput == nil {
+		return sealedValue{}, errors.New("secret value without output")
+	}
+
+	output := *value.GetSecretValueOutput
+	entry := sealedValue{fetchedAt: c.now()}
+
+	if output.SecretString != nil {
+		box, err := sealEnvelope(c.wrapper, []byte(*output.SecretString))
+		if err != nil {
+			return sealedValue{}, err
+		}
+		entry.str = &box
+	}
+
+	if output.SecretBinary != nil {
+		box, err := sealEnvelope(c.wrapper, output.SecretBinary)
+		if err != nil {
+			return sealedValue{}, err
+		}
+		entry.binary = &box
+	}
+
+	output.SecretString, output.SecretBinary = nil, nil
+	value.GetSecretValueOutput = &output
+	entry.value = value
+
+	return entry, nil
+}
+
+func (c *SecretValueCache) open(entry sealedValue) (*SecretValue, error) {
+	output := *entry.value.GetSecretValueOutput
+
+	if entry.str != nil {
+		plaintext, err := openEnvelope(c.wrapper, *entry.str)
+		if err != nil {
+			return nil, err
+		}
+		output.SecretString = aws.String(string(plaintext))
+		clear(plaintext)
+	}
+
+	if entry.binary != nil {
+		plaintext, err := openEnvelope(c.wrapper, *entry.binary)
+		if err != nil {
+			return nil, err
+		}
+		output.SecretBinary = plaintext
+	}
+
+	value := entry.value
+	value.GetSecretValueOutput = &output
+
+	return &value, nil
+}
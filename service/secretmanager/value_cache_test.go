@@ -0,0 +1,140 @@
+package secretmanager
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sm "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/go-errors/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFetcher serves secret values by stage and counts the calls.
+const dbArn = "arn:aws:secretsmanager:eu-central-1:123456789012:secret:db-AbCdEf"
+
+type fakeFetcher struct {
+	values map[string]string
+	calls  int
+}
+
+func (f *fakeFetcher) DescribeSecretValueByInput(query *sm.GetSecretValueInput) (*SecretValue, error) {
+	f.calls++
+
+	stage := aws.ToString(query.VersionStage)
+	str, ok := f.values[stage]
+	if !ok {
+		return nil, errors.Errorf("no version with stage %s", stage)
+	}
+
+	value := NewSecretValue(mockClient{}, &sm.GetSecretValueOutput{
+		ARN:           aws.String(dbArn),
+		Name:          aws.String("db"),
+		SecretString:  aws.String(str),
+		SecretBinary:  []byte(str),
+		VersionStages: []string{stage},
+	})
+
+	return &value, nil
+}
+
+func TestSecretValueCache(t *testing.T) {
+	key, err := NewRandomLocalKey()
+	require.NoError(t, err)
+
+	fetcher := &fakeFetcher{values: map[string]string{StageCurrent: "s3cr3t-current", StagePending: "s3cr3t-pending"}}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cache := NewSecretValueCache(fetcher, key).WithTtl(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	str, err := cache.GetSecretString("db")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-current", str)
+
+	pending, err := cache.GetSecretStringByStage("db", StagePending)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-pending", pending)
+
+	binary, err := cache.GetSecretBinaryByStage("db", StageCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("s3cr3t-current"), binary)
+	assert.Equal(t, 2, fetcher.calls, "one fetch per stage")
+
+	// the material is held encrypted only
+	for _, entry := range cache.entries {
+		assert.Nil(t, entry.value.SecretString)
+		assert.Nil(t, entry.value.SecretBinary)
+		assert.False(t, bytes.Contains(entry.str.ciphertext, []byte("s3cr3t")))
+		assert.False(t, bytes.Contains(entry.binary.ciphertext, []byte("s3cr3t")))
+	}
+
+	// callers own their copy
+	value, err := cache.GetSecretValueByStage("db", StageCurrent)
+	require.NoError(t, err)
+	value.SecretBinary[0] = 'X'
+	binary, _ = cache.GetSecretBinaryByStage("db", StageCurrent)
+	assert.Equal(t, []byte("s3cr3t-current"), binary)
+
+	// refreshed after the ttl
+	fetcher.values[StageCurrent] = "rotated"
+	now = now.Add(2 * time.Minute)
+	str, err = cache.GetSecretString("db")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", str)
+	assert.Equal(t, 3, fetcher.calls)
+
+	cache.Invalidate("db")
+	assert.Empty(t, cache.entries)
+
+	_, err = cache.GetSecretStringByStage("db", StagePrevious)
+	assert.Error(t, err)
+}
+
+func TestSecretValueCacheSharesNameAndArn(t *testing.T) {
+	key, err := NewRandomLocalKey()
+	require.NoError(t, err)
+
+	fetcher := &fakeFetcher{values: map[string]string{StageCurrent: "s3cr3t"}}
+	cache := NewSecretValueCache(fetcher, key)
+
+	_, err = cache.GetSecretString("db")
+	require.NoError(t, err)
+	_, err = cache.GetSecretString(dbArn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetcher.calls, "the name and the ARN share one entry")
+
+	// invalidating by ARN drops what was read by name, and the reverse
+	cache.Invalidate(dbArn)
+	assert.Empty(t, cache.entries)
+	assert.Empty(t, cache.arns)
+
+	_, err = cache.GetSecretString(dbArn)
+	require.NoError(t, err)
+	cache.Invalidate("db")
+	assert.Empty(t, cache.entries)
+	assert.Empty(t, cache.arns)
+}
+
+func TestLocalKey(t *testing.T) {
+	_, err := NewLocalKey([]byte("short"))
+	assert.Error(t, err)
+
+	key, err := NewLocalKey(bytes.Repeat([]byte{7}, dataKeySize))
+	require.NoError(t, err)
+
+	box, err := sealEnvelope(key, []byte("material"))
+	require.NoError(t, err)
+
+	plaintext, err := openEnvelope(key, box)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("material"), plaintext)
+
+	other, err := NewRandomLocalKey()
+	require.NoError(t, err)
+
+	_, err = openEnvelope(other, box)
+	assert.Error(t, err, "a box opens only under the key that sealed it")
+}
@@ -0,0 +1,216 @@
+package securityhub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+// AsffSchemaVersion is the ASFF version of the findings we import.
+const AsffSchemaVersion = "2018-10-08"
+
+// asffResourceTypes maps config resource types to their ASFF resource type;
+// types missing here are imported as "Other".
+var asffResourceTypes = map[cfg.ResourceType]string{
+	cfg.ResourceTypeInstance:                "AwsEc2Instance",
+	cfg.ResourceTypeVolume:                  "AwsEc2Volume",
+	cfg.ResourceTypeEip:                     "AwsEc2Eip",
+	cfg.ResourceTypeSecurityGroup:           "AwsEc2SecurityGroup",
+	cfg.ResourceTypeVpc:                     "AwsEc2Vpc",
+	cfg.ResourceTypeSubnet:                  "AwsEc2Subnet",
+	cfg.ResourceTypeNetworkInterface:        "AwsEc2NetworkInterface",
+	cfg.ResourceTypeBucket:                  "AwsS3Bucket",
+	cfg.ResourceTypeRole:                    "AwsIamRole",
+	cfg.ResourceTypeUser:                    "AwsIamUser",
+	cfg.ResourceTypePolicy:                  "AwsIamPolicy",
+	cfg.ResourceTypeFunction:                "AwsLambdaFunction",
+	cfg.ResourceTypeDBInstance:              "AwsRdsDbInstance",
+	cfg.ResourceTypeDBSnapshot:              "AwsRdsDbSnapshot",
+	cfg.ResourceTypeTable:                   "AwsDynamoDbTable",
+	cfg.ResourceTypeTopic:                   "AwsSnsTopic",
+	cfg.ResourceTypeQueue:                   "AwsSqsQueue",
+	cfg.ResourceTypeECSCluster:              "AwsEcsCluster",
+	cfg.ResourceTypeECSService:              "AwsEcsService",
+	cfg.ResourceTypeEKSCluster:              "AwsEksCluster",
+	cfg.ResourceTypeEFSFileSystem:           "AwsEfsFileSystem",
+	cfg.ResourceTypeLoadBalancerV2:          "AwsElbv2LoadBalancer",
+	cfg.ResourceTypeLoadBalancer:            "AwsElbLoadBalancer",
+	cfg.ResourceTypeAutoScalingGroup:        "AwsAutoScalingAutoScalingGroup",
+	cfg.ResourceTypeTrail:                   "AwsCloudTrailTrail",
+	cfg.ResourceTypeSecret:                  "AwsSecretsManagerSecret",
+	cfg.ResourceTypeDistribution:            "AwsCloudFrontDistribution",
+	cfg.ResourceTypeAthenaWorkGroup:         "AwsAthenaWorkGroup",
+	cfg.ResourceTypeBatchJobQueue:           "AwsBatchJobQueue",
+	cfg.ResourceTypeBatchComputeEnvironment: "AwsBatchComputeEnvironment",
+}
+
+// AsffResourceType returns the ASFF resource type of a config resource type,
+// "Other" when ASFF has none.
+func AsffResourceType(resourceType cfg.ResourceType) string {
+	if asffType, ok := asffResourceTypes[resourceType]; ok {
+		return asffType
+	}
+
+	return "Other"
+}
+
+// DefaultProductArn is the product of findings an account imports itself.
+func DefaultProductArn(accountID ptypes.AwsAccountID, region ptypes.AwsRegion) string {
+	return fmt.Sprintf("arn:%s:securityhub:%s:%s:product/%s/default", partitionOf(region), region, accountID, accountID)
+}
+
+// ResourceFinding is a check result about one inventory resource, to be
+// imported into Security Hub.
+type ResourceFinding struct {
+	// GeneratorID names the check, e.g. "awslib/waste/ebs-unattached". With
+	// the resource it makes the finding id, so the same check on the same
+	// resource updates one finding rather than adding another.
+	GeneratorID string
+	// Types are ASFF finding types, e.g.
+	// "Software and Configuration Checks/AWS Security Best Practices".
+	Types       []string
+	Severity    types.SeverityLabel
+	Title       string
+	Description string
+	// ProductFields carry check specific values, e.g. a rule id or cost.
+	ProductFields map[string]string
+
+	Resource service.ResourceInterface
+}
+
+// Id returns the finding id: the generator, account, region and resource.
+func (f ResourceFinding) Id() string {
+	return strings.Join([]string{f.GeneratorID, f.accountID().String(), f.region().String(), f.Resource.GetIdOrArn()}, "/")
+}
+
+// Asff renders the finding in ASFF, observed at now. Imported findings are
+// about the account and region of the resource, under the default product
+// of that account; global resources go to the default region. CreatedAt is
+// now too: KeepCreatedAt restores that of a finding imported before.
+func (f ResourceFinding) Asff(now time.Time) types.AwsSecurityFinding {
+	observedAt := aws.String(now.UTC().Format(time.RFC3339))
+	region := f.region()
+
+	resourceID := f.Resource.GetArn()
+	if resourceID == "" {
+		resourceID = f.Resource.GetIdOrArn()
+	}
+
+	return types.AwsSecurityFinding{
+		SchemaVersion:  aws.String(AsffSchemaVersion),
+		Id:             aws.String(f.Id()),
+		ProductArn:     aws.String(DefaultProductArn(f.accountID(), region)),
+		GeneratorId:    aws.String(f.GeneratorID),
+		AwsAccountId:   aws.String(f.accountID().String()),
+		Region:         aws.String(region.String()),
+		Types:          f.Types,
+		CreatedAt:      observedAt,
+		UpdatedAt:      observedAt,
+		LastObservedAt: observedAt,
+		Severity:       &types.Severity{Label: f.Severity},
+		Title:          aws.String(truncate(f.Title, 256)),
+		Description:    aws.String(truncate(f.Description, 1024)),
+		ProductFields:  f.ProductFields,
+		RecordState:    types.RecordStateActive,
+		Resources: []types.Resource{{
+			Type:      aws.String(AsffResourceType(f.Resource.GetType())),
+			Id:        aws.String(resourceID),
+			Partition: types.Partition(partitionOf(region)),
+			Region:    aws.String(region.String()),
+			Tags:      f.Resource.GetTags(),
+		}},
+	}
+}
+
+func (f ResourceFinding) accountID() ptypes.AwsAccountID {
+	return f.Resource.GetAccountID()
+}
+
+func (f ResourceFinding) region() ptypes.AwsRegion {
+	if region := f.Resource.GetRegion(); region != "" {
+		return region
+	}
+
+	return ptypes.DefaultAwsRegion
+}
+
+// Archive returns a copy of an imported finding marked archived at now,
+// for a check that no longer reports it.
+func Archive(finding types.AwsSecurityFinding, now time.Time) types.AwsSecurityFinding {
+	finding.RecordState = types.RecordStateArchived
+	finding.UpdatedAt = aws.String(now.UTC().Format(time.RFC3339))
+
+	return finding
+}
+
+// Stale returns, archived at now, the active previously imported findings
+// absent from current. Read previous with WithGeneratorPrefix over the same
+// checks, and import the result alongside current: findings of resources
+// fixed or deleted since are closed rather than left active.
+func Stale(previous []Finding, current []types.AwsSecurityFinding, now time.Time) []types.AwsSecurityFinding {
+	reported := map[string]bool{}
+	for _, finding := range current {
+		reported[aws.ToString(finding.Id)] = true
+	}
+
+	var stale []types.AwsSecurityFinding
+	for _, finding := range previous {
+		if finding.RecordState != types.RecordStateActive || reported[finding.GetId()] {
+			continue
+		}
+
+		stale = append(stale, Archive(finding.AwsSecurityFinding, now))
+	}
+
+	return stale
+}
+
+// KeepCreatedAt returns current with the CreatedAt of the active previously
+// imported finding of the same id, so re-importing a finding still open does
+// not make it look new. Read previous as for Stale.
+func KeepCreatedAt(previous []Finding, current []types.AwsSecurityFinding) []types.AwsSecurityFinding {
+	createdAt := map[string]*string{}
+	for _, finding := range previous {
+		if finding.RecordState == types.RecordStateActive && finding.AwsSecurityFinding.CreatedAt != nil {
+			createdAt[finding.GetId()] = finding.AwsSecurityFinding.CreatedAt
+		}
+	}
+
+	kept := make([]types.AwsSecurityFinding, 0, len(current))
+	for _, finding := range current {
+		if created, ok := createdAt[aws.ToString(finding.Id)]; ok {
+			finding.CreatedAt = created
+		}
+
+		kept = append(kept, finding)
+	}
+
+	return kept
+}
+
+// truncate cuts value to the length ASFF accepts for a field.
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+
+	return string(runes[:length-3]) + "..."
+}
+
+func partitionOf(region ptypes.AwsRegion) string {
+	switch {
+	case strings.HasPrefix(region.String(), "cn-"):
+		return string(types.PartitionAwsCn)
+	case strings.HasPrefix(region.String(), "us-gov-"):
+		return string(types.PartitionAwsUsGov)
+	}
+
+	return string(types.PartitionAws)
+}
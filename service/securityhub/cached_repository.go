@@ -0,0 +1,67 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package securityhub
+
+import (
+	"fmt"
+
+	awssecurityhub "github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// SecurityHubRepositoryCached wraps SecurityHubRepository and caches results of Get*/List* calls.
+type SecurityHubRepositoryCached struct {
+	repo  *SecurityHubRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a SecurityHubRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *SecurityHubRepository) WithCache(dc *cache.DataCache) *SecurityHubRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &SecurityHubRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListFindingsActive returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SecurityHubRepositoryCached) ListFindingsActive() ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsActive")
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsActive()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListFindingsByFilter returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SecurityHubRepositoryCached) ListFindingsByFilter(filter FindingFilter) ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsByFilter", filter)
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsByFilter(filter)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListFindingsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SecurityHubRepositoryCached) ListFindingsByInput(query *awssecurityhub.GetFindingsInput) ([]Finding, error) {
+	cacheKey := cache.Key("ListFindingsByInput", query)
+	var cached []Finding
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListFindingsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -0,0 +1,133 @@
+package securityhub
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+// FindingFilter builds GetFindings filters. Values of one field are ORed,
+// fields are ANDed. Every With* method returns a copy, so a base filter can
+// be shared:
+//
+//	active := securityhub.NewFindingFilter().WithRecordState(types.RecordStateActive)
+//	critical := active.WithSeverity(types.SeverityLabelCritical, types.SeverityLabelHigh)
+type FindingFilter struct {
+	filters types.AwsSecurityFindingFilters
+}
+
+func NewFindingFilter() FindingFilter {
+	return FindingFilter{}
+}
+
+// WithAccount keeps findings about the given accounts.
+func (f FindingFilter) WithAccount(accounts ...ptypes.AwsAccountID) FindingFilter {
+	values := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		values = append(values, account.String())
+	}
+
+	f.filters.AwsAccountId = equals(f.filters.AwsAccountId, values...)
+	return f
+}
+
+func (f FindingFilter) WithSeverity(labels ...types.SeverityLabel) FindingFilter {
+	f.filters.SeverityLabel = equals(f.filters.SeverityLabel, stringsOf(labels)...)
+	return f
+}
+
+func (f FindingFilter) WithRecordState(states ...types.RecordState) FindingFilter {
+	f.filters.RecordState = equals(f.filters.RecordState, stringsOf(states)...)
+	return f
+}
+
+func (f FindingFilter) WithWorkflowStatus(statuses ...types.WorkflowStatus) FindingFilter {
+	f.filters.WorkflowStatus = equals(f.filters.WorkflowStatus, stringsOf(statuses)...)
+	return f
+}
+
+func (f FindingFilter) WithComplianceStatus(statuses ...types.ComplianceStatus) FindingFilter {
+	f.filters.ComplianceStatus = equals(f.filters.ComplianceStatus, stringsOf(statuses)...)
+	return f
+}
+
+// WithProductName keeps findings of the given products, e.g. "GuardDuty",
+// "Inspector" or "Security Hub".
+func (f FindingFilter) WithProductName(names ...string) FindingFilter {
+	f.filters.ProductName = equals(f.filters.ProductName, names...)
+	return f
+}
+
+// WithResourceType keeps findings about resources of the given ASFF types,
+// e.g. "AwsS3Bucket".
+func (f FindingFilter) WithResourceType(resourceTypes ...string) FindingFilter {
+	f.filters.ResourceType = equals(f.filters.ResourceType, resourceTypes...)
+	return f
+}
+
+// WithResourceId keeps findings about the given resources, usually by ARN.
+func (f FindingFilter) WithResourceId(ids ...string) FindingFilter {
+	f.filters.ResourceId = equals(f.filters.ResourceId, ids...)
+	return f
+}
+
+// WithGeneratorPrefix keeps findings whose generator id starts with prefix,
+// e.g. the findings one awslib check imported.
+func (f FindingFilter) WithGeneratorPrefix(prefix string) FindingFilter {
+	f.filters.GeneratorId = append(clone(f.filters.GeneratorId), types.StringFilter{
+		Comparison: types.StringFilterComparisonPrefix,
+		Value:      aws.String(prefix),
+	})
+	return f
+}
+
+// WithUpdatedBetween keeps findings updated within [start, end].
+func (f FindingFilter) WithUpdatedBetween(start, end time.Time) FindingFilter {
+	f.filters.UpdatedAt = append(clone(f.filters.UpdatedAt), types.DateFilter{
+		Start: aws.String(start.UTC().Format(time.RFC3339)),
+		End:   aws.String(end.UTC().Format(time.RFC3339)),
+	})
+	return f
+}
+
+// WithUpdatedInLastDays keeps findings updated in the last days, counted by
+// Security Hub at query time.
+func (f FindingFilter) WithUpdatedInLastDays(days int32) FindingFilter {
+	f.filters.UpdatedAt = append(clone(f.filters.UpdatedAt), types.DateFilter{
+		DateRange: &types.DateRange{Unit: types.DateRangeUnitDays, Value: aws.Int32(days)},
+	})
+	return f
+}
+
+// Build returns the filters for GetFindingsInput.
+func (f FindingFilter) Build() *types.AwsSecurityFindingFilters {
+	filters := f.filters
+	return &filters
+}
+
+func equals(filters []types.StringFilter, values ...string) []types.StringFilter {
+	filters = clone(filters)
+	for _, value := range values {
+		filters = append(filters, types.StringFilter{
+			Comparison: types.StringFilterComparisonEquals,
+			Value:      aws.String(value),
+		})
+	}
+
+	return filters
+}
+
+func clone[T any](values []T) []T {
+	return append([]T(nil), values...)
+}
+
+func stringsOf[T ~string](values []T) []string {
+	converted := make([]string, 0, len(values))
+	for _, value := range values {
+		converted = append(converted, string(value))
+	}
+
+	return converted
+}
@@ -0,0 +1,129 @@
+package securityhub
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Finding is a Security Hub finding in ASFF, from AWS services, partner
+// products or BatchImportFindings. AccountID is the account the finding is
+// about, which for an aggregation region is not the account that read it.
+type Finding struct {
+	service.AbstractResource
+	types.AwsSecurityFinding
+}
+
+func NewFinding(client AwsClient, finding types.AwsSecurityFinding) Finding {
+	accountID := client.GetAccountID()
+	if account := aws.ToString(finding.AwsAccountId); account != "" {
+		accountID = ptypes.AwsAccountID(account)
+	}
+
+	region := client.GetRegion()
+	if findingRegion := aws.ToString(finding.Region); findingRegion != "" {
+		region = ptypes.AwsRegion(findingRegion)
+	}
+
+	e := Finding{
+		AbstractResource: service.AbstractResource{
+			AccountID: accountID,
+			Region:    region,
+			ID:        aws.ToString(finding.Id),
+			CreatedAt: parseTimestamp(finding.CreatedAt),
+			Type:      ccfg.ResourceTypeSecurityHubFinding,
+		},
+		AwsSecurityFinding: finding,
+	}
+
+	// findings of AWS services are identified by ARN, imported ones by any id
+	if fArn, err := arn.Parse(aws.ToString(finding.Id)); err == nil {
+		e.ARN = &fArn
+	}
+
+	return e
+}
+
+func (e Finding) GetName() string {
+	return aws.ToString(e.Title)
+}
+
+func (e Finding) GetSeverity() types.SeverityLabel {
+	if e.Severity == nil {
+		return ""
+	}
+
+	return e.Severity.Label
+}
+
+func (e Finding) GetUpdatedAt() time.Time {
+	return parseTimestamp(e.UpdatedAt)
+}
+
+// IsActive reports a finding neither archived by its provider nor resolved
+// or suppressed by a user.
+func (e Finding) IsActive() bool {
+	if e.RecordState != types.RecordStateActive {
+		return false
+	}
+
+	if e.Workflow == nil {
+		return true
+	}
+
+	return e.Workflow.Status != types.WorkflowStatusResolved && e.Workflow.Status != types.WorkflowStatusSuppressed
+}
+
+// IsFailed reports a control check that failed; findings that are not
+// control checks have no compliance status and are never failed.
+func (e Finding) IsFailed() bool {
+	return e.Compliance != nil && e.Compliance.Status == types.ComplianceStatusFailed
+}
+
+// GetResourceIds returns the ids of the resources the finding is about,
+// usually ARNs.
+func (e Finding) GetResourceIds() []string {
+	ids := make([]string, 0, len(e.Resources))
+	for _, resource := range e.Resources {
+		if id := aws.ToString(resource.Id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// GetTags returns the user-defined fields, the only key/value pairs a finding
+// carries; resource tags are on its Resources.
+func (e Finding) GetTags() map[string]string {
+	tags := make(map[string]string)
+
+	for key, value := range e.UserDefinedFields {
+		tags[key] = value
+	}
+
+	return tags
+}
+
+func (e Finding) GetTagValue(tag string) string {
+	val, ok := e.GetTags()[tag]
+	if !ok {
+		return ""
+	}
+
+	return val
+}
+
+func parseTimestamp(value *string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, aws.ToString(value))
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
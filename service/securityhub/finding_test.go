@@ -0,0 +1,194 @@
+package securityhub
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-central-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "111111111111" }
+
+type mockResource struct {
+	service.AbstractResource
+	tags map[string]string
+}
+
+func (m mockResource) GetName() string               { return m.ID }
+func (m mockResource) GetTags() map[string]string    { return m.tags }
+func (m mockResource) GetTagValue(tag string) string { return m.tags[tag] }
+
+func newMockResource(id, resourceArn string, resourceType cfg.ResourceType) mockResource {
+	r := mockResource{AbstractResource: service.AbstractResource{
+		AccountID: "222222222222",
+		Region:    "eu-west-1",
+		ID:        id,
+		Type:      resourceType,
+	}}
+
+	if parsed, err := arn.Parse(resourceArn); err == nil {
+		r.ARN = &parsed
+	}
+
+	return r
+}
+
+func TestNewFinding(t *testing.T) {
+	finding := NewFinding(mockClient{}, types.AwsSecurityFinding{
+		Id:           aws.String("arn:aws:securityhub:eu-west-1:222222222222:subscription/aws-foundational-security-best-practices/v/1.0.0/S3.8/finding/abc"),
+		AwsAccountId: aws.String("222222222222"),
+		Region:       aws.String("eu-west-1"),
+		CreatedAt:    aws.String("2026-10-01T10:00:00.000Z"),
+		Title:        aws.String("S3 general purpose buckets should block public access"),
+		Severity:     &types.Severity{Label: types.SeverityLabelHigh},
+		RecordState:  types.RecordStateActive,
+		Compliance:   &types.Compliance{Status: types.ComplianceStatusFailed},
+		Resources:    []types.Resource{{Id: aws.String("arn:aws:s3:::shared-bucket")}},
+	})
+
+	assert.Equal(t, ptypes.AwsAccountID("222222222222"), finding.GetAccountID())
+	assert.Equal(t, ptypes.AwsRegion("eu-west-1"), finding.GetRegion())
+	assert.Equal(t, ccfg.ResourceTypeSecurityHubFinding, finding.GetType())
+	assert.Equal(t, "securityhub", finding.ARN.Service)
+	assert.Equal(t, time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), finding.GetCreatedAt())
+	assert.Equal(t, types.SeverityLabelHigh, finding.GetSeverity())
+	assert.Equal(t, []string{"arn:aws:s3:::shared-bucket"}, finding.GetResourceIds())
+	assert.True(t, finding.IsActive())
+	assert.True(t, finding.IsFailed())
+
+	finding.Workflow = &types.Workflow{Status: types.WorkflowStatusSuppressed}
+	assert.False(t, finding.IsActive())
+
+	imported := NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/ebs-unattached/vol-1")})
+	assert.Empty(t, imported.GetArn())
+	assert.Equal(t, ptypes.AwsAccountID("111111111111"), imported.GetAccountID())
+}
+
+func TestFindingFilter(t *testing.T) {
+	base := NewFindingFilter().WithRecordState(types.RecordStateActive)
+	critical := base.WithSeverity(types.SeverityLabelCritical, types.SeverityLabelHigh)
+	_ = base.WithSeverity(types.SeverityLabelLow)
+
+	filters := critical.Build()
+	assert.Len(t, filters.RecordState, 1)
+	assert.Len(t, filters.SeverityLabel, 2)
+	assert.Equal(t, "CRITICAL", aws.ToString(filters.SeverityLabel[0].Value))
+	assert.Equal(t, types.StringFilterComparisonEquals, filters.SeverityLabel[0].Comparison)
+	assert.Empty(t, base.Build().SeverityLabel)
+
+	prefixed := base.WithGeneratorPrefix("awslib/").WithUpdatedInLastDays(7).Build()
+	assert.Equal(t, types.StringFilterComparisonPrefix, prefixed.GeneratorId[0].Comparison)
+	assert.Equal(t, int32(7), aws.ToInt32(prefixed.UpdatedAt[0].DateRange.Value))
+}
+
+func TestJoinFindingResources(t *testing.T) {
+	bucket := newMockResource("shared-bucket", "arn:aws:s3:::shared-bucket", cfg.ResourceTypeBucket)
+	volume := newMockResource("vol-1", "", cfg.ResourceTypeVolume)
+
+	findings := []Finding{
+		NewFinding(mockClient{}, types.AwsSecurityFinding{
+			Id:           aws.String("f-1"),
+			AwsAccountId: aws.String("222222222222"),
+			Resources: []types.Resource{
+				{Id: aws.String("arn:aws:s3:::shared-bucket")},
+				{Id: aws.String("arn:aws:s3:::unknown")},
+			},
+		}),
+		NewFinding(mockClient{}, types.AwsSecurityFinding{
+			Id:           aws.String("f-2"),
+			AwsAccountId: aws.String("222222222222"),
+			Resources:    []types.Resource{{Id: aws.String("vol-1")}},
+		}),
+		// same ID, other account: not our volume
+		NewFinding(mockClient{}, types.AwsSecurityFinding{
+			Id:        aws.String("f-3"),
+			Resources: []types.Resource{{Id: aws.String("vol-1")}},
+		}),
+	}
+
+	joined := JoinFindingResources(findings, []service.ResourceInterface{bucket, volume})
+	assert.Len(t, joined, 2)
+	assert.Equal(t, "f-1", joined[0].Finding.GetId())
+	assert.Equal(t, bucket, joined[0].Resource)
+	assert.Equal(t, "f-2", joined[1].Finding.GetId())
+
+	grouped := GroupByResource(joined)
+	assert.Len(t, grouped["arn:aws:s3:::shared-bucket"], 1)
+	assert.Len(t, grouped["vol-1"], 1)
+}
+
+func TestResourceFindingAsff(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	volume := newMockResource("vol-1", "arn:aws:ec2:eu-west-1:222222222222:volume/vol-1", cfg.ResourceTypeVolume)
+	volume.tags = map[string]string{"team": "data"}
+
+	finding := ResourceFinding{
+		GeneratorID: "awslib/waste/ebs-unattached",
+		Types:       []string{"Software and Configuration Checks/Cost Optimization/Unused Resource"},
+		Severity:    types.SeverityLabelMedium,
+		Title:       "Unused resource: ebs-unattached",
+		Description: "volume is available, attached to no instance",
+		Resource:    volume,
+	}.Asff(now)
+
+	assert.Equal(t, AsffSchemaVersion, aws.ToString(finding.SchemaVersion))
+	assert.Equal(t, "awslib/waste/ebs-unattached/222222222222/eu-west-1/vol-1", aws.ToString(finding.Id))
+	assert.Equal(t, "arn:aws:securityhub:eu-west-1:222222222222:product/222222222222/default", aws.ToString(finding.ProductArn))
+	assert.Equal(t, "222222222222", aws.ToString(finding.AwsAccountId))
+	assert.Equal(t, "2026-10-19T12:00:00Z", aws.ToString(finding.UpdatedAt))
+	assert.Equal(t, types.RecordStateActive, finding.RecordState)
+	assert.Len(t, finding.Resources, 1)
+	assert.Equal(t, "AwsEc2Volume", aws.ToString(finding.Resources[0].Type))
+	assert.Equal(t, "arn:aws:ec2:eu-west-1:222222222222:volume/vol-1", aws.ToString(finding.Resources[0].Id))
+	assert.Equal(t, types.PartitionAws, finding.Resources[0].Partition)
+	assert.Equal(t, "data", finding.Resources[0].Tags["team"])
+
+	assert.Equal(t, "Other", AsffResourceType(ccfg.ResourceTypeCloudWatchLogGroup))
+	assert.Equal(t, "arn:aws-cn:securityhub:cn-north-1:1:product/1/default", DefaultProductArn("1", "cn-north-1"))
+}
+
+func TestStale(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	previous := []Finding{
+		NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/r/1"), RecordState: types.RecordStateActive}),
+		NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/r/2"), RecordState: types.RecordStateActive}),
+		NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/r/3"), RecordState: types.RecordStateArchived}),
+	}
+	current := []types.AwsSecurityFinding{{Id: aws.String("awslib/waste/r/1")}}
+
+	stale := Stale(previous, current, now)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, "awslib/waste/r/2", aws.ToString(stale[0].Id))
+	assert.Equal(t, types.RecordStateArchived, stale[0].RecordState)
+	assert.Equal(t, "2026-10-19T12:00:00Z", aws.ToString(stale[0].UpdatedAt))
+	assert.Equal(t, types.RecordStateActive, previous[1].RecordState)
+}
+
+func TestKeepCreatedAt(t *testing.T) {
+	previous := []Finding{
+		NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/r/1"), RecordState: types.RecordStateActive, CreatedAt: aws.String("2026-01-01T00:00:00Z")}),
+		NewFinding(mockClient{}, types.AwsSecurityFinding{Id: aws.String("awslib/waste/r/2"), RecordState: types.RecordStateArchived, CreatedAt: aws.String("2026-01-01T00:00:00Z")}),
+	}
+	current := []types.AwsSecurityFinding{
+		{Id: aws.String("awslib/waste/r/1"), CreatedAt: aws.String("2026-10-19T12:00:00Z")},
+		{Id: aws.String("awslib/waste/r/2"), CreatedAt: aws.String("2026-10-19T12:00:00Z")},
+		{Id: aws.String("awslib/waste/r/3"), CreatedAt: aws.String("2026-10-19T12:00:00Z")},
+	}
+
+	kept := KeepCreatedAt(previous, current)
+	assert.Equal(t, "2026-01-01T00:00:00Z", aws.ToString(kept[0].CreatedAt))
+	// reopened after being archived, and new: created now
+	assert.Equal(t, "2026-10-19T12:00:00Z", aws.ToString(kept[1].CreatedAt))
+	assert.Equal(t, "2026-10-19T12:00:00Z", aws.ToString(kept[2].CreatedAt))
+	assert.Equal(t, "2026-10-19T12:00:00Z", aws.ToString(current[0].CreatedAt))
+}
@@ -0,0 +1,50 @@
+package securityhub
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	"github.com/imunhatep/awslib/service"
+)
+
+// FindingResource is an inventory resource a finding is about.
+type FindingResource struct {
+	Finding  Finding
+	Entry    types.Resource
+	Resource service.ResourceInterface
+}
+
+// JoinFindingResources matches the resources of the findings to inventory
+// resources. A finding resource id matches a resource ARN, or a resource ID
+// of the finding's account: most ASFF resources are identified by ARN, a few
+// by ID, e.g. AwsAccount. Finding resources matching no inventory resource
+// are left out.
+func JoinFindingResources(findings []Finding, inventory []service.ResourceInterface) []FindingResource {
+	index := service.NewResourceIndex(inventory)
+
+	var joined []FindingResource
+	for _, finding := range findings {
+		for _, entry := range finding.Resources {
+			if resource, ok := index.Find(finding.GetAccountID(), aws.ToString(entry.Id)); ok {
+				joined = append(joined, FindingResource{Finding: finding, Entry: entry, Resource: resource})
+			}
+		}
+	}
+
+	return joined
+}
+
+// GroupByResource indexes joined findings by the ARN, or else the ID, of
+// their inventory resource.
+func GroupByResource(joined []FindingResource) map[string][]Finding {
+	grouped := map[string][]Finding{}
+	for _, j := range joined {
+		key := j.Resource.GetArn()
+		if key == "" {
+			key = j.Resource.GetIdOrArn()
+		}
+
+		grouped[key] = append(grouped[key], j.Finding)
+	}
+
+	return grouped
+}
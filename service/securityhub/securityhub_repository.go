@@ -0,0 +1,155 @@
+package securityhub
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awssecurityhub "github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/securityhub"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// importBatchSize is the most findings BatchImportFindings accepts per call.
+const importBatchSize = 100
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// SecurityHubRepository reads Security Hub findings and imports findings of
+// our own. Security Hub is regional; an aggregation region also returns the
+// findings of its linked regions, and an administrator account those of its
+// member accounts.
+type SecurityHubRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewSecurityHubRepository(ctx context.Context, client *v3.Client) *SecurityHubRepository {
+	repo := &SecurityHubRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *SecurityHubRepository) securityHubClient() *awssecurityhub.Client {
+	return securityhub.GetClient(r.client)
+}
+
+func (r *SecurityHubRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *SecurityHubRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// ListFindingsActive returns the findings neither archived nor resolved or
+// suppressed.
+func (r *SecurityHubRepository) ListFindingsActive() ([]Finding, error) {
+	filter := NewFindingFilter().
+		WithRecordState(types.RecordStateActive).
+		WithWorkflowStatus(types.WorkflowStatusNew, types.WorkflowStatusNotified)
+
+	return r.ListFindingsByFilter(filter)
+}
+
+func (r *SecurityHubRepository) ListFindingsByFilter(filter FindingFilter) ([]Finding, error) {
+	return r.ListFindingsByInput(&awssecurityhub.GetFindingsInput{Filters: filter.Build()})
+}
+
+func (r *SecurityHubRepository) ListFindingsByInput(query *awssecurityhub.GetFindingsInput) ([]Finding, error) {
+	start := time.Now()
+	var findings []Finding
+
+	p := awssecurityhub.NewGetFindingsPaginator(r.securityHubClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetFindings", ccfg.ResourceTypeSecurityHubFinding)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetFindings", ccfg.ResourceTypeSecurityHubFinding)).Inc()
+			}
+
+			return findings, errors.New(err)
+		}
+
+		for _, v := range resp.Findings {
+			findings = append(findings, NewFinding(r.client, v))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetFindings", ccfg.ResourceTypeSecurityHubFinding)).
+			Add(float64(len(findings)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListFindingsByInput", ccfg.ResourceTypeSecurityHubFinding)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return findings, nil
+}
+
+// ImportResult is the outcome of ImportFindings. Findings Security Hub
+// rejected are listed in Failed with the reason; they do not fail the call.
+type ImportResult struct {
+	Imported int
+	Failed   []types.ImportFindingsError
+}
+
+// ImportFindings sends findings in ASFF through BatchImportFindings, 100 per
+// call. With the default product ARN, findings must be about the account
+// and region of the client. Importing a finding id again updates it.
+func (r *SecurityHubRepository) ImportFindings(findings []types.AwsSecurityFinding) (ImportResult, error) {
+	start := time.Now()
+	result := ImportResult{}
+
+	for i := 0; i < len(findings); i += importBatchSize {
+		batch := findings[i:min(i+importBatchSize, len(findings))]
+
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("BatchImportFindings", ccfg.ResourceTypeSecurityHubFinding)).Inc()
+		}
+
+		resp, err := r.securityHubClient().BatchImportFindings(r.ctx, &awssecurityhub.BatchImportFindingsInput{Findings: batch})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("BatchImportFindings", ccfg.ResourceTypeSecurityHubFinding)).Inc()
+			}
+
+			return result, errors.New(err)
+		}
+
+		result.Imported += int(aws.ToInt32(resp.SuccessCount))
+		result.Failed = append(result.Failed, resp.FailedFindings...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ImportFindings", ccfg.ResourceTypeSecurityHubFinding)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return result, nil
+}
@@ -73,6 +73,16 @@ func (e Topic) GetTags() map[string]string {
 	return tags
 }
 
+// WithTags returns a copy carrying the given tags, for bulk tag enrichment.
+func (e Topic) WithTags(tags map[string]string) service.ResourceInterface {
+	e.Tags = make([]types.Tag, 0, len(tags))
+	for key, value := range tags {
+		e.Tags = append(e.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return e
+}
+
 func (e Topic) GetTagValue(tag string) string {
 	val, ok := e.GetTags()[tag]
 	if !ok {
@@ -13,6 +13,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/sns"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -89,7 +90,10 @@ func (r *SnsRepository) ListTopicsByInput(query *awssns.ListTopicsInput) ([]Topi
 			return topics, errors.New(err)
 		}
 
-		tags, _ := r.GetTopicTags(v)
+		var tags []types.Tag
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetTopicTags(v)
+		}
 		topic := NewTopic(r.client, v, attrsOutput.Attributes, tags)
 		topics = append(topics, topic)
 	}
@@ -47,6 +47,12 @@ func (e Queue) GetTags() map[string]string {
 	return e.Tags
 }
 
+// WithTags returns a copy carrying the given tags, for bulk tag enrichment.
+func (e Queue) WithTags(tags map[string]string) service.ResourceInterface {
+	e.Tags = tags
+	return e
+}
+
 func (e Queue) GetTagValue(tag string) string {
 	val, ok := e.GetTags()[tag]
 	if !ok {
@@ -12,6 +12,7 @@ import (
 	ptypes "github.com/imunhatep/awslib/provider/types"
 	v3 "github.com/imunhatep/awslib/provider/v3"
 	"github.com/imunhatep/awslib/provider/v3/clients/sqs"
+	"github.com/imunhatep/awslib/service"
 	ccfg "github.com/imunhatep/awslib/service/cfg"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
@@ -94,7 +95,10 @@ func (r *SqsRepository) ListQueuesByInput(query *awssqs.ListQueuesInput) ([]Queu
 			return queues, errors.New(err)
 		}
 
-		tags, _ := r.GetQueueTags(queueUrl)
+		var tags map[string]string
+		if !service.TagFetchSkipped(r.ctx) {
+			tags, _ = r.GetQueueTags(queueUrl)
+		}
 		queue := NewQueue(r.client, queueUrl, attrsOutput.Attributes, tags)
 		queues = append(queues, queue)
 	}
@@ -0,0 +1,208 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package ssm
+
+import (
+	"fmt"
+
+	awsssm "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// SsmRepositoryCached wraps SsmRepository and caches results of Get*/List* calls.
+type SsmRepositoryCached struct {
+	repo  *SsmRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a SsmRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *SsmRepository) WithCache(dc *cache.DataCache) *SsmRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &SsmRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// GetParameter returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) GetParameter(name string) (*Parameter, error) {
+	cacheKey := cache.Key("GetParameter", name)
+	var cached *Parameter
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetParameter(name)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetParameterTags returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) GetParameterTags(name string) ([]types.Tag, error) {
+	cacheKey := cache.Key("GetParameterTags", name)
+	var cached []types.Tag
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetParameterTags(name)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// GetParametersByPath returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) GetParametersByPath(path string, recursive bool) ([]Parameter, error) {
+	cacheKey := cache.Key("GetParametersByPath", path, recursive)
+	var cached []Parameter
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.GetParametersByPath(path, recursive)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListComplianceItemsAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListComplianceItemsAll() ([]types.ComplianceItem, error) {
+	cacheKey := cache.Key("ListComplianceItemsAll")
+	var cached []types.ComplianceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListComplianceItemsAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListComplianceItemsByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListComplianceItemsByInput(query *awsssm.ListComplianceItemsInput) ([]types.ComplianceItem, error) {
+	cacheKey := cache.Key("ListComplianceItemsByInput", query)
+	var cached []types.ComplianceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListComplianceItemsByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListComplianceItemsByInstance returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListComplianceItemsByInstance(instanceID string) ([]types.ComplianceItem, error) {
+	cacheKey := cache.Key("ListComplianceItemsByInstance", instanceID)
+	var cached []types.ComplianceItem
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListComplianceItemsByInstance(instanceID)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListInstancePatchStates returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListInstancePatchStates(instanceIDs []string) ([]types.InstancePatchState, error) {
+	cacheKey := cache.Key("ListInstancePatchStates", instanceIDs)
+	var cached []types.InstancePatchState
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListInstancePatchStates(instanceIDs)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListInventoryEntries returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListInventoryEntries(instanceID string, typeName string) (InventoryEntries, error) {
+	cacheKey := cache.Key("ListInventoryEntries", instanceID, typeName)
+	var cached InventoryEntries
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListInventoryEntries(instanceID, typeName)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListInventoryEntriesByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListInventoryEntriesByInput(query *awsssm.ListInventoryEntriesInput) (InventoryEntries, error) {
+	cacheKey := cache.Key("ListInventoryEntriesByInput", query)
+	var cached InventoryEntries
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListInventoryEntriesByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListManagedInstancesAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListManagedInstancesAll() ([]ManagedInstance, error) {
+	cacheKey := cache.Key("ListManagedInstancesAll")
+	var cached []ManagedInstance
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListManagedInstancesAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListManagedInstancesByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListManagedInstancesByInput(query *awsssm.DescribeInstanceInformationInput) ([]ManagedInstance, error) {
+	cacheKey := cache.Key("ListManagedInstancesByInput", query)
+	var cached []ManagedInstance
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListManagedInstancesByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListParametersAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListParametersAll() ([]Parameter, error) {
+	cacheKey := cache.Key("ListParametersAll")
+	var cached []Parameter
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListParametersAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListParametersByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *SsmRepositoryCached) ListParametersByInput(query *awsssm.DescribeParametersInput) ([]Parameter, error) {
+	cacheKey := cache.Key("ListParametersByInput", query)
+	var cached []Parameter
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListParametersByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
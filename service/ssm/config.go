@@ -0,0 +1,284 @@
+package ssm
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// ParameterReader reads a parameter tree with values decrypted; SsmRepository
+// is one.
+type ParameterReader interface {
+	DecryptParametersByPath(path string, recursive bool) ([]Parameter, error)
+}
+
+// ConfigLoader fills a struct from the parameter tree under a path. Fields
+// name their parameter, relative to the path, with an `ssm` tag:
+//
+//	type Config struct {
+//		Port     int               `ssm:"port,default=8080"`
+//		Timeout  time.Duration     `ssm:"timeout"`
+//		Hosts    []string          `ssm:"hosts"` // a StringList, or any comma separated value
+//		Features map[string]string `ssm:"features"` // every parameter below features/
+//		DB       struct {
+//			Host     string `ssm:"host,required"`
+//			Password string `ssm:"password"` // SecureString, decrypted
+//		} `ssm:"db"` // parameters below db/
+//	}
+//
+// Untagged fields and fields tagged `ssm:"-"` are left alone. Supported field
+// types are strings, bools, numbers, time.Duration, []string,
+// map[string]string, encoding.TextUnmarshaler, structs and pointers to these.
+type ConfigLoader struct {
+	reader ParameterReader
+	path   string
+}
+
+func NewConfigLoader(reader ParameterReader, path string) ConfigLoader {
+	return ConfigLoader{reader: reader, path: path}
+}
+
+// Load reads the tree and fills target, a pointer to a struct. Every missing
+// required parameter and every value that does not parse is reported in the
+// one error.
+func (l ConfigLoader) Load(target any) error {
+	parameters, err := l.reader.DecryptParametersByPath(l.path, true)
+	if err != nil {
+		return err
+	}
+
+	return DecodeParameters(parameters, l.path, target)
+}
+
+// DecodeParameters fills target from parameters read under path, as Load
+// does.
+func DecodeParameters(parameters []Parameter, path string, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("ssm config target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	root := strings.TrimSuffix(path, "/") + "/"
+
+	values := map[string]string{}
+	for _, p := range parameters {
+		if name, ok := strings.CutPrefix(p.GetName(), root); ok {
+			values[name] = p.GetValue()
+		}
+	}
+
+	d := configDecoder{values: values, root: root}
+	d.decodeStruct(v.Elem(), "")
+
+	if len(d.problems) > 0 {
+		return errors.Errorf("ssm config %s: %s", path, strings.Join(d.problems, "; "))
+	}
+
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type configDecoder struct {
+	values   map[string]string
+	root     string
+	problems []string
+}
+
+type configTag struct {
+	name         string
+	required     bool
+	defaultValue *string
+}
+
+func parseConfigTag(tag string) configTag {
+	parts := strings.Split(tag, ",")
+	parsed := configTag{name: parts[0]}
+
+	for _, option := range parts[1:] {
+		switch {
+		case option == "required":
+			parsed.required = true
+		case strings.HasPrefix(option, "default="):
+			value := strings.TrimPrefix(option, "default=")
+			parsed.defaultValue = &value
+		}
+	}
+
+	return parsed
+}
+
+func (d *configDecoder) decodeStruct(v reflect.Value, prefix string) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		tag, ok := field.Tag.Lookup("ssm")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+
+		d.decodeField(v.Field(i), prefix, parseConfigTag(tag))
+	}
+}
+
+func (d *configDecoder) decodeField(v reflect.Value, prefix string, tag configTag) {
+	name := prefix + tag.name
+
+	// nested structs take the parameters below their name
+	if isConfigStruct(v.Type()) {
+		d.decodeStruct(v, name+"/")
+		return
+	}
+
+	if v.Kind() == reflect.Pointer && isConfigStruct(v.Type().Elem()) {
+		if !d.hasChildren(name + "/") {
+			return
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		d.decodeStruct(v.Elem(), name+"/")
+
+		return
+	}
+
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.String {
+			d.problems = append(d.problems, d.root+name+": only map[string]string is supported")
+			return
+		}
+
+		d.decodeMap(v, name+"/", tag.required)
+
+		return
+	}
+
+	value, ok := d.values[name]
+	if !ok && tag.defaultValue != nil {
+		value, ok = *tag.defaultValue, true
+	}
+
+	if !ok {
+		if tag.required {
+			d.problems = append(d.problems, d.root+name+" is required")
+		}
+
+		return
+	}
+
+	if err := setConfigValue(v, value); err != nil {
+		d.problems = append(d.problems, d.root+name+": "+err.Error())
+	}
+}
+
+func (d *configDecoder) decodeMap(v reflect.Value, prefix string, required bool) {
+	found := false
+	for name, value := range d.values {
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), reflect.ValueOf(value).Convert(v.Type().Elem()))
+		found = true
+	}
+
+	if !found && required {
+		d.problems = append(d.problems, d.root+strings.TrimSuffix(prefix, "/")+" is required")
+	}
+}
+
+func (d *configDecoder) hasChildren(prefix string) bool {
+	for name := range d.values {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isConfigStruct tells a struct decoded field by field from one decoded from
+// a single value.
+func isConfigStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setConfigValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return setConfigValue(v.Elem(), value)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if v.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(duration))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.Errorf("unsupported type %s", v.Type())
+		}
+
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(value, ",") {
+			items = reflect.Append(items, reflect.ValueOf(strings.TrimSpace(item)).Convert(v.Type().Elem()))
+		}
+		v.Set(items)
+	default:
+		return errors.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package ssm
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-central-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+// fakeReader serves a fixed tree of parameters.
+type fakeReader struct {
+	values map[string]string
+	path   string
+}
+
+func (f *fakeReader) DecryptParametersByPath(path string, recursive bool) ([]Parameter, error) {
+	f.path = path
+
+	var parameters []Parameter
+	for name, value := range f.values {
+		parameters = append(parameters, NewParameter(mockClient{}, types.Parameter{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+			Type:  types.ParameterTypeString,
+		}))
+	}
+
+	return parameters, nil
+}
+
+type appConfig struct {
+	Port     int               `ssm:"port,default=8080"`
+	Debug    bool              `ssm:"debug"`
+	Timeout  time.Duration     `ssm:"timeout"`
+	Ratio    float64           `ssm:"ratio"`
+	Hosts    []string          `ssm:"hosts"`
+	Features map[string]string `ssm:"features"`
+	Listen   netip.Addr        `ssm:"listen"`
+	Name     *string           `ssm:"name"`
+	Ignored  string
+	DB       struct {
+		Host     string `ssm:"host,required"`
+		Password string `ssm:"password"`
+	} `ssm:"db"`
+	Cache *struct {
+		Size uint `ssm:"size"`
+	} `ssm:"cache"`
+	Queue *struct {
+		Url string `ssm:"url"`
+	} `ssm:"queue"`
+}
+
+func TestConfigLoader(t *testing.T) {
+	reader := &fakeReader{values: map[string]string{
+		"/prod/app/debug":          "true",
+		"/prod/app/timeout":        "1m30s",
+		"/prod/app/ratio":          "0.25",
+		"/prod/app/hosts":          "a.example.com, b.example.com",
+		"/prod/app/features/beta":  "on",
+		"/prod/app/features/x/y":   "deep",
+		"/prod/app/listen":         "10.0.0.1",
+		"/prod/app/name":           "checkout",
+		"/prod/app/db/host":        "db.internal",
+		"/prod/app/db/password":    "s3cr3t",
+		"/prod/app/cache/size":     "512",
+		"/prod/other/db/host":      "elsewhere",
+		"/prod/application/ignore": "not under /prod/app/",
+	}}
+
+	var config appConfig
+	require.NoError(t, NewConfigLoader(reader, "/prod/app").Load(&config))
+
+	assert.Equal(t, "/prod/app", reader.path)
+	assert.Equal(t, 8080, config.Port)
+	assert.True(t, config.Debug)
+	assert.Equal(t, 90*time.Second, config.Timeout)
+	assert.Equal(t, 0.25, config.Ratio)
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, config.Hosts)
+	assert.Equal(t, map[string]string{"beta": "on", "x/y": "deep"}, config.Features)
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), config.Listen)
+	assert.Equal(t, "checkout", aws.ToString(config.Name))
+	assert.Equal(t, "db.internal", config.DB.Host)
+	assert.Equal(t, "s3cr3t", config.DB.Password)
+	require.NotNil(t, config.Cache)
+	assert.Equal(t, uint(512), config.Cache.Size)
+	assert.Nil(t, config.Queue, "no parameter below queue/")
+}
+
+func TestConfigLoaderReportsEveryProblem(t *testing.T) {
+	reader := &fakeReader{values: map[string]string{
+		"/app/port":    "eighty",
+		"/app/timeout": "soon",
+	}}
+
+	var config appConfig
+	err := NewConfigLoader(reader, "/app/").Load(&config)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "/app/port: ")
+	assert.Contains(t, err.Error(), "/app/timeout: ")
+	assert.Contains(t, err.Error(), "/app/db/host is required")
+
+	assert.Error(t, DecodeParameters(nil, "/app", config), "target must be a pointer")
+}
+
+func TestNewParameter(t *testing.T) {
+	parameter := NewParameter(mockClient{}, types.Parameter{
+		ARN:              aws.String("arn:aws:ssm:eu-central-1:123456789012:parameter/prod/app/db/password"),
+		Name:             aws.String("/prod/app/db/password"),
+		Type:             types.ParameterTypeSecureString,
+		Value:            aws.String("AQICAH..."),
+		Version:          3,
+		LastModifiedDate: aws.Time(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)),
+	})
+
+	assert.Equal(t, "/prod/app/db/password", parameter.GetName())
+	assert.Equal(t, "/prod/app/db/password", parameter.GetId())
+	assert.Equal(t, "arn:aws:ssm:eu-central-1:123456789012:parameter/prod/app/db/password", parameter.GetArn())
+	assert.Equal(t, ccfg.ResourceTypeSsmParameter, parameter.GetType())
+	assert.True(t, parameter.IsSecure())
+	assert.Equal(t, int64(3), parameter.Version)
+	assert.Equal(t, "AQICAH...", parameter.GetValue())
+
+	tagged := parameter.WithTags(map[string]string{"team": "payments"})
+	assert.Equal(t, map[string]string{"team": "payments"}, tagged.GetTags())
+}
@@ -0,0 +1,16 @@
+// Code generated by cmd/generate-gob/main.go; DO NOT EDIT.
+
+package ssm
+
+import "encoding/gob"
+
+// init registers this package's types with encoding/gob so they can be
+// serialized by the cache handlers. Importing this package is sufficient.
+func init() {
+	gob.Register(ConfigLoader{})
+	gob.Register(InstanceManagement{})
+	gob.Register(InstanceManagementJoin{})
+	gob.Register(InventoryEntries{})
+	gob.Register(ManagedInstance{})
+	gob.Register(Parameter{})
+}
@@ -0,0 +1,161 @@
+package ssm
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imunhatep/awslib/service/ec2"
+)
+
+const DefaultAgentStaleAfter = 24 * time.Hour
+
+type ManagementIssue string
+
+const (
+	// InstanceNotManaged is a running instance not registered with Systems
+	// Manager: no agent, no instance profile, or no route to the endpoints.
+	InstanceNotManaged ManagementIssue = "NotManaged"
+	// AgentStale is an agent that is not online or has not pinged within the
+	// stale window.
+	AgentStale ManagementIssue = "AgentStale"
+	// AgentOutdated is an agent behind the latest released version.
+	AgentOutdated ManagementIssue = "AgentOutdated"
+	// InstanceNonCompliant is a node with a NON_COMPLIANT compliance item, or
+	// with missing or failed patches.
+	InstanceNonCompliant ManagementIssue = "NonCompliant"
+)
+
+// InstanceManagement is what Systems Manager knows of an EC2 instance.
+type InstanceManagement struct {
+	Instance ec2.Instance
+	// Managed is nil when the instance is not registered.
+	Managed    *ManagedInstance
+	PatchState *types.InstancePatchState
+	// NonCompliant holds the NON_COMPLIANT items of the instance.
+	NonCompliant []types.ComplianceItem
+	Issues       []ManagementIssue
+}
+
+// HasIssue reports whether the instance was flagged with the given issue.
+func (m InstanceManagement) HasIssue(issue ManagementIssue) bool {
+	for _, i := range m.Issues {
+		if i == issue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsReachable reports an instance SSM can run commands and sessions on.
+func (m InstanceManagement) IsReachable() bool {
+	return m.Managed != nil && m.Managed.IsOnline() && !m.HasIssue(AgentStale)
+}
+
+// InstanceManagementJoin joins Systems Manager data onto EC2 instances.
+type InstanceManagementJoin struct {
+	staleAfter time.Duration
+}
+
+func NewInstanceManagementJoin() InstanceManagementJoin {
+	return InstanceManagementJoin{staleAfter: DefaultAgentStaleAfter}
+}
+
+// WithStaleAfter returns a join flagging agents that have not pinged for
+// longer than d.
+func (j InstanceManagementJoin) WithStaleAfter(d time.Duration) InstanceManagementJoin {
+	j.staleAfter = d
+	return j
+}
+
+// Join returns one InstanceManagement per instance, in the order given.
+// Instances come from Ec2Repository.ListInstancesAll; managed, compliance
+// and patch states from the SsmRepository of the same account and region.
+//
+// Only running instances are flagged: a stopped instance cannot ping, so
+// neither a missing registration nor a silent agent says anything about it.
+func (j InstanceManagementJoin) Join(
+	instances []ec2.Instance,
+	managed []ManagedInstance,
+	compliance []types.ComplianceItem,
+	patchStates []types.InstancePatchState,
+	now time.Time,
+) []InstanceManagement {
+	managedByID := map[string]ManagedInstance{}
+	for _, m := range managed {
+		managedByID[m.GetId()] = m
+	}
+
+	nonCompliantByID := map[string][]types.ComplianceItem{}
+	for _, item := range compliance {
+		if item.Status == types.ComplianceStatusNonCompliant {
+			id := aws.ToString(item.ResourceId)
+			nonCompliantByID[id] = append(nonCompliantByID[id], item)
+		}
+	}
+
+	patchStateByID := map[string]types.InstancePatchState{}
+	for _, state := range patchStates {
+		patchStateByID[aws.ToString(state.InstanceId)] = state
+	}
+
+	joined := make([]InstanceManagement, 0, len(instances))
+	for _, instance := range instances {
+		id := instance.GetId()
+		m := InstanceManagement{Instance: instance, NonCompliant: nonCompliantByID[id]}
+
+		if mi, ok := managedByID[id]; ok {
+			m.Managed = &mi
+		}
+
+		if state, ok := patchStateByID[id]; ok {
+			m.PatchState = &state
+		}
+
+		if isRunning(instance) {
+			m.Issues = j.issues(m, now)
+		}
+
+		joined = append(joined, m)
+	}
+
+	return joined
+}
+
+func (j InstanceManagementJoin) issues(m InstanceManagement, now time.Time) []ManagementIssue {
+	if m.Managed == nil {
+		return []ManagementIssue{InstanceNotManaged}
+	}
+
+	var issues []ManagementIssue
+	if !m.Managed.IsOnline() || now.Sub(m.Managed.GetLastPingTime()) > j.staleAfter {
+		issues = append(issues, AgentStale)
+	}
+
+	if !m.Managed.IsLatestAgent() {
+		issues = append(issues, AgentOutdated)
+	}
+
+	if len(m.NonCompliant) > 0 || hasPatchGaps(m.PatchState) {
+		issues = append(issues, InstanceNonCompliant)
+	}
+
+	return issues
+}
+
+func hasPatchGaps(state *types.InstancePatchState) bool {
+	if state == nil {
+		return false
+	}
+
+	return state.MissingCount > 0 ||
+		state.FailedCount > 0 ||
+		aws.ToInt32(state.CriticalNonCompliantCount) > 0 ||
+		aws.ToInt32(state.SecurityNonCompliantCount) > 0
+}
+
+func isRunning(instance ec2.Instance) bool {
+	return instance.State != nil && instance.State.Name == ec2types.InstanceStateNameRunning
+}
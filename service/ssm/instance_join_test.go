@@ -0,0 +1,103 @@
+package ssm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imunhatep/awslib/service/ec2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInstance(id string, state ec2types.InstanceStateName) ec2.Instance {
+	return ec2.NewInstance(mockClient{}, ec2types.Instance{
+		InstanceId: aws.String(id),
+		State:      &ec2types.InstanceState{Name: state},
+	})
+}
+
+func testManagedInstance(id string, ping types.PingStatus, lastPing time.Time, latest bool) ManagedInstance {
+	return NewManagedInstance(mockClient{}, types.InstanceInformation{
+		InstanceId:       aws.String(id),
+		PingStatus:       ping,
+		LastPingDateTime: aws.Time(lastPing),
+		IsLatestVersion:  aws.Bool(latest),
+	})
+}
+
+func TestInstanceManagementJoin(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	instances := []ec2.Instance{
+		testInstance("i-healthy", ec2types.InstanceStateNameRunning),
+		testInstance("i-unmanaged", ec2types.InstanceStateNameRunning),
+		testInstance("i-stopped", ec2types.InstanceStateNameStopped),
+		testInstance("i-lost", ec2types.InstanceStateNameRunning),
+		testInstance("i-silent", ec2types.InstanceStateNameRunning),
+		testInstance("i-old", ec2types.InstanceStateNameRunning),
+		testInstance("i-noncompliant", ec2types.InstanceStateNameRunning),
+		testInstance("i-unpatched", ec2types.InstanceStateNameRunning),
+	}
+
+	managed := []ManagedInstance{
+		testManagedInstance("i-healthy", types.PingStatusOnline, now.Add(-time.Minute), true),
+		testManagedInstance("i-lost", types.PingStatusConnectionLost, now.Add(-time.Hour), true),
+		testManagedInstance("i-silent", types.PingStatusOnline, now.Add(-48*time.Hour), true),
+		testManagedInstance("i-old", types.PingStatusOnline, now.Add(-time.Minute), false),
+		testManagedInstance("i-noncompliant", types.PingStatusOnline, now.Add(-time.Minute), true),
+		testManagedInstance("i-unpatched", types.PingStatusOnline, now.Add(-time.Minute), true),
+	}
+
+	compliance := []types.ComplianceItem{
+		{ResourceId: aws.String("i-healthy"), Status: types.ComplianceStatusCompliant},
+		{ResourceId: aws.String("i-noncompliant"), Status: types.ComplianceStatusNonCompliant, Title: aws.String("antivirus")},
+	}
+
+	patchStates := []types.InstancePatchState{
+		{InstanceId: aws.String("i-healthy")},
+		{InstanceId: aws.String("i-unpatched"), CriticalNonCompliantCount: aws.Int32(2)},
+	}
+
+	joined := NewInstanceManagementJoin().Join(instances, managed, compliance, patchStates, now)
+	require.Len(t, joined, len(instances))
+
+	byID := map[string]InstanceManagement{}
+	for _, m := range joined {
+		byID[m.Instance.GetId()] = m
+	}
+
+	assert.Empty(t, byID["i-healthy"].Issues)
+	assert.True(t, byID["i-healthy"].IsReachable())
+	assert.NotNil(t, byID["i-healthy"].PatchState)
+
+	assert.Equal(t, []ManagementIssue{InstanceNotManaged}, byID["i-unmanaged"].Issues)
+	assert.False(t, byID["i-unmanaged"].IsReachable())
+
+	assert.Empty(t, byID["i-stopped"].Issues, "stopped instances are not flagged")
+
+	assert.Equal(t, []ManagementIssue{AgentStale}, byID["i-lost"].Issues)
+	assert.Equal(t, []ManagementIssue{AgentStale}, byID["i-silent"].Issues)
+	assert.False(t, byID["i-silent"].IsReachable())
+
+	assert.Equal(t, []ManagementIssue{AgentOutdated}, byID["i-old"].Issues)
+	assert.True(t, byID["i-old"].IsReachable())
+
+	assert.Equal(t, []ManagementIssue{InstanceNonCompliant}, byID["i-noncompliant"].Issues)
+	assert.Len(t, byID["i-noncompliant"].NonCompliant, 1)
+
+	assert.True(t, byID["i-unpatched"].HasIssue(InstanceNonCompliant))
+}
+
+func TestInstanceManagementJoinStaleAfter(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	instances := []ec2.Instance{testInstance("i-1", ec2types.InstanceStateNameRunning)}
+	managed := []ManagedInstance{testManagedInstance("i-1", types.PingStatusOnline, now.Add(-2*time.Hour), true)}
+
+	joined := NewInstanceManagementJoin().WithStaleAfter(time.Hour).Join(instances, managed, nil, nil, now)
+	require.Len(t, joined, 1)
+	assert.Equal(t, []ManagementIssue{AgentStale}, joined[0].Issues)
+}
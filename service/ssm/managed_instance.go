@@ -0,0 +1,76 @@
+package ssm
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imunhatep/awslib/helper"
+	"github.com/imunhatep/awslib/service"
+)
+
+// ManagedInstance is a node registered with Systems Manager, an EC2 instance
+// ("i-...") or a hybrid activation ("mi-...").
+type ManagedInstance struct {
+	service.AbstractResource
+	types.InstanceInformation
+}
+
+func NewManagedInstance(client AwsClient, info types.InstanceInformation) ManagedInstance {
+	return ManagedInstance{
+		AbstractResource: service.AbstractResource{
+			AccountID: client.GetAccountID(),
+			Region:    client.GetRegion(),
+			ID:        aws.ToString(info.InstanceId),
+			ARN:       helper.BuildArn(client.GetAccountID(), client.GetRegion(), "ssm", "managed-instance/", info.InstanceId),
+			CreatedAt: aws.ToTime(info.RegistrationDate),
+			Type:      cfg.ResourceTypeManagedInstanceInventory,
+		},
+		InstanceInformation: info,
+	}
+}
+
+func (e ManagedInstance) GetName() string {
+	if e.InstanceInformation.Name != nil {
+		return aws.ToString(e.InstanceInformation.Name)
+	}
+
+	return aws.ToString(e.ComputerName)
+}
+
+// GetTags returns no tags: DescribeInstanceInformation does not return them,
+// and the tags of an EC2 node are those of its ec2.Instance.
+func (e ManagedInstance) GetTags() map[string]string {
+	return map[string]string{}
+}
+
+func (e ManagedInstance) GetTagValue(tag string) string {
+	return ""
+}
+
+// IsOnline reports an agent that pinged Systems Manager recently enough to
+// take commands and sessions.
+func (e ManagedInstance) IsOnline() bool {
+	return e.PingStatus == types.PingStatusOnline
+}
+
+// IsLatestAgent reports an agent at the latest released version. Systems
+// Manager reports no version state for some platforms; those count as latest.
+func (e ManagedInstance) IsLatestAgent() bool {
+	return e.IsLatestVersion == nil || aws.ToBool(e.IsLatestVersion)
+}
+
+func (e ManagedInstance) GetLastPingTime() time.Time {
+	return aws.ToTime(e.LastPingDateTime)
+}
+
+// InventoryEntries is one inventory type collected from a managed node, e.g.
+// AWS:Application or AWS:Network.
+type InventoryEntries struct {
+	InstanceID    string
+	TypeName      string
+	SchemaVersion string
+	CaptureTime   time.Time
+	Entries       []map[string]string
+}
@@ -0,0 +1,213 @@
+package ssm
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awsssm "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+)
+
+// patchStatesBatchSize is the most instance ids DescribeInstancePatchStates
+// accepts.
+const patchStatesBatchSize = 50
+
+// ListManagedInstancesAll returns every node registered with Systems Manager.
+func (r *SsmRepository) ListManagedInstancesAll() ([]ManagedInstance, error) {
+	return r.ListManagedInstancesByInput(&awsssm.DescribeInstanceInformationInput{})
+}
+
+func (r *SsmRepository) ListManagedInstancesByInput(query *awsssm.DescribeInstanceInformationInput) ([]ManagedInstance, error) {
+	start := time.Now()
+	var instances []ManagedInstance
+
+	p := awsssm.NewDescribeInstanceInformationPaginator(r.ssmClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeInstanceInformation", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeInstanceInformation", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+			}
+
+			return instances, errors.New(err)
+		}
+
+		for _, v := range resp.InstanceInformationList {
+			instances = append(instances, NewManagedInstance(r.client, v))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("DescribeInstanceInformation", cfg.ResourceTypeManagedInstanceInventory)).
+			Add(float64(len(instances)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListManagedInstancesByInput", cfg.ResourceTypeManagedInstanceInventory)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return instances, nil
+}
+
+// ListInventoryEntries returns the entries of one inventory type of a managed
+// node, e.g. "AWS:Application".
+func (r *SsmRepository) ListInventoryEntries(instanceID, typeName string) (InventoryEntries, error) {
+	return r.ListInventoryEntriesByInput(&awsssm.ListInventoryEntriesInput{
+		InstanceId: aws.String(instanceID),
+		TypeName:   aws.String(typeName),
+	})
+}
+
+// ListInventoryEntriesByInput follows ListInventoryEntries pagination, which
+// the SDK has no paginator for.
+func (r *SsmRepository) ListInventoryEntriesByInput(query *awsssm.ListInventoryEntriesInput) (InventoryEntries, error) {
+	start := time.Now()
+	input := *query
+
+	inventory := InventoryEntries{
+		InstanceID: aws.ToString(query.InstanceId),
+		TypeName:   aws.ToString(query.TypeName),
+	}
+
+	for {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListInventoryEntries", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+		}
+
+		resp, err := r.ssmClient().ListInventoryEntries(r.ctx, &input)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListInventoryEntries", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+			}
+
+			return inventory, errors.New(err)
+		}
+
+		inventory.SchemaVersion = aws.ToString(resp.SchemaVersion)
+		if captured, err := time.Parse(time.RFC3339, aws.ToString(resp.CaptureTime)); err == nil {
+			inventory.CaptureTime = captured
+		}
+		inventory.Entries = append(inventory.Entries, resp.Entries...)
+
+		if aws.ToString(resp.NextToken) == "" {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("ListInventoryEntries", cfg.ResourceTypeManagedInstanceInventory)).
+			Add(float64(len(inventory.Entries)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListInventoryEntriesByInput", cfg.ResourceTypeManagedInstanceInventory)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return inventory, nil
+}
+
+// ListComplianceItemsAll returns the compliance items, of every compliance
+// type, of every managed node.
+func (r *SsmRepository) ListComplianceItemsAll() ([]types.ComplianceItem, error) {
+	return r.ListComplianceItemsByInput(&awsssm.ListComplianceItemsInput{
+		ResourceTypes: []string{"ManagedInstance"},
+	})
+}
+
+// ListComplianceItemsByInstance returns the compliance items of one managed
+// node; ListComplianceItems takes a single resource id.
+func (r *SsmRepository) ListComplianceItemsByInstance(instanceID string) ([]types.ComplianceItem, error) {
+	return r.ListComplianceItemsByInput(&awsssm.ListComplianceItemsInput{
+		ResourceIds:   []string{instanceID},
+		ResourceTypes: []string{"ManagedInstance"},
+	})
+}
+
+func (r *SsmRepository) ListComplianceItemsByInput(query *awsssm.ListComplianceItemsInput) ([]types.ComplianceItem, error) {
+	start := time.Now()
+	var items []types.ComplianceItem
+
+	p := awsssm.NewListComplianceItemsPaginator(r.ssmClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("ListComplianceItems", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("ListComplianceItems", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+			}
+
+			return items, errors.New(err)
+		}
+
+		items = append(items, resp.ComplianceItems...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("ListComplianceItems", cfg.ResourceTypeManagedInstanceInventory)).
+			Add(float64(len(items)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListComplianceItemsByInput", cfg.ResourceTypeManagedInstanceInventory)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return items, nil
+}
+
+// ListInstancePatchStates returns the Patch Manager state of the given
+// managed nodes, asking for them in batches of 50. Nodes never scanned have
+// no state.
+func (r *SsmRepository) ListInstancePatchStates(instanceIDs []string) ([]types.InstancePatchState, error) {
+	start := time.Now()
+	var states []types.InstancePatchState
+
+	for offset := 0; offset < len(instanceIDs); offset += patchStatesBatchSize {
+		batch := instanceIDs[offset:min(offset+patchStatesBatchSize, len(instanceIDs))]
+
+		p := awsssm.NewDescribeInstancePatchStatesPaginator(r.ssmClient(), &awsssm.DescribeInstancePatchStatesInput{
+			InstanceIds: batch,
+		})
+		for p.HasMorePages() {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequests.With(r.promLabels("DescribeInstancePatchStates", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+			}
+
+			resp, err := p.NextPage(r.ctx)
+			if err != nil {
+				if metrics.AwsMetricsEnabled {
+					metrics.AwsApiRequestErrors.With(r.promLabels("DescribeInstancePatchStates", cfg.ResourceTypeManagedInstanceInventory)).Inc()
+				}
+
+				return states, errors.New(err)
+			}
+
+			states = append(states, resp.InstancePatchStates...)
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("DescribeInstancePatchStates", cfg.ResourceTypeManagedInstanceInventory)).
+			Add(float64(len(states)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListInstancePatchStates", cfg.ResourceTypeManagedInstanceInventory)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return states, nil
+}
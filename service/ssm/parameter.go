@@ -0,0 +1,105 @@
+package ssm
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+)
+
+// Parameter is a Parameter Store parameter. Listed parameters carry the
+// metadata of DescribeParameters and no value; read parameters carry the
+// value and the metadata the read returns.
+type Parameter struct {
+	service.AbstractResource
+	types.ParameterMetadata
+	// Value is the parameter value; of a SecureString, the ciphertext unless
+	// read with decryption.
+	Value *string
+	// Selector is the version or label the parameter was read by, e.g. ":3".
+	Selector *string
+	Tags     []types.Tag
+}
+
+func NewParameter(client AwsClient, parameter types.Parameter) Parameter {
+	p := newParameter(client, types.ParameterMetadata{
+		ARN:              parameter.ARN,
+		DataType:         parameter.DataType,
+		LastModifiedDate: parameter.LastModifiedDate,
+		Name:             parameter.Name,
+		Type:             parameter.Type,
+		Version:          parameter.Version,
+	})
+	p.Value = parameter.Value
+	p.Selector = parameter.Selector
+
+	return p
+}
+
+func NewParameterFromMetadata(client AwsClient, metadata types.ParameterMetadata, tags []types.Tag) Parameter {
+	p := newParameter(client, metadata)
+	p.Tags = tags
+
+	return p
+}
+
+func newParameter(client AwsClient, metadata types.ParameterMetadata) Parameter {
+	pArn, _ := arn.Parse(aws.ToString(metadata.ARN))
+
+	return Parameter{
+		AbstractResource: service.AbstractResource{
+			AccountID: client.GetAccountID(),
+			Region:    client.GetRegion(),
+			ID:        aws.ToString(metadata.Name),
+			ARN:       &pArn,
+			// Parameter Store keeps no creation date
+			CreatedAt: aws.ToTime(metadata.LastModifiedDate),
+			Type:      ccfg.ResourceTypeSsmParameter,
+		},
+		ParameterMetadata: metadata,
+	}
+}
+
+func (e Parameter) GetName() string {
+	return aws.ToString(e.ParameterMetadata.Name)
+}
+
+// GetValue returns the value, or "" for a listed parameter.
+func (e Parameter) GetValue() string {
+	return aws.ToString(e.Value)
+}
+
+// IsSecure reports a SecureString parameter.
+func (e Parameter) IsSecure() bool {
+	return e.ParameterMetadata.Type == types.ParameterTypeSecureString
+}
+
+func (e Parameter) GetTags() map[string]string {
+	tags := make(map[string]string)
+
+	for _, tag := range e.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return tags
+}
+
+// WithTags returns a copy carrying the given tags, for bulk tag enrichment.
+func (e Parameter) WithTags(tags map[string]string) service.ResourceInterface {
+	e.Tags = make([]types.Tag, 0, len(tags))
+	for key, value := range tags {
+		e.Tags = append(e.Tags, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return e
+}
+
+func (e Parameter) GetTagValue(tag string) string {
+	val, ok := e.GetTags()[tag]
+	if !ok {
+		return ""
+	}
+
+	return val
+}
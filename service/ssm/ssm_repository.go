@@ -0,0 +1,310 @@
+package ssm
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awsssm "github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/ssm"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// SsmRepository reads and writes Parameter Store parameters.
+//
+// Reads that decrypt SecureString values are named Decrypt* and Read*, not
+// Get* or List*, so the cached repository never stores plaintext: WithCache
+// gob-encodes results in the clear.
+type SsmRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewSsmRepository(ctx context.Context, client *v3.Client) *SsmRepository {
+	repo := &SsmRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *SsmRepository) ssmClient() *awsssm.Client {
+	return ssm.GetClient(r.client)
+}
+
+func (r *SsmRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *SsmRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// ListParametersAll returns the metadata of every parameter, without values.
+func (r *SsmRepository) ListParametersAll() ([]Parameter, error) {
+	return r.ListParametersByInput(&awsssm.DescribeParametersInput{})
+}
+
+// ListParametersByInput returns the metadata of the matching parameters, with
+// their tags unless the context skips tag fetching.
+func (r *SsmRepository) ListParametersByInput(query *awsssm.DescribeParametersInput) ([]Parameter, error) {
+	start := time.Now()
+	var parameters []Parameter
+
+	p := awsssm.NewDescribeParametersPaginator(r.ssmClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("DescribeParameters", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("DescribeParameters", ccfg.ResourceTypeSsmParameter)).Inc()
+			}
+
+			return parameters, errors.New(err)
+		}
+
+		for _, v := range resp.Parameters {
+			var tags []types.Tag
+			if !service.TagFetchSkipped(r.ctx) {
+				tags, _ = r.GetParameterTags(aws.ToString(v.Name))
+			}
+
+			parameters = append(parameters, NewParameterFromMetadata(r.client, v, tags))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("DescribeParameters", ccfg.ResourceTypeSsmParameter)).
+			Add(float64(len(parameters)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListParametersByInput", ccfg.ResourceTypeSsmParameter)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return parameters, nil
+}
+
+func (r *SsmRepository) GetParameterTags(name string) ([]types.Tag, error) {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("ListTagsForResource", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	tagOutput, err := r.ssmClient().ListTagsForResource(r.ctx, &awsssm.ListTagsForResourceInput{
+		ResourceId:   aws.String(name),
+		ResourceType: types.ResourceTypeForTaggingParameter,
+	})
+	if err != nil {
+		log.Debug().Str("parameter", name).Err(err).Msg("failed to fetch ssm.Parameter tags")
+		return []types.Tag{}, errors.New(err)
+	}
+
+	return tagOutput.TagList, nil
+}
+
+// GetParametersByPath returns the parameters under path, and below it when
+// recursive, with SecureString values left encrypted.
+func (r *SsmRepository) GetParametersByPath(path string, recursive bool) ([]Parameter, error) {
+	return r.ReadParametersByInput(&awsssm.GetParametersByPathInput{
+		Path:      aws.String(path),
+		Recursive: aws.Bool(recursive),
+	})
+}
+
+// DecryptParametersByPath returns the parameters under path, and below it
+// when recursive, with SecureString values decrypted.
+func (r *SsmRepository) DecryptParametersByPath(path string, recursive bool) ([]Parameter, error) {
+	return r.ReadParametersByInput(&awsssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(recursive),
+		WithDecryption: aws.Bool(true),
+	})
+}
+
+// ReadParametersByInput follows GetParametersByPath pagination. It decrypts
+// when the query asks to, which is why it is not cached.
+func (r *SsmRepository) ReadParametersByInput(query *awsssm.GetParametersByPathInput) ([]Parameter, error) {
+	start := time.Now()
+	var parameters []Parameter
+
+	p := awsssm.NewGetParametersByPathPaginator(r.ssmClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetParametersByPath", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetParametersByPath", ccfg.ResourceTypeSsmParameter)).Inc()
+			}
+
+			return parameters, errors.New(err)
+		}
+
+		for _, v := range resp.Parameters {
+			parameters = append(parameters, NewParameter(r.client, v))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetParametersByPath", ccfg.ResourceTypeSsmParameter)).
+			Add(float64(len(parameters)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ReadParametersByInput", ccfg.ResourceTypeSsmParameter)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return parameters, nil
+}
+
+// GetParameter returns one parameter, with a SecureString value left
+// encrypted. The name may select a version or label, e.g. "/app/db:3".
+func (r *SsmRepository) GetParameter(name string) (*Parameter, error) {
+	return r.getParameter(name, false)
+}
+
+// DecryptParameter returns one parameter, with a SecureString value
+// decrypted.
+func (r *SsmRepository) DecryptParameter(name string) (*Parameter, error) {
+	return r.getParameter(name, true)
+}
+
+func (r *SsmRepository) getParameter(name string, decrypt bool) (*Parameter, error) {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("GetParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	output, err := r.ssmClient().GetParameter(r.ctx, &awsssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(decrypt),
+	})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("GetParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		return nil, errors.New(err)
+	}
+
+	if output.Parameter == nil {
+		return nil, errors.New("parameter not found")
+	}
+
+	parameter := NewParameter(r.client, *output.Parameter)
+
+	return &parameter, nil
+}
+
+// PutParameter creates or, with Overwrite, updates a parameter and returns
+// its new version.
+func (r *SsmRepository) PutParameter(input *awsssm.PutParameterInput) (int64, error) {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("PutParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	output, err := r.ssmClient().PutParameter(r.ctx, input)
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("PutParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		return 0, errors.New(err)
+	}
+
+	return output.Version, nil
+}
+
+func (r *SsmRepository) DeleteParameter(name string) error {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("DeleteParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	_, err := r.ssmClient().DeleteParameter(r.ctx, &awsssm.DeleteParameterInput{Name: aws.String(name)})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("DeleteParameter", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		return errors.New(err)
+	}
+
+	return nil
+}
+
+// LabelParameterVersion moves the labels onto a version of a parameter; a
+// version of 0 labels the latest. Labels Parameter Store refuses are
+// returned as an error.
+func (r *SsmRepository) LabelParameterVersion(name string, version int64, labels ...string) error {
+	input := &awsssm.LabelParameterVersionInput{Name: aws.String(name), Labels: labels}
+	if version > 0 {
+		input.ParameterVersion = aws.Int64(version)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("LabelParameterVersion", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	output, err := r.ssmClient().LabelParameterVersion(r.ctx, input)
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("LabelParameterVersion", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		return errors.New(err)
+	}
+
+	if len(output.InvalidLabels) > 0 {
+		return errors.Errorf("invalid labels for parameter %s: %v", name, output.InvalidLabels)
+	}
+
+	return nil
+}
+
+// UnlabelParameterVersion removes labels from a version of a parameter.
+func (r *SsmRepository) UnlabelParameterVersion(name string, version int64, labels ...string) error {
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiRequests.With(r.promLabels("UnlabelParameterVersion", ccfg.ResourceTypeSsmParameter)).Inc()
+	}
+
+	_, err := r.ssmClient().UnlabelParameterVersion(r.ctx, &awsssm.UnlabelParameterVersionInput{
+		Name:             aws.String(name),
+		ParameterVersion: aws.Int64(version),
+		Labels:           labels,
+	})
+	if err != nil {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequestErrors.With(r.promLabels("UnlabelParameterVersion", ccfg.ResourceTypeSsmParameter)).Inc()
+		}
+
+		return errors.New(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// Code generated by generate-cached. DO NOT EDIT.
+package tagging
+
+import (
+	"fmt"
+
+	awsrgt "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/imunhatep/awslib/cache"
+)
+
+// TaggingRepositoryCached wraps TaggingRepository and caches results of Get*/List* calls.
+type TaggingRepositoryCached struct {
+	repo  *TaggingRepository
+	cache *cache.DataCache
+}
+
+// WithCache returns a TaggingRepositoryCached that stores/retrieves results via the given DataCache.
+// The cache namespace is set to "<accountID>:<region>".
+func (r *TaggingRepository) WithCache(dc *cache.DataCache) *TaggingRepositoryCached {
+	ns := fmt.Sprintf("%s:%s", r.client.GetAccountID(), r.client.GetRegion())
+	return &TaggingRepositoryCached{
+		repo:  r,
+		cache: dc.WithNamespace(ns),
+	}
+}
+
+// ListResourcesAll returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListResourcesAll() ([]TaggedResource, error) {
+	cacheKey := cache.Key("ListResourcesAll")
+	var cached []TaggedResource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesAll()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListResourcesByArns returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListResourcesByArns(arns []string) ([]TaggedResource, error) {
+	cacheKey := cache.Key("ListResourcesByArns", arns)
+	var cached []TaggedResource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesByArns(arns)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListResourcesByInput returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListResourcesByInput(query *awsrgt.GetResourcesInput) ([]TaggedResource, error) {
+	cacheKey := cache.Key("ListResourcesByInput", query)
+	var cached []TaggedResource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesByInput(query)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListResourcesByType returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListResourcesByType(resourceTypes []string) ([]TaggedResource, error) {
+	cacheKey := cache.Key("ListResourcesByType", resourceTypes)
+	var cached []TaggedResource
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListResourcesByType(resourceTypes)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListTagKeys returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListTagKeys() ([]string, error) {
+	cacheKey := cache.Key("ListTagKeys")
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListTagKeys()
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
+
+// ListTagValues returns cached results when available, otherwise delegates to the underlying repository.
+func (c *TaggingRepositoryCached) ListTagValues(key string) ([]string, error) {
+	cacheKey := cache.Key("ListTagValues", key)
+	var cached []string
+	if c.cache.Read(cacheKey, &cached) {
+		return cached, nil
+	}
+	r0, r1 := c.repo.ListTagValues(key)
+	if r1 == nil {
+		_ = c.cache.Write(cacheKey, r0)
+	}
+	return r0, r1
+}
@@ -0,0 +1,57 @@
+package tagging
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+)
+
+// TaggedResource is one ARN and its tags as the Resource Groups Tagging API
+// reports them. It is not a service.ResourceInterface: the API knows nothing
+// about a resource beyond its ARN.
+type TaggedResource struct {
+	AccountID         ptypes.AwsAccountID
+	Region            ptypes.AwsRegion
+	ARN               string
+	Tags              map[string]string
+	ComplianceDetails *types.ComplianceDetails
+}
+
+func NewTaggedResource(client AwsClient, mapping types.ResourceTagMapping) TaggedResource {
+	tags := make(map[string]string, len(mapping.Tags))
+	for _, tag := range mapping.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return TaggedResource{
+		AccountID:         client.GetAccountID(),
+		Region:            client.GetRegion(),
+		ARN:               aws.ToString(mapping.ResourceARN),
+		Tags:              tags,
+		ComplianceDetails: mapping.ComplianceDetails,
+	}
+}
+
+func (e TaggedResource) GetArn() string {
+	return e.ARN
+}
+
+func (e TaggedResource) GetTags() map[string]string {
+	return e.Tags
+}
+
+// ArnKey reduces an ARN to "service:resource" so the same resource matches
+// however its ARN was spelled: log group ARNs with and without the trailing
+// ":*", S3 bucket ARNs with or without region and account. It returns "" for
+// values that are not ARNs.
+func ArnKey(value string) string {
+	parsed, err := arn.Parse(value)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Service + ":" + strings.TrimSuffix(parsed.Resource, ":*")
+}
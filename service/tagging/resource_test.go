@@ -0,0 +1,45 @@
+package tagging
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct{}
+
+func (mockClient) GetRegion() ptypes.AwsRegion       { return "eu-west-1" }
+func (mockClient) GetAccountID() ptypes.AwsAccountID { return "123456789012" }
+
+func TestNewTaggedResource(t *testing.T) {
+	r := NewTaggedResource(mockClient{}, types.ResourceTagMapping{
+		ResourceARN: aws.String("arn:aws:sqs:eu-west-1:123456789012:jobs"),
+		Tags:        []types.Tag{{Key: aws.String("env"), Value: aws.String("prod")}},
+	})
+
+	assert.Equal(t, "arn:aws:sqs:eu-west-1:123456789012:jobs", r.GetArn())
+	assert.Equal(t, map[string]string{"env": "prod"}, r.GetTags())
+	assert.Equal(t, ptypes.AwsAccountID("123456789012"), r.AccountID)
+}
+
+func TestArnKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"log group wildcard", "arn:aws:logs:eu-west-1:123456789012:log-group:/app:*", "arn:aws:logs:eu-west-1:123456789012:log-group:/app"},
+		{"s3 bucket with region", "arn:aws:s3:eu-west-1:123456789012:bucket", "arn:aws:s3:::bucket"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEmpty(t, ArnKey(tt.a))
+			assert.Equal(t, ArnKey(tt.a), ArnKey(tt.b))
+		})
+	}
+
+	assert.Empty(t, ArnKey("https://sqs.eu-west-1.amazonaws.com/123456789012/jobs"))
+}
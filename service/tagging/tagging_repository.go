@@ -0,0 +1,267 @@
+package tagging
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cfg "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	awsrgt "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/go-errors/errors"
+	"github.com/imunhatep/awslib/metrics"
+	ptypes "github.com/imunhatep/awslib/provider/types"
+	v3 "github.com/imunhatep/awslib/provider/v3"
+	"github.com/imunhatep/awslib/provider/v3/clients/resourcegroupstaggingapi"
+	"github.com/imunhatep/awslib/service"
+	ccfg "github.com/imunhatep/awslib/service/cfg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// API limits: GetResources takes up to 100 ARNs per call, TagResources and
+// UntagResources up to 20.
+const (
+	maxArnsPerRead  = 100
+	maxArnsPerWrite = 20
+)
+
+type AwsClient interface {
+	GetRegion() ptypes.AwsRegion
+	GetAccountID() ptypes.AwsAccountID
+}
+
+// TaggingRepository reads and writes tags of any taggable resource in one
+// account and region through the Resource Groups Tagging API, instead of one
+// service-specific call per resource.
+type TaggingRepository struct {
+	ctx    context.Context
+	client *v3.Client
+}
+
+func NewTaggingRepository(ctx context.Context, client *v3.Client) *TaggingRepository {
+	repo := &TaggingRepository{
+		ctx:    ctx,
+		client: client,
+	}
+
+	return repo
+}
+
+func (r *TaggingRepository) taggingClient() *awsrgt.Client {
+	return resourcegroupstaggingapi.GetClient(r.client)
+}
+
+func (r *TaggingRepository) GetRegion() ptypes.AwsRegion {
+	return r.client.GetRegion()
+}
+
+func (r *TaggingRepository) GetAccountID() ptypes.AwsAccountID {
+	return r.client.GetAccountID()
+}
+
+func (r *TaggingRepository) promLabels(method string, resourceType cfg.ResourceType) prometheus.Labels {
+	return prometheus.Labels{
+		"account_id":    r.client.GetAccountID().String(),
+		"region":        r.client.GetRegion().String(),
+		"resource_type": ccfg.ResourceTypeToString(resourceType),
+		"method":        method,
+	}
+}
+
+// ListResourcesAll returns every tagged resource in the account and region.
+// Resources that never had a tag are not reported by the API.
+func (r *TaggingRepository) ListResourcesAll() ([]TaggedResource, error) {
+	return r.ListResourcesByInput(&awsrgt.GetResourcesInput{})
+}
+
+// ListResourcesByType returns tagged resources of the given Tagging API types,
+// e.g. "lambda:function", "sqs", "elasticloadbalancing:loadbalancer".
+func (r *TaggingRepository) ListResourcesByType(resourceTypes []string) ([]TaggedResource, error) {
+	return r.ListResourcesByInput(&awsrgt.GetResourcesInput{ResourceTypeFilters: resourceTypes})
+}
+
+// ListResourcesByArns returns the tags of the given resources, 100 ARNs per
+// call.
+func (r *TaggingRepository) ListResourcesByArns(arns []string) ([]TaggedResource, error) {
+	var resources []TaggedResource
+
+	for _, chunk := range service.ChunkSliceString(arns, maxArnsPerRead) {
+		items, err := r.ListResourcesByInput(&awsrgt.GetResourcesInput{ResourceARNList: chunk})
+		resources = append(resources, items...)
+		if err != nil {
+			return resources, err
+		}
+	}
+
+	return resources, nil
+}
+
+func (r *TaggingRepository) ListResourcesByInput(query *awsrgt.GetResourcesInput) ([]TaggedResource, error) {
+	start := time.Now()
+	var resources []TaggedResource
+
+	p := awsrgt.NewGetResourcesPaginator(r.taggingClient(), query)
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetResources", ccfg.ResourceTypeTaggedResource)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetResources", ccfg.ResourceTypeTaggedResource)).Inc()
+			}
+
+			return resources, errors.New(err)
+		}
+
+		for _, mapping := range resp.ResourceTagMappingList {
+			resources = append(resources, NewTaggedResource(r.client, mapping))
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsApiResourcesFetched.
+			With(r.promLabels("GetResources", ccfg.ResourceTypeTaggedResource)).
+			Add(float64(len(resources)))
+
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListResourcesByInput", ccfg.ResourceTypeTaggedResource)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return resources, nil
+}
+
+// ListTagKeys returns every tag key in use in the account and region.
+func (r *TaggingRepository) ListTagKeys() ([]string, error) {
+	start := time.Now()
+	var keys []string
+
+	p := awsrgt.NewGetTagKeysPaginator(r.taggingClient(), &awsrgt.GetTagKeysInput{})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetTagKeys", ccfg.ResourceTypeTaggedResource)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetTagKeys", ccfg.ResourceTypeTaggedResource)).Inc()
+			}
+
+			return keys, errors.New(err)
+		}
+
+		keys = append(keys, resp.TagKeys...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListTagKeys", ccfg.ResourceTypeTaggedResource)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return keys, nil
+}
+
+// ListTagValues returns every value in use for a tag key.
+func (r *TaggingRepository) ListTagValues(key string) ([]string, error) {
+	start := time.Now()
+	var values []string
+
+	p := awsrgt.NewGetTagValuesPaginator(r.taggingClient(), &awsrgt.GetTagValuesInput{Key: aws.String(key)})
+	for p.HasMorePages() {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("GetTagValues", ccfg.ResourceTypeTaggedResource)).Inc()
+		}
+
+		resp, err := p.NextPage(r.ctx)
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("GetTagValues", ccfg.ResourceTypeTaggedResource)).Inc()
+			}
+
+			return values, errors.New(err)
+		}
+
+		values = append(values, resp.TagValues...)
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("ListTagValues", ccfg.ResourceTypeTaggedResource)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return values, nil
+}
+
+// TagResources adds or overwrites tags on the given resources, 20 ARNs per
+// call. The API reports failures per ARN; they are returned keyed by ARN,
+// while err is reserved for calls that failed as a whole.
+func (r *TaggingRepository) TagResources(arns []string, tags map[string]string) (map[string]types.FailureInfo, error) {
+	start := time.Now()
+	failed := map[string]types.FailureInfo{}
+
+	for _, chunk := range service.ChunkSliceString(arns, maxArnsPerWrite) {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("TagResources", ccfg.ResourceTypeTaggedResource)).Inc()
+		}
+
+		resp, err := r.taggingClient().TagResources(r.ctx, &awsrgt.TagResourcesInput{ResourceARNList: chunk, Tags: tags})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("TagResources", ccfg.ResourceTypeTaggedResource)).Inc()
+			}
+
+			return failed, errors.New(err)
+		}
+
+		for resourceArn, info := range resp.FailedResourcesMap {
+			failed[resourceArn] = info
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("TagResources", ccfg.ResourceTypeTaggedResource)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return failed, nil
+}
+
+// UntagResources removes tag keys from the given resources, 20 ARNs per call.
+// Failures are reported as for TagResources.
+func (r *TaggingRepository) UntagResources(arns []string, keys []string) (map[string]types.FailureInfo, error) {
+	start := time.Now()
+	failed := map[string]types.FailureInfo{}
+
+	for _, chunk := range service.ChunkSliceString(arns, maxArnsPerWrite) {
+		if metrics.AwsMetricsEnabled {
+			metrics.AwsApiRequests.With(r.promLabels("UntagResources", ccfg.ResourceTypeTaggedResource)).Inc()
+		}
+
+		resp, err := r.taggingClient().UntagResources(r.ctx, &awsrgt.UntagResourcesInput{ResourceARNList: chunk, TagKeys: keys})
+		if err != nil {
+			if metrics.AwsMetricsEnabled {
+				metrics.AwsApiRequestErrors.With(r.promLabels("UntagResources", ccfg.ResourceTypeTaggedResource)).Inc()
+			}
+
+			return failed, errors.New(err)
+		}
+
+		for resourceArn, info := range resp.FailedResourcesMap {
+			failed[resourceArn] = info
+		}
+	}
+
+	if metrics.AwsMetricsEnabled {
+		metrics.AwsRepoCallDuration.
+			With(r.promLabels("UntagResources", ccfg.ResourceTypeTaggedResource)).
+			Observe(time.Since(start).Seconds())
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import "context"
+
+type skipTagFetchKey struct{}
+
+// WithoutTagFetch marks ctx so repositories that fetch tags with one extra call
+// per resource (Lambda, SQS, SNS, DynamoDB, ELB, CloudWatch Logs, S3) list the
+// resources without tags. Use it when tags are filled in afterwards in bulk,
+// e.g. from the Resource Groups Tagging API.
+func WithoutTagFetch(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTagFetchKey{}, true)
+}
+
+// TagFetchSkipped reports whether ctx was marked with WithoutTagFetch.
+func TagFetchSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipTagFetchKey{}).(bool)
+	return skip
+}
+
+// TagSetter is implemented by entities whose tags come from a separate call.
+// WithTags returns a copy of the entity carrying the given tags.
+type TagSetter interface {
+	ResourceInterface
+	WithTags(tags map[string]string) ResourceInterface
+}